@@ -19,8 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	MetadataService_GetMetadata_FullMethodName = "/MetadataService/GetMetadata"
-	MetadataService_PutMetadata_FullMethodName = "/MetadataService/PutMetadata"
+	MetadataService_GetMetadata_FullMethodName       = "/MetadataService/GetMetadata"
+	MetadataService_BatchGetMetadata_FullMethodName  = "/MetadataService/BatchGetMetadata"
+	MetadataService_PutMetadata_FullMethodName       = "/MetadataService/PutMetadata"
+	MetadataService_UpdateMetadata_FullMethodName    = "/MetadataService/UpdateMetadata"
+	MetadataService_SearchMovies_FullMethodName      = "/MetadataService/SearchMovies"
+	MetadataService_SuggestTitles_FullMethodName     = "/MetadataService/SuggestTitles"
+	MetadataService_PutTranslation_FullMethodName    = "/MetadataService/PutTranslation"
+	MetadataService_DeleteTranslation_FullMethodName = "/MetadataService/DeleteTranslation"
+	MetadataService_ListTranslations_FullMethodName  = "/MetadataService/ListTranslations"
+	MetadataService_ListChildren_FullMethodName      = "/MetadataService/ListChildren"
+	MetadataService_ListSimilarMovies_FullMethodName = "/MetadataService/ListSimilarMovies"
 )
 
 // MetadataServiceClient is the client API for MetadataService service.
@@ -28,7 +37,16 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MetadataServiceClient interface {
 	GetMetadata(ctx context.Context, in *GetMetadataRequest, opts ...grpc.CallOption) (*GetMetadataResponse, error)
+	BatchGetMetadata(ctx context.Context, in *BatchGetMetadataRequest, opts ...grpc.CallOption) (*BatchGetMetadataResponse, error)
 	PutMetadata(ctx context.Context, in *PutMetadataRequest, opts ...grpc.CallOption) (*PutMetadataResponse, error)
+	UpdateMetadata(ctx context.Context, in *UpdateMetadataRequest, opts ...grpc.CallOption) (*UpdateMetadataResponse, error)
+	SearchMovies(ctx context.Context, in *SearchMoviesRequest, opts ...grpc.CallOption) (*SearchMoviesResponse, error)
+	SuggestTitles(ctx context.Context, in *SuggestTitlesRequest, opts ...grpc.CallOption) (*SuggestTitlesResponse, error)
+	PutTranslation(ctx context.Context, in *PutTranslationRequest, opts ...grpc.CallOption) (*PutTranslationResponse, error)
+	DeleteTranslation(ctx context.Context, in *DeleteTranslationRequest, opts ...grpc.CallOption) (*DeleteTranslationResponse, error)
+	ListTranslations(ctx context.Context, in *ListTranslationsRequest, opts ...grpc.CallOption) (*ListTranslationsResponse, error)
+	ListChildren(ctx context.Context, in *ListChildrenRequest, opts ...grpc.CallOption) (*ListChildrenResponse, error)
+	ListSimilarMovies(ctx context.Context, in *ListSimilarMoviesRequest, opts ...grpc.CallOption) (*ListSimilarMoviesResponse, error)
 }
 
 type metadataServiceClient struct {
@@ -48,6 +66,15 @@ func (c *metadataServiceClient) GetMetadata(ctx context.Context, in *GetMetadata
 	return out, nil
 }
 
+func (c *metadataServiceClient) BatchGetMetadata(ctx context.Context, in *BatchGetMetadataRequest, opts ...grpc.CallOption) (*BatchGetMetadataResponse, error) {
+	out := new(BatchGetMetadataResponse)
+	err := c.cc.Invoke(ctx, MetadataService_BatchGetMetadata_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *metadataServiceClient) PutMetadata(ctx context.Context, in *PutMetadataRequest, opts ...grpc.CallOption) (*PutMetadataResponse, error) {
 	out := new(PutMetadataResponse)
 	err := c.cc.Invoke(ctx, MetadataService_PutMetadata_FullMethodName, in, out, opts...)
@@ -57,88 +84,1479 @@ func (c *metadataServiceClient) PutMetadata(ctx context.Context, in *PutMetadata
 	return out, nil
 }
 
-// MetadataServiceServer is the server API for MetadataService service.
-// All implementations must embed UnimplementedMetadataServiceServer
+func (c *metadataServiceClient) UpdateMetadata(ctx context.Context, in *UpdateMetadataRequest, opts ...grpc.CallOption) (*UpdateMetadataResponse, error) {
+	out := new(UpdateMetadataResponse)
+	err := c.cc.Invoke(ctx, MetadataService_UpdateMetadata_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataServiceClient) SearchMovies(ctx context.Context, in *SearchMoviesRequest, opts ...grpc.CallOption) (*SearchMoviesResponse, error) {
+	out := new(SearchMoviesResponse)
+	err := c.cc.Invoke(ctx, MetadataService_SearchMovies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataServiceClient) SuggestTitles(ctx context.Context, in *SuggestTitlesRequest, opts ...grpc.CallOption) (*SuggestTitlesResponse, error) {
+	out := new(SuggestTitlesResponse)
+	err := c.cc.Invoke(ctx, MetadataService_SuggestTitles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataServiceClient) PutTranslation(ctx context.Context, in *PutTranslationRequest, opts ...grpc.CallOption) (*PutTranslationResponse, error) {
+	out := new(PutTranslationResponse)
+	err := c.cc.Invoke(ctx, MetadataService_PutTranslation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataServiceClient) DeleteTranslation(ctx context.Context, in *DeleteTranslationRequest, opts ...grpc.CallOption) (*DeleteTranslationResponse, error) {
+	out := new(DeleteTranslationResponse)
+	err := c.cc.Invoke(ctx, MetadataService_DeleteTranslation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataServiceClient) ListTranslations(ctx context.Context, in *ListTranslationsRequest, opts ...grpc.CallOption) (*ListTranslationsResponse, error) {
+	out := new(ListTranslationsResponse)
+	err := c.cc.Invoke(ctx, MetadataService_ListTranslations_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataServiceClient) ListChildren(ctx context.Context, in *ListChildrenRequest, opts ...grpc.CallOption) (*ListChildrenResponse, error) {
+	out := new(ListChildrenResponse)
+	err := c.cc.Invoke(ctx, MetadataService_ListChildren_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metadataServiceClient) ListSimilarMovies(ctx context.Context, in *ListSimilarMoviesRequest, opts ...grpc.CallOption) (*ListSimilarMoviesResponse, error) {
+	out := new(ListSimilarMoviesResponse)
+	err := c.cc.Invoke(ctx, MetadataService_ListSimilarMovies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MetadataServiceServer is the server API for MetadataService service.
+// All implementations must embed UnimplementedMetadataServiceServer
+// for forward compatibility
+type MetadataServiceServer interface {
+	GetMetadata(context.Context, *GetMetadataRequest) (*GetMetadataResponse, error)
+	BatchGetMetadata(context.Context, *BatchGetMetadataRequest) (*BatchGetMetadataResponse, error)
+	PutMetadata(context.Context, *PutMetadataRequest) (*PutMetadataResponse, error)
+	UpdateMetadata(context.Context, *UpdateMetadataRequest) (*UpdateMetadataResponse, error)
+	SearchMovies(context.Context, *SearchMoviesRequest) (*SearchMoviesResponse, error)
+	SuggestTitles(context.Context, *SuggestTitlesRequest) (*SuggestTitlesResponse, error)
+	PutTranslation(context.Context, *PutTranslationRequest) (*PutTranslationResponse, error)
+	DeleteTranslation(context.Context, *DeleteTranslationRequest) (*DeleteTranslationResponse, error)
+	ListTranslations(context.Context, *ListTranslationsRequest) (*ListTranslationsResponse, error)
+	ListChildren(context.Context, *ListChildrenRequest) (*ListChildrenResponse, error)
+	ListSimilarMovies(context.Context, *ListSimilarMoviesRequest) (*ListSimilarMoviesResponse, error)
+	mustEmbedUnimplementedMetadataServiceServer()
+}
+
+// UnimplementedMetadataServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMetadataServiceServer struct {
+}
+
+func (UnimplementedMetadataServiceServer) GetMetadata(context.Context, *GetMetadataRequest) (*GetMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
+}
+func (UnimplementedMetadataServiceServer) BatchGetMetadata(context.Context, *BatchGetMetadataRequest) (*BatchGetMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetMetadata not implemented")
+}
+func (UnimplementedMetadataServiceServer) PutMetadata(context.Context, *PutMetadataRequest) (*PutMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutMetadata not implemented")
+}
+func (UnimplementedMetadataServiceServer) UpdateMetadata(context.Context, *UpdateMetadataRequest) (*UpdateMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMetadata not implemented")
+}
+func (UnimplementedMetadataServiceServer) SearchMovies(context.Context, *SearchMoviesRequest) (*SearchMoviesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMovies not implemented")
+}
+func (UnimplementedMetadataServiceServer) SuggestTitles(context.Context, *SuggestTitlesRequest) (*SuggestTitlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuggestTitles not implemented")
+}
+func (UnimplementedMetadataServiceServer) PutTranslation(context.Context, *PutTranslationRequest) (*PutTranslationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutTranslation not implemented")
+}
+func (UnimplementedMetadataServiceServer) DeleteTranslation(context.Context, *DeleteTranslationRequest) (*DeleteTranslationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTranslation not implemented")
+}
+func (UnimplementedMetadataServiceServer) ListTranslations(context.Context, *ListTranslationsRequest) (*ListTranslationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTranslations not implemented")
+}
+func (UnimplementedMetadataServiceServer) ListChildren(context.Context, *ListChildrenRequest) (*ListChildrenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChildren not implemented")
+}
+func (UnimplementedMetadataServiceServer) ListSimilarMovies(context.Context, *ListSimilarMoviesRequest) (*ListSimilarMoviesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSimilarMovies not implemented")
+}
+func (UnimplementedMetadataServiceServer) mustEmbedUnimplementedMetadataServiceServer() {}
+
+// UnsafeMetadataServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MetadataServiceServer will
+// result in compilation errors.
+type UnsafeMetadataServiceServer interface {
+	mustEmbedUnimplementedMetadataServiceServer()
+}
+
+func RegisterMetadataServiceServer(s grpc.ServiceRegistrar, srv MetadataServiceServer) {
+	s.RegisterService(&MetadataService_ServiceDesc, srv)
+}
+
+func _MetadataService_GetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).GetMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_GetMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).GetMetadata(ctx, req.(*GetMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_BatchGetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).BatchGetMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_BatchGetMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).BatchGetMetadata(ctx, req.(*BatchGetMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_PutMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).PutMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_PutMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).PutMetadata(ctx, req.(*PutMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_UpdateMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).UpdateMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_UpdateMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).UpdateMetadata(ctx, req.(*UpdateMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_SearchMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).SearchMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_SearchMovies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).SearchMovies(ctx, req.(*SearchMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_SuggestTitles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestTitlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).SuggestTitles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_SuggestTitles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).SuggestTitles(ctx, req.(*SuggestTitlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_PutTranslation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutTranslationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).PutTranslation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_PutTranslation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).PutTranslation(ctx, req.(*PutTranslationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_DeleteTranslation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTranslationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).DeleteTranslation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_DeleteTranslation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).DeleteTranslation(ctx, req.(*DeleteTranslationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_ListTranslations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTranslationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).ListTranslations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_ListTranslations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).ListTranslations(ctx, req.(*ListTranslationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_ListChildren_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChildrenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).ListChildren(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_ListChildren_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).ListChildren(ctx, req.(*ListChildrenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetadataService_ListSimilarMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSimilarMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetadataServiceServer).ListSimilarMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetadataService_ListSimilarMovies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetadataServiceServer).ListSimilarMovies(ctx, req.(*ListSimilarMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MetadataService_ServiceDesc is the grpc.ServiceDesc for MetadataService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MetadataService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "MetadataService",
+	HandlerType: (*MetadataServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetMetadata",
+			Handler:    _MetadataService_GetMetadata_Handler,
+		},
+		{
+			MethodName: "BatchGetMetadata",
+			Handler:    _MetadataService_BatchGetMetadata_Handler,
+		},
+		{
+			MethodName: "PutMetadata",
+			Handler:    _MetadataService_PutMetadata_Handler,
+		},
+		{
+			MethodName: "UpdateMetadata",
+			Handler:    _MetadataService_UpdateMetadata_Handler,
+		},
+		{
+			MethodName: "SearchMovies",
+			Handler:    _MetadataService_SearchMovies_Handler,
+		},
+		{
+			MethodName: "SuggestTitles",
+			Handler:    _MetadataService_SuggestTitles_Handler,
+		},
+		{
+			MethodName: "PutTranslation",
+			Handler:    _MetadataService_PutTranslation_Handler,
+		},
+		{
+			MethodName: "DeleteTranslation",
+			Handler:    _MetadataService_DeleteTranslation_Handler,
+		},
+		{
+			MethodName: "ListTranslations",
+			Handler:    _MetadataService_ListTranslations_Handler,
+		},
+		{
+			MethodName: "ListChildren",
+			Handler:    _MetadataService_ListChildren_Handler,
+		},
+		{
+			MethodName: "ListSimilarMovies",
+			Handler:    _MetadataService_ListSimilarMovies_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "movie.proto",
+}
+
+const (
+	PeopleService_GetPerson_FullMethodName           = "/PeopleService/GetPerson"
+	PeopleService_PutPerson_FullMethodName           = "/PeopleService/PutPerson"
+	PeopleService_DeletePerson_FullMethodName        = "/PeopleService/DeletePerson"
+	PeopleService_PutCredit_FullMethodName           = "/PeopleService/PutCredit"
+	PeopleService_DeleteCredit_FullMethodName        = "/PeopleService/DeleteCredit"
+	PeopleService_ListCreditsForMovie_FullMethodName = "/PeopleService/ListCreditsForMovie"
+	PeopleService_ListMoviesForPerson_FullMethodName = "/PeopleService/ListMoviesForPerson"
+)
+
+// PeopleServiceClient is the client API for PeopleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PeopleServiceClient interface {
+	GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*GetPersonResponse, error)
+	PutPerson(ctx context.Context, in *PutPersonRequest, opts ...grpc.CallOption) (*PutPersonResponse, error)
+	DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*DeletePersonResponse, error)
+	PutCredit(ctx context.Context, in *PutCreditRequest, opts ...grpc.CallOption) (*PutCreditResponse, error)
+	DeleteCredit(ctx context.Context, in *DeleteCreditRequest, opts ...grpc.CallOption) (*DeleteCreditResponse, error)
+	ListCreditsForMovie(ctx context.Context, in *ListCreditsForMovieRequest, opts ...grpc.CallOption) (*ListCreditsForMovieResponse, error)
+	ListMoviesForPerson(ctx context.Context, in *ListMoviesForPersonRequest, opts ...grpc.CallOption) (*ListMoviesForPersonResponse, error)
+}
+
+type peopleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPeopleServiceClient(cc grpc.ClientConnInterface) PeopleServiceClient {
+	return &peopleServiceClient{cc}
+}
+
+func (c *peopleServiceClient) GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*GetPersonResponse, error) {
+	out := new(GetPersonResponse)
+	err := c.cc.Invoke(ctx, PeopleService_GetPerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) PutPerson(ctx context.Context, in *PutPersonRequest, opts ...grpc.CallOption) (*PutPersonResponse, error) {
+	out := new(PutPersonResponse)
+	err := c.cc.Invoke(ctx, PeopleService_PutPerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*DeletePersonResponse, error) {
+	out := new(DeletePersonResponse)
+	err := c.cc.Invoke(ctx, PeopleService_DeletePerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) PutCredit(ctx context.Context, in *PutCreditRequest, opts ...grpc.CallOption) (*PutCreditResponse, error) {
+	out := new(PutCreditResponse)
+	err := c.cc.Invoke(ctx, PeopleService_PutCredit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) DeleteCredit(ctx context.Context, in *DeleteCreditRequest, opts ...grpc.CallOption) (*DeleteCreditResponse, error) {
+	out := new(DeleteCreditResponse)
+	err := c.cc.Invoke(ctx, PeopleService_DeleteCredit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) ListCreditsForMovie(ctx context.Context, in *ListCreditsForMovieRequest, opts ...grpc.CallOption) (*ListCreditsForMovieResponse, error) {
+	out := new(ListCreditsForMovieResponse)
+	err := c.cc.Invoke(ctx, PeopleService_ListCreditsForMovie_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) ListMoviesForPerson(ctx context.Context, in *ListMoviesForPersonRequest, opts ...grpc.CallOption) (*ListMoviesForPersonResponse, error) {
+	out := new(ListMoviesForPersonResponse)
+	err := c.cc.Invoke(ctx, PeopleService_ListMoviesForPerson_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PeopleServiceServer is the server API for PeopleService service.
+// All implementations must embed UnimplementedPeopleServiceServer
+// for forward compatibility
+type PeopleServiceServer interface {
+	GetPerson(context.Context, *GetPersonRequest) (*GetPersonResponse, error)
+	PutPerson(context.Context, *PutPersonRequest) (*PutPersonResponse, error)
+	DeletePerson(context.Context, *DeletePersonRequest) (*DeletePersonResponse, error)
+	PutCredit(context.Context, *PutCreditRequest) (*PutCreditResponse, error)
+	DeleteCredit(context.Context, *DeleteCreditRequest) (*DeleteCreditResponse, error)
+	ListCreditsForMovie(context.Context, *ListCreditsForMovieRequest) (*ListCreditsForMovieResponse, error)
+	ListMoviesForPerson(context.Context, *ListMoviesForPersonRequest) (*ListMoviesForPersonResponse, error)
+	mustEmbedUnimplementedPeopleServiceServer()
+}
+
+// UnimplementedPeopleServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPeopleServiceServer struct {
+}
+
+func (UnimplementedPeopleServiceServer) GetPerson(context.Context, *GetPersonRequest) (*GetPersonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerson not implemented")
+}
+func (UnimplementedPeopleServiceServer) PutPerson(context.Context, *PutPersonRequest) (*PutPersonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutPerson not implemented")
+}
+func (UnimplementedPeopleServiceServer) DeletePerson(context.Context, *DeletePersonRequest) (*DeletePersonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePerson not implemented")
+}
+func (UnimplementedPeopleServiceServer) PutCredit(context.Context, *PutCreditRequest) (*PutCreditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutCredit not implemented")
+}
+func (UnimplementedPeopleServiceServer) DeleteCredit(context.Context, *DeleteCreditRequest) (*DeleteCreditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCredit not implemented")
+}
+func (UnimplementedPeopleServiceServer) ListCreditsForMovie(context.Context, *ListCreditsForMovieRequest) (*ListCreditsForMovieResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCreditsForMovie not implemented")
+}
+func (UnimplementedPeopleServiceServer) ListMoviesForPerson(context.Context, *ListMoviesForPersonRequest) (*ListMoviesForPersonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMoviesForPerson not implemented")
+}
+func (UnimplementedPeopleServiceServer) mustEmbedUnimplementedPeopleServiceServer() {}
+
+// UnsafePeopleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PeopleServiceServer will
+// result in compilation errors.
+type UnsafePeopleServiceServer interface {
+	mustEmbedUnimplementedPeopleServiceServer()
+}
+
+func RegisterPeopleServiceServer(s grpc.ServiceRegistrar, srv PeopleServiceServer) {
+	s.RegisterService(&PeopleService_ServiceDesc, srv)
+}
+
+func _PeopleService_GetPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).GetPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_GetPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).GetPerson(ctx, req.(*GetPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_PutPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).PutPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_PutPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).PutPerson(ctx, req.(*PutPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_DeletePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).DeletePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_DeletePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).DeletePerson(ctx, req.(*DeletePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_PutCredit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutCreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).PutCredit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_PutCredit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).PutCredit(ctx, req.(*PutCreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_DeleteCredit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).DeleteCredit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_DeleteCredit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).DeleteCredit(ctx, req.(*DeleteCreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_ListCreditsForMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCreditsForMovieRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).ListCreditsForMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_ListCreditsForMovie_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).ListCreditsForMovie(ctx, req.(*ListCreditsForMovieRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_ListMoviesForPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMoviesForPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).ListMoviesForPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_ListMoviesForPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).ListMoviesForPerson(ctx, req.(*ListMoviesForPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PeopleService_ServiceDesc is the grpc.ServiceDesc for PeopleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PeopleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "PeopleService",
+	HandlerType: (*PeopleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPerson",
+			Handler:    _PeopleService_GetPerson_Handler,
+		},
+		{
+			MethodName: "PutPerson",
+			Handler:    _PeopleService_PutPerson_Handler,
+		},
+		{
+			MethodName: "DeletePerson",
+			Handler:    _PeopleService_DeletePerson_Handler,
+		},
+		{
+			MethodName: "PutCredit",
+			Handler:    _PeopleService_PutCredit_Handler,
+		},
+		{
+			MethodName: "DeleteCredit",
+			Handler:    _PeopleService_DeleteCredit_Handler,
+		},
+		{
+			MethodName: "ListCreditsForMovie",
+			Handler:    _PeopleService_ListCreditsForMovie_Handler,
+		},
+		{
+			MethodName: "ListMoviesForPerson",
+			Handler:    _PeopleService_ListMoviesForPerson_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "movie.proto",
+}
+
+const (
+	RatingService_GetAggregatedRating_FullMethodName       = "/RatingService/GetAggregatedRating"
+	RatingService_BatchGetAggregatedRatings_FullMethodName = "/RatingService/BatchGetAggregatedRatings"
+	RatingService_PutRating_FullMethodName                 = "/RatingService/PutRating"
+	RatingService_GetRating_FullMethodName                 = "/RatingService/GetRating"
+	RatingService_BatchGetRatings_FullMethodName           = "/RatingService/BatchGetRatings"
+	RatingService_DeleteRating_FullMethodName              = "/RatingService/DeleteRating"
+	RatingService_GetRatingStats_FullMethodName            = "/RatingService/GetRatingStats"
+	RatingService_ListTrending_FullMethodName              = "/RatingService/ListTrending"
+	RatingService_ListTopRated_FullMethodName              = "/RatingService/ListTopRated"
+	RatingService_PutReview_FullMethodName                 = "/RatingService/PutReview"
+	RatingService_GetReview_FullMethodName                 = "/RatingService/GetReview"
+	RatingService_ListReviews_FullMethodName               = "/RatingService/ListReviews"
+	RatingService_VoteReview_FullMethodName                = "/RatingService/VoteReview"
+	RatingService_DeleteReviewVote_FullMethodName          = "/RatingService/DeleteReviewVote"
+	RatingService_ReportReview_FullMethodName              = "/RatingService/ReportReview"
+)
+
+// RatingServiceClient is the client API for RatingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RatingServiceClient interface {
+	GetAggregatedRating(ctx context.Context, in *GetAggregatedRatingRequest, opts ...grpc.CallOption) (*GetAggregatedRatingResponse, error)
+	BatchGetAggregatedRatings(ctx context.Context, in *BatchGetAggregatedRatingsRequest, opts ...grpc.CallOption) (*BatchGetAggregatedRatingsResponse, error)
+	PutRating(ctx context.Context, in *PutRatingRequest, opts ...grpc.CallOption) (*PutRatingResponse, error)
+	GetRating(ctx context.Context, in *GetRatingRequest, opts ...grpc.CallOption) (*GetRatingResponse, error)
+	BatchGetRatings(ctx context.Context, in *BatchGetRatingsRequest, opts ...grpc.CallOption) (*BatchGetRatingsResponse, error)
+	DeleteRating(ctx context.Context, in *DeleteRatingRequest, opts ...grpc.CallOption) (*DeleteRatingResponse, error)
+	GetRatingStats(ctx context.Context, in *GetRatingStatsRequest, opts ...grpc.CallOption) (*GetRatingStatsResponse, error)
+	ListTrending(ctx context.Context, in *ListTrendingRequest, opts ...grpc.CallOption) (*ListTrendingResponse, error)
+	ListTopRated(ctx context.Context, in *ListTopRatedRequest, opts ...grpc.CallOption) (*ListTopRatedResponse, error)
+	PutReview(ctx context.Context, in *PutReviewRequest, opts ...grpc.CallOption) (*PutReviewResponse, error)
+	GetReview(ctx context.Context, in *GetReviewRequest, opts ...grpc.CallOption) (*GetReviewResponse, error)
+	ListReviews(ctx context.Context, in *ListReviewsRequest, opts ...grpc.CallOption) (*ListReviewsResponse, error)
+	VoteReview(ctx context.Context, in *VoteReviewRequest, opts ...grpc.CallOption) (*VoteReviewResponse, error)
+	DeleteReviewVote(ctx context.Context, in *DeleteReviewVoteRequest, opts ...grpc.CallOption) (*DeleteReviewVoteResponse, error)
+	ReportReview(ctx context.Context, in *ReportReviewRequest, opts ...grpc.CallOption) (*ReportReviewResponse, error)
+}
+
+type ratingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRatingServiceClient(cc grpc.ClientConnInterface) RatingServiceClient {
+	return &ratingServiceClient{cc}
+}
+
+func (c *ratingServiceClient) GetAggregatedRating(ctx context.Context, in *GetAggregatedRatingRequest, opts ...grpc.CallOption) (*GetAggregatedRatingResponse, error) {
+	out := new(GetAggregatedRatingResponse)
+	err := c.cc.Invoke(ctx, RatingService_GetAggregatedRating_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) BatchGetAggregatedRatings(ctx context.Context, in *BatchGetAggregatedRatingsRequest, opts ...grpc.CallOption) (*BatchGetAggregatedRatingsResponse, error) {
+	out := new(BatchGetAggregatedRatingsResponse)
+	err := c.cc.Invoke(ctx, RatingService_BatchGetAggregatedRatings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) PutRating(ctx context.Context, in *PutRatingRequest, opts ...grpc.CallOption) (*PutRatingResponse, error) {
+	out := new(PutRatingResponse)
+	err := c.cc.Invoke(ctx, RatingService_PutRating_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) GetRating(ctx context.Context, in *GetRatingRequest, opts ...grpc.CallOption) (*GetRatingResponse, error) {
+	out := new(GetRatingResponse)
+	err := c.cc.Invoke(ctx, RatingService_GetRating_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) BatchGetRatings(ctx context.Context, in *BatchGetRatingsRequest, opts ...grpc.CallOption) (*BatchGetRatingsResponse, error) {
+	out := new(BatchGetRatingsResponse)
+	err := c.cc.Invoke(ctx, RatingService_BatchGetRatings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) DeleteRating(ctx context.Context, in *DeleteRatingRequest, opts ...grpc.CallOption) (*DeleteRatingResponse, error) {
+	out := new(DeleteRatingResponse)
+	err := c.cc.Invoke(ctx, RatingService_DeleteRating_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) GetRatingStats(ctx context.Context, in *GetRatingStatsRequest, opts ...grpc.CallOption) (*GetRatingStatsResponse, error) {
+	out := new(GetRatingStatsResponse)
+	err := c.cc.Invoke(ctx, RatingService_GetRatingStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) ListTrending(ctx context.Context, in *ListTrendingRequest, opts ...grpc.CallOption) (*ListTrendingResponse, error) {
+	out := new(ListTrendingResponse)
+	err := c.cc.Invoke(ctx, RatingService_ListTrending_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) ListTopRated(ctx context.Context, in *ListTopRatedRequest, opts ...grpc.CallOption) (*ListTopRatedResponse, error) {
+	out := new(ListTopRatedResponse)
+	err := c.cc.Invoke(ctx, RatingService_ListTopRated_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) PutReview(ctx context.Context, in *PutReviewRequest, opts ...grpc.CallOption) (*PutReviewResponse, error) {
+	out := new(PutReviewResponse)
+	err := c.cc.Invoke(ctx, RatingService_PutReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) GetReview(ctx context.Context, in *GetReviewRequest, opts ...grpc.CallOption) (*GetReviewResponse, error) {
+	out := new(GetReviewResponse)
+	err := c.cc.Invoke(ctx, RatingService_GetReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) ListReviews(ctx context.Context, in *ListReviewsRequest, opts ...grpc.CallOption) (*ListReviewsResponse, error) {
+	out := new(ListReviewsResponse)
+	err := c.cc.Invoke(ctx, RatingService_ListReviews_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) VoteReview(ctx context.Context, in *VoteReviewRequest, opts ...grpc.CallOption) (*VoteReviewResponse, error) {
+	out := new(VoteReviewResponse)
+	err := c.cc.Invoke(ctx, RatingService_VoteReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) DeleteReviewVote(ctx context.Context, in *DeleteReviewVoteRequest, opts ...grpc.CallOption) (*DeleteReviewVoteResponse, error) {
+	out := new(DeleteReviewVoteResponse)
+	err := c.cc.Invoke(ctx, RatingService_DeleteReviewVote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) ReportReview(ctx context.Context, in *ReportReviewRequest, opts ...grpc.CallOption) (*ReportReviewResponse, error) {
+	out := new(ReportReviewResponse)
+	err := c.cc.Invoke(ctx, RatingService_ReportReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RatingServiceServer is the server API for RatingService service.
+// All implementations must embed UnimplementedRatingServiceServer
+// for forward compatibility
+type RatingServiceServer interface {
+	GetAggregatedRating(context.Context, *GetAggregatedRatingRequest) (*GetAggregatedRatingResponse, error)
+	BatchGetAggregatedRatings(context.Context, *BatchGetAggregatedRatingsRequest) (*BatchGetAggregatedRatingsResponse, error)
+	PutRating(context.Context, *PutRatingRequest) (*PutRatingResponse, error)
+	GetRating(context.Context, *GetRatingRequest) (*GetRatingResponse, error)
+	BatchGetRatings(context.Context, *BatchGetRatingsRequest) (*BatchGetRatingsResponse, error)
+	DeleteRating(context.Context, *DeleteRatingRequest) (*DeleteRatingResponse, error)
+	GetRatingStats(context.Context, *GetRatingStatsRequest) (*GetRatingStatsResponse, error)
+	ListTrending(context.Context, *ListTrendingRequest) (*ListTrendingResponse, error)
+	ListTopRated(context.Context, *ListTopRatedRequest) (*ListTopRatedResponse, error)
+	PutReview(context.Context, *PutReviewRequest) (*PutReviewResponse, error)
+	GetReview(context.Context, *GetReviewRequest) (*GetReviewResponse, error)
+	ListReviews(context.Context, *ListReviewsRequest) (*ListReviewsResponse, error)
+	VoteReview(context.Context, *VoteReviewRequest) (*VoteReviewResponse, error)
+	DeleteReviewVote(context.Context, *DeleteReviewVoteRequest) (*DeleteReviewVoteResponse, error)
+	ReportReview(context.Context, *ReportReviewRequest) (*ReportReviewResponse, error)
+	mustEmbedUnimplementedRatingServiceServer()
+}
+
+// UnimplementedRatingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRatingServiceServer struct {
+}
+
+func (UnimplementedRatingServiceServer) GetAggregatedRating(context.Context, *GetAggregatedRatingRequest) (*GetAggregatedRatingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAggregatedRating not implemented")
+}
+func (UnimplementedRatingServiceServer) BatchGetAggregatedRatings(context.Context, *BatchGetAggregatedRatingsRequest) (*BatchGetAggregatedRatingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetAggregatedRatings not implemented")
+}
+func (UnimplementedRatingServiceServer) PutRating(context.Context, *PutRatingRequest) (*PutRatingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutRating not implemented")
+}
+func (UnimplementedRatingServiceServer) GetRating(context.Context, *GetRatingRequest) (*GetRatingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRating not implemented")
+}
+func (UnimplementedRatingServiceServer) BatchGetRatings(context.Context, *BatchGetRatingsRequest) (*BatchGetRatingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetRatings not implemented")
+}
+func (UnimplementedRatingServiceServer) DeleteRating(context.Context, *DeleteRatingRequest) (*DeleteRatingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRating not implemented")
+}
+func (UnimplementedRatingServiceServer) GetRatingStats(context.Context, *GetRatingStatsRequest) (*GetRatingStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRatingStats not implemented")
+}
+func (UnimplementedRatingServiceServer) ListTrending(context.Context, *ListTrendingRequest) (*ListTrendingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrending not implemented")
+}
+func (UnimplementedRatingServiceServer) ListTopRated(context.Context, *ListTopRatedRequest) (*ListTopRatedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTopRated not implemented")
+}
+func (UnimplementedRatingServiceServer) PutReview(context.Context, *PutReviewRequest) (*PutReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutReview not implemented")
+}
+func (UnimplementedRatingServiceServer) GetReview(context.Context, *GetReviewRequest) (*GetReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReview not implemented")
+}
+func (UnimplementedRatingServiceServer) ListReviews(context.Context, *ListReviewsRequest) (*ListReviewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReviews not implemented")
+}
+func (UnimplementedRatingServiceServer) VoteReview(context.Context, *VoteReviewRequest) (*VoteReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VoteReview not implemented")
+}
+func (UnimplementedRatingServiceServer) DeleteReviewVote(context.Context, *DeleteReviewVoteRequest) (*DeleteReviewVoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReviewVote not implemented")
+}
+func (UnimplementedRatingServiceServer) ReportReview(context.Context, *ReportReviewRequest) (*ReportReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportReview not implemented")
+}
+func (UnimplementedRatingServiceServer) mustEmbedUnimplementedRatingServiceServer() {}
+
+// UnsafeRatingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RatingServiceServer will
+// result in compilation errors.
+type UnsafeRatingServiceServer interface {
+	mustEmbedUnimplementedRatingServiceServer()
+}
+
+func RegisterRatingServiceServer(s grpc.ServiceRegistrar, srv RatingServiceServer) {
+	s.RegisterService(&RatingService_ServiceDesc, srv)
+}
+
+func _RatingService_GetAggregatedRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAggregatedRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetAggregatedRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_GetAggregatedRating_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetAggregatedRating(ctx, req.(*GetAggregatedRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_BatchGetAggregatedRatings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetAggregatedRatingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).BatchGetAggregatedRatings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_BatchGetAggregatedRatings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).BatchGetAggregatedRatings(ctx, req.(*BatchGetAggregatedRatingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_PutRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).PutRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_PutRating_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).PutRating(ctx, req.(*PutRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_GetRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_GetRating_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetRating(ctx, req.(*GetRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_BatchGetRatings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetRatingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).BatchGetRatings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_BatchGetRatings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).BatchGetRatings(ctx, req.(*BatchGetRatingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_DeleteRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).DeleteRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_DeleteRating_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).DeleteRating(ctx, req.(*DeleteRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_GetRatingStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRatingStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetRatingStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_GetRatingStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetRatingStats(ctx, req.(*GetRatingStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_ListTrending_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrendingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).ListTrending(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_ListTrending_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).ListTrending(ctx, req.(*ListTrendingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_ListTopRated_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTopRatedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).ListTopRated(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_ListTopRated_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).ListTopRated(ctx, req.(*ListTopRatedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_PutReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).PutReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_PutReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).PutReview(ctx, req.(*PutReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_GetReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_GetReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetReview(ctx, req.(*GetReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_ListReviews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReviewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).ListReviews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_ListReviews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).ListReviews(ctx, req.(*ListReviewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_VoteReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoteReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).VoteReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_VoteReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).VoteReview(ctx, req.(*VoteReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_DeleteReviewVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReviewVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).DeleteReviewVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_DeleteReviewVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).DeleteReviewVote(ctx, req.(*DeleteReviewVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_ReportReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).ReportReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingService_ReportReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).ReportReview(ctx, req.(*ReportReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RatingService_ServiceDesc is the grpc.ServiceDesc for RatingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RatingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "RatingService",
+	HandlerType: (*RatingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAggregatedRating",
+			Handler:    _RatingService_GetAggregatedRating_Handler,
+		},
+		{
+			MethodName: "BatchGetAggregatedRatings",
+			Handler:    _RatingService_BatchGetAggregatedRatings_Handler,
+		},
+		{
+			MethodName: "PutRating",
+			Handler:    _RatingService_PutRating_Handler,
+		},
+		{
+			MethodName: "GetRating",
+			Handler:    _RatingService_GetRating_Handler,
+		},
+		{
+			MethodName: "BatchGetRatings",
+			Handler:    _RatingService_BatchGetRatings_Handler,
+		},
+		{
+			MethodName: "DeleteRating",
+			Handler:    _RatingService_DeleteRating_Handler,
+		},
+		{
+			MethodName: "GetRatingStats",
+			Handler:    _RatingService_GetRatingStats_Handler,
+		},
+		{
+			MethodName: "ListTrending",
+			Handler:    _RatingService_ListTrending_Handler,
+		},
+		{
+			MethodName: "ListTopRated",
+			Handler:    _RatingService_ListTopRated_Handler,
+		},
+		{
+			MethodName: "PutReview",
+			Handler:    _RatingService_PutReview_Handler,
+		},
+		{
+			MethodName: "GetReview",
+			Handler:    _RatingService_GetReview_Handler,
+		},
+		{
+			MethodName: "ListReviews",
+			Handler:    _RatingService_ListReviews_Handler,
+		},
+		{
+			MethodName: "VoteReview",
+			Handler:    _RatingService_VoteReview_Handler,
+		},
+		{
+			MethodName: "DeleteReviewVote",
+			Handler:    _RatingService_DeleteReviewVote_Handler,
+		},
+		{
+			MethodName: "ReportReview",
+			Handler:    _RatingService_ReportReview_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "movie.proto",
+}
+
+const (
+	RatingModerationService_ListFlaggedItems_FullMethodName      = "/RatingModerationService/ListFlaggedItems"
+	RatingModerationService_SetModerationStatus_FullMethodName   = "/RatingModerationService/SetModerationStatus"
+	RatingModerationService_ListModerationActions_FullMethodName = "/RatingModerationService/ListModerationActions"
+)
+
+// RatingModerationServiceClient is the client API for RatingModerationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RatingModerationServiceClient interface {
+	ListFlaggedItems(ctx context.Context, in *ListFlaggedItemsRequest, opts ...grpc.CallOption) (*ListFlaggedItemsResponse, error)
+	SetModerationStatus(ctx context.Context, in *SetModerationStatusRequest, opts ...grpc.CallOption) (*SetModerationStatusResponse, error)
+	ListModerationActions(ctx context.Context, in *ListModerationActionsRequest, opts ...grpc.CallOption) (*ListModerationActionsResponse, error)
+}
+
+type ratingModerationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRatingModerationServiceClient(cc grpc.ClientConnInterface) RatingModerationServiceClient {
+	return &ratingModerationServiceClient{cc}
+}
+
+func (c *ratingModerationServiceClient) ListFlaggedItems(ctx context.Context, in *ListFlaggedItemsRequest, opts ...grpc.CallOption) (*ListFlaggedItemsResponse, error) {
+	out := new(ListFlaggedItemsResponse)
+	err := c.cc.Invoke(ctx, RatingModerationService_ListFlaggedItems_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingModerationServiceClient) SetModerationStatus(ctx context.Context, in *SetModerationStatusRequest, opts ...grpc.CallOption) (*SetModerationStatusResponse, error) {
+	out := new(SetModerationStatusResponse)
+	err := c.cc.Invoke(ctx, RatingModerationService_SetModerationStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingModerationServiceClient) ListModerationActions(ctx context.Context, in *ListModerationActionsRequest, opts ...grpc.CallOption) (*ListModerationActionsResponse, error) {
+	out := new(ListModerationActionsResponse)
+	err := c.cc.Invoke(ctx, RatingModerationService_ListModerationActions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RatingModerationServiceServer is the server API for RatingModerationService service.
+// All implementations must embed UnimplementedRatingModerationServiceServer
 // for forward compatibility
-type MetadataServiceServer interface {
-	GetMetadata(context.Context, *GetMetadataRequest) (*GetMetadataResponse, error)
-	PutMetadata(context.Context, *PutMetadataRequest) (*PutMetadataResponse, error)
-	mustEmbedUnimplementedMetadataServiceServer()
+type RatingModerationServiceServer interface {
+	ListFlaggedItems(context.Context, *ListFlaggedItemsRequest) (*ListFlaggedItemsResponse, error)
+	SetModerationStatus(context.Context, *SetModerationStatusRequest) (*SetModerationStatusResponse, error)
+	ListModerationActions(context.Context, *ListModerationActionsRequest) (*ListModerationActionsResponse, error)
+	mustEmbedUnimplementedRatingModerationServiceServer()
 }
 
-// UnimplementedMetadataServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedMetadataServiceServer struct {
+// UnimplementedRatingModerationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRatingModerationServiceServer struct {
 }
 
-func (UnimplementedMetadataServiceServer) GetMetadata(context.Context, *GetMetadataRequest) (*GetMetadataResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
+func (UnimplementedRatingModerationServiceServer) ListFlaggedItems(context.Context, *ListFlaggedItemsRequest) (*ListFlaggedItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFlaggedItems not implemented")
 }
-func (UnimplementedMetadataServiceServer) PutMetadata(context.Context, *PutMetadataRequest) (*PutMetadataResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method PutMetadata not implemented")
+func (UnimplementedRatingModerationServiceServer) SetModerationStatus(context.Context, *SetModerationStatusRequest) (*SetModerationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetModerationStatus not implemented")
+}
+func (UnimplementedRatingModerationServiceServer) ListModerationActions(context.Context, *ListModerationActionsRequest) (*ListModerationActionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModerationActions not implemented")
+}
+func (UnimplementedRatingModerationServiceServer) mustEmbedUnimplementedRatingModerationServiceServer() {
 }
-func (UnimplementedMetadataServiceServer) mustEmbedUnimplementedMetadataServiceServer() {}
 
-// UnsafeMetadataServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to MetadataServiceServer will
+// UnsafeRatingModerationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RatingModerationServiceServer will
 // result in compilation errors.
-type UnsafeMetadataServiceServer interface {
-	mustEmbedUnimplementedMetadataServiceServer()
+type UnsafeRatingModerationServiceServer interface {
+	mustEmbedUnimplementedRatingModerationServiceServer()
 }
 
-func RegisterMetadataServiceServer(s grpc.ServiceRegistrar, srv MetadataServiceServer) {
-	s.RegisterService(&MetadataService_ServiceDesc, srv)
+func RegisterRatingModerationServiceServer(s grpc.ServiceRegistrar, srv RatingModerationServiceServer) {
+	s.RegisterService(&RatingModerationService_ServiceDesc, srv)
 }
 
-func _MetadataService_GetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetMetadataRequest)
+func _RatingModerationService_ListFlaggedItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlaggedItemsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetadataServiceServer).GetMetadata(ctx, in)
+		return srv.(RatingModerationServiceServer).ListFlaggedItems(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetadataService_GetMetadata_FullMethodName,
+		FullMethod: RatingModerationService_ListFlaggedItems_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetadataServiceServer).GetMetadata(ctx, req.(*GetMetadataRequest))
+		return srv.(RatingModerationServiceServer).ListFlaggedItems(ctx, req.(*ListFlaggedItemsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetadataService_PutMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PutMetadataRequest)
+func _RatingModerationService_SetModerationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetModerationStatusRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetadataServiceServer).PutMetadata(ctx, in)
+		return srv.(RatingModerationServiceServer).SetModerationStatus(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetadataService_PutMetadata_FullMethodName,
+		FullMethod: RatingModerationService_SetModerationStatus_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetadataServiceServer).PutMetadata(ctx, req.(*PutMetadataRequest))
+		return srv.(RatingModerationServiceServer).SetModerationStatus(ctx, req.(*SetModerationStatusRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// MetadataService_ServiceDesc is the grpc.ServiceDesc for MetadataService service.
+func _RatingModerationService_ListModerationActions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModerationActionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingModerationServiceServer).ListModerationActions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RatingModerationService_ListModerationActions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingModerationServiceServer).ListModerationActions(ctx, req.(*ListModerationActionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RatingModerationService_ServiceDesc is the grpc.ServiceDesc for RatingModerationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var MetadataService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "MetadataService",
-	HandlerType: (*MetadataServiceServer)(nil),
+var RatingModerationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "RatingModerationService",
+	HandlerType: (*RatingModerationServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "GetMetadata",
-			Handler:    _MetadataService_GetMetadata_Handler,
+			MethodName: "ListFlaggedItems",
+			Handler:    _RatingModerationService_ListFlaggedItems_Handler,
 		},
 		{
-			MethodName: "PutMetadata",
-			Handler:    _MetadataService_PutMetadata_Handler,
+			MethodName: "SetModerationStatus",
+			Handler:    _RatingModerationService_SetModerationStatus_Handler,
+		},
+		{
+			MethodName: "ListModerationActions",
+			Handler:    _RatingModerationService_ListModerationActions_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -146,126 +1564,126 @@ var MetadataService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	RatingService_GetAggregatedRating_FullMethodName = "/RatingService/GetAggregatedRating"
-	RatingService_PutRating_FullMethodName           = "/RatingService/PutRating"
+	RecommendationService_RecommendForUser_FullMethodName = "/RecommendationService/RecommendForUser"
+	RecommendationService_SimilarItems_FullMethodName     = "/RecommendationService/SimilarItems"
 )
 
-// RatingServiceClient is the client API for RatingService service.
+// RecommendationServiceClient is the client API for RecommendationService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-type RatingServiceClient interface {
-	GetAggregatedRating(ctx context.Context, in *GetAggregatedRatingRequest, opts ...grpc.CallOption) (*GetAggregatedRatingResponse, error)
-	PutRating(ctx context.Context, in *PutRatingRequest, opts ...grpc.CallOption) (*PutRatingResponse, error)
+type RecommendationServiceClient interface {
+	RecommendForUser(ctx context.Context, in *RecommendForUserRequest, opts ...grpc.CallOption) (*RecommendForUserResponse, error)
+	SimilarItems(ctx context.Context, in *SimilarItemsRequest, opts ...grpc.CallOption) (*SimilarItemsResponse, error)
 }
 
-type ratingServiceClient struct {
+type recommendationServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewRatingServiceClient(cc grpc.ClientConnInterface) RatingServiceClient {
-	return &ratingServiceClient{cc}
+func NewRecommendationServiceClient(cc grpc.ClientConnInterface) RecommendationServiceClient {
+	return &recommendationServiceClient{cc}
 }
 
-func (c *ratingServiceClient) GetAggregatedRating(ctx context.Context, in *GetAggregatedRatingRequest, opts ...grpc.CallOption) (*GetAggregatedRatingResponse, error) {
-	out := new(GetAggregatedRatingResponse)
-	err := c.cc.Invoke(ctx, RatingService_GetAggregatedRating_FullMethodName, in, out, opts...)
+func (c *recommendationServiceClient) RecommendForUser(ctx context.Context, in *RecommendForUserRequest, opts ...grpc.CallOption) (*RecommendForUserResponse, error) {
+	out := new(RecommendForUserResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_RecommendForUser_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *ratingServiceClient) PutRating(ctx context.Context, in *PutRatingRequest, opts ...grpc.CallOption) (*PutRatingResponse, error) {
-	out := new(PutRatingResponse)
-	err := c.cc.Invoke(ctx, RatingService_PutRating_FullMethodName, in, out, opts...)
+func (c *recommendationServiceClient) SimilarItems(ctx context.Context, in *SimilarItemsRequest, opts ...grpc.CallOption) (*SimilarItemsResponse, error) {
+	out := new(SimilarItemsResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_SimilarItems_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// RatingServiceServer is the server API for RatingService service.
-// All implementations must embed UnimplementedRatingServiceServer
+// RecommendationServiceServer is the server API for RecommendationService service.
+// All implementations must embed UnimplementedRecommendationServiceServer
 // for forward compatibility
-type RatingServiceServer interface {
-	GetAggregatedRating(context.Context, *GetAggregatedRatingRequest) (*GetAggregatedRatingResponse, error)
-	PutRating(context.Context, *PutRatingRequest) (*PutRatingResponse, error)
-	mustEmbedUnimplementedRatingServiceServer()
+type RecommendationServiceServer interface {
+	RecommendForUser(context.Context, *RecommendForUserRequest) (*RecommendForUserResponse, error)
+	SimilarItems(context.Context, *SimilarItemsRequest) (*SimilarItemsResponse, error)
+	mustEmbedUnimplementedRecommendationServiceServer()
 }
 
-// UnimplementedRatingServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedRatingServiceServer struct {
+// UnimplementedRecommendationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRecommendationServiceServer struct {
 }
 
-func (UnimplementedRatingServiceServer) GetAggregatedRating(context.Context, *GetAggregatedRatingRequest) (*GetAggregatedRatingResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetAggregatedRating not implemented")
+func (UnimplementedRecommendationServiceServer) RecommendForUser(context.Context, *RecommendForUserRequest) (*RecommendForUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecommendForUser not implemented")
 }
-func (UnimplementedRatingServiceServer) PutRating(context.Context, *PutRatingRequest) (*PutRatingResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method PutRating not implemented")
+func (UnimplementedRecommendationServiceServer) SimilarItems(context.Context, *SimilarItemsRequest) (*SimilarItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimilarItems not implemented")
 }
-func (UnimplementedRatingServiceServer) mustEmbedUnimplementedRatingServiceServer() {}
+func (UnimplementedRecommendationServiceServer) mustEmbedUnimplementedRecommendationServiceServer() {}
 
-// UnsafeRatingServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to RatingServiceServer will
+// UnsafeRecommendationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RecommendationServiceServer will
 // result in compilation errors.
-type UnsafeRatingServiceServer interface {
-	mustEmbedUnimplementedRatingServiceServer()
+type UnsafeRecommendationServiceServer interface {
+	mustEmbedUnimplementedRecommendationServiceServer()
 }
 
-func RegisterRatingServiceServer(s grpc.ServiceRegistrar, srv RatingServiceServer) {
-	s.RegisterService(&RatingService_ServiceDesc, srv)
+func RegisterRecommendationServiceServer(s grpc.ServiceRegistrar, srv RecommendationServiceServer) {
+	s.RegisterService(&RecommendationService_ServiceDesc, srv)
 }
 
-func _RatingService_GetAggregatedRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetAggregatedRatingRequest)
+func _RecommendationService_RecommendForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendForUserRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RatingServiceServer).GetAggregatedRating(ctx, in)
+		return srv.(RecommendationServiceServer).RecommendForUser(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RatingService_GetAggregatedRating_FullMethodName,
+		FullMethod: RecommendationService_RecommendForUser_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RatingServiceServer).GetAggregatedRating(ctx, req.(*GetAggregatedRatingRequest))
+		return srv.(RecommendationServiceServer).RecommendForUser(ctx, req.(*RecommendForUserRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RatingService_PutRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PutRatingRequest)
+func _RecommendationService_SimilarItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimilarItemsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RatingServiceServer).PutRating(ctx, in)
+		return srv.(RecommendationServiceServer).SimilarItems(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RatingService_PutRating_FullMethodName,
+		FullMethod: RecommendationService_SimilarItems_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RatingServiceServer).PutRating(ctx, req.(*PutRatingRequest))
+		return srv.(RecommendationServiceServer).SimilarItems(ctx, req.(*SimilarItemsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// RatingService_ServiceDesc is the grpc.ServiceDesc for RatingService service.
+// RecommendationService_ServiceDesc is the grpc.ServiceDesc for RecommendationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var RatingService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "RatingService",
-	HandlerType: (*RatingServiceServer)(nil),
+var RecommendationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "RecommendationService",
+	HandlerType: (*RecommendationServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "GetAggregatedRating",
-			Handler:    _RatingService_GetAggregatedRating_Handler,
+			MethodName: "RecommendForUser",
+			Handler:    _RecommendationService_RecommendForUser_Handler,
 		},
 		{
-			MethodName: "PutRating",
-			Handler:    _RatingService_PutRating_Handler,
+			MethodName: "SimilarItems",
+			Handler:    _RecommendationService_SimilarItems_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -273,7 +1691,9 @@ var RatingService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	MovieService_GetMovieDetails_FullMethodName = "/MovieService/GetMovieDetails"
+	MovieService_GetMovieDetails_FullMethodName    = "/MovieService/GetMovieDetails"
+	MovieService_ListMovies_FullMethodName         = "/MovieService/ListMovies"
+	MovieService_ListTopRatedMovies_FullMethodName = "/MovieService/ListTopRatedMovies"
 )
 
 // MovieServiceClient is the client API for MovieService service.
@@ -281,6 +1701,8 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MovieServiceClient interface {
 	GetMovieDetails(ctx context.Context, in *GetMovieDetailsRequest, opts ...grpc.CallOption) (*GetMovieDetailsResponse, error)
+	ListMovies(ctx context.Context, in *ListMoviesRequest, opts ...grpc.CallOption) (*ListMoviesResponse, error)
+	ListTopRatedMovies(ctx context.Context, in *ListTopRatedMoviesRequest, opts ...grpc.CallOption) (*ListMoviesResponse, error)
 }
 
 type movieServiceClient struct {
@@ -300,11 +1722,31 @@ func (c *movieServiceClient) GetMovieDetails(ctx context.Context, in *GetMovieDe
 	return out, nil
 }
 
+func (c *movieServiceClient) ListMovies(ctx context.Context, in *ListMoviesRequest, opts ...grpc.CallOption) (*ListMoviesResponse, error) {
+	out := new(ListMoviesResponse)
+	err := c.cc.Invoke(ctx, MovieService_ListMovies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) ListTopRatedMovies(ctx context.Context, in *ListTopRatedMoviesRequest, opts ...grpc.CallOption) (*ListMoviesResponse, error) {
+	out := new(ListMoviesResponse)
+	err := c.cc.Invoke(ctx, MovieService_ListTopRatedMovies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MovieServiceServer is the server API for MovieService service.
 // All implementations must embed UnimplementedMovieServiceServer
 // for forward compatibility
 type MovieServiceServer interface {
 	GetMovieDetails(context.Context, *GetMovieDetailsRequest) (*GetMovieDetailsResponse, error)
+	ListMovies(context.Context, *ListMoviesRequest) (*ListMoviesResponse, error)
+	ListTopRatedMovies(context.Context, *ListTopRatedMoviesRequest) (*ListMoviesResponse, error)
 	mustEmbedUnimplementedMovieServiceServer()
 }
 
@@ -315,6 +1757,12 @@ type UnimplementedMovieServiceServer struct {
 func (UnimplementedMovieServiceServer) GetMovieDetails(context.Context, *GetMovieDetailsRequest) (*GetMovieDetailsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMovieDetails not implemented")
 }
+func (UnimplementedMovieServiceServer) ListMovies(context.Context, *ListMoviesRequest) (*ListMoviesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMovies not implemented")
+}
+func (UnimplementedMovieServiceServer) ListTopRatedMovies(context.Context, *ListTopRatedMoviesRequest) (*ListMoviesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTopRatedMovies not implemented")
+}
 func (UnimplementedMovieServiceServer) mustEmbedUnimplementedMovieServiceServer() {}
 
 // UnsafeMovieServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -346,6 +1794,42 @@ func _MovieService_GetMovieDetails_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MovieService_ListMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).ListMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_ListMovies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).ListMovies(ctx, req.(*ListMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_ListTopRatedMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTopRatedMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).ListTopRatedMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_ListTopRatedMovies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).ListTopRatedMovies(ctx, req.(*ListTopRatedMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MovieService_ServiceDesc is the grpc.ServiceDesc for MovieService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -357,6 +1841,14 @@ var MovieService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMovieDetails",
 			Handler:    _MovieService_GetMovieDetails_Handler,
 		},
+		{
+			MethodName: "ListMovies",
+			Handler:    _MovieService_ListMovies_Handler,
+		},
+		{
+			MethodName: "ListTopRatedMovies",
+			Handler:    _MovieService_ListTopRatedMovies_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "movie.proto",
@@ -2,9 +2,12 @@ package grpcutil
 
 import (
 	"context"
+	"errors"
 	"math/rand"
+	"time"
 
 	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/errs"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -12,7 +15,9 @@ import (
 // ServiceConnection attempts to select a random service instance and returns a gRPC connection to it.
 func ServiceConnection(ctx context.Context, serviceName string, registry discovery.Registry) (*grpc.ClientConn, error) {
 	addrs, err := registry.ServiceAddresses(ctx, serviceName)
-	if err != nil {
+	if err != nil && errors.Is(err, discovery.ErrNotFound) {
+		return nil, errs.Unavailable(serviceName+" service unavailable", time.Second).Wrap(err)
+	} else if err != nil {
 		return nil, err
 	}
 
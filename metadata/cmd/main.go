@@ -6,16 +6,23 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/phongld0308/movie-example/gen"
 	"github.com/phongld0308/movie-example/metadata/internal/controller/metadata"
+	"github.com/phongld0308/movie-example/metadata/internal/controller/people"
+	ratinggateway "github.com/phongld0308/movie-example/metadata/internal/gateway/rating/grpc"
 	grpchandler "github.com/phongld0308/movie-example/metadata/internal/handler/grpc"
+	httphandler "github.com/phongld0308/movie-example/metadata/internal/handler/http"
 	"github.com/phongld0308/movie-example/metadata/internal/repository/postgres"
 	"github.com/phongld0308/movie-example/pkg/discovery"
 	"github.com/phongld0308/movie-example/pkg/discovery/consul"
+	"github.com/phongld0308/movie-example/pkg/idempotency"
+	idempotencystore "github.com/phongld0308/movie-example/pkg/idempotency/postgres"
+	"github.com/phongld0308/movie-example/pkg/validation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -23,10 +30,11 @@ import (
 const serviceName = "metadata"
 
 func main() {
-	var port int
+	var port, httpPort int
 	flag.IntVar(&port, "port", 8081, "API handler port")
+	flag.IntVar(&httpPort, "http-port", 8091, "HTTP API handler port")
 	flag.Parse()
-	log.Printf("Starting the metadata service on port %d", port)
+	log.Printf("Starting the metadata service on port %d, HTTP on port %d", port, httpPort)
 
 	// Get configuration from environment
 	consulAddr := getEnvOrDefault("CONSUL_ADDR", "consul:8500")
@@ -78,26 +86,103 @@ func main() {
 	}
 	defer repo.Close()
 
-	ctrl := metadata.New(repo)
+	// Initialize idempotency key store
+	idempotencyTTL, err := time.ParseDuration(getEnvOrDefault("IDEMPOTENCY_TTL", idempotency.DefaultTTL.String()))
+	if err != nil {
+		panic(fmt.Sprintf("invalid idempotency TTL: %v", err))
+	}
+	idempotencyStore, err := idempotencystore.New(
+		dbHost,
+		dbPortInt,
+		dbUser,
+		dbPassword,
+		dbName,
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer idempotencyStore.Close()
+
+	go func() {
+		for {
+			time.Sleep(1 * time.Hour)
+			if err := idempotencyStore.DeleteExpired(ctx); err != nil {
+				log.Println("Failed to delete expired idempotency keys: " + err.Error())
+			}
+		}
+	}()
+
+	ctrl := metadata.NewWithRatingGateway(repo, ratinggateway.New(registry))
+
+	// Build the title suggestion index and refresh it so that
+	// rating counts stay current. The rating service may start
+	// after this one; titles are indexed without counts until
+	// it is reachable.
+	suggestRefresh, err := time.ParseDuration(getEnvOrDefault("SUGGEST_REFRESH_INTERVAL", "10m"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid suggestion refresh interval: %v", err))
+	}
+	if err := ctrl.LoadSuggestions(ctx); err != nil {
+		log.Println("Failed to load title suggestions: " + err.Error())
+	}
+	go func() {
+		for {
+			time.Sleep(suggestRefresh)
+			if err := ctrl.LoadSuggestions(ctx); err != nil {
+				log.Println("Failed to refresh title suggestions: " + err.Error())
+			}
+		}
+	}()
+
+	// Build the similar movie index. Writes through this
+	// instance keep it current; the refresh picks up the
+	// others'.
+	similarRefresh, err := time.ParseDuration(getEnvOrDefault("SIMILAR_REFRESH_INTERVAL", "1h"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid similar movie refresh interval: %v", err))
+	}
+	if err := ctrl.LoadSimilar(ctx); err != nil {
+		panic(err)
+	}
+	go func() {
+		for {
+			time.Sleep(similarRefresh)
+			if err := ctrl.LoadSimilar(ctx); err != nil {
+				log.Println("Failed to refresh similar movies: " + err.Error())
+			}
+		}
+	}()
+
+	// Serve the HTTP API, with ETags for conditional writes
+	// and idempotency keys for retried ones, next to the gRPC
+	// API.
+	mux := http.NewServeMux()
+	httphandler.New(ctrl).Register(mux)
+	go func() {
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", httpPort), idempotency.Middleware(idempotencyStore, idempotencyTTL, mux)); err != nil {
+			panic(err)
+		}
+	}()
+
 	h := grpchandler.New(ctrl)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%v", port))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	srv := grpc.NewServer()
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		validation.UnaryServerInterceptor(),
+		idempotency.UnaryServerInterceptor(idempotencyStore, idempotencyTTL,
+			gen.MetadataService_PutMetadata_FullMethodName,
+			gen.MetadataService_UpdateMetadata_FullMethodName,
+		),
+	))
 	reflection.Register(srv)
 	gen.RegisterMetadataServiceServer(srv, h)
+	gen.RegisterPeopleServiceServer(srv, grpchandler.NewPeople(people.New(repo)))
 	if err := srv.Serve(lis); err != nil {
 		panic(err)
 	}
-	// h := httphandler.New(ctrl)
-
-	// http.Handle("/metadata", http.HandlerFunc(h.GetMetadata))
-
-	// if err := http.ListenAndServe(":8081", nil); err != nil {
-	// 	panic(err)
-	// }
 }
 
 func getEnvOrDefault(key, defaultValue string) string {
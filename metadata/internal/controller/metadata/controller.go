@@ -3,41 +3,387 @@ package metadata
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
 
 	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	"github.com/phongld0308/movie-example/metadata/internal/similar"
+	"github.com/phongld0308/movie-example/metadata/internal/suggest"
 	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
 )
 
 // ErrNotFound is returned when request record is not found.
-var ErrNotFound = errors.New("not found")
+var ErrNotFound = errs.NotFound("metadata not found")
+
+// ErrVersionMismatch is returned when a write expects a
+// version that differs from the stored one.
+var ErrVersionMismatch = errs.PreconditionFailed("version mismatch")
+
+// ErrInvalidUpdateMask is returned when an update mask is
+// empty or references a field that cannot be updated.
+var ErrInvalidUpdateMask = errs.InvalidArgument("invalid update mask")
+
+// ErrInvalidMetadata is returned when metadata fields are
+// malformed.
+var ErrInvalidMetadata = errs.InvalidArgument("invalid metadata")
 
 type metadataRepository interface {
 	Get(ctx context.Context, id string) (*model.Metadata, error)
-	Put(ctx context.Context, id string, metadata *model.Metadata) error
+	GetMany(ctx context.Context, ids []string) ([]model.Metadata, error)
+	Put(ctx context.Context, id string, metadata *model.Metadata, expectedVersion int64) (int64, error)
+	Update(ctx context.Context, id string, metadata *model.Metadata, fields []string, expectedVersion int64) (*model.Metadata, error)
+	Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, int, error)
+	Titles(ctx context.Context) ([]model.TitleSuggestion, error)
+	PutTranslation(ctx context.Context, t *model.Translation) error
+	DeleteTranslation(ctx context.Context, movieID, locale string) error
+	ListTranslations(ctx context.Context, movieID string) ([]model.Translation, error)
+	ListTranslationsOf(ctx context.Context, movieIDs []string) (map[string][]model.Translation, error)
+	ListChildren(ctx context.Context, parentID string) ([]model.Metadata, error)
+	ListAll(ctx context.Context) ([]model.Metadata, error)
 }
 
+type ratingGateway interface {
+	BatchGetRatingCounts(ctx context.Context, keys []ratingmodel.RecordKey) (map[ratingmodel.RecordKey]int64, error)
+}
+
+// DefaultSearchPageSize is the number of search results per
+// page when none is requested.
+const DefaultSearchPageSize = 20
+
 // Controller defines a metadata service controller.
 type Controller struct {
-	repo metadataRepository
+	repo          metadataRepository
+	ratingGateway ratingGateway
+	suggestions   *suggest.Index
+	similar       *similar.Index
 }
 
 // New creates a new metadata service controller.
 func New(repo metadataRepository) *Controller {
-	return &Controller{repo}
+	return &Controller{repo: repo, suggestions: suggest.New(), similar: similar.New()}
 }
 
-// Get returns movie metada by id.
-func (c *Controller) Get(ctx context.Context, id string) (*model.Metadata, error) {
+// NewWithRatingGateway creates a new metadata service
+// controller that ranks title suggestions by the rating
+// counts of the rating service.
+func NewWithRatingGateway(repo metadataRepository, ratingGateway ratingGateway) *Controller {
+	c := New(repo)
+	c.ratingGateway = ratingGateway
+	return c
+}
+
+// Get returns movie metada by id. The title and description
+// are translated to the best match of the preferred locales,
+// given in Accept-Language form, if any.
+func (c *Controller) Get(ctx context.Context, id string, locales string) (*model.Metadata, error) {
 	res, err := c.repo.Get(ctx, id)
 
 	if err != nil && errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if locales != "" {
+		translations, err := c.repo.ListTranslations(ctx, id)
+		if err != nil && errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		} else if err != nil {
+			return nil, err
+		}
+		localize(res, translations, locales)
+	}
+
+	return res, nil
+}
+
+// BatchGet returns the metadata of the given ids that exist,
+// in the order of ids and each once, translated as by Get.
+func (c *Controller) BatchGet(ctx context.Context, ids []string, locales string) ([]model.Metadata, error) {
+	found, err := c.repo.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var translations map[string][]model.Translation
+	if locales != "" && len(found) > 0 {
+		if translations, err = c.repo.ListTranslationsOf(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	byID := make(map[string]*model.Metadata, len(found))
+	for i := range found {
+		byID[found[i].ID] = &found[i]
+	}
+	res := make([]model.Metadata, 0, len(found))
+	for _, id := range ids {
+		m, ok := byID[id]
+		if !ok {
+			continue
+		}
+		delete(byID, id)
+		if locales != "" {
+			localize(m, translations[id], locales)
+		}
+		res = append(res, *m)
+	}
+	return res, nil
+}
+
+// Put creates or updates movie metadata and returns the new
+// version. A non-zero expectedVersion must match the stored
+// version or ErrVersionMismatch is returned.
+func (c *Controller) Put(ctx context.Context, metadata *model.Metadata, expectedVersion int64) (int64, error) {
+	if metadata.Type == "" {
+		metadata.Type = model.RecordTypeMovie
+	}
+	if err := validateDetails(metadata, nil); err != nil {
+		return 0, err
+	}
+	if err := c.validateParent(ctx, metadata); err != nil {
+		return 0, err
+	}
+	if err := c.validateTypeChange(ctx, metadata); err != nil {
+		return 0, err
+	}
+	v, err := c.repo.Put(ctx, metadata.ID, metadata, expectedVersion)
+	if err != nil && errors.Is(err, repository.ErrVersionMismatch) {
+		return 0, ErrVersionMismatch
+	} else if err != nil {
+		return 0, err
+	}
+	c.suggestions.Put(metadata.ID, metadata.Title)
+	stored := metadata.Clone()
+	stored.Version = v
+	c.similar.Put(stored)
+	return v, nil
+}
+
+// Update changes only the metadata fields listed in paths
+// and returns the updated record. A non-zero expectedVersion
+// must match the stored version.
+func (c *Controller) Update(ctx context.Context, metadata *model.Metadata, paths []string, expectedVersion int64) (*model.Metadata, error) {
+	if len(paths) == 0 {
+		return nil, ErrInvalidUpdateMask.WithViolations(errs.FieldViolation{Field: "update_mask", Description: "no fields specified"})
+	}
+	for _, p := range paths {
+		if !model.IsUpdatableField(p) {
+			return nil, ErrInvalidUpdateMask.WithViolations(errs.FieldViolation{Field: "update_mask", Description: fmt.Sprintf("unknown field %q", p)})
+		}
+	}
+	if err := validateDetails(metadata, paths); err != nil {
+		return nil, err
+	}
+
+	res, err := c.repo.Update(ctx, metadata.ID, metadata, paths, expectedVersion)
+	if err != nil && errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil && errors.Is(err, repository.ErrVersionMismatch) {
+		return nil, ErrVersionMismatch
+	} else if err != nil {
 		return nil, err
 	}
+	c.suggestions.Put(res.ID, res.Title)
+	c.similar.Put(res)
 
 	return res, nil
 }
 
-// Put creates or updates movie metadata.
-func (c *Controller) Put(ctx context.Context, metadata *model.Metadata) error {
-	return c.repo.Put(ctx, metadata.ID, metadata)
+// validateDetails checks that the title is set and the
+// formats that field rules cannot express, for the fields
+// listed in paths or all fields if paths is nil.
+func validateDetails(m *model.Metadata, paths []string) error {
+	listed := func(field string) bool {
+		return paths == nil || slices.Contains(paths, field)
+	}
+
+	var violations []errs.FieldViolation
+	if listed(model.FieldTitle) && m.Title == "" {
+		violations = append(violations, errs.FieldViolation{Field: "metadata.title", Description: "must not be empty"})
+	}
+	if listed(model.FieldReleaseDate) && m.ReleaseDate != "" {
+		if _, err := time.Parse(model.ReleaseDateLayout, m.ReleaseDate); err != nil {
+			violations = append(violations, errs.FieldViolation{Field: "metadata.release_date", Description: "must be a date in YYYY-MM-DD form"})
+		}
+	}
+	for _, f := range []struct {
+		field, value string
+	}{{model.FieldPosterURL, m.PosterURL}, {model.FieldBackdropURL, m.BackdropURL}} {
+		if !listed(f.field) || f.value == "" {
+			continue
+		}
+		if u, err := url.Parse(f.value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			violations = append(violations, errs.FieldViolation{Field: "metadata." + f.field, Description: "must be an absolute http or https URL"})
+		}
+	}
+
+	if len(violations) > 0 {
+		return ErrInvalidMetadata.WithViolations(violations...)
+	}
+	return nil
+}
+
+// validateParent checks that seasons belong to a series and
+// episodes to a season, and that movies and series have no
+// parent.
+func (c *Controller) validateParent(ctx context.Context, m *model.Metadata) error {
+	parentType := m.Type.ParentType()
+	if parentType == "" {
+		if m.ParentID != "" {
+			return ErrInvalidMetadata.WithViolations(errs.FieldViolation{Field: "metadata.parent_id", Description: fmt.Sprintf("a %s has no parent", m.Type)})
+		}
+		return nil
+	}
+
+	var violations []errs.FieldViolation
+	switch {
+	case m.Type == model.RecordTypeSeason && m.SeasonNumber <= 0:
+		violations = append(violations, errs.FieldViolation{Field: "metadata.season_number", Description: "must be positive for a season"})
+	case m.Type == model.RecordTypeEpisode && m.EpisodeNumber <= 0:
+		violations = append(violations, errs.FieldViolation{Field: "metadata.episode_number", Description: "must be positive for an episode"})
+	}
+	if m.ParentID == "" {
+		violations = append(violations, errs.FieldViolation{Field: "metadata.parent_id", Description: fmt.Sprintf("a %s must have a %s parent", m.Type, parentType)})
+	} else if m.ParentID == m.ID {
+		violations = append(violations, errs.FieldViolation{Field: "metadata.parent_id", Description: "must not be the record itself"})
+	} else {
+		parent, err := c.repo.Get(ctx, m.ParentID)
+		if err != nil && errors.Is(err, repository.ErrNotFound) {
+			violations = append(violations, errs.FieldViolation{Field: "metadata.parent_id", Description: "parent not found"})
+		} else if err != nil {
+			return err
+		} else if parent.Type != parentType {
+			violations = append(violations, errs.FieldViolation{Field: "metadata.parent_id", Description: fmt.Sprintf("must refer to a %s, not a %s", parentType, parent.Type)})
+		}
+	}
+
+	if len(violations) > 0 {
+		return ErrInvalidMetadata.WithViolations(violations...)
+	}
+	return nil
+}
+
+// validateTypeChange checks that records with children keep
+// their type, so that seasons stay in a series and episodes
+// in a season.
+func (c *Controller) validateTypeChange(ctx context.Context, m *model.Metadata) error {
+	stored, err := c.repo.Get(ctx, m.ID)
+	if err != nil && errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if stored.Type == m.Type {
+		return nil
+	}
+
+	children, err := c.repo.ListChildren(ctx, m.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if len(children) > 0 {
+		return ErrInvalidMetadata.WithViolations(errs.FieldViolation{Field: "metadata.type", Description: fmt.Sprintf("a %s with children cannot become a %s", stored.Type, m.Type)})
+	}
+	return nil
+}
+
+// ListChildren returns the seasons of a series or the
+// episodes of a season, in season and episode order.
+func (c *Controller) ListChildren(ctx context.Context, parentID string) ([]model.Metadata, error) {
+	res, err := c.repo.ListChildren(ctx, parentID)
+	if err != nil && errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return res, err
+}
+
+// Search returns a 1-based page of movies matching query,
+// most relevant first, together with the total number of
+// matches.
+func (c *Controller) Search(ctx context.Context, query string, page, pageSize int) ([]model.SearchResult, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultSearchPageSize
+	}
+	return c.repo.Search(ctx, query, (page-1)*pageSize, pageSize)
+}
+
+// LoadSuggestions rebuilds the title suggestion index from
+// the repository, with rating counts from the rating service.
+// Titles written meanwhile are kept. If the rating counts are
+// unavailable, the titles keep their previous counts and the
+// error is returned once the index is rebuilt.
+func (c *Controller) LoadSuggestions(ctx context.Context) error {
+	var countErr error
+	err := c.suggestions.Rebuild(func() ([]model.TitleSuggestion, error) {
+		titles, err := c.repo.Titles(ctx)
+		if err != nil || c.ratingGateway == nil {
+			return titles, err
+		}
+
+		keys := make([]ratingmodel.RecordKey, len(titles))
+		for i, t := range titles {
+			keys[i] = ratingmodel.RecordKey{ID: ratingmodel.RecordID(t.ID), Type: ratingmodel.RecordType(t.RecordType)}
+		}
+		counts, err := c.ratingGateway.BatchGetRatingCounts(ctx, keys)
+		for i := range titles {
+			if err != nil {
+				titles[i].RatingCount = c.suggestions.RatingCount(titles[i].ID)
+			} else {
+				titles[i].RatingCount = counts[keys[i]]
+			}
+		}
+		countErr = err
+		return titles, nil
+	})
+	if err != nil {
+		return err
+	}
+	if countErr != nil {
+		return fmt.Errorf("failed to get rating counts: %w", countErr)
+	}
+	return nil
+}
+
+// SuggestTitles returns up to limit movie titles starting
+// with prefix, tolerating a typo, most rated first.
+func (c *Controller) SuggestTitles(prefix string, limit int) []model.TitleSuggestion {
+	return c.suggestions.Suggest(prefix, limit)
+}
+
+// LoadSimilar rebuilds the similar movie index from the
+// repository. Records written meanwhile are kept.
+func (c *Controller) LoadSimilar(ctx context.Context) error {
+	return c.similar.Rebuild(func() ([]model.Metadata, error) {
+		return c.repo.ListAll(ctx)
+	})
+}
+
+// SimilarMovies returns up to limit records of the same type
+// as a movie that are most alike in director, genres, title
+// and description, most similar first. Ratings play no part,
+// so that new movies are placed too.
+func (c *Controller) SimilarMovies(ctx context.Context, id string, limit int) ([]model.SimilarMovie, error) {
+	if res, ok := c.similar.Similar(id, limit); ok {
+		return res, nil
+	}
+
+	// The movie may have been written by another instance
+	// since the index was loaded.
+	m, err := c.repo.Get(ctx, id)
+	if err != nil && errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	c.similar.Put(m)
+	res, _ := c.similar.Similar(id, limit)
+	return res, nil
 }
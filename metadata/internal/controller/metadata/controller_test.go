@@ -0,0 +1,357 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/phongld0308/movie-example/metadata/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	if _, err := ctrl.Put(ctx, &model.Metadata{ID: "1", Title: "The Matrix", Description: "A hacker", Director: "Wachowskis"}, 0); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	got, err := ctrl.Update(ctx, &model.Metadata{ID: "1", Title: "ignored", Director: "Lana Wachowski"}, []string{model.FieldDirector}, 1)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	want := model.Metadata{ID: "1", Title: "The Matrix", Description: "A hacker", Director: "Lana Wachowski", Version: 2, Type: model.RecordTypeMovie}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("update returned %+v, want %+v", *got, want)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		paths   []string
+		version int64
+		want    error
+	}{
+		{"empty mask", "1", nil, 0, ErrInvalidUpdateMask},
+		{"unknown path", "1", []string{"rating"}, 0, ErrInvalidUpdateMask},
+		{"immutable id", "1", []string{"id"}, 0, ErrInvalidUpdateMask},
+		{"missing record", "2", []string{model.FieldDirector}, 0, ErrNotFound},
+		{"stale version", "1", []string{model.FieldDirector}, 1, ErrVersionMismatch},
+		{"empty required field", "1", []string{model.FieldTitle}, 0, ErrInvalidMetadata},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ctrl.Update(ctx, &model.Metadata{ID: tt.id}, tt.paths, tt.version); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+	stored, err := ctrl.Get(ctx, "1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "The Matrix" {
+		t.Errorf("title after rejected updates = %q, want The Matrix", stored.Title)
+	}
+}
+
+func TestPutVersion(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	m := &model.Metadata{ID: "1", Title: "Heat"}
+
+	if _, err := ctrl.Put(ctx, m, 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("conditional create: got %v, want %v", err, ErrVersionMismatch)
+	}
+	v, err := ctrl.Put(ctx, m, 0)
+	if err != nil || v != 1 {
+		t.Fatalf("unconditional put: got version %d, error %v", v, err)
+	}
+	if v, err = ctrl.Put(ctx, m, 1); err != nil || v != 2 {
+		t.Fatalf("conditional put: got version %d, error %v", v, err)
+	}
+	if _, err := ctrl.Put(ctx, m, 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("stale put: got %v, want %v", err, ErrVersionMismatch)
+	}
+}
+
+func TestPutDetails(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	m := &model.Metadata{
+		ID:               "1",
+		Title:            "Amélie",
+		ReleaseDate:      "2001-04-25",
+		RuntimeMinutes:   122,
+		Genres:           []string{"Comedy", "Romance"},
+		OriginalLanguage: "fr",
+		SpokenLanguages:  []string{"fr"},
+		Country:          "FR",
+		PosterURL:        "https://img.example.com/amelie.jpg",
+		ContentRating:    "R",
+	}
+	if _, err := ctrl.Put(ctx, m, 0); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	got, err := ctrl.Get(ctx, "1", "")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	want := *m
+	want.Version = 1
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	for _, bad := range []*model.Metadata{
+		{ID: "2", Title: "Bad date", ReleaseDate: "25/04/2001"},
+		{ID: "2", Title: "Bad poster", PosterURL: "/amelie.jpg"},
+	} {
+		if _, err := ctrl.Put(ctx, bad, 0); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("put %q: got %v, want %v", bad.Title, err, ErrInvalidMetadata)
+		}
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	if _, err := ctrl.Put(ctx, &model.Metadata{ID: "1", Title: "Spirited Away", Description: "A girl enters the spirit world.", OriginalLanguage: "ja"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range []*model.Translation{
+		{MovieID: "1", Locale: "fr", Title: "Le Voyage de Chihiro", Description: "Une fille entre dans le monde des esprits."},
+		{MovieID: "1", Locale: "pt-br", Title: "A Viagem de Chihiro"},
+		{MovieID: "1", Locale: "ja", Title: "千と千尋の神隠し"},
+	} {
+		if err := ctrl.PutTranslation(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		locales, title, locale string
+	}{
+		{"", "Spirited Away", ""},
+		{"fr-CA", "Le Voyage de Chihiro", "fr"},
+		{"de, pt-BR;q=0.8", "A Viagem de Chihiro", "pt-BR"},
+		// The original language wins over its own translation.
+		{"ja", "Spirited Away", ""},
+		{"de", "Spirited Away", ""},
+		{"not a locale;;", "Spirited Away", ""},
+	} {
+		m, err := ctrl.Get(ctx, "1", tt.locales)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Title != tt.title || m.Locale != tt.locale {
+			t.Errorf("Get(%q) = %q in %q, want %q in %q", tt.locales, m.Title, m.Locale, tt.title, tt.locale)
+		}
+	}
+
+	// Descriptions fall back to the original.
+	if m, _ := ctrl.Get(ctx, "1", "pt-BR"); m.Description != "A girl enters the spirit world." {
+		t.Errorf("got description %q, want the original", m.Description)
+	}
+
+	if err := ctrl.DeleteTranslation(ctx, "1", "FR"); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := ctrl.Get(ctx, "1", "fr"); m.Locale != "" {
+		t.Errorf("got locale %q after delete, want the original", m.Locale)
+	}
+	if err := ctrl.PutTranslation(ctx, &model.Translation{MovieID: "1", Locale: "??", Title: "x"}); !errors.Is(err, ErrInvalidLocale) {
+		t.Errorf("got %v, want %v", err, ErrInvalidLocale)
+	}
+	if err := ctrl.PutTranslation(ctx, &model.Translation{MovieID: "2", Locale: "fr", Title: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	for _, m := range []*model.Metadata{
+		{ID: "1", Title: "Spirited Away", OriginalLanguage: "ja"},
+		{ID: "2", Title: "Heat", OriginalLanguage: "en"},
+	} {
+		if _, err := ctrl.Put(ctx, m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ctrl.PutTranslation(ctx, &model.Translation{MovieID: "1", Locale: "fr", Title: "Le Voyage de Chihiro"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Records come in request order, each once; missing ones
+	// are left out.
+	res, err := ctrl.BatchGet(ctx, []string{"2", "missing", "1", "2"}, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, m := range res {
+		titles = append(titles, m.Title)
+	}
+	if want := []string{"Heat", "Le Voyage de Chihiro"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("got titles %v, want %v", titles, want)
+	}
+}
+
+type fakeRatingGateway struct {
+	counts map[ratingmodel.RecordKey]int64
+	err    error
+}
+
+func (g *fakeRatingGateway) BatchGetRatingCounts(_ context.Context, _ []ratingmodel.RecordKey) (map[ratingmodel.RecordKey]int64, error) {
+	return g.counts, g.err
+}
+
+func TestLoadSuggestions(t *testing.T) {
+	ctx := context.Background()
+	gateway := &fakeRatingGateway{counts: map[ratingmodel.RecordKey]int64{
+		{ID: "1", Type: ratingmodel.RecordTypeMovie}: 3,
+		{ID: "2", Type: ratingmodel.RecordTypeMovie}: 7,
+		// Counts of other record types do not apply.
+		{ID: "1", Type: ratingmodel.RecordTypeSeries}: 100,
+	}}
+	ctrl := NewWithRatingGateway(memory.New(), gateway)
+	for _, m := range []*model.Metadata{
+		{ID: "1", Title: "Heat"},
+		{ID: "2", Title: "Heathers"},
+	} {
+		if _, err := ctrl.Put(ctx, m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func() []string {
+		var res []string
+		for _, s := range ctrl.SuggestTitles("heat", 10) {
+			res = append(res, s.ID)
+		}
+		return res
+	}
+	if err := ctrl.LoadSuggestions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(), []string{"2", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want the most rated title first %v", got, want)
+	}
+
+	// Without the rating service, titles keep their counts.
+	gateway.err = errors.New("unavailable")
+	if err := ctrl.LoadSuggestions(ctx); err == nil {
+		t.Error("got no error without rating counts")
+	}
+	if got, want := ids(), []string{"2", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v after a failed count, want %v", got, want)
+	}
+}
+
+func TestChildren(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	for _, m := range []*model.Metadata{
+		{ID: "got", Title: "Game of Thrones", Type: model.RecordTypeSeries},
+		{ID: "got-s2", Title: "Season 2", Type: model.RecordTypeSeason, ParentID: "got", SeasonNumber: 2},
+		{ID: "got-s1", Title: "Season 1", Type: model.RecordTypeSeason, ParentID: "got", SeasonNumber: 1},
+		{ID: "got-s1e2", Title: "The Kingsroad", Type: model.RecordTypeEpisode, ParentID: "got-s1", SeasonNumber: 1, EpisodeNumber: 2},
+		{ID: "got-s1e1", Title: "Winter Is Coming", Type: model.RecordTypeEpisode, ParentID: "got-s1", SeasonNumber: 1, EpisodeNumber: 1},
+	} {
+		if _, err := ctrl.Put(ctx, m, 0); err != nil {
+			t.Fatalf("put %s: %v", m.ID, err)
+		}
+	}
+
+	for parent, want := range map[string][]string{
+		"got":    {"got-s1", "got-s2"},
+		"got-s1": {"got-s1e1", "got-s1e2"},
+		"got-s2": nil,
+	} {
+		children, err := ctrl.ListChildren(ctx, parent)
+		if err != nil {
+			t.Fatalf("list children of %s: %v", parent, err)
+		}
+		var got []string
+		for _, c := range children {
+			got = append(got, c.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("children of %s = %v, want %v", parent, got, want)
+		}
+	}
+	if _, err := ctrl.ListChildren(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("list children of missing record: got %v, want %v", err, ErrNotFound)
+	}
+
+	for _, m := range []*model.Metadata{
+		{ID: "m", Title: "Movie with a parent", ParentID: "got"},
+		{ID: "s", Title: "Orphan season", Type: model.RecordTypeSeason, SeasonNumber: 1},
+		{ID: "s", Title: "Season of a season", Type: model.RecordTypeSeason, ParentID: "got-s1", SeasonNumber: 1},
+		{ID: "e", Title: "Episode of a series", Type: model.RecordTypeEpisode, ParentID: "got", EpisodeNumber: 1},
+		{ID: "e", Title: "Unnumbered episode", Type: model.RecordTypeEpisode, ParentID: "got-s1"},
+		{ID: "e", Title: "Episode of a missing season", Type: model.RecordTypeEpisode, ParentID: "missing", EpisodeNumber: 1},
+		{ID: "got", Title: "Series turned movie", Type: model.RecordTypeMovie},
+		{ID: "got-s1", Title: "Season turned series", Type: model.RecordTypeSeries},
+	} {
+		if _, err := ctrl.Put(ctx, m, 0); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("put %q: got %v, want %v", m.Title, err, ErrInvalidMetadata)
+		}
+	}
+
+	// Records without children may change type.
+	if _, err := ctrl.Put(ctx, &model.Metadata{ID: "got-s2", Title: "Season 2", Type: model.RecordTypeEpisode, ParentID: "got-s1", SeasonNumber: 1, EpisodeNumber: 3}, 0); err != nil {
+		t.Errorf("put childless season as an episode: %v", err)
+	}
+}
+
+func TestSimilarMovies(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	// Written before the index is loaded.
+	if _, err := repo.Put(ctx, "1", &model.Metadata{ID: "1", Title: "The Matrix", Director: "Lana Wachowski", Genres: []string{"Sci-Fi"}, Type: model.RecordTypeMovie}, 0); err != nil {
+		t.Fatal(err)
+	}
+	ctrl := New(repo)
+	if err := ctrl.LoadSimilar(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*model.Metadata{
+		{ID: "2", Title: "The Matrix Reloaded", Director: "Lana Wachowski", Genres: []string{"Sci-Fi"}},
+		{ID: "3", Title: "The Notebook", Director: "Nick Cassavetes", Genres: []string{"Romance"}},
+	} {
+		if _, err := ctrl.Put(ctx, m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Written by another instance after the index was loaded.
+	if _, err := repo.Put(ctx, "4", &model.Metadata{ID: "4", Title: "Speed Racer", Director: "Lana Wachowski", Type: model.RecordTypeMovie}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ctrl.SimilarMovies(ctx, "1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Metadata.ID != "2" || res[0].Metadata.Version != 1 {
+		t.Errorf("similar to 1 = %+v, want 2 then 4", res)
+	}
+	if res, err := ctrl.SimilarMovies(ctx, "4", 1); err != nil || len(res) != 1 || res[0].Metadata.ID != "1" {
+		t.Errorf("similar to 4 = %+v, %v, want 1", res, err)
+	}
+
+	// Updates move a movie.
+	if _, err := ctrl.Update(ctx, &model.Metadata{ID: "3", Director: "Lana Wachowski"}, []string{model.FieldDirector}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := ctrl.SimilarMovies(ctx, "3", 0); err != nil || len(res) != 3 {
+		t.Errorf("similar to 3 = %+v, %v, want 3 movies", res, err)
+	}
+
+	if _, err := ctrl.SimilarMovies(ctx, "missing", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing movie: got %v, want %v", err, ErrNotFound)
+	}
+}
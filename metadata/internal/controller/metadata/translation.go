@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+
+	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"golang.org/x/text/language"
+)
+
+// ErrInvalidLocale is returned when a translation locale is
+// not a valid BCP 47 language tag.
+var ErrInvalidLocale = errs.InvalidArgument("invalid locale")
+
+// PutTranslation creates or replaces the translation of a
+// movie in a locale. The locale is stored in canonical form.
+func (c *Controller) PutTranslation(ctx context.Context, t *model.Translation) error {
+	locale, err := canonicalLocale(t.Locale, "translation.locale")
+	if err != nil {
+		return err
+	}
+	stored := *t
+	stored.Locale = locale
+	if err := c.repo.PutTranslation(ctx, &stored); err != nil && errors.Is(err, repository.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteTranslation deletes the translation of a movie in a
+// locale.
+func (c *Controller) DeleteTranslation(ctx context.Context, movieID, locale string) error {
+	locale, err := canonicalLocale(locale, "locale")
+	if err != nil {
+		return err
+	}
+	if err := c.repo.DeleteTranslation(ctx, movieID, locale); err != nil && errors.Is(err, repository.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListTranslations returns the translations of a movie.
+func (c *Controller) ListTranslations(ctx context.Context, movieID string) ([]model.Translation, error) {
+	res, err := c.repo.ListTranslations(ctx, movieID)
+	if err != nil && errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return res, err
+}
+
+func canonicalLocale(locale, field string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", ErrInvalidLocale.WithViolations(errs.FieldViolation{Field: field, Description: "must be a BCP 47 language tag"})
+	}
+	return tag.String(), nil
+}
+
+// localize replaces the title and description of m with the
+// translation best matching the preferred locales, given in
+// Accept-Language form. The original is kept when it matches
+// better or nothing matches. Malformed preferences are
+// ignored.
+func localize(m *model.Metadata, translations []model.Translation, locales string) {
+	prefs, _, err := language.ParseAcceptLanguage(locales)
+	if err != nil || len(prefs) == 0 || len(translations) == 0 {
+		return
+	}
+
+	// The first supported tag is the fallback of the matcher.
+	supported := []language.Tag{language.Make(m.OriginalLanguage)}
+	for _, t := range translations {
+		supported = append(supported, language.Make(t.Locale))
+	}
+	_, i, confidence := language.NewMatcher(supported).Match(prefs...)
+	if i == 0 || confidence == language.No {
+		return
+	}
+
+	t := translations[i-1]
+	m.Title = t.Title
+	if t.Description != "" {
+		m.Description = t.Description
+	}
+	m.Locale = t.Locale
+}
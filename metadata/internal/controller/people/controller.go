@@ -0,0 +1,101 @@
+package people
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
+)
+
+// ErrNotFound is returned when a requested person, movie or
+// credit is not found.
+var ErrNotFound = errs.NotFound("not found")
+
+// ErrInvalidPerson is returned when person fields are
+// malformed.
+var ErrInvalidPerson = errs.InvalidArgument("invalid person")
+
+// ErrInvalidCredit is returned when credit fields do not fit
+// the credited role.
+var ErrInvalidCredit = errs.InvalidArgument("invalid credit")
+
+type peopleRepository interface {
+	GetPerson(ctx context.Context, id string) (*model.Person, error)
+	PutPerson(ctx context.Context, person *model.Person) error
+	DeletePerson(ctx context.Context, id string) error
+	PutCredit(ctx context.Context, credit *model.Credit) error
+	DeleteCredit(ctx context.Context, movieID, personID string, role model.Role) error
+	ListCreditsForMovie(ctx context.Context, movieID string, role model.Role) ([]model.CreditedPerson, error)
+	ListMoviesForPerson(ctx context.Context, personID string, role model.Role) ([]model.CreditedMovie, error)
+}
+
+// Controller defines a people and credits controller.
+type Controller struct {
+	repo peopleRepository
+}
+
+// New creates a new people and credits controller.
+func New(repo peopleRepository) *Controller {
+	return &Controller{repo}
+}
+
+// GetPerson returns a person by id.
+func (c *Controller) GetPerson(ctx context.Context, id string) (*model.Person, error) {
+	p, err := c.repo.GetPerson(ctx, id)
+	return p, notFound(err)
+}
+
+// PutPerson creates or replaces a person.
+func (c *Controller) PutPerson(ctx context.Context, person *model.Person) error {
+	if person.BirthDate != "" {
+		if _, err := time.Parse(model.ReleaseDateLayout, person.BirthDate); err != nil {
+			return ErrInvalidPerson.WithViolations(errs.FieldViolation{Field: "person.birth_date", Description: "must be a date in YYYY-MM-DD form"})
+		}
+	}
+	return c.repo.PutPerson(ctx, person)
+}
+
+// DeletePerson deletes a person together with their credits.
+func (c *Controller) DeletePerson(ctx context.Context, id string) error {
+	return notFound(c.repo.DeletePerson(ctx, id))
+}
+
+// PutCredit creates or replaces the credit of a person on a
+// movie. Both must exist.
+func (c *Controller) PutCredit(ctx context.Context, credit *model.Credit) error {
+	if credit.Character != "" && credit.Role != model.RoleActor {
+		return ErrInvalidCredit.WithViolations(errs.FieldViolation{Field: "credit.character", Description: "must be empty for non-actor roles"})
+	}
+	return notFound(c.repo.PutCredit(ctx, credit))
+}
+
+// DeleteCredit deletes the credit of a person on a movie.
+func (c *Controller) DeleteCredit(ctx context.Context, movieID, personID string, role model.Role) error {
+	return notFound(c.repo.DeleteCredit(ctx, movieID, personID, role))
+}
+
+// ListCreditsForMovie returns the people credited on a movie,
+// directors first, then writers, then actors, each in billing
+// order. An empty role lists all roles.
+func (c *Controller) ListCreditsForMovie(ctx context.Context, movieID string, role model.Role) ([]model.CreditedPerson, error) {
+	res, err := c.repo.ListCreditsForMovie(ctx, movieID, role)
+	return res, notFound(err)
+}
+
+// ListMoviesForPerson returns the movies a person is credited
+// on, newest first. An empty role lists all roles.
+func (c *Controller) ListMoviesForPerson(ctx context.Context, personID string, role model.Role) ([]model.CreditedMovie, error) {
+	res, err := c.repo.ListMoviesForPerson(ctx, personID, role)
+	return res, notFound(err)
+}
+
+// notFound translates repository.ErrNotFound to ErrNotFound.
+func notFound(err error) error {
+	if err != nil && errors.Is(err, repository.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
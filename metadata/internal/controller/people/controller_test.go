@@ -0,0 +1,88 @@
+package people
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/phongld0308/movie-example/metadata/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+func TestCredits(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	for _, m := range []*model.Metadata{
+		{ID: "m1", Title: "Memento", ReleaseDate: "2000-09-05"},
+		{ID: "m2", Title: "Inception", ReleaseDate: "2010-07-08"},
+	} {
+		if _, err := repo.Put(ctx, m.ID, m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctrl := New(repo)
+	for _, p := range []*model.Person{
+		{ID: "p1", Name: "Christopher Nolan"},
+		{ID: "p2", Name: "Guy Pearce"},
+		{ID: "p3", Name: "Carrie-Anne Moss"},
+	} {
+		if err := ctrl.PutPerson(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []*model.Credit{
+		{MovieID: "m1", PersonID: "p3", Role: model.RoleActor, Character: "Natalie", BillingOrder: 2},
+		{MovieID: "m1", PersonID: "p2", Role: model.RoleActor, Character: "Leonard", BillingOrder: 1},
+		{MovieID: "m1", PersonID: "p1", Role: model.RoleWriter},
+		{MovieID: "m1", PersonID: "p1", Role: model.RoleDirector},
+		{MovieID: "m2", PersonID: "p1", Role: model.RoleDirector},
+	} {
+		if err := ctrl.PutCredit(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	credits, err := ctrl.ListCreditsForMovie(ctx, "m1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range credits {
+		got = append(got, fmt.Sprintf("%s %s", c.Credit.Role, c.Person.Name))
+	}
+	if want := "[director Christopher Nolan writer Christopher Nolan actor Guy Pearce actor Carrie-Anne Moss]"; fmt.Sprint(got) != want {
+		t.Errorf("got credits %v, want %s", got, want)
+	}
+
+	movies, err := ctrl.ListMoviesForPerson(ctx, "p1", model.RoleDirector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(movies) != 2 || movies[0].Movie.Title != "Inception" || movies[1].Movie.Title != "Memento" {
+		t.Errorf("got %+v, want Inception then Memento", movies)
+	}
+
+	// Deleting a person deletes their credits.
+	if err := ctrl.DeletePerson(ctx, "p2"); err != nil {
+		t.Fatal(err)
+	}
+	if credits, _ := ctrl.ListCreditsForMovie(ctx, "m1", model.RoleActor); len(credits) != 1 {
+		t.Errorf("got %d actors after delete, want 1", len(credits))
+	}
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"missing movie", ctrl.PutCredit(ctx, &model.Credit{MovieID: "m3", PersonID: "p1", Role: model.RoleActor}), ErrNotFound},
+		{"missing person", ctrl.PutCredit(ctx, &model.Credit{MovieID: "m1", PersonID: "p4", Role: model.RoleActor}), ErrNotFound},
+		{"missing credit", ctrl.DeleteCredit(ctx, "m2", "p3", model.RoleActor), ErrNotFound},
+		{"character of a director", ctrl.PutCredit(ctx, &model.Credit{MovieID: "m2", PersonID: "p1", Role: model.RoleDirector, Character: "Cobb"}), ErrInvalidCredit},
+	} {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/internal/grpcutil"
+	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// maxBatchSize is the largest number of records the rating
+// service accepts in a batch request.
+const maxBatchSize = 100
+
+// Gateway defines a gRPC gateway for the rating service.
+type Gateway struct {
+	registry discovery.Registry
+}
+
+// New creates a new gRPC gateway for the rating service.
+func New(registry discovery.Registry) *Gateway {
+	return &Gateway{registry: registry}
+}
+
+// BatchGetRatingCounts returns the number of ratings of the
+// given records that have ratings, batching the requests as
+// the rating service allows.
+func (g *Gateway) BatchGetRatingCounts(ctx context.Context, keys []model.RecordKey) (map[model.RecordKey]int64, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "rating", g.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	client := gen.NewRatingServiceClient(conn)
+
+	res := make(map[model.RecordKey]int64, len(keys))
+	for start := 0; start < len(keys); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(keys))
+		req := &gen.BatchGetAggregatedRatingsRequest{}
+		for _, k := range keys[start:end] {
+			req.Records = append(req.Records, model.RecordKeyToProto(k))
+		}
+		resp, err := client.BatchGetAggregatedRatings(ctx, req)
+		if err != nil {
+			return nil, errs.FromGRPC(err)
+		}
+		for _, r := range resp.Ratings {
+			res[model.RecordKey{ID: model.RecordID(r.RecordId), Type: model.RecordType(r.RecordType)}] = r.RatingCount
+		}
+	}
+	return res, nil
+}
@@ -2,16 +2,17 @@ package grpc
 
 import (
 	"context"
-	"errors"
+	"strings"
 
 	"github.com/phongld0308/movie-example/gen"
 	"github.com/phongld0308/movie-example/metadata/internal/controller/metadata"
 	"github.com/phongld0308/movie-example/metadata/pkg/model"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	grpcmetadata "google.golang.org/grpc/metadata"
 )
 
-// Handler defines a movie metadata gRPC handler.
+// Handler defines a movie metadata gRPC handler. Requests are
+// expected to be validated by the validation interceptor.
 type Handler struct {
 	gen.UnimplementedMetadataServiceServer
 	ctrl *metadata.Controller
@@ -22,17 +23,17 @@ func New(ctrl *metadata.Controller) *Handler {
 	return &Handler{ctrl: ctrl}
 }
 
-// Getmetadata returns movie metadata.
-func (h *Handler) Getmetadata(ctx context.Context, req *gen.GetMetadataRequest) (*gen.GetMetadataResponse, error) {
-	if req == nil || req.MovieId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "nil req or empty id")
+// GetMetadata returns movie metadata, translated to the
+// requested locale or the accept-language request metadata.
+func (h *Handler) GetMetadata(ctx context.Context, req *gen.GetMetadataRequest) (*gen.GetMetadataResponse, error) {
+	locale := req.Locale
+	if md, ok := grpcmetadata.FromIncomingContext(ctx); ok && locale == "" {
+		locale = strings.Join(md.Get("accept-language"), ", ")
 	}
 
-	m, err := h.ctrl.Get(ctx, req.MovieId)
-	if err != nil && errors.Is(err, metadata.ErrNotFound) {
-		return nil, status.Errorf(codes.NotFound, err.Error())
-	} else if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	m, err := h.ctrl.Get(ctx, req.MovieId, locale)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
 	}
 
 	return &gen.GetMetadataResponse{
@@ -40,21 +41,129 @@ func (h *Handler) Getmetadata(ctx context.Context, req *gen.GetMetadataRequest)
 	}, nil
 }
 
+// BatchGetMetadata returns the metadata of the movies found,
+// translated as by GetMetadata.
+func (h *Handler) BatchGetMetadata(ctx context.Context, req *gen.BatchGetMetadataRequest) (*gen.BatchGetMetadataResponse, error) {
+	locale := req.Locale
+	if md, ok := grpcmetadata.FromIncomingContext(ctx); ok && locale == "" {
+		locale = strings.Join(md.Get("accept-language"), ", ")
+	}
+
+	res, err := h.ctrl.BatchGet(ctx, req.MovieIds, locale)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+
+	resp := &gen.BatchGetMetadataResponse{Metadata: make([]*gen.Metadata, len(res))}
+	for i := range res {
+		resp.Metadata[i] = model.MetadataToProto(&res[i])
+	}
+	return resp, nil
+}
+
+// PutMetadata creates or replaces movie metadata.
 func (h *Handler) PutMetadata(ctx context.Context, req *gen.PutMetadataRequest) (*gen.PutMetadataResponse, error) {
-	if req == nil || req.Metadata == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "nil req or metadata")
+	v, err := h.ctrl.Put(ctx, model.MetadataFromProto(req.Metadata), req.ExpectedVersion)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+
+	return &gen.PutMetadataResponse{Version: v}, nil
+}
+
+// UpdateMetadata changes only the metadata fields listed in
+// the update mask and returns the updated record.
+func (h *Handler) UpdateMetadata(ctx context.Context, req *gen.UpdateMetadataRequest) (*gen.UpdateMetadataResponse, error) {
+	if req.Metadata.Id == "" {
+		return nil, errs.ToGRPC(errs.InvalidArgument("invalid request", errs.FieldViolation{Field: "metadata.id", Description: "must not be empty"}))
 	}
 
-	metadata := &model.Metadata{
-		ID:          req.Metadata.Id,
-		Title:       req.Metadata.Title,
-		Description: req.Metadata.Description,
-		Director:    req.Metadata.Director,
+	m, err := h.ctrl.Update(ctx, model.MetadataFromProto(req.Metadata), req.GetUpdateMask().GetPaths(), req.ExpectedVersion)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
 	}
 
-	if err := h.ctrl.Put(ctx, metadata); err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	return &gen.UpdateMetadataResponse{Metadata: model.MetadataToProto(m)}, nil
+}
+
+// SearchMovies returns the movies matching a full-text query.
+func (h *Handler) SearchMovies(ctx context.Context, req *gen.SearchMoviesRequest) (*gen.SearchMoviesResponse, error) {
+	results, total, err := h.ctrl.Search(ctx, req.Query, int(req.Page), int(req.PageSize))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
 	}
 
-	return &gen.PutMetadataResponse{}, nil
+	resp := &gen.SearchMoviesResponse{TotalResults: int64(total)}
+	for i := range results {
+		resp.Results = append(resp.Results, model.SearchResultToProto(&results[i]))
+	}
+	return resp, nil
+}
+
+// SuggestTitles returns movie titles completing a prefix.
+func (h *Handler) SuggestTitles(_ context.Context, req *gen.SuggestTitlesRequest) (*gen.SuggestTitlesResponse, error) {
+	resp := &gen.SuggestTitlesResponse{}
+	for _, s := range h.ctrl.SuggestTitles(req.Prefix, int(req.Limit)) {
+		resp.Suggestions = append(resp.Suggestions, model.TitleSuggestionToProto(&s))
+	}
+	return resp, nil
+}
+
+// PutTranslation creates or replaces the translation of a
+// movie in a locale.
+func (h *Handler) PutTranslation(ctx context.Context, req *gen.PutTranslationRequest) (*gen.PutTranslationResponse, error) {
+	if err := h.ctrl.PutTranslation(ctx, model.TranslationFromProto(req.Translation)); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.PutTranslationResponse{}, nil
+}
+
+// DeleteTranslation deletes the translation of a movie in a
+// locale.
+func (h *Handler) DeleteTranslation(ctx context.Context, req *gen.DeleteTranslationRequest) (*gen.DeleteTranslationResponse, error) {
+	if err := h.ctrl.DeleteTranslation(ctx, req.MovieId, req.Locale); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.DeleteTranslationResponse{}, nil
+}
+
+// ListTranslations returns the translations of a movie.
+func (h *Handler) ListTranslations(ctx context.Context, req *gen.ListTranslationsRequest) (*gen.ListTranslationsResponse, error) {
+	translations, err := h.ctrl.ListTranslations(ctx, req.MovieId)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListTranslationsResponse{}
+	for i := range translations {
+		resp.Translations = append(resp.Translations, model.TranslationToProto(&translations[i]))
+	}
+	return resp, nil
+}
+
+// ListChildren returns the seasons of a series or the
+// episodes of a season.
+func (h *Handler) ListChildren(ctx context.Context, req *gen.ListChildrenRequest) (*gen.ListChildrenResponse, error) {
+	children, err := h.ctrl.ListChildren(ctx, req.ParentId)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListChildrenResponse{}
+	for i := range children {
+		resp.Children = append(resp.Children, model.MetadataToProto(&children[i]))
+	}
+	return resp, nil
+}
+
+// ListSimilarMovies returns the movies most alike a movie in
+// their metadata.
+func (h *Handler) ListSimilarMovies(ctx context.Context, req *gen.ListSimilarMoviesRequest) (*gen.ListSimilarMoviesResponse, error) {
+	movies, err := h.ctrl.SimilarMovies(ctx, req.MovieId, int(req.Limit))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListSimilarMoviesResponse{}
+	for i := range movies {
+		resp.Movies = append(resp.Movies, model.SimilarMovieToProto(&movies[i]))
+	}
+	return resp, nil
 }
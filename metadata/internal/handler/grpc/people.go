@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/metadata/internal/controller/people"
+	"github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
+)
+
+// PeopleHandler defines a people and credits gRPC handler.
+// Requests are expected to be validated by the validation
+// interceptor.
+type PeopleHandler struct {
+	gen.UnimplementedPeopleServiceServer
+	ctrl *people.Controller
+}
+
+// NewPeople creates a new people and credits gRPC handler.
+func NewPeople(ctrl *people.Controller) *PeopleHandler {
+	return &PeopleHandler{ctrl: ctrl}
+}
+
+// GetPerson returns a person.
+func (h *PeopleHandler) GetPerson(ctx context.Context, req *gen.GetPersonRequest) (*gen.GetPersonResponse, error) {
+	p, err := h.ctrl.GetPerson(ctx, req.PersonId)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.GetPersonResponse{Person: model.PersonToProto(p)}, nil
+}
+
+// PutPerson creates or replaces a person.
+func (h *PeopleHandler) PutPerson(ctx context.Context, req *gen.PutPersonRequest) (*gen.PutPersonResponse, error) {
+	if err := h.ctrl.PutPerson(ctx, model.PersonFromProto(req.Person)); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.PutPersonResponse{}, nil
+}
+
+// DeletePerson deletes a person and their credits.
+func (h *PeopleHandler) DeletePerson(ctx context.Context, req *gen.DeletePersonRequest) (*gen.DeletePersonResponse, error) {
+	if err := h.ctrl.DeletePerson(ctx, req.PersonId); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.DeletePersonResponse{}, nil
+}
+
+// PutCredit creates or replaces a credit.
+func (h *PeopleHandler) PutCredit(ctx context.Context, req *gen.PutCreditRequest) (*gen.PutCreditResponse, error) {
+	if err := h.ctrl.PutCredit(ctx, model.CreditFromProto(req.Credit)); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.PutCreditResponse{}, nil
+}
+
+// DeleteCredit deletes a credit.
+func (h *PeopleHandler) DeleteCredit(ctx context.Context, req *gen.DeleteCreditRequest) (*gen.DeleteCreditResponse, error) {
+	if err := h.ctrl.DeleteCredit(ctx, req.MovieId, req.PersonId, model.Role(req.Role)); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.DeleteCreditResponse{}, nil
+}
+
+// ListCreditsForMovie returns the people credited on a movie.
+func (h *PeopleHandler) ListCreditsForMovie(ctx context.Context, req *gen.ListCreditsForMovieRequest) (*gen.ListCreditsForMovieResponse, error) {
+	credits, err := h.ctrl.ListCreditsForMovie(ctx, req.MovieId, model.Role(req.Role))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListCreditsForMovieResponse{}
+	for i := range credits {
+		resp.Credits = append(resp.Credits, model.CreditedPersonToProto(&credits[i]))
+	}
+	return resp, nil
+}
+
+// ListMoviesForPerson returns the movies a person is credited
+// on.
+func (h *PeopleHandler) ListMoviesForPerson(ctx context.Context, req *gen.ListMoviesForPersonRequest) (*gen.ListMoviesForPersonResponse, error) {
+	movies, err := h.ctrl.ListMoviesForPerson(ctx, req.PersonId, model.Role(req.Role))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListMoviesForPersonResponse{}
+	for i := range movies {
+		resp.Movies = append(resp.Movies, model.CreditedMovieToProto(&movies[i]))
+	}
+	return resp, nil
+}
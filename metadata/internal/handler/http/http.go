@@ -2,12 +2,17 @@ package http
 
 import (
 	"encoding/json"
-	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/phongld0308/movie-example/gen"
 	"github.com/phongld0308/movie-example/metadata/internal/controller/metadata"
-	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	"github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/validation"
+	"google.golang.org/protobuf/proto"
 )
 
 // Handler defines a movie metada HTTP handler.
@@ -20,30 +25,136 @@ func New(ctrl *metadata.Controller) *Handler {
 	return &Handler{ctrl}
 }
 
+// params maps request message fields to the query
+// parameters and JSON body fields they are decoded from.
+var params = func() map[string]string {
+	names := map[string]string{"movie_id": "id"}
+	fields := (&gen.Metadata{}).ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		names["metadata."+string(fd.Name())] = fd.JSONName()
+	}
+	return names
+}()
+
+// request returns the validated message of req, reporting
+// violations under the names clients send.
+func request(req *http.Request, decode validation.DecodeFunc) (proto.Message, error) {
+	m, err := validation.Request(req, decode)
+	if err != nil {
+		return nil, validation.Rename(err, params)
+	}
+	return m, nil
+}
+
+// Register registers the metadata endpoints on mux.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/metadata", h.Handle)
+}
+
+// Handle handles GET and PUT /metadata requests.
+func (h *Handler) Handle(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		h.GetMetadata(w, req)
+	case http.MethodPut:
+		h.PutMetadata(w, req)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// DecodeGetMetadata decodes GET /metadata requests. The
+// locale parameter takes precedence over Accept-Language.
+func DecodeGetMetadata(req *http.Request) (proto.Message, error) {
+	locale := req.FormValue("locale")
+	if locale == "" {
+		locale = req.Header.Get("Accept-Language")
+	}
+	return &gen.GetMetadataRequest{MovieId: req.FormValue("id"), Locale: locale}, nil
+}
+
+// DecodePutMetadata decodes PUT /metadata requests.
+func DecodePutMetadata(req *http.Request) (proto.Message, error) {
+	var m model.Metadata
+	if err := json.NewDecoder(req.Body).Decode(&m); err != nil {
+		return nil, errs.InvalidArgument("malformed request body: " + err.Error())
+	}
+	return &gen.PutMetadataRequest{Metadata: model.MetadataToProto(&m)}, nil
+}
+
 // GetMetadata handles GET /metadata requests.
 func (h *Handler) GetMetadata(w http.ResponseWriter, req *http.Request) {
-	id := req.FormValue("id")
-
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	r, err := request(req, DecodeGetMetadata)
+	if err != nil {
+		errs.WriteHTTP(w, err)
 		return
 	}
 
 	ctx := req.Context()
 
-	m, err := h.ctrl.Get(ctx, id)
+	getReq := r.(*gen.GetMetadataRequest)
+	m, err := h.ctrl.Get(ctx, getReq.MovieId, getReq.Locale)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
 
-	if err != nil && errors.Is(err, repository.ErrNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+	w.Header().Set("ETag", etag(m.Version))
+	w.Header().Set("Vary", "Accept-Language")
+	if m.Locale != "" {
+		w.Header().Set("Content-Language", m.Locale)
+	}
+	if err := json.NewEncoder(w).Encode(m); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
 
+// PutMetadata handles PUT /metadata requests. An If-Match
+// header makes the write conditional on the current ETag.
+func (h *Handler) PutMetadata(w http.ResponseWriter, req *http.Request) {
+	r, err := request(req, DecodePutMetadata)
+	if err != nil {
+		errs.WriteHTTP(w, err)
 		return
-	} else if err != nil {
-		log.Printf("Repository get error: %v\n", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	var expected int64
+	if ifMatch := req.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" {
+		v, ok := parseETag(ifMatch)
+		if !ok {
+			errs.WriteHTTP(w, metadata.ErrVersionMismatch)
+			return
+		}
+		expected = v
+	}
+
+	v, err := h.ctrl.Put(req.Context(), model.MetadataFromProto(r.(*gen.PutMetadataRequest).Metadata), expected)
+	if err != nil {
+		errs.WriteHTTP(w, err)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(m); err != nil {
-		log.Printf("Response encode error: %v\n", err)
+	w.Header().Set("ETag", etag(v))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// etag formats a metadata version as a strong entity tag.
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseETag extracts the version from an entity tag
+// produced by etag.
+func parseETag(s string) (int64, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, `"`) || !strings.HasSuffix(s, `"`) || len(s) < 2 {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s[1:len(s)-1], 10, 64)
+	if err != nil || v <= 0 {
+		return 0, false
 	}
+	return v, true
 }
@@ -4,3 +4,7 @@ import "errors"
 
 // ErrNotFound is returned when a request record is not found.
 var ErrNotFound = errors.New("not found")
+
+// ErrVersionMismatch is returned when a conditional write
+// expects a version that differs from the stored one.
+var ErrVersionMismatch = errors.New("version mismatch")
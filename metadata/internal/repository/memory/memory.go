@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/phongld0308/movie-example/metadata/internal/repository"
@@ -10,12 +11,24 @@ import (
 
 type Repository struct {
 	sync.RWMutex
-	data map[string]*model.Metadata
+	data    map[string]*model.Metadata
+	index   *index
+	people  map[string]*model.Person
+	credits map[creditKey]model.Credit
+	// translations maps movie ids to translations by locale.
+	translations map[string]map[string]model.Translation
 }
 
 // New creates a new memory repository.
 func New() *Repository {
-	return &Repository{data: map[string]*model.Metadata{}}
+	return &Repository{
+		data:    map[string]*model.Metadata{},
+		index:   newIndex(),
+		people:  map[string]*model.Person{},
+		credits: map[creditKey]model.Credit{},
+
+		translations: map[string]map[string]model.Translation{},
+	}
 }
 
 // Get retrieves movie metadata for by movie id.
@@ -30,13 +43,148 @@ func (r *Repository) Get(_ context.Context, id string) (*model.Metadata, error)
 		return nil, repository.ErrNotFound
 	}
 
-	return m, nil
+	return m.Clone(), nil
+}
+
+// GetMany retrieves the metadata of the given movie ids that
+// exist, in no particular order.
+func (r *Repository) GetMany(_ context.Context, ids []string) ([]model.Metadata, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []model.Metadata
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if m, ok := r.data[id]; ok && !seen[id] {
+			seen[id] = true
+			res = append(res, *m.Clone())
+		}
+	}
+	return res, nil
+}
+
+// Put adds movie metadata for a given movie id and returns
+// the new record version. A non-zero expectedVersion must
+// match the stored version.
+func (r *Repository) Put(_ context.Context, id string, metadata *model.Metadata, expectedVersion int64) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+	var version int64
+	if m, ok := r.data[id]; ok {
+		version = m.Version
+	}
+	if expectedVersion != 0 && expectedVersion != version {
+		return 0, repository.ErrVersionMismatch
+	}
+
+	stored := metadata.Clone()
+	stored.Version = version + 1
+	r.data[id] = stored
+	r.index.add(stored)
+	return stored.Version, nil
 }
 
-// Put adds movie metadata for a given movie id.
-func (r *Repository) Put(_ context.Context, id string, metadata *model.Metadata) error {
+// Update changes only the listed fields of the stored
+// movie metadata and returns the updated record.
+func (r *Repository) Update(_ context.Context, id string, metadata *model.Metadata, fields []string, expectedVersion int64) (*model.Metadata, error) {
 	r.Lock()
 	defer r.Unlock()
-	r.data[id] = metadata
-	return nil
+	m, ok := r.data[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if expectedVersion != 0 && expectedVersion != m.Version {
+		return nil, repository.ErrVersionMismatch
+	}
+
+	updated := m.Clone()
+	model.ApplyFields(updated, metadata, fields)
+	updated.Version++
+	r.data[id] = updated
+	r.index.add(updated)
+
+	return updated.Clone(), nil
+}
+
+// Search returns the movies matching query, most relevant
+// first, skipping offset results. It also returns the total
+// number of matches. The query uses web search syntax:
+// quoted phrases, "or" and "-" exclusions are supported.
+func (r *Repository) Search(_ context.Context, query string, offset, limit int) ([]model.SearchResult, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	ids, scores := r.index.search(query)
+	total := len(ids)
+	if offset >= len(ids) {
+		return nil, total, nil
+	}
+	ids = ids[offset:]
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	res := make([]model.SearchResult, 0, len(ids))
+	for _, id := range ids {
+		m := r.data[id]
+		res = append(res, model.SearchResult{
+			Metadata: *m.Clone(),
+			Score:    scores[id],
+			Snippet:  snippet(query, m.Description, m.Title, m.Director),
+		})
+	}
+	return res, total, nil
+}
+
+// Titles returns the title and type of every movie. Rating
+// counts are left zero; they belong to the rating service.
+func (r *Repository) Titles(_ context.Context) ([]model.TitleSuggestion, error) {
+	r.RLock()
+	defer r.RUnlock()
+	res := make([]model.TitleSuggestion, 0, len(r.data))
+	for id, m := range r.data {
+		res = append(res, model.TitleSuggestion{ID: id, Title: m.Title, RecordType: m.Type})
+	}
+	return res, nil
+}
+
+// ListAll returns the metadata of every record.
+func (r *Repository) ListAll(_ context.Context) ([]model.Metadata, error) {
+	r.RLock()
+	defer r.RUnlock()
+	res := make([]model.Metadata, 0, len(r.data))
+	for _, m := range r.data {
+		res = append(res, *m.Clone())
+	}
+	return res, nil
+}
+
+// ListChildren returns the seasons of a series or the
+// episodes of a season in order.
+func (r *Repository) ListChildren(_ context.Context, parentID string) ([]model.Metadata, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if _, ok := r.data[parentID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	var res []model.Metadata
+	for _, m := range r.data {
+		if m.ParentID == parentID {
+			res = append(res, *m.Clone())
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if a.SeasonNumber != b.SeasonNumber {
+			return a.SeasonNumber < b.SeasonNumber
+		}
+		if a.EpisodeNumber != b.EpisodeNumber {
+			return a.EpisodeNumber < b.EpisodeNumber
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.ID < b.ID
+	})
+	return res, nil
 }
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// creditKey identifies a credit.
+type creditKey struct {
+	movieID, personID string
+	role              model.Role
+}
+
+// GetPerson retrieves a person by id.
+func (r *Repository) GetPerson(_ context.Context, id string) (*model.Person, error) {
+	r.RLock()
+	defer r.RUnlock()
+	p, ok := r.people[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return p.Clone(), nil
+}
+
+// PutPerson creates or replaces a person.
+func (r *Repository) PutPerson(_ context.Context, person *model.Person) error {
+	r.Lock()
+	defer r.Unlock()
+	r.people[person.ID] = person.Clone()
+	return nil
+}
+
+// DeletePerson deletes a person together with their credits.
+func (r *Repository) DeletePerson(_ context.Context, id string) error {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.people[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.people, id)
+	for k := range r.credits {
+		if k.personID == id {
+			delete(r.credits, k)
+		}
+	}
+	return nil
+}
+
+// PutCredit creates or replaces a credit. Both the movie and
+// the person must exist.
+func (r *Repository) PutCredit(_ context.Context, credit *model.Credit) error {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.data[credit.MovieID]; !ok {
+		return repository.ErrNotFound
+	}
+	if _, ok := r.people[credit.PersonID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.credits[creditKey{credit.MovieID, credit.PersonID, credit.Role}] = *credit
+	return nil
+}
+
+// DeleteCredit deletes a credit.
+func (r *Repository) DeleteCredit(_ context.Context, movieID, personID string, role model.Role) error {
+	r.Lock()
+	defer r.Unlock()
+	k := creditKey{movieID, personID, role}
+	if _, ok := r.credits[k]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.credits, k)
+	return nil
+}
+
+// ListCreditsForMovie returns the credits of a movie,
+// optionally limited to a role, in credits order.
+func (r *Repository) ListCreditsForMovie(_ context.Context, movieID string, role model.Role) ([]model.CreditedPerson, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if _, ok := r.data[movieID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	var res []model.CreditedPerson
+	for k, c := range r.credits {
+		if k.movieID == movieID && (role == "" || k.role == role) {
+			res = append(res, model.CreditedPerson{Credit: c, Person: *r.people[k.personID].Clone()})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if ra, rb := a.Credit.Role.Rank(), b.Credit.Role.Rank(); ra != rb {
+			return ra < rb
+		}
+		if a.Credit.BillingOrder != b.Credit.BillingOrder {
+			return a.Credit.BillingOrder < b.Credit.BillingOrder
+		}
+		if a.Person.Name != b.Person.Name {
+			return a.Person.Name < b.Person.Name
+		}
+		return a.Person.ID < b.Person.ID
+	})
+	return res, nil
+}
+
+// ListMoviesForPerson returns the credits of a person,
+// optionally limited to a role, newest movies first.
+func (r *Repository) ListMoviesForPerson(_ context.Context, personID string, role model.Role) ([]model.CreditedMovie, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if _, ok := r.people[personID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	var res []model.CreditedMovie
+	for k, c := range r.credits {
+		if k.personID == personID && (role == "" || k.role == role) {
+			res = append(res, model.CreditedMovie{Credit: c, Movie: *r.data[k.movieID].Clone()})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if a.Movie.ReleaseDate != b.Movie.ReleaseDate {
+			return a.Movie.ReleaseDate > b.Movie.ReleaseDate
+		}
+		if a.Movie.Title != b.Movie.Title {
+			return a.Movie.Title < b.Movie.Title
+		}
+		if a.Movie.ID != b.Movie.ID {
+			return a.Movie.ID < b.Movie.ID
+		}
+		return a.Credit.Role.Rank() < b.Credit.Role.Rank()
+	})
+	return res, nil
+}
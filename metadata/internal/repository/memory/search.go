@@ -0,0 +1,457 @@
+package memory
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// Field weights, matching the default ts_rank weights of the
+// A, B and C labels used by the PostgreSQL repository.
+const (
+	titleWeight       = 1.0
+	directorWeight    = 0.4
+	descriptionWeight = 0.2
+)
+
+// snippetWords is the maximum number of words in a snippet.
+const snippetWords = 20
+
+// posting holds the term frequencies of a term in the fields
+// of a single movie.
+type posting struct {
+	title, director, description int
+}
+
+func (p posting) weight() float64 {
+	var w float64
+	for _, f := range []struct {
+		tf     int
+		weight float64
+	}{{p.title, titleWeight}, {p.director, directorWeight}, {p.description, descriptionWeight}} {
+		if f.tf > 0 {
+			w += f.weight * (1 + math.Log(float64(f.tf)))
+		}
+	}
+	return w
+}
+
+// index defines an inverted index over movie metadata text.
+type index struct {
+	postings map[string]map[string]posting
+	// docs holds the terms of the title, director and
+	// description of every movie in order, with stop words
+	// left empty, to match phrases.
+	docs map[string][][]string
+}
+
+func newIndex() *index {
+	return &index{postings: map[string]map[string]posting{}, docs: map[string][][]string{}}
+}
+
+// add indexes m, replacing its previous version if any.
+func (idx *index) add(m *model.Metadata) {
+	idx.remove(m.ID)
+
+	fields := [][]string{tokens(m.Title), tokens(m.Director), tokens(m.Description)}
+	docs := map[string]posting{}
+	for i, field := range fields {
+		for _, t := range field {
+			if t == "" {
+				continue
+			}
+			p := docs[t]
+			switch i {
+			case 0:
+				p.title++
+			case 1:
+				p.director++
+			default:
+				p.description++
+			}
+			docs[t] = p
+		}
+	}
+
+	for t, p := range docs {
+		if idx.postings[t] == nil {
+			idx.postings[t] = map[string]posting{}
+		}
+		idx.postings[t][m.ID] = p
+	}
+	idx.docs[m.ID] = fields
+}
+
+func (idx *index) remove(id string) {
+	for _, field := range idx.docs[id] {
+		for _, t := range field {
+			delete(idx.postings[t], id)
+			if len(idx.postings[t]) == 0 {
+				delete(idx.postings, t)
+			}
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// search returns the IDs of movies matching query together
+// with their scores, best first. The query uses the web search
+// syntax of the PostgreSQL repository: see parseQuery.
+func (idx *index) search(query string) ([]string, map[string]float64) {
+	alts := parseQuery(query)
+	if len(alts) == 0 {
+		return nil, nil
+	}
+
+	scores := map[string]float64{}
+	for _, alt := range alts {
+		for _, id := range idx.candidates(alt) {
+			if _, ok := scores[id]; !ok && idx.matches(id, alt) {
+				scores[id] = 0
+			}
+		}
+	}
+	if len(scores) == 0 {
+		return nil, nil
+	}
+
+	n := float64(len(idx.docs))
+	for _, t := range queryTerms(alts) {
+		docs := idx.postings[t]
+		idf := math.Log(1 + n/float64(len(docs)+1))
+		for id := range scores {
+			if p, ok := docs[id]; ok {
+				scores[id] += idf * p.weight()
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids, scores
+}
+
+// candidates returns the movies that may match every clause
+// of alt: those containing its rarest positive term, or every
+// movie when all clauses are negated.
+func (idx *index) candidates(alt []clause) []string {
+	var docs map[string]posting
+	found := false
+	for _, c := range alt {
+		if c.negate {
+			continue
+		}
+		for _, t := range c.terms {
+			if p := idx.postings[t]; t != "" && (!found || len(p) < len(docs)) {
+				docs, found = p, true
+			}
+		}
+	}
+
+	var ids []string
+	if found {
+		for id := range docs {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	for id := range idx.docs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// matches reports whether the movie id contains every positive
+// clause of alt and none of the negated ones.
+func (idx *index) matches(id string, alt []clause) bool {
+	for _, c := range alt {
+		if idx.contains(id, c.terms) == c.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether a field of the movie id contains
+// phrase, with empty terms standing for any word.
+func (idx *index) contains(id string, phrase []string) bool {
+	if len(phrase) == 1 {
+		_, ok := idx.postings[phrase[0]][id]
+		return ok
+	}
+	for _, field := range idx.docs[id] {
+	next:
+		for i := 0; i+len(phrase) <= len(field); i++ {
+			for j, t := range phrase {
+				if t != "" && field[i+j] != t {
+					continue next
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// clause defines a word or quoted phrase of a search query.
+// Stop words are kept as empty terms, so that the other terms
+// of a phrase keep their distance.
+type clause struct {
+	terms  []string
+	negate bool
+}
+
+// parseQuery parses query the way websearch_to_tsquery does:
+// unquoted words and quoted phrases must all match, "or"
+// separates alternatives, a leading "-" excludes a word or
+// phrase, and stop words are ignored. Words joined by
+// punctuation, such as "sci-fi", match as a phrase.
+func parseQuery(query string) [][]clause {
+	var alts [][]clause
+	var alt []clause
+	for i := 0; i < len(query); {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		negate := false
+		if query[i] == '-' {
+			negate = true
+			i++
+		}
+
+		var text string
+		quoted := i < len(query) && query[i] == '"'
+		if quoted {
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				end = len(query) - i - 1
+			}
+			text = query[i+1 : i+1+end]
+			i += end + 2
+		} else {
+			end := strings.IndexFunc(query[i:], func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(query) - i
+			}
+			text = query[i : i+end]
+			i += end
+		}
+
+		if !quoted && !negate && strings.EqualFold(text, "or") {
+			if len(alt) > 0 {
+				alts = append(alts, alt)
+				alt = nil
+			}
+			continue
+		}
+		if terms := trimStopWords(tokens(text)); len(terms) > 0 {
+			alt = append(alt, clause{terms: terms, negate: negate})
+		}
+	}
+	if len(alt) > 0 {
+		alts = append(alts, alt)
+	}
+	return alts
+}
+
+func trimStopWords(terms []string) []string {
+	for len(terms) > 0 && terms[0] == "" {
+		terms = terms[1:]
+	}
+	for len(terms) > 0 && terms[len(terms)-1] == "" {
+		terms = terms[:len(terms)-1]
+	}
+	return terms
+}
+
+// queryTerms returns the distinct terms of the positive
+// clauses of alts, which are scored and highlighted.
+func queryTerms(alts [][]clause) []string {
+	var res []string
+	for _, alt := range alts {
+		for _, c := range alt {
+			if c.negate {
+				continue
+			}
+			for _, t := range c.terms {
+				if t != "" {
+					res = append(res, t)
+				}
+			}
+		}
+	}
+	return unique(res)
+}
+
+// tokens splits text into normalized search terms, with stop
+// words left empty.
+func tokens(text string) []string {
+	words := strings.FieldsFunc(text, isSeparator)
+	res := make([]string, 0, len(words))
+	for _, w := range words {
+		res = append(res, term(w))
+	}
+	return res
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// term returns the normalized search term of a word, or an
+// empty string for stop words.
+func term(w string) string {
+	w = strings.ToLower(w)
+	if stopWords[w] {
+		return ""
+	}
+	return normalize(w)
+}
+
+// normalize strips a plural suffix from a lower-case word, so
+// that "dreams" and "dream" match.
+func normalize(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// stopWords holds the words of the PostgreSQL english
+// dictionary that are too common to be searched for.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		a about above after again against all am an and any are as at
+		be because been before being below between both but by
+		can could did do does doing down during each few for from further
+		had has have having he her here hers herself him himself his how
+		i if in into is it its itself just me more most my myself
+		no nor not now of off on once only or other our ours ourselves out over own
+		same she should so some such than that the their theirs them themselves
+		then there these they this those through to too under until up very
+		was we were what when where which while who whom why will with would
+		you your yours yourself yourselves`) {
+		stopWords[w] = true
+	}
+}
+
+func unique(s []string) []string {
+	seen := map[string]bool{}
+	var res []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// snippet returns an excerpt of the first of texts containing
+// a query term, HTML-escaped with matching words highlighted.
+func snippet(query string, texts ...string) string {
+	match := map[string]bool{}
+	for _, t := range queryTerms(parseQuery(query)) {
+		match[t] = true
+	}
+
+	for _, text := range texts {
+		if s, ok := highlight(text, match); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+type span struct{ start, end int }
+
+// highlight wraps the words of text found in match, keeping a
+// window of words around the first match. The text is
+// HTML-escaped, so only the highlight markers are markup.
+func highlight(text string, match map[string]bool) (string, bool) {
+	var words []span
+	start := -1
+	for i, r := range text {
+		if isSeparator(r) {
+			if start >= 0 {
+				words = append(words, span{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, span{start, len(text)})
+	}
+
+	first := -1
+	for i, w := range words {
+		if match[term(text[w.start:w.end])] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	// Keep a few words of context before the first match and
+	// fill the rest of the window after it.
+	from := first - 3
+	if from < 0 {
+		from = 0
+	}
+	to := from + snippetWords
+	if to > len(words) {
+		to = len(words)
+		from = to - snippetWords
+		if from < 0 {
+			from = 0
+		}
+	}
+
+	var b strings.Builder
+	pos := 0
+	if from > 0 {
+		b.WriteString("...")
+		pos = words[from].start
+	}
+	for _, w := range words[from:to] {
+		b.WriteString(html.EscapeString(text[pos:w.start]))
+		word := html.EscapeString(text[w.start:w.end])
+		if match[term(text[w.start:w.end])] {
+			b.WriteString(model.HighlightStart + word + model.HighlightEnd)
+		} else {
+			b.WriteString(word)
+		}
+		pos = w.end
+	}
+	if to < len(words) {
+		b.WriteString("...")
+	} else {
+		b.WriteString(html.EscapeString(text[pos:]))
+	}
+	return b.String(), true
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+	for _, m := range []*model.Metadata{
+		{ID: "1", Title: "Inception", Director: "Christopher Nolan", Description: "A thief who steals secrets through dreams."},
+		{ID: "2", Title: "Dreams", Director: "Akira Kurosawa", Description: "Eight tales based on the director's recurring dream."},
+		{ID: "3", Title: "Memento", Director: "Christopher Nolan", Description: "A man with short-term memory loss hunts a killer."},
+	} {
+		if _, err := r.Put(ctx, m.ID, m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, total, err := r.Search(ctx, "dream", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(res) != 2 || res[0].Metadata.ID != "2" || res[1].Metadata.ID != "1" {
+		t.Fatalf("got %+v (total %d), want the title match before the description match", res, total)
+	}
+	if res[0].Score <= res[1].Score {
+		t.Errorf("scores are not descending: %v, %v", res[0].Score, res[1].Score)
+	}
+	if want := "A thief who steals secrets through <mark>dreams</mark>."; res[1].Snippet != want {
+		t.Errorf("got snippet %q, want %q", res[1].Snippet, want)
+	}
+
+	// Every query word must match.
+	if res, _, _ := r.Search(ctx, "nolan memory", 0, 10); len(res) != 1 || res[0].Metadata.ID != "3" {
+		t.Errorf("got %+v, want only Memento", res)
+	}
+
+	// Pages.
+	if res, total, _ := r.Search(ctx, "nolan", 1, 1); total != 2 || len(res) != 1 {
+		t.Errorf("got %d results of %d, want 1 of 2", len(res), total)
+	}
+
+	// Updates are reindexed.
+	if _, err := r.Update(ctx, "1", &model.Metadata{Description: "A heist inside the mind."}, []string{model.FieldDescription}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if res, _, _ := r.Search(ctx, "dream", 0, 10); len(res) != 1 || res[0].Metadata.ID != "2" {
+		t.Errorf("got %+v after update, want only Dreams", res)
+	}
+}
+
+func TestSearchSyntax(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+	for _, m := range []*model.Metadata{
+		{ID: "1", Title: "The Dark Knight", Director: "Christopher Nolan"},
+		{ID: "2", Title: "Knight of Cups", Director: "Terrence Malick"},
+		{ID: "3", Title: "Dark City", Director: "Alex Proyas"},
+		{ID: "4", Title: "Knight and Day", Director: "James Mangold"},
+	} {
+		if _, err := r.Put(ctx, m.ID, m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"knight", []string{"1", "2", "4"}},
+		{"dark knight", []string{"1"}},
+		{"nolan or malick", []string{"1", "2"}},
+		{"dark knight or city", []string{"1", "3"}},
+		{"knight -dark", []string{"2", "4"}},
+		{`"dark knight"`, []string{"1"}},
+		{`"knight dark"`, nil},
+		{`"knight of cups"`, []string{"2"}},
+		{`knight -"knight of cups"`, []string{"1", "4"}},
+		{"the dark knight", []string{"1"}},
+		{"the", nil},
+		{"-knight", []string{"3"}},
+		{"or", nil},
+	}
+	for _, tt := range tests {
+		res, _, err := r.Search(ctx, tt.query, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, sr := range res {
+			got = append(got, sr.Metadata.ID)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSnippetEscapesHTML(t *testing.T) {
+	got := snippet("alert", `<script>alert("x")</script> & more`)
+	want := `&lt;script&gt;<mark>alert</mark>(&#34;x&#34;)&lt;/script&gt; &amp; more`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHighlightWindow(t *testing.T) {
+	text := "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour target"
+	got, ok := highlight(text, map[string]bool{"target": true})
+	if !ok {
+		t.Fatal("no match")
+	}
+	want := "...six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour <mark>target</mark>"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
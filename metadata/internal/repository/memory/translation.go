@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// PutTranslation creates or replaces the translation of a
+// movie in a locale. The movie must exist.
+func (r *Repository) PutTranslation(_ context.Context, t *model.Translation) error {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.data[t.MovieID]; !ok {
+		return repository.ErrNotFound
+	}
+	if r.translations[t.MovieID] == nil {
+		r.translations[t.MovieID] = map[string]model.Translation{}
+	}
+	r.translations[t.MovieID][t.Locale] = *t
+	return nil
+}
+
+// DeleteTranslation deletes the translation of a movie in a
+// locale.
+func (r *Repository) DeleteTranslation(_ context.Context, movieID, locale string) error {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.translations[movieID][locale]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.translations[movieID], locale)
+	return nil
+}
+
+// ListTranslations returns the translations of a movie by
+// locale.
+func (r *Repository) ListTranslations(_ context.Context, movieID string) ([]model.Translation, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if _, ok := r.data[movieID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+	var res []model.Translation
+	for _, t := range r.translations[movieID] {
+		res = append(res, t)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Locale < res[j].Locale })
+	return res, nil
+}
+
+// ListTranslationsOf returns the translations of the given
+// movies by movie id. Movies without translations are left
+// out.
+func (r *Repository) ListTranslationsOf(_ context.Context, movieIDs []string) (map[string][]model.Translation, error) {
+	r.RLock()
+	defer r.RUnlock()
+	res := map[string][]model.Translation{}
+	for _, id := range movieIDs {
+		if _, ok := res[id]; ok || len(r.translations[id]) == 0 {
+			continue
+		}
+		for _, t := range r.translations[id] {
+			res[id] = append(res[id], t)
+		}
+		sort.Slice(res[id], func(i, j int) bool { return res[id][i].Locale < res[id][j].Locale })
+	}
+	return res, nil
+}
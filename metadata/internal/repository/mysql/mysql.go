@@ -3,6 +3,9 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/phongld0308/movie-example/metadata/internal/repository"
@@ -10,6 +13,9 @@ import (
 )
 
 // Repository defines a MySQL-based movie metadata repository.
+// It keeps the records, their versions and their hierarchy;
+// search, translations and people need the PostgreSQL
+// repository.
 type Repository struct {
 	db *sql.DB
 }
@@ -24,45 +30,256 @@ func New() (*Repository, error) {
 	return &Repository{db}, nil
 }
 
-// Get retrieves movie metatdata for by movie id.
+// metadataColumns lists the movie columns read by
+// scanMetadata, in order.
+const metadataColumns = "title, COALESCE(description, ''), COALESCE(director, ''), version, release_date, runtime_minutes, genres, original_language, spoken_languages, country, poster_url, backdrop_url, content_rating, record_type, COALESCE(parent_id, ''), season_number, episode_number"
+
+// scanMetadata scans metadataColumns into m, followed by any
+// extra columns. Genres and spoken languages are stored as
+// JSON arrays.
+func scanMetadata(row interface{ Scan(...any) error }, m *model.Metadata, extra ...any) error {
+	var releaseDate sql.NullString
+	var genres, spokenLanguages []byte
+	dest := append([]any{&m.Title, &m.Description, &m.Director, &m.Version,
+		&releaseDate, &m.RuntimeMinutes, &genres, &m.OriginalLanguage, &spokenLanguages,
+		&m.Country, &m.PosterURL, &m.BackdropURL, &m.ContentRating,
+		&m.Type, &m.ParentID, &m.SeasonNumber, &m.EpisodeNumber}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	m.ReleaseDate = releaseDate.String
+	for _, c := range []struct {
+		raw []byte
+		dst *[]string
+	}{{genres, &m.Genres}, {spokenLanguages, &m.SpokenLanguages}} {
+		if len(c.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(c.raw, c.dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullDate returns a release date column value.
+func nullDate(date string) any {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
+// nullID returns a nullable reference column value.
+func nullID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+// jsonArray returns a JSON array column value.
+func jsonArray(s []string) any {
+	if s == nil {
+		s = []string{}
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// Get retrieves movie metadata by movie id.
 func (r *Repository) Get(ctx context.Context, id string) (*model.Metadata, error) {
-	var title, description, director string
-	row := r.db.QueryRowContext(ctx, "SELECT title, description, director FROM movies WHERE id = ?", id)
-	if err := row.Scan(&title, &description, &director); err != nil {
+	res := &model.Metadata{ID: id}
+	row := r.db.QueryRowContext(ctx, "SELECT "+metadataColumns+" FROM movies WHERE id = ?", id)
+	if err := scanMetadata(row, res); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, repository.ErrNotFound
 		}
 		return nil, err
 	}
 
-	return &model.Metadata{
-		ID:          id,
-		Title:       title,
-		Description: description,
-		Director:    description,
-	}, nil
+	return res, nil
+}
+
+// GetMany retrieves the metadata of the given movie ids that
+// exist, in no particular order.
+func (r *Repository) GetMany(ctx context.Context, ids []string) ([]model.Metadata, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return r.queryMetadata(ctx, "SELECT "+metadataColumns+", id FROM movies WHERE id IN (?"+strings.Repeat(", ?", len(ids)-1)+")", args...)
+}
+
+// ListAll returns the metadata of every record.
+func (r *Repository) ListAll(ctx context.Context) ([]model.Metadata, error) {
+	return r.queryMetadata(ctx, "SELECT "+metadataColumns+", id FROM movies")
+}
+
+// ListChildren returns the seasons of a series or the
+// episodes of a season in order.
+func (r *Repository) ListChildren(ctx context.Context, parentID string) ([]model.Metadata, error) {
+	res, err := r.queryMetadata(ctx, "SELECT "+metadataColumns+", id FROM movies WHERE parent_id = ? ORDER BY season_number, episode_number, title, id", parentID)
+	if err != nil || len(res) > 0 {
+		return res, err
+	}
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM movies WHERE id = ?)", parentID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, repository.ErrNotFound
+	}
+	return nil, nil
+}
+
+// queryMetadata returns the records of a query selecting
+// metadataColumns and the id.
+func (r *Repository) queryMetadata(ctx context.Context, query string, args ...any) ([]model.Metadata, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []model.Metadata
+	for rows.Next() {
+		var m model.Metadata
+		if err := scanMetadata(rows, &m, &m.ID); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
 }
 
-// Put addas movie metadata for a given movie id.
-func (r *Repository) Put(ctx context.Context, id string, metadata *model.Metadata) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO movies (id, title, description, director) VALUES (?, ?, ?, ?)", id, metadata.Title, metadata.Description, metadata.Director)
-	return err
+// Put adds movie metadata for a given movie id and returns
+// the new record version. A non-zero expectedVersion must
+// match the stored version.
+func (r *Repository) Put(ctx context.Context, id string, metadata *model.Metadata, expectedVersion int64) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err := lockVersion(ctx, tx, id)
+	if err != nil && err != repository.ErrNotFound {
+		return 0, err
+	}
+	if expectedVersion != 0 && expectedVersion != version {
+		return 0, repository.ErrVersionMismatch
+	}
+
+	values := []any{
+		metadata.Title, metadata.Description, metadata.Director,
+		nullDate(metadata.ReleaseDate), metadata.RuntimeMinutes, jsonArray(metadata.Genres),
+		metadata.OriginalLanguage, jsonArray(metadata.SpokenLanguages), metadata.Country,
+		metadata.PosterURL, metadata.BackdropURL, metadata.ContentRating,
+		string(metadata.Type), nullID(metadata.ParentID), metadata.SeasonNumber, metadata.EpisodeNumber,
+	}
+	if err == repository.ErrNotFound {
+		_, err = tx.ExecContext(ctx, "INSERT INTO movies (title, description, director, release_date, runtime_minutes, genres, original_language, spoken_languages, country, poster_url, backdrop_url, content_rating, record_type, parent_id, season_number, episode_number, id, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)", append(values, id)...)
+	} else {
+		_, err = tx.ExecContext(ctx, "UPDATE movies SET title = ?, description = ?, director = ?, release_date = ?, runtime_minutes = ?, genres = ?, original_language = ?, spoken_languages = ?, country = ?, poster_url = ?, backdrop_url = ?, content_rating = ?, record_type = ?, parent_id = ?, season_number = ?, episode_number = ?, version = version + 1 WHERE id = ?", append(values, id)...)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return version + 1, tx.Commit()
 }
 
-func (r *Repository) GetMovieDetails(ctx context.Context, id string) (*model.Metadata, error) {
-	var title, description, director string
-	row := r.db.QueryRowContext(ctx, "SELECT title, description, director FROM movies WHERE id = ?", id)
-	if err := row.Scan(&title, &description, &director); err != nil {
+// lockVersion returns the current version of a movie,
+// locking its row until the transaction ends.
+func lockVersion(ctx context.Context, tx *sql.Tx, id string) (int64, error) {
+	var version int64
+	if err := tx.QueryRowContext(ctx, "SELECT version FROM movies WHERE id = ? FOR UPDATE", id).Scan(&version); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, repository.ErrNotFound
+			return 0, repository.ErrNotFound
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+// updateColumns maps update mask paths to table columns.
+var updateColumns = map[string]string{
+	model.FieldTitle:            "title",
+	model.FieldDescription:      "description",
+	model.FieldDirector:         "director",
+	model.FieldReleaseDate:      "release_date",
+	model.FieldRuntimeMinutes:   "runtime_minutes",
+	model.FieldGenres:           "genres",
+	model.FieldOriginalLanguage: "original_language",
+	model.FieldSpokenLanguages:  "spoken_languages",
+	model.FieldCountry:          "country",
+	model.FieldPosterURL:        "poster_url",
+	model.FieldBackdropURL:      "backdrop_url",
+	model.FieldContentRating:    "content_rating",
+}
+
+// Update changes only the listed fields of the stored
+// movie metadata and returns the updated record.
+func (r *Repository) Update(ctx context.Context, id string, metadata *model.Metadata, fields []string, expectedVersion int64) (*model.Metadata, error) {
+	values := map[string]any{
+		model.FieldTitle:            metadata.Title,
+		model.FieldDescription:      metadata.Description,
+		model.FieldDirector:         metadata.Director,
+		model.FieldReleaseDate:      nullDate(metadata.ReleaseDate),
+		model.FieldRuntimeMinutes:   metadata.RuntimeMinutes,
+		model.FieldGenres:           jsonArray(metadata.Genres),
+		model.FieldOriginalLanguage: metadata.OriginalLanguage,
+		model.FieldSpokenLanguages:  jsonArray(metadata.SpokenLanguages),
+		model.FieldCountry:          metadata.Country,
+		model.FieldPosterURL:        metadata.PosterURL,
+		model.FieldBackdropURL:      metadata.BackdropURL,
+		model.FieldContentRating:    metadata.ContentRating,
+	}
+
+	sets := []string{"version = version + 1"}
+	var args []any
+	for _, f := range fields {
+		col, ok := updateColumns[f]
+		if !ok {
+			return nil, fmt.Errorf("unsupported update field %q", f)
 		}
+		sets = append(sets, col+" = ?")
+		args = append(args, values[f])
+	}
+	args = append(args, id)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	version, err := lockVersion(ctx, tx, id)
+	if err != nil {
 		return nil, err
 	}
+	if expectedVersion != 0 && expectedVersion != version {
+		return nil, repository.ErrVersionMismatch
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE movies SET "+strings.Join(sets, ", ")+" WHERE id = ?", args...); err != nil {
+		return nil, err
+	}
+
+	res := &model.Metadata{ID: id}
+	row := tx.QueryRowContext(ctx, "SELECT "+metadataColumns+" FROM movies WHERE id = ?", id)
+	if err := scanMetadata(row, res); err != nil {
+		return nil, err
+	}
+
+	return res, tx.Commit()
+}
 
-	return &model.Metadata{
-		ID:          id,
-		Title:       title,
-		Description: description,
-		Director:    director,
-	}, nil
+// Close closes the database connection.
+func (r *Repository) Close() error {
+	return r.db.Close()
 }
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	"github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// foreignKeyViolation is the PostgreSQL error code of writes
+// referencing a missing row.
+const foreignKeyViolation = "23503"
+
+// roleRank orders credits by role, matching model.Role.Rank.
+const roleRank = "CASE c.role WHEN 'director' THEN 0 WHEN 'writer' THEN 1 ELSE 2 END"
+
+// personColumns lists the people columns read by scanPerson,
+// in order.
+const personColumns = "p.id, p.name, p.aliases, p.birth_date"
+
+func scanPerson(row interface{ Scan(...any) error }, p *model.Person, extra ...any) error {
+	var birthDate sql.NullTime
+	dest := append([]any{&p.ID, &p.Name, pq.Array(&p.Aliases), &birthDate}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	if birthDate.Valid {
+		p.BirthDate = birthDate.Time.Format(model.ReleaseDateLayout)
+	}
+	return nil
+}
+
+// GetPerson retrieves a person by id.
+func (r *Repository) GetPerson(ctx context.Context, id string) (*model.Person, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+personColumns+" FROM people p WHERE p.id = $1", id)
+	var p model.Person
+	if err := scanPerson(row, &p); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan person: %v", err)
+	}
+	return &p, nil
+}
+
+// PutPerson creates or replaces a person.
+func (r *Repository) PutPerson(ctx context.Context, person *model.Person) error {
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO people (id, name, aliases, birth_date)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (id) DO UPDATE
+		 SET name = $2, aliases = $3, birth_date = $4`,
+		person.ID, person.Name, textArray(person.Aliases), nullDate(person.BirthDate),
+	); err != nil {
+		return fmt.Errorf("failed to insert person: %v", err)
+	}
+	return nil
+}
+
+// DeletePerson deletes a person together with their credits.
+func (r *Repository) DeletePerson(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM people WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete person: %v", err)
+	}
+	return requireAffected(res)
+}
+
+// PutCredit creates or replaces a credit. Both the movie and
+// the person must exist.
+func (r *Repository) PutCredit(ctx context.Context, credit *model.Credit) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO credits (movie_id, person_id, role, character_name, billing_order)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (movie_id, person_id, role) DO UPDATE
+		 SET character_name = $4, billing_order = $5`,
+		credit.MovieID, credit.PersonID, string(credit.Role), credit.Character, credit.BillingOrder,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return repository.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to insert credit: %v", err)
+	}
+	return nil
+}
+
+// DeleteCredit deletes a credit.
+func (r *Repository) DeleteCredit(ctx context.Context, movieID, personID string, role model.Role) error {
+	res, err := r.db.ExecContext(ctx,
+		"DELETE FROM credits WHERE movie_id = $1 AND person_id = $2 AND role = $3",
+		movieID, personID, string(role),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete credit: %v", err)
+	}
+	return requireAffected(res)
+}
+
+// requireAffected returns repository.ErrNotFound if a write
+// affected no rows.
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListCreditsForMovie returns the credits of a movie,
+// optionally limited to a role, in credits order.
+func (r *Repository) ListCreditsForMovie(ctx context.Context, movieID string, role model.Role) ([]model.CreditedPerson, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+personColumns+`, c.role, c.character_name, c.billing_order
+		 FROM credits c JOIN people p ON p.id = c.person_id
+		 WHERE c.movie_id = $1 AND ($2 = '' OR c.role = $2)
+		 ORDER BY `+roleRank+`, c.billing_order, p.name, p.id`,
+		movieID, string(role),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credits: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.CreditedPerson
+	for rows.Next() {
+		cp := model.CreditedPerson{Credit: model.Credit{MovieID: movieID}}
+		if err := scanPerson(rows, &cp.Person, &cp.Credit.Role, &cp.Credit.Character, &cp.Credit.BillingOrder); err != nil {
+			return nil, fmt.Errorf("failed to scan credit: %v", err)
+		}
+		cp.Credit.PersonID = cp.Person.ID
+		res = append(res, cp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credits: %v", err)
+	}
+
+	if len(res) == 0 {
+		return nil, r.requireExists(ctx, "movies", movieID)
+	}
+	return res, nil
+}
+
+// ListMoviesForPerson returns the credits of a person,
+// optionally limited to a role, newest movies first.
+func (r *Repository) ListMoviesForPerson(ctx context.Context, personID string, role model.Role) ([]model.CreditedMovie, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+metadataColumns+`, m.id, c.role, c.character_name, c.billing_order
+		 FROM credits c JOIN movies m ON m.id = c.movie_id
+		 WHERE c.person_id = $1 AND ($2 = '' OR c.role = $2)
+		 ORDER BY m.release_date DESC NULLS LAST, m.title, m.id, `+roleRank,
+		personID, string(role),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credits: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.CreditedMovie
+	for rows.Next() {
+		cm := model.CreditedMovie{Credit: model.Credit{PersonID: personID}}
+		if err := scanMetadata(rows, &cm.Movie, &cm.Movie.ID, &cm.Credit.Role, &cm.Credit.Character, &cm.Credit.BillingOrder); err != nil {
+			return nil, fmt.Errorf("failed to scan credit: %v", err)
+		}
+		cm.Credit.MovieID = cm.Movie.ID
+		res = append(res, cm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credits: %v", err)
+	}
+
+	if len(res) == 0 {
+		return nil, r.requireExists(ctx, "people", personID)
+	}
+	return res, nil
+}
+
+// requireExists returns repository.ErrNotFound if table has
+// no row with the given id. The table name must be constant.
+func (r *Repository) requireExists(ctx context.Context, table, id string) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM "+table+" WHERE id = $1)", id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check %s: %v", table, err)
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"html"
+	"strings"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/phongld0308/movie-example/metadata/internal/repository"
 	"github.com/phongld0308/movie-example/metadata/pkg/model"
 )
@@ -36,43 +38,377 @@ func New(host string, port int, user, password, dbname string) (*Repository, err
 	return &Repository{db}, nil
 }
 
+// metadataColumns lists the movie columns read by
+// scanMetadata, in order.
+const metadataColumns = `title, COALESCE(description, ''), COALESCE(director, ''), version,
+	release_date, runtime_minutes, genres, original_language, spoken_languages,
+	country, poster_url, backdrop_url, content_rating,
+	record_type, COALESCE(parent_id, ''), season_number, episode_number`
+
+// scanMetadata scans metadataColumns into m, followed by any
+// extra columns.
+func scanMetadata(row interface{ Scan(...any) error }, m *model.Metadata, extra ...any) error {
+	var releaseDate sql.NullTime
+	dest := append([]any{
+		&m.Title, &m.Description, &m.Director, &m.Version,
+		&releaseDate, &m.RuntimeMinutes, pq.Array(&m.Genres), &m.OriginalLanguage, pq.Array(&m.SpokenLanguages),
+		&m.Country, &m.PosterURL, &m.BackdropURL, &m.ContentRating,
+		&m.Type, &m.ParentID, &m.SeasonNumber, &m.EpisodeNumber,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	if releaseDate.Valid {
+		m.ReleaseDate = releaseDate.Time.Format(model.ReleaseDateLayout)
+	}
+	return nil
+}
+
+// nullDate returns a release date column value.
+func nullDate(date string) any {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
+// nullID returns a nullable reference column value.
+func nullID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+// textArray returns a non-null text array column value.
+func textArray(s []string) any {
+	if s == nil {
+		s = []string{}
+	}
+	return pq.Array(s)
+}
+
 // Get retrieves movie metadata by movie id.
 func (r *Repository) Get(ctx context.Context, id string) (*model.Metadata, error) {
-	var title, description, director string
-
 	row := r.db.QueryRowContext(ctx,
-		"SELECT title, description, director FROM movies WHERE id = $1",
+		"SELECT "+metadataColumns+" FROM movies WHERE id = $1",
 		id,
 	)
 
-	if err := row.Scan(&title, &description, &director); err != nil {
+	res := &model.Metadata{ID: id}
+	if err := scanMetadata(row, res); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, repository.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to scan movie data: %v", err)
 	}
 
-	return &model.Metadata{
-		ID:          id,
-		Title:       title,
-		Description: description,
-		Director:    director,
-	}, nil
+	return res, nil
+}
+
+// GetMany retrieves the metadata of the given movie ids that
+// exist, in no particular order.
+func (r *Repository) GetMany(ctx context.Context, ids []string) ([]model.Metadata, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+metadataColumns+", id FROM movies WHERE id = ANY($1)",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query movies: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.Metadata
+	for rows.Next() {
+		var m model.Metadata
+		if err := scanMetadata(rows, &m, &m.ID); err != nil {
+			return nil, fmt.Errorf("failed to scan movie: %v", err)
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating movies: %v", err)
+	}
+	return res, nil
 }
 
-// Put adds movie metadata for a given movie id.
-func (r *Repository) Put(ctx context.Context, id string, metadata *model.Metadata) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO movies (id, title, description, director) 
-		 VALUES ($1, $2, $3, $4)
-		 ON CONFLICT (id) DO UPDATE 
-		 SET title = $2, description = $3, director = $4`,
+// Put adds movie metadata for a given movie id and returns
+// the new record version. A non-zero expectedVersion must
+// match the stored version.
+func (r *Repository) Put(ctx context.Context, id string, metadata *model.Metadata, expectedVersion int64) (int64, error) {
+	args := []any{
 		id, metadata.Title, metadata.Description, metadata.Director,
+		nullDate(metadata.ReleaseDate), metadata.RuntimeMinutes, textArray(metadata.Genres),
+		metadata.OriginalLanguage, textArray(metadata.SpokenLanguages), metadata.Country,
+		metadata.PosterURL, metadata.BackdropURL, metadata.ContentRating,
+		string(metadata.Type), nullID(metadata.ParentID), metadata.SeasonNumber, metadata.EpisodeNumber,
+	}
+
+	var row *sql.Row
+	if expectedVersion == 0 {
+		row = r.db.QueryRowContext(ctx,
+			`INSERT INTO movies (id, title, description, director,
+			     release_date, runtime_minutes, genres, original_language, spoken_languages,
+			     country, poster_url, backdrop_url, content_rating,
+			     record_type, parent_id, season_number, episode_number)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			 ON CONFLICT (id) DO UPDATE
+			 SET title = $2, description = $3, director = $4,
+			     release_date = $5, runtime_minutes = $6, genres = $7, original_language = $8, spoken_languages = $9,
+			     country = $10, poster_url = $11, backdrop_url = $12, content_rating = $13,
+			     record_type = $14, parent_id = $15, season_number = $16, episode_number = $17,
+			     version = movies.version + 1
+			 RETURNING version`,
+			args...,
+		)
+	} else {
+		row = r.db.QueryRowContext(ctx,
+			`UPDATE movies
+			 SET title = $2, description = $3, director = $4,
+			     release_date = $5, runtime_minutes = $6, genres = $7, original_language = $8, spoken_languages = $9,
+			     country = $10, poster_url = $11, backdrop_url = $12, content_rating = $13,
+			     record_type = $14, parent_id = $15, season_number = $16, episode_number = $17,
+			     version = version + 1
+			 WHERE id = $1 AND version = $18
+			 RETURNING version`,
+			append(args, expectedVersion)...,
+		)
+	}
+
+	var version int64
+	if err := row.Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, repository.ErrVersionMismatch
+		}
+		return 0, fmt.Errorf("failed to insert movie: %v", err)
+	}
+	return version, nil
+}
+
+// updateColumns maps update mask paths to table columns.
+var updateColumns = map[string]string{
+	model.FieldTitle:            "title",
+	model.FieldDescription:      "description",
+	model.FieldDirector:         "director",
+	model.FieldReleaseDate:      "release_date",
+	model.FieldRuntimeMinutes:   "runtime_minutes",
+	model.FieldGenres:           "genres",
+	model.FieldOriginalLanguage: "original_language",
+	model.FieldSpokenLanguages:  "spoken_languages",
+	model.FieldCountry:          "country",
+	model.FieldPosterURL:        "poster_url",
+	model.FieldBackdropURL:      "backdrop_url",
+	model.FieldContentRating:    "content_rating",
+}
+
+// Update changes only the listed fields of the stored
+// movie metadata and returns the updated record.
+func (r *Repository) Update(ctx context.Context, id string, metadata *model.Metadata, fields []string, expectedVersion int64) (*model.Metadata, error) {
+	values := map[string]any{
+		model.FieldTitle:            metadata.Title,
+		model.FieldDescription:      metadata.Description,
+		model.FieldDirector:         metadata.Director,
+		model.FieldReleaseDate:      nullDate(metadata.ReleaseDate),
+		model.FieldRuntimeMinutes:   metadata.RuntimeMinutes,
+		model.FieldGenres:           textArray(metadata.Genres),
+		model.FieldOriginalLanguage: metadata.OriginalLanguage,
+		model.FieldSpokenLanguages:  textArray(metadata.SpokenLanguages),
+		model.FieldCountry:          metadata.Country,
+		model.FieldPosterURL:        metadata.PosterURL,
+		model.FieldBackdropURL:      metadata.BackdropURL,
+		model.FieldContentRating:    metadata.ContentRating,
+	}
+
+	sets := []string{"version = version + 1"}
+	args := []any{id, expectedVersion}
+	for _, f := range fields {
+		col, ok := updateColumns[f]
+		if !ok {
+			return nil, fmt.Errorf("unsupported update field %q", f)
+		}
+		args = append(args, values[f])
+		sets = append(sets, fmt.Sprintf("%s = $%d", col, len(args)))
+	}
+
+	res := &model.Metadata{ID: id}
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE movies SET `+strings.Join(sets, ", ")+`
+		 WHERE id = $1 AND ($2 = 0 OR version = $2)
+		 RETURNING `+metadataColumns,
+		args...,
+	)
+	if err := scanMetadata(row, res); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, r.missingOrMismatch(ctx, id)
+		}
+		return nil, fmt.Errorf("failed to update movie: %v", err)
+	}
+
+	return res, nil
+}
+
+// missingOrMismatch tells apart the two reasons a
+// conditional update can match no rows.
+func (r *Repository) missingOrMismatch(ctx context.Context, id string) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM movies WHERE id = $1)", id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check movie: %v", err)
+	}
+	if exists {
+		return repository.ErrVersionMismatch
+	}
+	return repository.ErrNotFound
+}
+
+// Markers ts_headline wraps around matching words. They are
+// removed from the movie text first and replaced by the
+// highlight markers once the snippet is HTML-escaped.
+const (
+	headlineStart = "\x01"
+	headlineEnd   = "\x02"
+)
+
+// headlineOptions configures the ts_headline snippets of
+// search results.
+var headlineOptions = fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=20, MinWords=5, ShortWord=2",
+	headlineStart, headlineEnd)
+
+// headlineMarkup replaces the ts_headline markers of an
+// escaped snippet with the highlight markers.
+var headlineMarkup = strings.NewReplacer(headlineStart, model.HighlightStart, headlineEnd, model.HighlightEnd)
+
+// snippetHTML HTML-escapes a ts_headline snippet and
+// highlights its matching words.
+func snippetHTML(headline string) string {
+	return headlineMarkup.Replace(html.EscapeString(headline))
+}
+
+// Search returns the movies matching query, most relevant
+// first, skipping offset results. It also returns the total
+// number of matches. The query uses web search syntax:
+// quoted phrases, "or" and "-" exclusions are supported.
+func (r *Repository) Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+metadataColumns+`, id,
+		        ts_rank(search_vector, q) AS score,
+		        CASE WHEN to_tsvector('english', COALESCE(description, '')) @@ q
+		             THEN ts_headline('english', translate(description, $5, ''), q, $4)
+		             ELSE ts_headline('english', translate(title, $5, ''), q, $4)
+		        END AS snippet,
+		        COUNT(*) OVER () AS total
+		 FROM movies, websearch_to_tsquery('english', $1) q
+		 WHERE search_vector @@ q
+		 ORDER BY score DESC, id
+		 LIMIT $2 OFFSET $3`,
+		query, limit, offset, headlineOptions, headlineStart+headlineEnd,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to insert movie: %v", err)
+		return nil, 0, fmt.Errorf("failed to search movies: %v", err)
 	}
-	return nil
+	defer rows.Close()
+
+	var res []model.SearchResult
+	total := 0
+	for rows.Next() {
+		var sr model.SearchResult
+		if err := scanMetadata(rows, &sr.Metadata, &sr.Metadata.ID, &sr.Score, &sr.Snippet, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		sr.Snippet = snippetHTML(sr.Snippet)
+		res = append(res, sr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating search results: %v", err)
+	}
+
+	// Pages past the last match carry no window count.
+	if len(res) == 0 && offset > 0 {
+		if err := r.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM movies WHERE search_vector @@ websearch_to_tsquery('english', $1)",
+			query,
+		).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count search results: %v", err)
+		}
+	}
+
+	return res, total, nil
+}
+
+// Titles returns the title and type of every movie. Rating
+// counts are left zero; they belong to the rating service.
+func (r *Repository) Titles(ctx context.Context) ([]model.TitleSuggestion, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, title, record_type FROM movies")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query titles: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.TitleSuggestion
+	for rows.Next() {
+		var t model.TitleSuggestion
+		if err := rows.Scan(&t.ID, &t.Title, &t.RecordType); err != nil {
+			return nil, fmt.Errorf("failed to scan title: %v", err)
+		}
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating titles: %v", err)
+	}
+	return res, nil
+}
+
+// ListAll returns the metadata of every record.
+func (r *Repository) ListAll(ctx context.Context) ([]model.Metadata, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+metadataColumns+`, id FROM movies`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query movies: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.Metadata
+	for rows.Next() {
+		var m model.Metadata
+		if err := scanMetadata(rows, &m, &m.ID); err != nil {
+			return nil, fmt.Errorf("failed to scan movie: %v", err)
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating movies: %v", err)
+	}
+	return res, nil
+}
+
+// ListChildren returns the seasons of a series or the
+// episodes of a season in order.
+func (r *Repository) ListChildren(ctx context.Context, parentID string) ([]model.Metadata, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+metadataColumns+`, id FROM movies
+		 WHERE parent_id = $1
+		 ORDER BY season_number, episode_number, title, id`,
+		parentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query children: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.Metadata
+	for rows.Next() {
+		var m model.Metadata
+		if err := scanMetadata(rows, &m, &m.ID); err != nil {
+			return nil, fmt.Errorf("failed to scan child: %v", err)
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating children: %v", err)
+	}
+
+	if len(res) == 0 {
+		return nil, r.requireExists(ctx, "movies", parentID)
+	}
+	return res, nil
 }
 
 // Close closes the database connection.
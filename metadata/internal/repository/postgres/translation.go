@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/phongld0308/movie-example/metadata/internal/repository"
+	"github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// PutTranslation creates or replaces the translation of a
+// movie in a locale. The movie must exist.
+func (r *Repository) PutTranslation(ctx context.Context, t *model.Translation) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO movie_translations (movie_id, locale, title, description)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (movie_id, locale) DO UPDATE
+		 SET title = $3, description = $4`,
+		t.MovieID, t.Locale, t.Title, t.Description,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return repository.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to insert translation: %v", err)
+	}
+	return nil
+}
+
+// DeleteTranslation deletes the translation of a movie in a
+// locale.
+func (r *Repository) DeleteTranslation(ctx context.Context, movieID, locale string) error {
+	res, err := r.db.ExecContext(ctx,
+		"DELETE FROM movie_translations WHERE movie_id = $1 AND locale = $2",
+		movieID, locale,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete translation: %v", err)
+	}
+	return requireAffected(res)
+}
+
+// ListTranslations returns the translations of a movie by
+// locale.
+func (r *Repository) ListTranslations(ctx context.Context, movieID string) ([]model.Translation, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT locale, title, description FROM movie_translations WHERE movie_id = $1 ORDER BY locale",
+		movieID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.Translation
+	for rows.Next() {
+		t := model.Translation{MovieID: movieID}
+		if err := rows.Scan(&t.Locale, &t.Title, &t.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %v", err)
+		}
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating translations: %v", err)
+	}
+
+	if len(res) == 0 {
+		return nil, r.requireExists(ctx, "movies", movieID)
+	}
+	return res, nil
+}
+
+// ListTranslationsOf returns the translations of the given
+// movies by movie id. Movies without translations are left
+// out.
+func (r *Repository) ListTranslationsOf(ctx context.Context, movieIDs []string) (map[string][]model.Translation, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT movie_id, locale, title, description FROM movie_translations WHERE movie_id = ANY($1) ORDER BY movie_id, locale",
+		pq.Array(movieIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %v", err)
+	}
+	defer rows.Close()
+
+	res := map[string][]model.Translation{}
+	for rows.Next() {
+		var t model.Translation
+		if err := rows.Scan(&t.MovieID, &t.Locale, &t.Title, &t.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %v", err)
+		}
+		res[t.MovieID] = append(res[t.MovieID], t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating translations: %v", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,342 @@
+// Package similar finds records alike in their metadata: the
+// director, the genres and the words of the title and
+// description.
+package similar
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// DefaultLimit is the number of similar records returned when
+// none is requested.
+const DefaultLimit = 10
+
+// Weights of the parts of a similarity score. They sum to 1,
+// so that scores range from 0 to 1.
+const (
+	directorWeight = 0.25
+	genresWeight   = 0.35
+	textWeight     = 0.4
+)
+
+// minTermLen is the length of the shortest word indexed.
+const minTermLen = 2
+
+// maxCandidatePostings bounds the records a word, genre or
+// director may bring in as candidates. Those of more records
+// are too common to be worth scanning for the little they add
+// to a score, though they still count when scoring.
+const maxCandidatePostings = 5000
+
+// Index defines an in-memory index of record metadata. Word
+// counts are kept per record and the number of records of
+// each word over the whole index. The TF-IDF vector of a
+// record is computed when it is indexed, from the counts as
+// of then, so that records can be put one at a time; the
+// vectors follow the counts again at the next rebuild.
+type Index struct {
+	mu     sync.RWMutex
+	corpus *corpus
+	seq    uint64
+}
+
+// document defines an indexed record.
+type document struct {
+	meta     *model.Metadata
+	director string
+	genres   []string
+	terms    map[string]int
+	// vector is the TF-IDF vector of the words, of unit
+	// length, or nil if there are none.
+	vector map[string]float64
+	seq    uint64
+}
+
+// corpus defines the indexed records together with the
+// records of each word, director and genre.
+type corpus struct {
+	docs      map[string]*document
+	postings  map[string]map[string]bool
+	directors map[string]map[string]bool
+	genres    map[string]map[string]bool
+}
+
+// New creates a new empty index.
+func New() *Index {
+	return &Index{corpus: newCorpus()}
+}
+
+// Rebuild replaces the indexed records with the ones returned
+// by load. Records put while load runs are kept.
+func (x *Index) Rebuild(load func() ([]model.Metadata, error)) error {
+	x.mu.RLock()
+	seq := x.seq
+	x.mu.RUnlock()
+
+	records, err := load()
+	if err != nil {
+		return err
+	}
+	c := newCorpus()
+	for i := range records {
+		c.add(newDocument(&records[i], 0))
+	}
+	for _, d := range c.docs {
+		d.vector = c.vector(d)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for id, d := range x.corpus.docs {
+		if d.seq > seq {
+			c.remove(id)
+			c.add(d)
+			d.vector = c.vector(d)
+		}
+	}
+	x.corpus = c
+	return nil
+}
+
+// Put adds or replaces the metadata of a record.
+func (x *Index) Put(m *model.Metadata) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.seq++
+	x.corpus.remove(m.ID)
+	d := newDocument(m, x.seq)
+	x.corpus.add(d)
+	d.vector = x.corpus.vector(d)
+}
+
+// Similar returns up to limit records of the same type as the
+// record id, most similar first, and whether the record is
+// indexed. The score adds up a shared director, the Jaccard
+// index of the genres and the cosine of the TF-IDF vectors of
+// the title and description words. Records sharing none of
+// these are left out.
+func (x *Index) Similar(id string, limit int) ([]model.SimilarMovie, bool) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	c := x.corpus
+	d, ok := c.docs[id]
+	if !ok {
+		return nil, false
+	}
+
+	candidates := map[string]bool{}
+	collect := func(ids map[string]bool) {
+		if len(ids) > maxCandidatePostings {
+			return
+		}
+		for id := range ids {
+			candidates[id] = true
+		}
+	}
+	if d.director != "" {
+		collect(c.directors[d.director])
+	}
+	for _, g := range d.genres {
+		collect(c.genres[g])
+	}
+	for t := range d.terms {
+		collect(c.postings[t])
+	}
+
+	res := []model.SimilarMovie{}
+	for cid := range candidates {
+		other := c.docs[cid]
+		if cid == id || other.meta.Type != d.meta.Type {
+			continue
+		}
+		var score float64
+		if d.director != "" && other.director == d.director {
+			score += directorWeight
+		}
+		score += genresWeight * jaccard(d.genres, other.genres)
+		score += textWeight * dot(d.vector, other.vector)
+		if score > 0 {
+			res = append(res, model.SimilarMovie{Metadata: *other.meta, Score: score})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Metadata.ID < res[j].Metadata.ID
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	for i := range res {
+		res[i].Metadata = *res[i].Metadata.Clone()
+	}
+	return res, true
+}
+
+func newCorpus() *corpus {
+	return &corpus{
+		docs:      map[string]*document{},
+		postings:  map[string]map[string]bool{},
+		directors: map[string]map[string]bool{},
+		genres:    map[string]map[string]bool{},
+	}
+}
+
+func newDocument(m *model.Metadata, seq uint64) *document {
+	d := &document{
+		meta:     m.Clone(),
+		director: normalize(m.Director),
+		terms:    map[string]int{},
+		seq:      seq,
+	}
+	if d.meta.Type == "" {
+		d.meta.Type = model.RecordTypeMovie
+	}
+	seen := map[string]bool{}
+	for _, g := range m.Genres {
+		if g = normalize(g); g != "" && !seen[g] {
+			seen[g] = true
+			d.genres = append(d.genres, g)
+		}
+	}
+	for _, t := range terms(m.Title + " " + m.Description) {
+		d.terms[t]++
+	}
+	return d
+}
+
+func (c *corpus) add(d *document) {
+	id := d.meta.ID
+	c.docs[id] = d
+	for t := range d.terms {
+		insert(c.postings, t, id)
+	}
+	if d.director != "" {
+		insert(c.directors, d.director, id)
+	}
+	for _, g := range d.genres {
+		insert(c.genres, g, id)
+	}
+}
+
+func (c *corpus) remove(id string) {
+	d, ok := c.docs[id]
+	if !ok {
+		return
+	}
+	delete(c.docs, id)
+	for t := range d.terms {
+		erase(c.postings, t, id)
+	}
+	if d.director != "" {
+		erase(c.directors, d.director, id)
+	}
+	for _, g := range d.genres {
+		erase(c.genres, g, id)
+	}
+}
+
+// vector returns the TF-IDF weights of the words of d,
+// divided by their Euclidean norm, or nil if d has no words.
+// Word counts are dampened logarithmically and the inverse
+// record frequency is smoothed, so that words in every record
+// still weigh a little.
+func (c *corpus) vector(d *document) map[string]float64 {
+	if len(d.terms) == 0 {
+		return nil
+	}
+	n := float64(len(c.docs))
+	weights := make(map[string]float64, len(d.terms))
+	var sq float64
+	for t, count := range d.terms {
+		idf := math.Log((1+n)/(1+float64(len(c.postings[t])))) + 1
+		w := (1 + math.Log(float64(count))) * idf
+		weights[t] = w
+		sq += w * w
+	}
+	norm := math.Sqrt(sq)
+	for t := range weights {
+		weights[t] /= norm
+	}
+	return weights
+}
+
+// dot returns the dot product of two vectors, the cosine of
+// unit ones.
+func dot(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var res float64
+	for t, w := range a {
+		res += w * b[t]
+	}
+	return res
+}
+
+func insert(index map[string]map[string]bool, key, id string) {
+	ids := index[key]
+	if ids == nil {
+		ids = map[string]bool{}
+		index[key] = ids
+	}
+	ids[id] = true
+}
+
+func erase(index map[string]map[string]bool, key, id string) {
+	delete(index[key], id)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+// jaccard returns the size of the intersection of two sets
+// of distinct values over the size of their union.
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	var shared int
+	for _, s := range a {
+		for _, t := range b {
+			if s == t {
+				shared++
+				break
+			}
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// terms splits text into lower-case words of letters and
+// digits, leaving out the shortest.
+func terms(text string) []string {
+	var res []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(w) >= minTermLen {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+// normalize lower-cases s and collapses its spaces.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
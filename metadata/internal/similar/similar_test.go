@@ -0,0 +1,128 @@
+package similar
+
+import (
+	"fmt"
+	"testing"
+
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+func ids(res []model.SimilarMovie) []string {
+	var out []string
+	for _, s := range res {
+		out = append(out, s.Metadata.ID)
+	}
+	return out
+}
+
+func build(t testing.TB, x *Index, records []model.Metadata) {
+	t.Helper()
+	if err := x.Rebuild(func() ([]model.Metadata, error) { return records, nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var catalog = []model.Metadata{
+	{ID: "matrix", Title: "The Matrix", Description: "A hacker learns the world is a simulation run by machines.", Director: "Lana Wachowski", Genres: []string{"Action", "Sci-Fi"}},
+	{ID: "reloaded", Title: "The Matrix Reloaded", Description: "Neo fights the machines to save Zion.", Director: "Lana  wachowski", Genres: []string{"action", "sci-fi"}},
+	{ID: "speed", Title: "Speed Racer", Description: "A young driver races for his family.", Director: "Lana Wachowski", Genres: []string{"Family", "Sport"}},
+	{ID: "blade", Title: "Blade Runner", Description: "A blade runner hunts replicant machines.", Director: "Ridley Scott", Genres: []string{"Sci-Fi", "Thriller"}},
+	{ID: "notebook", Title: "The Notebook", Description: "A poor man falls in love with a rich woman.", Director: "Nick Cassavetes", Genres: []string{"Romance"}},
+	{ID: "series", Title: "The Matrix Resurrections", Director: "Lana Wachowski", Genres: []string{"Action", "Sci-Fi"}, Type: model.RecordTypeSeries},
+}
+
+func TestSimilar(t *testing.T) {
+	x := New()
+	build(t, x, catalog)
+
+	res, ok := x.Similar("matrix", 0)
+	if !ok {
+		t.Fatal("matrix not indexed")
+	}
+	// A shared director counts for more than a shared genre and
+	// word. The notebook shares only the most common word, and
+	// the series is of another type.
+	if got, want := fmt.Sprint(ids(res)), "[reloaded speed blade notebook]"; got != want {
+		t.Errorf("similar to matrix = %s, want %s", got, want)
+	}
+	for i, s := range res {
+		if s.Score <= 0 || s.Score > 1 {
+			t.Errorf("%s: score %v out of range", s.Metadata.ID, s.Score)
+		}
+		if i > 0 && s.Score > res[i-1].Score {
+			t.Errorf("%s ranks after a lower score", s.Metadata.ID)
+		}
+	}
+	if got, want := fmt.Sprint(ids(mustSimilar(t, x, "matrix", 2))), "[reloaded speed]"; got != want {
+		t.Errorf("2 most similar to matrix = %s, want %s", got, want)
+	}
+	if _, ok := x.Similar("missing", 0); ok {
+		t.Error("missing record reported as indexed")
+	}
+
+	// Returned metadata is a copy.
+	res[0].Metadata.Genres[0] = "changed"
+	if got := mustSimilar(t, x, "matrix", 1)[0].Metadata.Genres[0]; got != "action" {
+		t.Errorf("indexed genre = %q, want action", got)
+	}
+}
+
+func TestPut(t *testing.T) {
+	x := New()
+	build(t, x, catalog)
+
+	// A new record is placed as soon as it is put. Ties are
+	// broken by ID.
+	x.Put(&model.Metadata{ID: "cloud", Title: "Cloud Atlas", Description: "Lives connected across centuries.", Director: "Lana Wachowski", Genres: []string{"Drama", "Sci-Fi"}})
+	if got, want := fmt.Sprint(ids(mustSimilar(t, x, "cloud", 0))), "[matrix reloaded speed blade]"; got != want {
+		t.Errorf("similar to cloud = %s, want %s", got, want)
+	}
+
+	// Replacing a record drops its old words and genres.
+	x.Put(&model.Metadata{ID: "notebook", Title: "Machines", Description: "A hacker and machines in a simulation.", Director: "Lana Wachowski", Genres: []string{"Action", "Sci-Fi"}})
+	if got, want := fmt.Sprint(ids(mustSimilar(t, x, "matrix", 2))), "[notebook reloaded]"; got != want {
+		t.Errorf("2 most similar to matrix = %s, want %s", got, want)
+	}
+	if _, ok := x.corpus.genres["romance"]; ok {
+		t.Error("replaced genre still indexed")
+	}
+
+	// Records put while rebuilding are kept.
+	if err := x.Rebuild(func() ([]model.Metadata, error) {
+		x.Put(&model.Metadata{ID: "late", Title: "The Matrix Revolutions", Director: "Lana Wachowski", Genres: []string{"Action", "Sci-Fi"}})
+		return catalog, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := x.Similar("late", 0); !ok {
+		t.Error("record put while rebuilding was dropped")
+	}
+	if _, ok := x.Similar("cloud", 0); ok {
+		t.Error("record put before rebuilding was kept")
+	}
+}
+
+func TestCommonCandidates(t *testing.T) {
+	x := New()
+	// The genre and the word film are too common to bring in
+	// candidates, and only the record sharing a rarer word is
+	// scored.
+	var records []model.Metadata
+	for i := 0; i <= maxCandidatePostings; i++ {
+		records = append(records, model.Metadata{ID: fmt.Sprint("d", i), Title: fmt.Sprintf("Film x%d", i), Genres: []string{"Drama"}})
+	}
+	records = append(records, model.Metadata{ID: "twin", Title: "Twin x0", Genres: []string{"Drama"}})
+	build(t, x, records)
+	if got, want := fmt.Sprint(ids(mustSimilar(t, x, "d0", 0))), "[twin]"; got != want {
+		t.Errorf("similar to d0 = %s, want %s", got, want)
+	}
+}
+
+func mustSimilar(t *testing.T, x *Index, id string, limit int) []model.SimilarMovie {
+	t.Helper()
+	res, ok := x.Similar(id, limit)
+	if !ok {
+		t.Fatalf("%s not indexed", id)
+	}
+	return res
+}
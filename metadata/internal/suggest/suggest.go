@@ -0,0 +1,462 @@
+// Package suggest implements typo-tolerant title prefix
+// suggestions ranked by popularity.
+package suggest
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// DefaultLimit is the number of suggestions returned when
+// none is requested.
+const DefaultLimit = 10
+
+// minFuzzyLen is the shortest normalized prefix matched with
+// a typo. Shorter prefixes match too many titles to be useful.
+const minFuzzyLen = 4
+
+// maxFuzzyRanges bounds the title ranges a fuzzy lookup may
+// collect, so that pathological prefixes stay fast.
+const maxFuzzyRanges = 256
+
+// Index defines an in-memory title suggestion index. Titles
+// live in an immutable snapshot that is rebuilt wholesale,
+// plus the titles written since, so that writes stay cheap
+// for large catalogs.
+type Index struct {
+	mu      sync.RWMutex
+	snap    *snapshot
+	pending map[string]pendingEntry
+	seq     uint64
+}
+
+type pendingEntry struct {
+	model.TitleSuggestion
+	key string
+	seq uint64
+}
+
+// New creates a new empty suggestion index.
+func New() *Index {
+	return &Index{snap: newSnapshot(nil), pending: map[string]pendingEntry{}}
+}
+
+// Rebuild replaces the indexed titles with the ones returned
+// by load. Titles put while load runs are kept.
+func (x *Index) Rebuild(load func() ([]model.TitleSuggestion, error)) error {
+	x.mu.RLock()
+	seq := x.seq
+	x.mu.RUnlock()
+
+	titles, err := load()
+	if err != nil {
+		return err
+	}
+	snap := newSnapshot(titles)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.snap = snap
+	for id, p := range x.pending {
+		if p.seq <= seq {
+			delete(x.pending, id)
+		}
+	}
+	return nil
+}
+
+// Put adds or renames a title, keeping its rating count.
+func (x *Index) Put(id, title string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var count int64
+	if p, ok := x.pending[id]; ok {
+		count = p.RatingCount
+	} else if i, ok := x.snap.ids[id]; ok {
+		count = x.snap.entries[i].RatingCount
+	}
+	x.seq++
+	x.pending[id] = pendingEntry{
+		TitleSuggestion: model.TitleSuggestion{ID: id, Title: title, RatingCount: count},
+		key:             normalize(title),
+		seq:             x.seq,
+	}
+}
+
+// RatingCount returns the rating count of an indexed title,
+// or zero if it is not indexed.
+func (x *Index) RatingCount(id string) int64 {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if p, ok := x.pending[id]; ok {
+		return p.RatingCount
+	}
+	if i, ok := x.snap.ids[id]; ok {
+		return x.snap.entries[i].RatingCount
+	}
+	return 0
+}
+
+// Suggest returns up to limit titles starting with prefix,
+// most rated first. When fewer match exactly, titles within
+// one typo of the prefix follow.
+func (x *Index) Suggest(prefix string, limit int) []model.TitleSuggestion {
+	q := normalize(prefix)
+	if q == "" {
+		return nil
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	res := make([]model.TitleSuggestion, 0, limit)
+	seen := map[string]bool{}
+
+	lo, hi := x.snap.prefixRange(q)
+	res = x.collect(res, seen, []span{{lo, hi}}, x.pendingMatches(q, 0), limit)
+	if len(res) == limit || len(q) < minFuzzyLen {
+		return res
+	}
+
+	ranges := x.snap.fuzzyRanges(q, 1)
+	return x.collect(res, seen, ranges, x.pendingMatches(q, 1), limit)
+}
+
+// pendingMatches returns the pending titles whose key is
+// within edits of starting with q.
+func (x *Index) pendingMatches(q string, edits int) []model.TitleSuggestion {
+	var res []model.TitleSuggestion
+	for _, p := range x.pending {
+		if prefixDistance(p.key, q, edits) <= edits {
+			res = append(res, p.TitleSuggestion)
+		}
+	}
+	return res
+}
+
+// collect appends the most rated titles of ranges and extra
+// to res until it holds limit titles. Snapshot titles that
+// were put since the snapshot was built are skipped in favor
+// of their pending version.
+func (x *Index) collect(res []model.TitleSuggestion, seen map[string]bool, ranges []span, extra []model.TitleSuggestion, limit int) []model.TitleSuggestion {
+	s := x.snap
+	h := &candidates{snap: s}
+	for _, r := range ranges {
+		h.pushRange(r.lo, r.hi)
+	}
+	for i := range extra {
+		heap.Push(h, candidate{entry: &extra[i]})
+	}
+
+	for h.Len() > 0 && len(res) < limit {
+		c := heap.Pop(h).(candidate)
+		e := c.entry
+		if e == nil {
+			e = &s.entries[c.pos]
+			h.pushRange(c.lo, c.pos)
+			h.pushRange(c.pos+1, c.hi)
+			if _, ok := x.pending[e.ID]; ok {
+				continue
+			}
+		}
+		if seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		res = append(res, *e)
+	}
+	return res
+}
+
+// candidate defines a title considered for a result: either
+// a pending title or the most rated title of a snapshot range.
+type candidate struct {
+	entry       *model.TitleSuggestion
+	pos, lo, hi int
+	count       int64
+	key         string
+}
+
+type candidates struct {
+	snap  *snapshot
+	items []candidate
+}
+
+func (h *candidates) pushRange(lo, hi int) {
+	if pos := h.snap.argmax(lo, hi); pos >= 0 {
+		heap.Push(h, candidate{pos: pos, lo: lo, hi: hi})
+	}
+}
+
+func (h *candidates) Len() int { return len(h.items) }
+
+func (h *candidates) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if a.count != b.count {
+		return a.count > b.count
+	}
+	return a.key < b.key
+}
+
+func (h *candidates) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *candidates) Push(v any) {
+	c := v.(candidate)
+	if c.entry != nil {
+		c.count, c.key = c.entry.RatingCount, normalize(c.entry.Title)
+	} else {
+		c.count, c.key = h.snap.entries[c.pos].RatingCount, h.snap.keys[c.pos]
+	}
+	h.items = append(h.items, c)
+}
+
+func (h *candidates) Pop() any {
+	c := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return c
+}
+
+// normalize lower-cases a title and collapses punctuation and
+// spacing, so that "Spider-Man" is suggested for "spider man".
+func normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			space = true
+		}
+	}
+	// A trailing separator is kept so that "star " only matches
+	// "Stardust" as a typo.
+	if space && b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// prefixDistance returns the smallest edit distance between q
+// and a prefix of key, counting a swap of adjacent characters
+// as one edit, or a value above maxEdits once it is known to
+// exceed it.
+func prefixDistance(key, q string, maxEdits int) int {
+	// cur[i] is the distance between q[:i] and key[:j], prev
+	// and prev2 hold the rows for key[:j-1] and key[:j-2].
+	prev2 := make([]int, len(q)+1)
+	prev := make([]int, len(q)+1)
+	cur := make([]int, len(q)+1)
+	for i := range prev {
+		prev[i] = i
+	}
+	best := prev[len(q)]
+	for j := 1; j <= len(key); j++ {
+		cur[0] = j
+		rowMin := cur[0]
+		for i := 1; i <= len(q); i++ {
+			cost := 1
+			if q[i-1] == key[j-1] {
+				cost = 0
+			}
+			cur[i] = min(prev[i-1]+cost, prev[i]+1, cur[i-1]+1)
+			if i > 1 && j > 1 && q[i-1] == key[j-2] && q[i-2] == key[j-1] {
+				cur[i] = min(cur[i], prev2[i-2]+1)
+			}
+			rowMin = min(rowMin, cur[i])
+		}
+		best = min(best, cur[len(q)])
+		if rowMin > maxEdits {
+			break
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return best
+}
+
+// span defines a range of snapshot positions.
+type span struct{ lo, hi int }
+
+// snapshot defines an immutable index of titles sorted by
+// normalized key. Every key prefix maps to a contiguous range
+// of positions, which makes the sorted keys an implicit trie,
+// and a segment tree finds the most rated title of any range.
+type snapshot struct {
+	keys    []string
+	entries []model.TitleSuggestion
+	ids     map[string]int
+	// tree holds the position of the most rated title of each
+	// segment, with leaves at len(keys)+i.
+	tree []int32
+}
+
+func newSnapshot(titles []model.TitleSuggestion) *snapshot {
+	n := len(titles)
+	s := &snapshot{
+		keys:    make([]string, n),
+		entries: make([]model.TitleSuggestion, n),
+		ids:     make(map[string]int, n),
+		tree:    make([]int32, 2*n),
+	}
+
+	order := make([]int, n)
+	keys := make([]string, n)
+	for i := range titles {
+		order[i] = i
+		keys[i] = normalize(titles[i].Title)
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return keys[order[a]] < keys[order[b]]
+	})
+	for i, o := range order {
+		s.keys[i] = keys[o]
+		s.entries[i] = titles[o]
+		s.ids[titles[o].ID] = i
+		s.tree[n+i] = int32(i)
+	}
+	for i := n - 1; i > 0; i-- {
+		s.tree[i] = s.better(s.tree[2*i], s.tree[2*i+1])
+	}
+	return s
+}
+
+func (s *snapshot) better(a, b int32) int32 {
+	switch {
+	case a < 0:
+		return b
+	case b < 0:
+		return a
+	case s.entries[b].RatingCount > s.entries[a].RatingCount:
+		return b
+	case s.entries[b].RatingCount == s.entries[a].RatingCount && b < a:
+		return b
+	}
+	return a
+}
+
+// argmax returns the position of the most rated title in
+// [lo, hi), or -1 for an empty range.
+func (s *snapshot) argmax(lo, hi int) int {
+	n := len(s.keys)
+	res := int32(-1)
+	for l, r := lo+n, hi+n; l < r; l, r = l>>1, r>>1 {
+		if l&1 == 1 {
+			res = s.better(res, s.tree[l])
+			l++
+		}
+		if r&1 == 1 {
+			r--
+			res = s.better(res, s.tree[r])
+		}
+	}
+	return int(res)
+}
+
+// prefixRange returns the range of keys starting with q.
+func (s *snapshot) prefixRange(q string) (int, int) {
+	lo := sort.SearchStrings(s.keys, q)
+	hi := lo + sort.Search(len(s.keys)-lo, func(i int) bool {
+		return !strings.HasPrefix(s.keys[lo+i], q)
+	})
+	return lo, hi
+}
+
+// child narrows [lo, hi), whose keys share a prefix of length
+// d, to the keys continuing with c.
+func (s *snapshot) child(lo, hi, d int, c byte) (int, int) {
+	l := lo + sort.Search(hi-lo, func(i int) bool {
+		k := s.keys[lo+i]
+		return len(k) > d && k[d] >= c
+	})
+	h := l + sort.Search(hi-l, func(i int) bool {
+		return s.keys[l+i][d] > c
+	})
+	return l, h
+}
+
+// fuzzyRanges returns ranges of keys starting with a string
+// within maxEdits of q. Ranges may overlap.
+func (s *snapshot) fuzzyRanges(q string, maxEdits int) []span {
+	w := walker{s: s, q: q}
+	w.walk(0, len(s.keys), 0, 0, maxEdits)
+	return w.out
+}
+
+// smallRange is the range size below which keys are matched
+// one by one instead of walking the implicit trie.
+const smallRange = 16
+
+type walker struct {
+	s   *snapshot
+	q   string
+	out []span
+}
+
+// walk matches q[j:] against the keys of [lo, hi), which share
+// a prefix of length d, with at most edits typos.
+func (w *walker) walk(lo, hi, d, j, edits int) {
+	if lo >= hi || len(w.out) >= maxFuzzyRanges {
+		return
+	}
+	if j == len(w.q) {
+		w.out = append(w.out, span{lo, hi})
+		return
+	}
+	if hi-lo <= smallRange {
+		for i := lo; i < hi; i++ {
+			if prefixDistance(w.s.keys[i][d:], w.q[j:], edits) <= edits {
+				w.out = append(w.out, span{i, i + 1})
+			}
+		}
+		return
+	}
+
+	c := w.q[j]
+	if l, h := w.s.child(lo, hi, d, c); l < h {
+		w.walk(l, h, d+1, j+1, edits)
+	}
+	if edits == 0 {
+		return
+	}
+
+	// The query has an extra character.
+	w.walk(lo, hi, d, j+1, edits-1)
+
+	// Two adjacent characters are swapped.
+	if j+1 < len(w.q) && w.q[j+1] != c {
+		if l, h := w.s.child(lo, hi, d, w.q[j+1]); l < h {
+			if l, h := w.s.child(l, h, d+1, c); l < h {
+				w.walk(l, h, d+2, j+2, edits-1)
+			}
+		}
+	}
+
+	// The query has a wrong or a missing character.
+	start := lo
+	for start < hi && len(w.s.keys[start]) == d {
+		start++
+	}
+	for start < hi {
+		b := w.s.keys[start][d]
+		_, end := w.s.child(start, hi, d, b)
+		if b != c {
+			w.walk(start, end, d+1, j+1, edits-1)
+		}
+		w.walk(start, end, d+1, j, edits-1)
+		start = end
+	}
+}
@@ -0,0 +1,113 @@
+package suggest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+func titles(res []model.TitleSuggestion) []string {
+	var out []string
+	for _, s := range res {
+		out = append(out, s.Title)
+	}
+	return out
+}
+
+func build(t testing.TB, x *Index, titles []model.TitleSuggestion) {
+	t.Helper()
+	if err := x.Rebuild(func() ([]model.TitleSuggestion, error) { return titles, nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	x := New()
+	build(t, x, []model.TitleSuggestion{
+		{ID: "1", Title: "The Matrix", RatingCount: 50},
+		{ID: "2", Title: "The Matrix Reloaded", RatingCount: 80},
+		{ID: "3", Title: "The Mask", RatingCount: 10},
+		{ID: "4", Title: "Spider-Man", RatingCount: 30},
+		{ID: "5", Title: "Stardust", RatingCount: 5},
+	})
+
+	for _, tt := range []struct {
+		prefix string
+		limit  int
+		want   string
+	}{
+		{"the ma", 0, "[The Matrix Reloaded The Matrix The Mask]"},
+		{"the ma", 2, "[The Matrix Reloaded The Matrix]"},
+		{"spider man", 0, "[Spider-Man]"},
+		// One typo: substitution, transposition, deletion and insertion.
+		{"the matrox", 0, "[The Matrix Reloaded The Matrix]"},
+		{"the mtarix", 0, "[The Matrix Reloaded The Matrix]"},
+		{"the matix", 0, "[The Matrix Reloaded The Matrix]"},
+		{"the maatrix", 0, "[The Matrix Reloaded The Matrix]"},
+		// Exact matches come before typo matches.
+		{"the mas", 0, "[The Mask The Matrix Reloaded The Matrix]"},
+		// Short prefixes are not matched with typos.
+		{"stx", 0, "[]"},
+	} {
+		if got := fmt.Sprint(titles(x.Suggest(tt.prefix, tt.limit))); got != tt.want {
+			t.Errorf("Suggest(%q, %d) = %s, want %s", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestPut(t *testing.T) {
+	x := New()
+	build(t, x, []model.TitleSuggestion{
+		{ID: "1", Title: "Alien", RatingCount: 20},
+		{ID: "2", Title: "Aliens", RatingCount: 10},
+	})
+
+	// Renames keep the rating count and hide the old title.
+	x.Put("1", "Alien: Covenant")
+	x.Put("3", "Alien Resurrection")
+	if got, want := fmt.Sprint(titles(x.Suggest("alien", 0))), "[Alien: Covenant Aliens Alien Resurrection]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// Titles put before a rebuild are replaced by the rebuilt ones.
+	build(t, x, []model.TitleSuggestion{
+		{ID: "1", Title: "Alien: Covenant", RatingCount: 20},
+		{ID: "3", Title: "Alien Resurrection", RatingCount: 40},
+	})
+	if got, want := fmt.Sprint(titles(x.Suggest("alien", 0))), "[Alien Resurrection Alien: Covenant]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func BenchmarkSuggest(b *testing.B) {
+	words := []string{"the", "star", "dark", "night", "love", "war", "return", "last", "king", "city",
+		"dream", "shadow", "river", "ghost", "blood", "summer", "secret", "lost", "empire", "world"}
+	rnd := rand.New(rand.NewSource(1))
+	data := make([]model.TitleSuggestion, 1_000_000)
+	for i := range data {
+		title := words[rnd.Intn(len(words))]
+		for n := rnd.Intn(3); n >= 0; n-- {
+			title += " " + words[rnd.Intn(len(words))]
+		}
+		data[i] = model.TitleSuggestion{
+			ID:          fmt.Sprint(i),
+			Title:       fmt.Sprintf("%s %d", title, i),
+			RatingCount: rnd.Int63n(100_000),
+		}
+	}
+	x := New()
+	build(b, x, data)
+	for i := 0; i < 100; i++ {
+		x.Put(fmt.Sprint(i), data[i].Title)
+	}
+
+	for _, prefix := range []string{"s", "the dark", "teh dark nigth", "shadwo river"} {
+		b.Run(prefix, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				x.Suggest(prefix, DefaultLimit)
+			}
+		})
+	}
+}
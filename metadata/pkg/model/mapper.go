@@ -6,10 +6,25 @@ import "github.com/phongld0308/movie-example/gen"
 // generated proto counterpart.
 func MetadataToProto(m *Metadata) *gen.Metadata {
 	return &gen.Metadata{
-		Id:          m.ID,
-		Title:       m.Title,
-		Description: m.Description,
-		Director:    m.Director,
+		Id:               m.ID,
+		Title:            m.Title,
+		Description:      m.Description,
+		Director:         m.Director,
+		Version:          m.Version,
+		ReleaseDate:      m.ReleaseDate,
+		RuntimeMinutes:   m.RuntimeMinutes,
+		Genres:           m.Genres,
+		OriginalLanguage: m.OriginalLanguage,
+		SpokenLanguages:  m.SpokenLanguages,
+		Country:          m.Country,
+		PosterUrl:        m.PosterURL,
+		BackdropUrl:      m.BackdropURL,
+		ContentRating:    m.ContentRating,
+		Locale:           m.Locale,
+		Type:             string(m.Type),
+		ParentId:         m.ParentID,
+		SeasonNumber:     m.SeasonNumber,
+		EpisodeNumber:    m.EpisodeNumber,
 	}
 }
 
@@ -17,9 +32,148 @@ func MetadataToProto(m *Metadata) *gen.Metadata {
 // into a Metadata struct.
 func MetadataFromProto(m *gen.Metadata) *Metadata {
 	return &Metadata{
-		ID:          m.Id,
-		Title:       m.Title,
-		Description: m.Description,
-		Director:    m.Director,
+		ID:               m.Id,
+		Title:            m.Title,
+		Description:      m.Description,
+		Director:         m.Director,
+		Version:          m.Version,
+		ReleaseDate:      m.ReleaseDate,
+		RuntimeMinutes:   m.RuntimeMinutes,
+		Genres:           m.Genres,
+		OriginalLanguage: m.OriginalLanguage,
+		SpokenLanguages:  m.SpokenLanguages,
+		Country:          m.Country,
+		PosterURL:        m.PosterUrl,
+		BackdropURL:      m.BackdropUrl,
+		ContentRating:    m.ContentRating,
+		Locale:           m.Locale,
+		Type:             RecordType(m.Type),
+		ParentID:         m.ParentId,
+		SeasonNumber:     m.SeasonNumber,
+		EpisodeNumber:    m.EpisodeNumber,
+	}
+}
+
+// SearchResultToProto converts a SearchResult struct into a
+// generated proto counterpart.
+func SearchResultToProto(r *SearchResult) *gen.SearchResult {
+	return &gen.SearchResult{
+		Metadata: MetadataToProto(&r.Metadata),
+		Score:    r.Score,
+		Snippet:  r.Snippet,
+	}
+}
+
+// TitleSuggestionToProto converts a TitleSuggestion struct
+// into a generated proto counterpart.
+func TitleSuggestionToProto(s *TitleSuggestion) *gen.TitleSuggestion {
+	return &gen.TitleSuggestion{
+		Id:          s.ID,
+		Title:       s.Title,
+		RatingCount: s.RatingCount,
+	}
+}
+
+// SimilarMovieToProto converts a SimilarMovie struct into a
+// generated proto counterpart.
+func SimilarMovieToProto(m *SimilarMovie) *gen.SimilarMovie {
+	return &gen.SimilarMovie{
+		Metadata: MetadataToProto(&m.Metadata),
+		Score:    m.Score,
+	}
+}
+
+// SimilarMovieFromProto converts a generated proto
+// counterpart into a SimilarMovie struct.
+func SimilarMovieFromProto(m *gen.SimilarMovie) *SimilarMovie {
+	return &SimilarMovie{
+		Metadata: *MetadataFromProto(m.Metadata),
+		Score:    m.Score,
+	}
+}
+
+// PersonToProto converts a Person struct into a generated
+// proto counterpart.
+func PersonToProto(p *Person) *gen.Person {
+	return &gen.Person{
+		Id:        p.ID,
+		Name:      p.Name,
+		Aliases:   p.Aliases,
+		BirthDate: p.BirthDate,
+	}
+}
+
+// PersonFromProto converts a generated proto counterpart
+// into a Person struct.
+func PersonFromProto(p *gen.Person) *Person {
+	return &Person{
+		ID:        p.Id,
+		Name:      p.Name,
+		Aliases:   p.Aliases,
+		BirthDate: p.BirthDate,
+	}
+}
+
+// CreditToProto converts a Credit struct into a generated
+// proto counterpart.
+func CreditToProto(c *Credit) *gen.Credit {
+	return &gen.Credit{
+		MovieId:      c.MovieID,
+		PersonId:     c.PersonID,
+		Role:         string(c.Role),
+		Character:    c.Character,
+		BillingOrder: c.BillingOrder,
+	}
+}
+
+// CreditFromProto converts a generated proto counterpart
+// into a Credit struct.
+func CreditFromProto(c *gen.Credit) *Credit {
+	return &Credit{
+		MovieID:      c.MovieId,
+		PersonID:     c.PersonId,
+		Role:         Role(c.Role),
+		Character:    c.Character,
+		BillingOrder: c.BillingOrder,
+	}
+}
+
+// CreditedPersonToProto converts a CreditedPerson struct
+// into a generated proto counterpart.
+func CreditedPersonToProto(c *CreditedPerson) *gen.CreditedPerson {
+	return &gen.CreditedPerson{
+		Credit: CreditToProto(&c.Credit),
+		Person: PersonToProto(&c.Person),
+	}
+}
+
+// CreditedMovieToProto converts a CreditedMovie struct into
+// a generated proto counterpart.
+func CreditedMovieToProto(c *CreditedMovie) *gen.CreditedMovie {
+	return &gen.CreditedMovie{
+		Credit: CreditToProto(&c.Credit),
+		Movie:  MetadataToProto(&c.Movie),
+	}
+}
+
+// TranslationToProto converts a Translation struct into a
+// generated proto counterpart.
+func TranslationToProto(t *Translation) *gen.Translation {
+	return &gen.Translation{
+		MovieId:     t.MovieID,
+		Locale:      t.Locale,
+		Title:       t.Title,
+		Description: t.Description,
+	}
+}
+
+// TranslationFromProto converts a generated proto
+// counterpart into a Translation struct.
+func TranslationFromProto(t *gen.Translation) *Translation {
+	return &Translation{
+		MovieID:     t.MovieId,
+		Locale:      t.Locale,
+		Title:       t.Title,
+		Description: t.Description,
 	}
 }
@@ -1,8 +1,128 @@
 package model
 
+import (
+	"slices"
+
+	"github.com/phongld0308/movie-example/pkg/recordtype"
+)
+
 type Metadata struct {
 	ID          string `json:"id" yaml:"id"`
 	Title       string `json:"title" yaml:"title"`
 	Description string `json:"description" yaml:"description"`
 	Director    string `json:"director" yaml:"director"`
+	Version     int64  `json:"version" yaml:"version"`
+	// ReleaseDate is in YYYY-MM-DD form, empty if unknown.
+	ReleaseDate string `json:"releaseDate" yaml:"releaseDate"`
+	// RuntimeMinutes is zero if unknown.
+	RuntimeMinutes   int32    `json:"runtimeMinutes" yaml:"runtimeMinutes"`
+	Genres           []string `json:"genres" yaml:"genres"`
+	OriginalLanguage string   `json:"originalLanguage" yaml:"originalLanguage"`
+	SpokenLanguages  []string `json:"spokenLanguages" yaml:"spokenLanguages"`
+	Country          string   `json:"country" yaml:"country"`
+	PosterURL        string   `json:"posterUrl" yaml:"posterUrl"`
+	BackdropURL      string   `json:"backdropUrl" yaml:"backdropUrl"`
+	ContentRating    string   `json:"contentRating" yaml:"contentRating"`
+	// Locale is the locale of Title and Description when they
+	// are translated, empty for the original. It is output only.
+	Locale string `json:"locale,omitempty" yaml:"locale,omitempty"`
+	// Type is the record type, a movie when empty.
+	Type RecordType `json:"type" yaml:"type"`
+	// ParentID is the series of a season or the season of an
+	// episode.
+	ParentID      string `json:"parentId" yaml:"parentId"`
+	SeasonNumber  int32  `json:"seasonNumber" yaml:"seasonNumber"`
+	EpisodeNumber int32  `json:"episodeNumber" yaml:"episodeNumber"`
+}
+
+// RecordType defines the type of a catalog record. Record
+// types match the rating service record types.
+type RecordType string
+
+// Existing record types, as defined by package recordtype.
+// Seasons belong to a series and episodes to a season.
+const (
+	RecordTypeMovie   = RecordType(recordtype.Movie)
+	RecordTypeSeries  = RecordType(recordtype.Series)
+	RecordTypeSeason  = RecordType(recordtype.Season)
+	RecordTypeEpisode = RecordType(recordtype.Episode)
+)
+
+// ParentType returns the record type of the parent of t, or
+// an empty type if t has no parent.
+func (t RecordType) ParentType() RecordType {
+	return RecordType(recordtype.Parent(string(t)))
+}
+
+// ReleaseDateLayout is the time layout of release dates.
+const ReleaseDateLayout = "2006-01-02"
+
+// Clone returns a copy of m that shares no slices with it.
+func (m *Metadata) Clone() *Metadata {
+	res := *m
+	res.Genres = slices.Clone(m.Genres)
+	res.SpokenLanguages = slices.Clone(m.SpokenLanguages)
+	return &res
+}
+
+// Updatable metadata field paths, as used in update masks.
+const (
+	FieldTitle            = "title"
+	FieldDescription      = "description"
+	FieldDirector         = "director"
+	FieldReleaseDate      = "release_date"
+	FieldRuntimeMinutes   = "runtime_minutes"
+	FieldGenres           = "genres"
+	FieldOriginalLanguage = "original_language"
+	FieldSpokenLanguages  = "spoken_languages"
+	FieldCountry          = "country"
+	FieldPosterURL        = "poster_url"
+	FieldBackdropURL      = "backdrop_url"
+	FieldContentRating    = "content_rating"
+)
+
+// IsUpdatableField reports whether the given field path
+// can be used in a partial metadata update.
+func IsUpdatableField(path string) bool {
+	switch path {
+	case FieldTitle, FieldDescription, FieldDirector,
+		FieldReleaseDate, FieldRuntimeMinutes, FieldGenres,
+		FieldOriginalLanguage, FieldSpokenLanguages, FieldCountry,
+		FieldPosterURL, FieldBackdropURL, FieldContentRating:
+		return true
+	}
+	return false
+}
+
+// ApplyFields copies the fields listed in paths from src
+// into dst. Unknown paths are ignored.
+func ApplyFields(dst, src *Metadata, paths []string) {
+	for _, p := range paths {
+		switch p {
+		case FieldTitle:
+			dst.Title = src.Title
+		case FieldDescription:
+			dst.Description = src.Description
+		case FieldDirector:
+			dst.Director = src.Director
+		case FieldReleaseDate:
+			dst.ReleaseDate = src.ReleaseDate
+		case FieldRuntimeMinutes:
+			dst.RuntimeMinutes = src.RuntimeMinutes
+		case FieldGenres:
+			dst.Genres = slices.Clone(src.Genres)
+		case FieldOriginalLanguage:
+			dst.OriginalLanguage = src.OriginalLanguage
+		case FieldSpokenLanguages:
+			dst.SpokenLanguages = slices.Clone(src.SpokenLanguages)
+		case FieldCountry:
+			dst.Country = src.Country
+		case FieldPosterURL:
+			dst.PosterURL = src.PosterURL
+		case FieldBackdropURL:
+			dst.BackdropURL = src.BackdropURL
+		case FieldContentRating:
+			dst.ContentRating = src.ContentRating
+		}
+	}
 }
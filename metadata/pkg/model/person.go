@@ -0,0 +1,68 @@
+package model
+
+import "slices"
+
+// Person defines a person credited on movies.
+type Person struct {
+	ID      string   `json:"id" yaml:"id"`
+	Name    string   `json:"name" yaml:"name"`
+	Aliases []string `json:"aliases" yaml:"aliases"`
+	// BirthDate is in YYYY-MM-DD form, empty if unknown.
+	BirthDate string `json:"birthDate" yaml:"birthDate"`
+}
+
+// Clone returns a copy of p that shares no slices with it.
+func (p *Person) Clone() *Person {
+	res := *p
+	res.Aliases = slices.Clone(p.Aliases)
+	return &res
+}
+
+// Role defines the role of a person in a movie.
+type Role string
+
+// Existing roles, in credits order.
+const (
+	RoleDirector = Role("director")
+	RoleWriter   = Role("writer")
+	RoleActor    = Role("actor")
+)
+
+// Rank returns the position of r in movie credits.
+func (r Role) Rank() int {
+	switch r {
+	case RoleDirector:
+		return 0
+	case RoleWriter:
+		return 1
+	}
+	return 2
+}
+
+// Credit defines a link between a person and a movie they
+// worked on. A person has at most one credit per movie and
+// role.
+type Credit struct {
+	MovieID  string `json:"movieId" yaml:"movieId"`
+	PersonID string `json:"personId" yaml:"personId"`
+	Role     Role   `json:"role" yaml:"role"`
+	// Character is the character played, for actors only.
+	Character string `json:"character" yaml:"character"`
+	// BillingOrder is the position in the credits of the
+	// role, lowest first.
+	BillingOrder int32 `json:"billingOrder" yaml:"billingOrder"`
+}
+
+// CreditedPerson defines a movie credit together with the
+// credited person.
+type CreditedPerson struct {
+	Credit Credit `json:"credit"`
+	Person Person `json:"person"`
+}
+
+// CreditedMovie defines a person credit together with the
+// movie metadata.
+type CreditedMovie struct {
+	Credit Credit   `json:"credit"`
+	Movie  Metadata `json:"movie"`
+}
@@ -0,0 +1,27 @@
+package model
+
+// Markers wrapped around matching words in search snippets.
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// SearchResult defines a movie matching a search query.
+type SearchResult struct {
+	Metadata Metadata `json:"metadata"`
+	// Score is the relevance of the movie to the query;
+	// higher is better.
+	Score float64 `json:"score"`
+	// Snippet is an HTML-escaped excerpt of the movie text
+	// with matching words highlighted.
+	Snippet string `json:"snippet"`
+}
+
+// TitleSuggestion defines a title suggested for a typed
+// prefix.
+type TitleSuggestion struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	RecordType  RecordType `json:"recordType"`
+	RatingCount int64      `json:"ratingCount"`
+}
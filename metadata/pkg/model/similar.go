@@ -0,0 +1,9 @@
+package model
+
+// SimilarMovie defines a movie alike another in its metadata.
+type SimilarMovie struct {
+	Metadata Metadata `json:"metadata"`
+	// Score is the similarity of the movie to the other one,
+	// from 0 to 1.
+	Score float64 `json:"score"`
+}
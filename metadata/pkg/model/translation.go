@@ -0,0 +1,12 @@
+package model
+
+// Translation defines the title and description of a movie
+// in a locale.
+type Translation struct {
+	MovieID string `json:"movieId" yaml:"movieId"`
+	// Locale is a canonical BCP 47 language tag, such as "fr"
+	// or "pt-BR".
+	Locale      string `json:"locale" yaml:"locale"`
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description" yaml:"description"`
+}
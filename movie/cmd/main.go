@@ -13,6 +13,7 @@ import (
 	"github.com/phongld0308/movie-example/movie/internal/controller/movie"
 	metadatagateway "github.com/phongld0308/movie-example/movie/internal/gateway/metadata/grpc"
 	ratinggateway "github.com/phongld0308/movie-example/movie/internal/gateway/rating/grpc"
+	graphqlhandler "github.com/phongld0308/movie-example/movie/internal/handler/graphql"
 	httphandler "github.com/phongld0308/movie-example/movie/internal/handler/http"
 	"github.com/phongld0308/movie-example/movie/internal/repository/postgres"
 	"github.com/phongld0308/movie-example/pkg/discovery"
@@ -85,7 +86,12 @@ func main() {
 	// Initialize controller with both repository and gateways
 	ctrl := movie.NewWithRepo(repo, ratingGateway, metadataGateway)
 	h := httphandler.New(ctrl)
-	http.Handle("/movie", http.HandlerFunc(h.GetMovieDetails))
+	h.Register(http.DefaultServeMux)
+	gh, err := graphqlhandler.New(ratingGateway, metadataGateway)
+	if err != nil {
+		panic(err)
+	}
+	http.Handle("/graphql", gh)
 	if err := http.ListenAndServe(":8083", nil); err != nil {
 		panic(err)
 	}
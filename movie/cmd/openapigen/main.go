@@ -0,0 +1,29 @@
+// Command openapigen writes the OpenAPI document of the movie
+// HTTP API. Run it through go generate after changing routes
+// or response types:
+//
+//	go generate ./movie/internal/handler/http
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	httphandler "github.com/phongld0308/movie-example/movie/internal/handler/http"
+	"github.com/phongld0308/movie-example/pkg/openapi"
+)
+
+func main() {
+	var out string
+	flag.StringVar(&out, "o", "movie/internal/handler/http/docs/openapi.json", "output file")
+	flag.Parse()
+
+	b, err := openapi.Marshal(httphandler.Spec())
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+	if err := os.WriteFile(out, b, 0o644); err != nil {
+		log.Fatalf("Failed to write OpenAPI document: %v", err)
+	}
+}
@@ -2,25 +2,45 @@ package movie
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
 
 	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
 	"github.com/phongld0308/movie-example/movie/internal/gateway"
 	"github.com/phongld0308/movie-example/movie/internal/repository"
 	"github.com/phongld0308/movie-example/movie/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
 	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
 )
 
 // ErrNotFound is returned when the movie metadata is not found.
-var ErrNotFound = errors.New("movie metadata not found")
+var ErrNotFound = errs.NotFound("movie metadata not found")
+
+// ErrInvalidPageToken is returned when a page token is
+// malformed or was issued for a different query.
+var ErrInvalidPageToken = errs.InvalidArgument("invalid page token",
+	errs.FieldViolation{Field: "page_token", Description: "must be a token returned for the same query"})
+
+// Listing page sizes.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
 
 type ratingGateway interface {
-	GetAggregatedRating(ctx context.Context, recordID ratingmodel.RecordID, recordType ratingmodel.RecordType) (float64, error)
+	GetRatingStats(ctx context.Context, recordID ratingmodel.RecordID, recordType ratingmodel.RecordType) (*ratingmodel.Stats, error)
 	PutRating(ctx context.Context, recordID ratingmodel.RecordID, recordType ratingmodel.RecordType, rating *ratingmodel.Rating) error
+	ListTopRated(ctx context.Context, recordType ratingmodel.RecordType, minVotes int64, limit int, pageToken string) (*ratingmodel.TopRatedPage, error)
 }
 
 type metadataGateway interface {
-	Get(ctx context.Context, id string) (*metadatamodel.Metadata, error)
+	Get(ctx context.Context, id string, locale string) (*metadatamodel.Metadata, error)
+	BatchGet(ctx context.Context, ids []string, locale string) (map[string]*metadatamodel.Metadata, error)
+	ListSimilarMovies(ctx context.Context, id string, limit int) ([]metadatamodel.SimilarMovie, error)
 }
 
 type Controller struct {
@@ -43,10 +63,14 @@ func New(ratingGateway ratingGateway, metadataGateway metadataGateway) *Controll
 	return &Controller{ratingGateway, metadataGateway, nil}
 }
 
-// Get returns the movie details including the aggregated
-// rating and movie metadata.
-func (c *Controller) Get(ctx context.Context, id string) (*model.MovieDetails, error) {
-	metadata, err := c.metadataGateway.Get(ctx, id)
+// Get returns the movie details including the rating by the
+// aggregator configured for its type, as in top-rated lists,
+// its vote count, histogram and review count, and
+// movie metadata. The metadata is translated to the best
+// match of the preferred locales, given in Accept-Language
+// form, if any.
+func (c *Controller) Get(ctx context.Context, id string, locale string) (*model.MovieDetails, error) {
+	metadata, err := c.metadataGateway.Get(ctx, id, locale)
 	if err != nil && errors.Is(err, gateway.ErrNotFound) {
 		return nil, ErrNotFound
 	} else if err != nil {
@@ -54,14 +78,155 @@ func (c *Controller) Get(ctx context.Context, id string) (*model.MovieDetails, e
 	}
 
 	details := &model.MovieDetails{Metadata: *metadata}
-	rating, err := c.ratingGateway.GetAggregatedRating(ctx, ratingmodel.RecordID(id), ratingmodel.RecordTypeMovie)
-	if err != nil && !errors.Is(err, gateway.ErrNotFound) {
+	recordType := ratingmodel.RecordTypeMovie
+	if metadata.Type != "" {
+		recordType = ratingmodel.RecordType(metadata.Type)
+	}
+	stats, err := c.ratingGateway.GetRatingStats(ctx, ratingmodel.RecordID(id), recordType)
+	if err != nil && errors.Is(err, gateway.ErrNotFound) {
 		// Just proceed in this case, it's ok not to have rating yet.
 	} else if err != nil {
 		return nil, err
 	} else {
-		details.Rating = &rating
+		details.Rating = &stats.Rating
+		details.RatingCount = stats.Count
+		details.RatingHistogram = stats.Histogram
+		details.ReviewCount = stats.ReviewCount
 	}
 
 	return details, nil
 }
+
+// TopRated returns a page of the highest rated movies of a
+// type with at least minVotes ratings, ranked by the rating
+// service, together with their metadata in the preferred
+// locales. Movies whose metadata is gone are left out.
+func (c *Controller) TopRated(ctx context.Context, recordType metadatamodel.RecordType, minVotes int64, limit int, token string, locale string) (*model.MoviePage, error) {
+	if recordType == "" {
+		recordType = metadatamodel.RecordTypeMovie
+	}
+	ranked, err := c.ratingGateway.ListTopRated(ctx, ratingmodel.RecordType(recordType), minVotes, limit, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the metadata of the page in batches. Records
+	// missing from the catalog are left out.
+	ids := make([]string, len(ranked.Records))
+	for i, r := range ranked.Records {
+		ids[i] = string(r.RecordID)
+	}
+	metadata, err := c.metadataGateway.BatchGet(ctx, ids, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.MoviePage{Movies: []model.MovieSummary{}, NextPageToken: ranked.NextPageToken}
+	for _, r := range ranked.Records {
+		m, ok := metadata[string(r.RecordID)]
+		if !ok {
+			continue
+		}
+		rating := r.Rating
+		page.Movies = append(page.Movies, model.MovieSummary{Metadata: *m, Rating: &rating, RatingCount: r.RatingCount})
+	}
+	return page, nil
+}
+
+// Similar returns up to limit movies most alike a movie in
+// director, genres, title and description, most similar
+// first. Unlike rating based recommendations, it places
+// movies nobody has rated yet.
+func (c *Controller) Similar(ctx context.Context, id string, limit int) (*model.SimilarMovies, error) {
+	movies, err := c.metadataGateway.ListSimilarMovies(ctx, id, limit)
+	if err != nil && errors.Is(err, gateway.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &model.SimilarMovies{Movies: movies}, nil
+}
+
+// pageToken defines the decoded form of a listing page token.
+// It pins the query it was issued for, so that a token cannot
+// be replayed against different filters or order.
+type pageToken struct {
+	Query string             `json:"q"`
+	After *repository.Cursor `json:"a"`
+}
+
+// List returns a page of the movie catalog and the token of
+// the next page, which is empty on the last page.
+func (c *Controller) List(ctx context.Context, query repository.ListQuery, token string) (*model.MoviePage, error) {
+	if c.repo == nil {
+		return nil, errors.New("movie listing requires a repository")
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit > MaxPageSize {
+		query.Limit = MaxPageSize
+	}
+	if query.SortBy == "" {
+		query.SortBy = repository.SortByTitle
+	}
+
+	fingerprint := queryFingerprint(query)
+	if token != "" {
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, ErrInvalidPageToken
+		}
+		var t pageToken
+		if err := json.Unmarshal(b, &t); err != nil || t.Query != fingerprint || t.After == nil {
+			return nil, ErrInvalidPageToken
+		}
+		query.After = t.After
+	}
+
+	// Fetch one extra movie to learn whether another page follows.
+	limit := query.Limit
+	query.Limit++
+	movies, err := c.repo.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.MoviePage{Movies: movies}
+	if len(movies) > limit {
+		page.Movies = movies[:limit]
+		last := page.Movies[limit-1]
+		b, err := json.Marshal(pageToken{Query: fingerprint, After: cursorOf(query.SortBy, &last)})
+		if err != nil {
+			return nil, err
+		}
+		page.NextPageToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+	if page.Movies == nil {
+		page.Movies = []model.MovieSummary{}
+	}
+	return page, nil
+}
+
+// queryFingerprint identifies the filters and order of a
+// listing query, ignoring its page.
+func queryFingerprint(q repository.ListQuery) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%q|%q|%v|%d|%s|%t", q.RecordType, q.Director, q.TitlePrefix, q.MinRating, q.MinVotes, q.SortBy, q.Descending)
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+func cursorOf(sortBy repository.SortField, m *model.MovieSummary) *repository.Cursor {
+	c := &repository.Cursor{ID: m.Metadata.ID}
+	switch sortBy {
+	case repository.SortByTitle:
+		c.Title = m.Metadata.Title
+	case repository.SortByRating:
+		if m.Rating != nil {
+			c.Rating = *m.Rating
+		}
+	case repository.SortByRatingCount:
+		c.RatingCount = m.RatingCount
+	}
+	return c
+}
@@ -0,0 +1,172 @@
+package movie
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/movie/internal/gateway"
+	"github.com/phongld0308/movie-example/movie/internal/repository"
+	"github.com/phongld0308/movie-example/movie/pkg/model"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// listRepository implements keyset listing by title over a
+// fixed set of movies.
+type listRepository struct {
+	repository.Repository
+	movies []model.MovieSummary
+}
+
+func (r *listRepository) List(_ context.Context, q repository.ListQuery) ([]model.MovieSummary, error) {
+	movies := append([]model.MovieSummary{}, r.movies...)
+	sort.Slice(movies, func(i, j int) bool { return movies[i].Metadata.Title < movies[j].Metadata.Title })
+	var out []model.MovieSummary
+	for _, m := range movies {
+		if q.After != nil && m.Metadata.Title <= q.After.Title {
+			continue
+		}
+		if len(out) == q.Limit {
+			break
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func TestListPages(t *testing.T) {
+	repo := &listRepository{}
+	for _, title := range []string{"E", "A", "D", "B", "C"} {
+		repo.movies = append(repo.movies, model.MovieSummary{Metadata: metadatamodel.Metadata{ID: title, Title: title}})
+	}
+	ctrl := NewWithRepo(repo, nil, nil)
+	ctx := context.Background()
+
+	var titles []string
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("listing did not terminate")
+		}
+		page, err := ctrl.List(ctx, repository.ListQuery{Limit: 2}, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range page.Movies {
+			titles = append(titles, m.Metadata.Title)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		token = page.NextPageToken
+	}
+	if got := len(titles); got != 5 || titles[0] != "A" || titles[4] != "E" {
+		t.Errorf("got titles %v, want A to E", titles)
+	}
+
+	// Tokens are bound to the query they were issued for.
+	page, err := ctrl.List(ctx, repository.ListQuery{Limit: 2}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.List(ctx, repository.ListQuery{Limit: 2, Director: "Nolan"}, page.NextPageToken); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("got %v, want ErrInvalidPageToken", err)
+	}
+	if _, err := ctrl.List(ctx, repository.ListQuery{}, "not a token"); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("got %v, want ErrInvalidPageToken", err)
+	}
+}
+
+// topRatedGateway serves a fixed top-rated page.
+type topRatedGateway struct {
+	ratingGateway
+	page *ratingmodel.TopRatedPage
+}
+
+func (g *topRatedGateway) ListTopRated(context.Context, ratingmodel.RecordType, int64, int, string) (*ratingmodel.TopRatedPage, error) {
+	return g.page, nil
+}
+
+// titleGateway serves metadata with the titles of known IDs.
+type titleGateway map[string]string
+
+func (g titleGateway) Get(_ context.Context, id string, _ string) (*metadatamodel.Metadata, error) {
+	title, ok := g[id]
+	if !ok {
+		return nil, gateway.ErrNotFound
+	}
+	return &metadatamodel.Metadata{ID: id, Title: title}, nil
+}
+
+func (g titleGateway) BatchGet(ctx context.Context, ids []string, locale string) (map[string]*metadatamodel.Metadata, error) {
+	res := map[string]*metadatamodel.Metadata{}
+	for _, id := range ids {
+		if m, err := g.Get(ctx, id, locale); err == nil {
+			res[id] = m
+		}
+	}
+	return res, nil
+}
+
+// ListSimilarMovies returns the other known IDs by ID.
+func (g titleGateway) ListSimilarMovies(_ context.Context, id string, limit int) ([]metadatamodel.SimilarMovie, error) {
+	if _, ok := g[id]; !ok {
+		return nil, gateway.ErrNotFound
+	}
+	res := []metadatamodel.SimilarMovie{}
+	for other, title := range g {
+		if other != id {
+			res = append(res, metadatamodel.SimilarMovie{Metadata: metadatamodel.Metadata{ID: other, Title: title}, Score: 0.5})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Metadata.ID < res[j].Metadata.ID })
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func TestTopRated(t *testing.T) {
+	ratings := &topRatedGateway{page: &ratingmodel.TopRatedPage{
+		Records: []ratingmodel.RankedRecord{
+			{RecordID: "2", Rating: 4.5, RatingCount: 10},
+			{RecordID: "gone", Rating: 4.2, RatingCount: 8},
+			{RecordID: "1", Rating: 4, RatingCount: 30},
+		},
+		NextPageToken: "next",
+	}}
+	ctrl := New(ratings, titleGateway{"1": "Heat", "2": "Alien"})
+
+	page, err := ctrl.TopRated(context.Background(), "", 5, 3, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NextPageToken != "next" {
+		t.Errorf("got next page token %q, want next", page.NextPageToken)
+	}
+	// Records without metadata are left out and the rating
+	// order is kept.
+	if len(page.Movies) != 2 || page.Movies[0].Metadata.Title != "Alien" || page.Movies[1].Metadata.Title != "Heat" {
+		t.Fatalf("got %+v, want Alien and Heat", page.Movies)
+	}
+	if m := page.Movies[1]; *m.Rating != 4 || m.RatingCount != 30 {
+		t.Errorf("got rating %v with %d votes, want 4 with 30", *m.Rating, m.RatingCount)
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	ctrl := New(nil, titleGateway{"1": "Heat", "2": "Alien", "3": "Ronin"})
+
+	similar, err := ctrl.Similar(context.Background(), "1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar.Movies) != 1 || similar.Movies[0].Metadata.Title != "Alien" {
+		t.Errorf("got %+v, want Alien", similar.Movies)
+	}
+	if _, err := ctrl.Similar(context.Background(), "missing", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing movie: got %v, want %v", err, ErrNotFound)
+	}
+}
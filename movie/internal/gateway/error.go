@@ -1,6 +1,8 @@
 package gateway
 
-import "errors"
+import "github.com/phongld0308/movie-example/pkg/errs"
 
 // ErrNotFound is returned when the movie metadata is not found.
-var ErrNotFound = errors.New("not found")
+// It matches any not found error mapped back from a remote
+// service.
+var ErrNotFound = errs.ErrNotFound
@@ -7,8 +7,13 @@ import (
 	"github.com/phongld0308/movie-example/internal/grpcutil"
 	"github.com/phongld0308/movie-example/metadata/pkg/model"
 	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/errs"
 )
 
+// maxBatchSize is the largest number of movies the metadata
+// service accepts in a batch request.
+const maxBatchSize = 100
+
 // Gateway defines a movie metadata gRPC gateway.
 type Gateway struct {
 	registry discovery.Registry
@@ -19,8 +24,9 @@ func New(registry discovery.Registry) *Gateway {
 	return &Gateway{registry: registry}
 }
 
-// Get turns movie metadata by movie id.
-func (g *Gateway) Get(ctx context.Context, id string) (*model.Metadata, error) {
+// Get turns movie metadata by movie id, translated to the
+// best match of the preferred locales if any.
+func (g *Gateway) Get(ctx context.Context, id string, locale string) (*model.Metadata, error) {
 	conn, err := grpcutil.ServiceConnection(ctx, "metadata", g.registry)
 	if err != nil {
 		return nil, err
@@ -29,10 +35,59 @@ func (g *Gateway) Get(ctx context.Context, id string) (*model.Metadata, error) {
 	defer conn.Close()
 	client := gen.NewMetadataServiceClient(conn)
 
-	resp, err := client.GetMetadata(ctx, &gen.GetMetadataRequest{MovieId: id})
+	resp, err := client.GetMetadata(ctx, &gen.GetMetadataRequest{MovieId: id, Locale: locale})
 	if err != nil {
-		return nil, err
+		return nil, errs.FromGRPC(err)
 	}
 
 	return model.MetadataFromProto(resp.Metadata), nil
 }
+
+// BatchGet returns the metadata of the given movie ids that
+// exist by id, translated as by Get, batching the requests as
+// the metadata service allows.
+func (g *Gateway) BatchGet(ctx context.Context, ids []string, locale string) (map[string]*model.Metadata, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "metadata", g.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	client := gen.NewMetadataServiceClient(conn)
+
+	res := make(map[string]*model.Metadata, len(ids))
+	for start := 0; start < len(ids); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(ids))
+		resp, err := client.BatchGetMetadata(ctx, &gen.BatchGetMetadataRequest{MovieIds: ids[start:end], Locale: locale})
+		if err != nil {
+			return nil, errs.FromGRPC(err)
+		}
+		for _, m := range resp.Metadata {
+			res[m.Id] = model.MetadataFromProto(m)
+		}
+	}
+	return res, nil
+}
+
+// ListSimilarMovies returns up to limit movies most alike a
+// movie in their metadata, most similar first.
+func (g *Gateway) ListSimilarMovies(ctx context.Context, id string, limit int) ([]model.SimilarMovie, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "metadata", g.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	client := gen.NewMetadataServiceClient(conn)
+
+	resp, err := client.ListSimilarMovies(ctx, &gen.ListSimilarMoviesRequest{MovieId: id, Limit: int32(limit)})
+	if err != nil {
+		return nil, errs.FromGRPC(err)
+	}
+
+	res := make([]model.SimilarMovie, 0, len(resp.Movies))
+	for _, m := range resp.Movies {
+		res = append(res, *model.SimilarMovieFromProto(m))
+	}
+	return res, nil
+}
@@ -3,14 +3,13 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"log"
 	"math/rand"
 	"net/http"
 
 	model "github.com/phongld0308/movie-example/metadata/pkg/model"
-	"github.com/phongld0308/movie-example/movie/internal/gateway"
 	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/errs"
 )
 
 // Gateway defines a movie metadata HTTP gateway.
@@ -24,8 +23,9 @@ func New(registry discovery.Registry) *Gateway {
 	return &Gateway{registry}
 }
 
-// Get gets movie metadata by a movie id.
-func (g *Gateway) Get(ctx context.Context, id string) (*model.Metadata, error) {
+// Get gets movie metadata by a movie id, translated to the
+// best match of the preferred locales if any.
+func (g *Gateway) Get(ctx context.Context, id string, locale string) (*model.Metadata, error) {
 	addrs, err := g.registry.ServiceAddresses(ctx, "metadata")
 	if err != nil {
 		return nil, err
@@ -41,6 +41,9 @@ func (g *Gateway) Get(ctx context.Context, id string) (*model.Metadata, error) {
 	req = req.WithContext(ctx)
 	values := req.URL.Query()
 	values.Add("id", id)
+	if locale != "" {
+		values.Add("locale", locale)
+	}
 
 	req.URL.RawQuery = values.Encode()
 
@@ -52,10 +55,8 @@ func (g *Gateway) Get(ctx context.Context, id string) (*model.Metadata, error) {
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, gateway.ErrNotFound
-	} else if resp.StatusCode/100 != 2 {
-		return nil, fmt.Errorf("non-2xx response: %v", resp)
+	if err := errs.FromHTTP(resp); err != nil {
+		return nil, err
 	}
 
 	var v *model.Metadata
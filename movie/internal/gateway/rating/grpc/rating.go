@@ -6,6 +6,7 @@ import (
 	"github.com/phongld0308/movie-example/gen"
 	"github.com/phongld0308/movie-example/internal/grpcutil"
 	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/errs"
 	model "github.com/phongld0308/movie-example/rating/pkg/model"
 )
 
@@ -33,13 +34,41 @@ func (g *Gateway) GetAggregatedRating(ctx context.Context, recordID model.Record
 
 	resp, err := client.GetAggregatedRating(ctx, &gen.GetAggregatedRatingRequest{RecordId: string(recordID), RecordType: string(recordType)})
 	if err != nil {
-		return 0, err
+		return 0, errs.FromGRPC(err)
 	}
 
 	return resp.RatingValue, nil
 }
 
-// PutRating returns the aggregated rating for a record or ErrNotFound if there are no ratings for it.
+// BatchGetAggregatedRatings returns the aggregated ratings of
+// the given records that have ratings.
+func (g *Gateway) BatchGetAggregatedRatings(ctx context.Context, keys []model.RecordKey) (map[model.RecordKey]float64, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "rating", g.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	client := gen.NewRatingServiceClient(conn)
+
+	req := &gen.BatchGetAggregatedRatingsRequest{}
+	for _, k := range keys {
+		req.Records = append(req.Records, model.RecordKeyToProto(k))
+	}
+	resp, err := client.BatchGetAggregatedRatings(ctx, req)
+	if err != nil {
+		return nil, errs.FromGRPC(err)
+	}
+
+	res := make(map[model.RecordKey]float64, len(resp.Ratings))
+	for _, r := range resp.Ratings {
+		res[model.RecordKey{ID: model.RecordID(r.RecordId), Type: model.RecordType(r.RecordType)}] = r.RatingValue
+	}
+	return res, nil
+}
+
+// PutRating writes a rating for a given record.
 func (g *Gateway) PutRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rating *model.Rating) error {
 	conn, err := grpcutil.ServiceConnection(ctx, "rating", g.registry)
 
@@ -51,10 +80,102 @@ func (g *Gateway) PutRating(ctx context.Context, recordID model.RecordID, record
 
 	client := gen.NewRatingServiceClient(conn)
 
-	_, err = client.PutRating(ctx, &gen.PutRatingRequest{RecordId: string(recordID), RecordType: string(recordType), RatingValue: int32(rating.Value)})
+	_, err = client.PutRating(ctx, &gen.PutRatingRequest{UserId: string(rating.UserID), RecordId: string(recordID), RecordType: string(recordType), RatingValue: int32(rating.Value)})
 	if err != nil {
-		return err
+		return errs.FromGRPC(err)
 	}
 
 	return nil
 }
+
+// GetRating returns the rating a user gave to a record or
+// ErrNotFound if the user has not rated it.
+func (g *Gateway) GetRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (model.RatingValue, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "rating", g.registry)
+	if err != nil {
+		return 0, err
+	}
+
+	defer conn.Close()
+
+	client := gen.NewRatingServiceClient(conn)
+
+	resp, err := client.GetRating(ctx, &gen.GetRatingRequest{UserId: string(userID), RecordId: string(recordID), RecordType: string(recordType)})
+	if err != nil {
+		return 0, errs.FromGRPC(err)
+	}
+
+	return model.RatingValue(resp.RatingValue), nil
+}
+
+// BatchGetRatings returns the ratings a user gave to the
+// given records that they rated.
+func (g *Gateway) BatchGetRatings(ctx context.Context, keys []model.RecordKey, userID model.UserID) (map[model.RecordKey]model.RatingValue, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "rating", g.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	client := gen.NewRatingServiceClient(conn)
+
+	req := &gen.BatchGetRatingsRequest{UserId: string(userID)}
+	for _, k := range keys {
+		req.Records = append(req.Records, model.RecordKeyToProto(k))
+	}
+	resp, err := client.BatchGetRatings(ctx, req)
+	if err != nil {
+		return nil, errs.FromGRPC(err)
+	}
+
+	res := make(map[model.RecordKey]model.RatingValue, len(resp.Ratings))
+	for _, r := range resp.Ratings {
+		res[model.RecordKey{ID: model.RecordID(r.RecordId), Type: model.RecordType(r.RecordType)}] = model.RatingValue(r.RatingValue)
+	}
+	return res, nil
+}
+
+// GetRatingStats returns the rating statistics of a record
+// or ErrNotFound if there are no ratings for it.
+func (g *Gateway) GetRatingStats(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Stats, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "rating", g.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	client := gen.NewRatingServiceClient(conn)
+
+	resp, err := client.GetRatingStats(ctx, &gen.GetRatingStatsRequest{RecordId: string(recordID), RecordType: string(recordType)})
+	if err != nil {
+		return nil, errs.FromGRPC(err)
+	}
+
+	return model.StatsFromProto(resp.Stats), nil
+}
+
+// ListTopRated returns a page of the highest rated records of
+// a type with at least minVotes ratings.
+func (g *Gateway) ListTopRated(ctx context.Context, recordType model.RecordType, minVotes int64, limit int, pageToken string) (*model.TopRatedPage, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "rating", g.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	client := gen.NewRatingServiceClient(conn)
+
+	resp, err := client.ListTopRated(ctx, &gen.ListTopRatedRequest{RecordType: string(recordType), MinVotes: minVotes, Limit: int32(limit), PageToken: pageToken})
+	if err != nil {
+		return nil, errs.FromGRPC(err)
+	}
+
+	page := &model.TopRatedPage{Records: []model.RankedRecord{}, NextPageToken: resp.NextPageToken}
+	for _, r := range resp.Records {
+		page.Records = append(page.Records, *model.RankedRecordFromProto(r))
+	}
+	return page, nil
+}
@@ -8,8 +8,8 @@ import (
 	"math/rand"
 	"net/http"
 
-	"github.com/phongld0308/movie-example/movie/internal/gateway"
 	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/errs"
 	model "github.com/phongld0308/movie-example/rating/pkg/model"
 )
 
@@ -50,10 +50,8 @@ func (g *Gateway) GetAggregatedRating(ctx context.Context, recordID model.Record
 	}
 
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return 0, gateway.ErrNotFound
-	} else if resp.StatusCode/100 != 2 {
-		return 0, fmt.Errorf("non-2xx response: %v", resp)
+	if err := errs.FromHTTP(resp); err != nil {
+		return 0, err
 	}
 	var v float64
 	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
@@ -63,6 +61,44 @@ func (g *Gateway) GetAggregatedRating(ctx context.Context, recordID model.Record
 	return v, nil
 }
 
+// GetRating returns the rating a user gave to a record or
+// ErrNotFound if the user has not rated it.
+func (g *Gateway) GetRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (model.RatingValue, error) {
+	addrs, err := g.registry.ServiceAddresses(ctx, "rating")
+	if err != nil {
+		return 0, err
+	}
+
+	url := "http://" + addrs[rand.Intn(len(addrs))] + "/rating"
+	log.Printf("Calling rating service. Request: GET " + url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req = req.WithContext(ctx)
+	values := req.URL.Query()
+	values.Add("id", string(recordID))
+	values.Add("type", fmt.Sprintf("%v", recordType))
+	values.Add("userId", string(userID))
+	req.URL.RawQuery = values.Encode()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+	if err := errs.FromHTTP(resp); err != nil {
+		return 0, err
+	}
+	var v model.RatingValue
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return 0, err
+	}
+
+	return v, nil
+}
+
 // PutRating writes a rating.
 func (g *Gateway) PutRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rating *model.Rating) error {
 	addrs, err := g.registry.ServiceAddresses(ctx, "rating")
@@ -80,15 +116,91 @@ func (g *Gateway) PutRating(ctx context.Context, recordID model.RecordID, record
 	values := req.URL.Query()
 	values.Add("id", string(recordID))
 	values.Add("type", fmt.Sprintf("%v", recordType))
+	values.Add("userId", string(rating.UserID))
 	values.Add("value", fmt.Sprintf("%v", rating.Value))
 	req.URL.RawQuery = values.Encode()
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil
+		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("non-2xx response: %v", resp)
+	return errs.FromHTTP(resp)
+}
+
+// GetRatingStats returns the rating statistics of a record
+// or ErrNotFound if there are no ratings for it.
+func (g *Gateway) GetRatingStats(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Stats, error) {
+	addrs, err := g.registry.ServiceAddresses(ctx, "rating")
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	url := "http://" + addrs[rand.Intn(len(addrs))] + "/rating/stats"
+	log.Printf("Calling rating service. Request: GET " + url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	values := req.URL.Query()
+	values.Add("id", string(recordID))
+	values.Add("type", fmt.Sprintf("%v", recordType))
+	req.URL.RawQuery = values.Encode()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if err := errs.FromHTTP(resp); err != nil {
+		return nil, err
+	}
+	var stats model.Stats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// ListTopRated returns a page of the highest rated records of
+// a type with at least minVotes ratings.
+func (g *Gateway) ListTopRated(ctx context.Context, recordType model.RecordType, minVotes int64, limit int, pageToken string) (*model.TopRatedPage, error) {
+	addrs, err := g.registry.ServiceAddresses(ctx, "rating")
+	if err != nil {
+		return nil, err
+	}
+
+	url := "http://" + addrs[rand.Intn(len(addrs))] + "/rating/top"
+	log.Printf("Calling rating service. Request: GET " + url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	values := req.URL.Query()
+	values.Add("type", fmt.Sprintf("%v", recordType))
+	values.Add("minVotes", fmt.Sprintf("%v", minVotes))
+	values.Add("limit", fmt.Sprintf("%v", limit))
+	if pageToken != "" {
+		values.Add("pageToken", pageToken)
+	}
+	req.URL.RawQuery = values.Encode()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if err := errs.FromHTTP(resp); err != nil {
+		return nil, err
+	}
+	var page model.TopRatedPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
 }
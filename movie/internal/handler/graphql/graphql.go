@@ -0,0 +1,155 @@
+// Package graphql serves the GraphQL API of the movie
+// service. Resolvers load data through the metadata and
+// rating gateways using request-scoped batching loaders.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/movie/internal/gateway"
+	"github.com/phongld0308/movie-example/pkg/dataloader"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// UserIDHeader identifies the current user of a request.
+const UserIDHeader = "X-User-ID"
+
+//go:embed schema.graphql
+var schema string
+
+type ratingGateway interface {
+	BatchGetAggregatedRatings(ctx context.Context, keys []ratingmodel.RecordKey) (map[ratingmodel.RecordKey]float64, error)
+	BatchGetRatings(ctx context.Context, keys []ratingmodel.RecordKey, userID ratingmodel.UserID) (map[ratingmodel.RecordKey]ratingmodel.RatingValue, error)
+}
+
+type metadataGateway interface {
+	BatchGet(ctx context.Context, ids []string, locale string) (map[string]*metadatamodel.Metadata, error)
+}
+
+// Handler defines a GraphQL HTTP handler.
+type Handler struct {
+	schema          *graphql.Schema
+	ratingGateway   ratingGateway
+	metadataGateway metadataGateway
+}
+
+// New creates a new GraphQL HTTP handler.
+func New(ratingGateway ratingGateway, metadataGateway metadataGateway) (*Handler, error) {
+	s, err := graphql.ParseSchema(schema, &queryResolver{}, graphql.MaxDepth(10))
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{s, ratingGateway, metadataGateway}, nil
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ServeHTTP handles GET and POST /graphql requests.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var r request
+	switch req.Method {
+	case http.MethodGet:
+		r.Query = req.FormValue("query")
+		r.OperationName = req.FormValue("operationName")
+		if v := req.FormValue("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &r.Variables); err != nil {
+				errs.WriteHTTP(w, errs.InvalidArgument("invalid variables"))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			errs.WriteHTTP(w, errs.InvalidArgument("invalid request body"))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Query == "" {
+		errs.WriteHTTP(w, errs.InvalidArgument("invalid request", errs.FieldViolation{Field: "query", Description: "must not be empty"}))
+		return
+	}
+
+	ctx := context.WithValue(req.Context(), loadersKey{}, h.newLoaders(ratingmodel.UserID(req.Header.Get(UserIDHeader)), req.Header.Get("Accept-Language")))
+	resp := h.schema.Exec(ctx, r.Query, r.OperationName, r.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// loaders holds the batching loaders of a single request.
+type loaders struct {
+	userID     ratingmodel.UserID
+	metadata   *dataloader.Loader[string, *metadatamodel.Metadata]
+	rating     *dataloader.Loader[ratingmodel.RecordKey, float64]
+	userRating *dataloader.Loader[ratingmodel.RecordKey, ratingmodel.RatingValue]
+}
+
+type loadersKey struct{}
+
+// newLoaders returns loaders fetching each batch with a
+// single gateway call. Keys missing from a batch response
+// load as gateway.ErrNotFound.
+func (h *Handler) newLoaders(userID ratingmodel.UserID, locale string) *loaders {
+	return &loaders{
+		userID: userID,
+		metadata: dataloader.New(dataloader.Map(gateway.ErrNotFound, func(ctx context.Context, ids []string) (map[string]*metadatamodel.Metadata, error) {
+			return h.metadataGateway.BatchGet(ctx, ids, locale)
+		})),
+		rating: dataloader.New(dataloader.Map(gateway.ErrNotFound, h.ratingGateway.BatchGetAggregatedRatings)),
+		userRating: dataloader.New(dataloader.Map(gateway.ErrNotFound, func(ctx context.Context, keys []ratingmodel.RecordKey) (map[ratingmodel.RecordKey]ratingmodel.RatingValue, error) {
+			return h.ratingGateway.BatchGetRatings(ctx, keys, userID)
+		})),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// gqlError reports a domain error to GraphQL clients with its
+// error code as an extension.
+type gqlError struct {
+	err *errs.Error
+}
+
+func (e gqlError) Error() string {
+	return e.err.Error()
+}
+
+func (e gqlError) Unwrap() error {
+	return e.err
+}
+
+func (e gqlError) Extensions() map[string]any {
+	ext := map[string]any{"code": e.err.Kind.String()}
+	if len(e.err.Violations) > 0 {
+		ext["fieldViolations"] = e.err.Violations
+	}
+	return ext
+}
+
+func resolverError(err error) error {
+	var e *errs.Error
+	if !errors.As(err, &e) {
+		log.Printf("Internal error: %v\n", err)
+		e = &errs.Error{Message: "internal error"}
+	}
+	return gqlError{e}
+}
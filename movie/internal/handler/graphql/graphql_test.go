@@ -0,0 +1,159 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// fakeGateways records the keys of each batch call.
+type fakeGateways struct {
+	mu    sync.Mutex
+	calls map[string][]string
+}
+
+func (f *fakeGateways) record(call string, keys ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sort.Strings(keys)
+	f.calls[call] = append(f.calls[call], strings.Join(keys, ","))
+}
+
+func (f *fakeGateways) BatchGet(_ context.Context, ids []string, _ string) (map[string]*metadatamodel.Metadata, error) {
+	f.record("metadata", ids...)
+	res := map[string]*metadatamodel.Metadata{}
+	for _, id := range ids {
+		switch id {
+		case "missing":
+		case "got":
+			res[id] = &metadatamodel.Metadata{ID: id, Title: "Series " + id, Type: metadatamodel.RecordTypeSeries}
+		default:
+			res[id] = &metadatamodel.Metadata{ID: id, Title: "Movie " + id}
+		}
+	}
+	return res, nil
+}
+
+func keyNames(keys []ratingmodel.RecordKey) []string {
+	var names []string
+	for _, k := range keys {
+		names = append(names, string(k.Type)+"/"+string(k.ID))
+	}
+	return names
+}
+
+func (f *fakeGateways) BatchGetAggregatedRatings(_ context.Context, keys []ratingmodel.RecordKey) (map[ratingmodel.RecordKey]float64, error) {
+	f.record("rating", keyNames(keys)...)
+	res := map[ratingmodel.RecordKey]float64{}
+	for _, k := range keys {
+		if k.ID != "2" {
+			res[k] = 4.5
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeGateways) BatchGetRatings(_ context.Context, keys []ratingmodel.RecordKey, userID ratingmodel.UserID) (map[ratingmodel.RecordKey]ratingmodel.RatingValue, error) {
+	f.record("user rating "+string(userID), keyNames(keys)...)
+	res := map[ratingmodel.RecordKey]ratingmodel.RatingValue{}
+	for _, k := range keys {
+		res[k] = 3
+	}
+	return res, nil
+}
+
+func TestMoviesQuery(t *testing.T) {
+	f := &fakeGateways{calls: map[string][]string{}}
+	h, err := New(f, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"query": "{ movies(ids: [\"1\", \"2\", \"1\", \"got\", \"missing\"]) { id metadata { title } rating { average userRating } } }"}`
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set(UserIDHeader, "u1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp struct {
+		Errors []any `json:"errors"`
+		Data   struct {
+			Movies []*struct {
+				ID       string `json:"id"`
+				Metadata struct {
+					Title string `json:"title"`
+				} `json:"metadata"`
+				Rating struct {
+					Average    *float64 `json:"average"`
+					UserRating *int     `json:"userRating"`
+				} `json:"rating"`
+			} `json:"movies"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body.String(), err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+
+	movies := resp.Data.Movies
+	if len(movies) != 5 || movies[3] == nil || movies[4] != nil {
+		t.Fatalf("got movies %s, want 4 movies and a trailing null", rec.Body.String())
+	}
+	if movies[0].Metadata.Title != "Movie 1" || movies[0].Rating.Average == nil || *movies[0].Rating.Average != 4.5 {
+		t.Errorf("unexpected first movie: %s", rec.Body.String())
+	}
+	if movies[1].Rating.Average != nil {
+		t.Errorf("unrated movie has average %v", *movies[1].Rating.Average)
+	}
+	if movies[0].Rating.UserRating == nil || *movies[0].Rating.UserRating != 3 {
+		t.Errorf("missing user rating: %s", rec.Body.String())
+	}
+
+	// Loaders fetch in batches, each key once, and ratings are
+	// keyed by record type.
+	want := map[string][]string{
+		"metadata":       {"1", "2", "got", "missing"},
+		"rating":         {"movie/1", "movie/2", "series/got"},
+		"user rating u1": {"movie/1", "movie/2", "series/got"},
+	}
+	for call, keys := range want {
+		var got []string
+		for _, batch := range f.calls[call] {
+			got = append(got, strings.Split(batch, ",")...)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, keys) {
+			t.Errorf("%s fetched %v, want %v", call, got, keys)
+		}
+	}
+	if n := len(f.calls["metadata"]); n != 1 {
+		t.Errorf("metadata fetched in %d batches, want 1", n)
+	}
+}
+
+func TestMovieNotFound(t *testing.T) {
+	f := &fakeGateways{calls: map[string][]string{}}
+	h, err := New(f, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, `/graphql?query={movie(id:"missing"){id}}`, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got, want := strings.TrimSpace(rec.Body.String()), `{"data":{"movie":null}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/graph-gophers/graphql-go"
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/movie/internal/gateway"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// maxMovies limits the number of IDs in a movies query.
+const maxMovies = 100
+
+type queryResolver struct{}
+
+func (*queryResolver) Movie(ctx context.Context, args struct{ ID graphql.ID }) (*movieResolver, error) {
+	m, err := loadersFrom(ctx).metadata.Load(ctx, string(args.ID))
+	if errors.Is(err, gateway.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, resolverError(err)
+	}
+	return &movieResolver{m}, nil
+}
+
+func (*queryResolver) Movies(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*movieResolver, error) {
+	if len(args.IDs) > maxMovies {
+		return nil, resolverError(errs.InvalidArgument("too many movie IDs", errs.FieldViolation{
+			Field:       "ids",
+			Description: fmt.Sprintf("must contain at most %d IDs", maxMovies),
+		}))
+	}
+
+	ids := make([]string, len(args.IDs))
+	for i, id := range args.IDs {
+		ids[i] = string(id)
+	}
+	metadata, loadErrs := loadersFrom(ctx).metadata.LoadMany(ctx, ids)
+
+	movies := make([]*movieResolver, len(ids))
+	for i, err := range loadErrs {
+		if errors.Is(err, gateway.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, resolverError(err)
+		}
+		movies[i] = &movieResolver{metadata[i]}
+	}
+	return movies, nil
+}
+
+type movieResolver struct {
+	metadata *metadatamodel.Metadata
+}
+
+func (r *movieResolver) ID() graphql.ID {
+	return graphql.ID(r.metadata.ID)
+}
+
+func (r *movieResolver) Metadata() *metadataResolver {
+	return &metadataResolver{r.metadata}
+}
+
+func (r *movieResolver) Rating() *ratingResolver {
+	typ := ratingmodel.RecordType(r.metadata.Type)
+	if typ == "" {
+		typ = ratingmodel.RecordTypeMovie
+	}
+	return &ratingResolver{ratingmodel.RecordKey{ID: ratingmodel.RecordID(r.metadata.ID), Type: typ}}
+}
+
+type metadataResolver struct {
+	m *metadatamodel.Metadata
+}
+
+func (r *metadataResolver) ID() graphql.ID      { return graphql.ID(r.m.ID) }
+func (r *metadataResolver) Title() string       { return r.m.Title }
+func (r *metadataResolver) Description() string { return r.m.Description }
+func (r *metadataResolver) Director() string    { return r.m.Director }
+func (r *metadataResolver) Version() int32      { return int32(r.m.Version) }
+
+func (r *metadataResolver) ReleaseDate() *string {
+	if r.m.ReleaseDate == "" {
+		return nil
+	}
+	return &r.m.ReleaseDate
+}
+
+func (r *metadataResolver) RuntimeMinutes() *int32 {
+	if r.m.RuntimeMinutes == 0 {
+		return nil
+	}
+	return &r.m.RuntimeMinutes
+}
+
+func (r *metadataResolver) Genres() []string          { return nonNil(r.m.Genres) }
+func (r *metadataResolver) OriginalLanguage() string  { return r.m.OriginalLanguage }
+func (r *metadataResolver) SpokenLanguages() []string { return nonNil(r.m.SpokenLanguages) }
+func (r *metadataResolver) Country() string           { return r.m.Country }
+func (r *metadataResolver) PosterUrl() string         { return r.m.PosterURL }
+func (r *metadataResolver) BackdropUrl() string       { return r.m.BackdropURL }
+func (r *metadataResolver) ContentRating() string     { return r.m.ContentRating }
+
+// nonNil returns s, or an empty slice for non-null lists.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+type ratingResolver struct {
+	key ratingmodel.RecordKey
+}
+
+func (r *ratingResolver) Average(ctx context.Context) (*float64, error) {
+	v, err := loadersFrom(ctx).rating.Load(ctx, r.key)
+	if errors.Is(err, gateway.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, resolverError(err)
+	}
+	return &v, nil
+}
+
+func (r *ratingResolver) UserRating(ctx context.Context) (*int32, error) {
+	l := loadersFrom(ctx)
+	if l.userID == "" {
+		return nil, nil
+	}
+	v, err := l.userRating.Load(ctx, r.key)
+	if errors.Is(err, gateway.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, resolverError(err)
+	}
+	value := int32(v)
+	return &value, nil
+}
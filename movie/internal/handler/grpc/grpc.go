@@ -2,16 +2,17 @@ package grpc
 
 import (
 	"context"
-	"errors"
 
 	"github.com/phongld0308/movie-example/gen"
 	"github.com/phongld0308/movie-example/metadata/pkg/model"
 	"github.com/phongld0308/movie-example/movie/internal/controller/movie"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/phongld0308/movie-example/movie/internal/repository"
+	moviemodel "github.com/phongld0308/movie-example/movie/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
 )
 
-// Handler defines a movie gRPC handler.
+// Handler defines a movie gRPC handler. Requests are expected
+// to be validated by the validation interceptor.
 type Handler struct {
 	gen.UnimplementedMovieServiceServer
 	ctrl *movie.Controller
@@ -24,16 +25,58 @@ func New(ctrl *movie.Controller) *Handler {
 
 // GetMovieDetails return a movie details by id.
 func (h *Handler) GetMovieDetails(ctx context.Context, req *gen.GetMovieDetailsRequest) (*gen.GetMovieDetailsResponse, error) {
-	if req == nil || req.MovieId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "nil req or empty id")
+	m, err := h.ctrl.Get(ctx, req.MovieId, req.Locale)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
 	}
 
-	m, err := h.ctrl.Get(ctx, req.MovieId)
-	if err != nil && errors.Is(err, movie.ErrNotFound) {
-		return nil, status.Errorf(codes.NotFound, err.Error())
-	} else if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	details := &gen.MovieDetails{
+		Metadata:        model.MetadataToProto(&m.Metadata),
+		RatingCount:     m.RatingCount,
+		RatingHistogram: m.RatingHistogram,
+		ReviewCount:     m.ReviewCount,
 	}
+	if m.Rating != nil {
+		details.Rating = float32(*m.Rating)
+	}
+
+	return &gen.GetMovieDetailsResponse{MovieDetails: details}, nil
+}
 
-	return &gen.GetMovieDetailsResponse{MovieDetails: &gen.MovieDetails{Metadata: model.MetadataToProto(&m.Metadata), Rating: float32(*m.Rating)}}, nil
+// ListMovies returns a page of the movie catalog.
+func (h *Handler) ListMovies(ctx context.Context, req *gen.ListMoviesRequest) (*gen.ListMoviesResponse, error) {
+	page, err := h.ctrl.List(ctx, repository.ListQuery{
+		RecordType:  model.RecordType(req.Type),
+		Director:    req.Director,
+		TitlePrefix: req.TitlePrefix,
+		MinRating:   req.MinRating,
+		MinVotes:    req.MinVotes,
+		SortBy:      repository.SortField(req.OrderBy),
+		Descending:  req.Descending,
+		Limit:       int(req.PageSize),
+	}, req.PageToken)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+
+	resp := &gen.ListMoviesResponse{NextPageToken: page.NextPageToken}
+	for i := range page.Movies {
+		resp.Movies = append(resp.Movies, moviemodel.MovieSummaryToProto(&page.Movies[i]))
+	}
+	return resp, nil
+}
+
+// ListTopRatedMovies returns a page of the highest rated
+// movies.
+func (h *Handler) ListTopRatedMovies(ctx context.Context, req *gen.ListTopRatedMoviesRequest) (*gen.ListMoviesResponse, error) {
+	page, err := h.ctrl.TopRated(ctx, model.RecordType(req.Type), req.MinVotes, int(req.PageSize), req.PageToken, req.Locale)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+
+	resp := &gen.ListMoviesResponse{NextPageToken: page.NextPageToken}
+	for i := range page.Movies {
+		resp.Movies = append(resp.Movies, moviemodel.MovieSummaryToProto(&page.Movies[i]))
+	}
+	return resp, nil
 }
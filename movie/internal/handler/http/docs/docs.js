@@ -0,0 +1,189 @@
+// Renders the OpenAPI document served at /openapi.json.
+(function () {
+  "use strict";
+
+  function el(tag, attrs, children) {
+    var e = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (k) {
+      if (k === "text") {
+        e.textContent = attrs[k];
+      } else {
+        e.setAttribute(k, attrs[k]);
+      }
+    });
+    (children || []).forEach(function (c) {
+      if (c) {
+        e.appendChild(c);
+      }
+    });
+    return e;
+  }
+
+  function refName(ref) {
+    return ref.substring(ref.lastIndexOf("/") + 1);
+  }
+
+  // describe returns a short description of a schema type and
+  // its constraints.
+  function describe(s) {
+    if (s.$ref) {
+      return refName(s.$ref);
+    }
+    var parts = [s.type === "array" ? describe(s.items) + "[]" : s.type || "any"];
+    if (s.format) {
+      parts.push("(" + s.format + ")");
+    }
+    if (s.nullable) {
+      parts.push("nullable");
+    }
+    if (s.enum) {
+      parts.push("one of " + s.enum.join(", "));
+    }
+    if (s.minLength !== undefined) {
+      parts.push("min length " + s.minLength);
+    }
+    if (s.maxLength !== undefined) {
+      parts.push("max length " + s.maxLength);
+    }
+    if (s.minimum !== undefined) {
+      parts.push(">= " + s.minimum);
+    }
+    if (s.maximum !== undefined) {
+      parts.push("<= " + s.maximum);
+    }
+    return parts.join(" ");
+  }
+
+  function schemaLink(s) {
+    if (s.$ref) {
+      return el("a", { href: "#schema-" + refName(s.$ref), text: refName(s.$ref) });
+    }
+    return el("code", { text: describe(s) });
+  }
+
+  function parametersTable(params) {
+    var rows = params.map(function (p) {
+      return el("tr", {}, [
+        el("td", {}, [el("code", { text: p.name }), p.required ? el("span", { class: "required", text: " *" }) : null]),
+        el("td", { text: p.in }),
+        el("td", {}, [el("code", { text: describe(p.schema) })]),
+        el("td", { text: p.description || "" }),
+      ]);
+    });
+    var head = el("tr", {}, ["Name", "In", "Schema", "Description"].map(function (h) {
+      return el("th", { text: h });
+    }));
+    return el("table", {}, [el("thead", {}, [head]), el("tbody", {}, rows)]);
+  }
+
+  function responsesTable(responses) {
+    var rows = Object.keys(responses).sort().map(function (status) {
+      var r = responses[status];
+      var media = r.content && r.content["application/json"];
+      return el("tr", {}, [
+        el("td", { text: status }),
+        el("td", { text: r.description }),
+        el("td", {}, [media ? schemaLink(media.schema) : null]),
+      ]);
+    });
+    var head = el("tr", {}, ["Status", "Description", "Body"].map(function (h) {
+      return el("th", { text: h });
+    }));
+    return el("table", {}, [el("thead", {}, [head]), el("tbody", {}, rows)]);
+  }
+
+  // tryIt returns a form sending the operation to the service.
+  function tryIt(path, method, op) {
+    var inputs = {};
+    var fields = (op.parameters || []).map(function (p) {
+      inputs[p.name] = el("input", { name: p.name, placeholder: p.name });
+      return el("label", {}, [document.createTextNode(p.name + " "), inputs[p.name]]);
+    });
+    var output = el("pre", { text: "" });
+    var button = el("button", { type: "submit", text: "Send" });
+    var form = el("form", {}, fields.concat([button, output]));
+    form.addEventListener("submit", function (e) {
+      e.preventDefault();
+      var url = path;
+      var query = new URLSearchParams();
+      var headers = {};
+      (op.parameters || []).forEach(function (p) {
+        var v = inputs[p.name].value;
+        if (p.in === "path") {
+          url = url.replace("{" + p.name + "}", encodeURIComponent(v));
+        } else if (v === "") {
+          return;
+        } else if (p.in === "header") {
+          headers[p.name] = v;
+        } else {
+          query.append(p.name, v);
+        }
+      });
+      if (query.toString()) {
+        url += "?" + query.toString();
+      }
+      fetch(url, { method: method.toUpperCase(), headers: headers }).then(function (resp) {
+        return resp.text().then(function (body) {
+          output.textContent = resp.status + " " + resp.statusText + "\n\n" + body;
+        });
+      }).catch(function (err) {
+        output.textContent = String(err);
+      });
+    });
+    return form;
+  }
+
+  function renderOperations(doc) {
+    var root = document.getElementById("operations");
+    Object.keys(doc.paths).sort().forEach(function (path) {
+      var item = doc.paths[path];
+      Object.keys(item).sort().forEach(function (method) {
+        var op = item[method];
+        var summary = el("summary", {}, [
+          el("span", { class: "method " + method, text: method.toUpperCase() }),
+          el("code", { text: path }),
+          document.createTextNode(" " + (op.summary || "")),
+        ]);
+        var body = el("div", {}, [
+          op.description ? el("p", { text: op.description }) : null,
+          op.parameters ? el("h4", { text: "Parameters" }) : null,
+          op.parameters ? parametersTable(op.parameters) : null,
+          el("h4", { text: "Responses" }),
+          responsesTable(op.responses),
+          el("h4", { text: "Try it" }),
+          tryIt(path, method, op),
+        ]);
+        root.appendChild(el("details", { class: "operation", id: op.operationId }, [summary, body]));
+      });
+    });
+  }
+
+  function renderSchemas(doc) {
+    var root = document.getElementById("schemas");
+    var schemas = doc.components.schemas;
+    Object.keys(schemas).sort().forEach(function (name) {
+      var s = schemas[name];
+      var required = s.required || [];
+      var rows = Object.keys(s.properties || {}).map(function (prop) {
+        return el("tr", {}, [
+          el("td", {}, [el("code", { text: prop }), required.indexOf(prop) >= 0 ? el("span", { class: "required", text: " *" }) : null]),
+          el("td", {}, [schemaLink(s.properties[prop])]),
+        ]);
+      });
+      root.appendChild(el("h3", { id: "schema-" + name, text: name }));
+      root.appendChild(el("table", {}, [el("tbody", {}, rows)]));
+    });
+  }
+
+  fetch("/openapi.json").then(function (resp) {
+    return resp.json();
+  }).then(function (doc) {
+    document.title = doc.info.title;
+    document.getElementById("title").textContent = doc.info.title + " " + doc.info.version;
+    document.getElementById("description").textContent = doc.info.description || "";
+    renderOperations(doc);
+    renderSchemas(doc);
+  }).catch(function (err) {
+    document.getElementById("operations").textContent = "Failed to load the API document: " + err;
+  });
+})();
@@ -2,11 +2,17 @@ package http
 
 import (
 	"encoding/json"
-	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/phongld0308/movie-example/gen"
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
 	"github.com/phongld0308/movie-example/movie/internal/controller/movie"
+	"github.com/phongld0308/movie-example/movie/internal/repository"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/validation"
+	"google.golang.org/protobuf/proto"
 )
 
 // Handler defines a move handler
@@ -19,20 +25,203 @@ func New(ctrl *movie.Controller) *Handler {
 	return &Handler{ctrl}
 }
 
+// DecodeGetMovieDetails decodes GET /movie requests. The
+// locale parameter takes precedence over Accept-Language.
+func DecodeGetMovieDetails(req *http.Request) (proto.Message, error) {
+	locale := req.FormValue("locale")
+	if locale == "" {
+		locale = req.Header.Get("Accept-Language")
+	}
+	return &gen.GetMovieDetailsRequest{MovieId: req.FormValue("id"), Locale: locale}, nil
+}
+
 // GetMovieDetails handles GET /movie requests.
 func (h *Handler) GetMovieDetails(w http.ResponseWriter, req *http.Request) {
-	id := req.FormValue("id")
-	details, err := h.ctrl.Get(req.Context(), id)
-	if err != nil && errors.Is(err, movie.ErrNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+	r, err := validation.Request(req, DecodeGetMovieDetails)
+	if err != nil {
+		errs.WriteHTTP(w, err)
 		return
-	} else if err != nil {
-		log.Printf("Repository get error: %v\n", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	getReq := r.(*gen.GetMovieDetailsRequest)
+	details, err := h.ctrl.Get(req.Context(), getReq.MovieId, getReq.Locale)
+	if err != nil {
+		errs.WriteHTTP(w, err)
 		return
 	}
 
+	w.Header().Set("Vary", "Accept-Language")
+	if details.Metadata.Locale != "" {
+		w.Header().Set("Content-Language", details.Metadata.Locale)
+	}
+
 	if err := json.NewEncoder(w).Encode(details); err != nil {
 		log.Printf("Response encode error: %v\n", err)
 	}
 }
+
+// DecodeListMovies decodes GET /movies requests.
+func DecodeListMovies(req *http.Request) (proto.Message, error) {
+	p := queryParser{req: req}
+	m := &gen.ListMoviesRequest{
+		PageSize:    int32(p.int("pageSize")),
+		PageToken:   req.FormValue("pageToken"),
+		OrderBy:     req.FormValue("orderBy"),
+		Descending:  p.bool("desc"),
+		Director:    req.FormValue("director"),
+		TitlePrefix: req.FormValue("titlePrefix"),
+		MinRating:   p.float("minRating"),
+		MinVotes:    int64(p.int("minVotes")),
+		Type:        req.FormValue("type"),
+	}
+	return m, p.err()
+}
+
+// ListMovies handles GET /movies requests.
+func (h *Handler) ListMovies(w http.ResponseWriter, req *http.Request) {
+	m, err := validation.Request(req, DecodeListMovies)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	r := m.(*gen.ListMoviesRequest)
+	page, err := h.ctrl.List(req.Context(), repository.ListQuery{
+		RecordType:  metadatamodel.RecordType(r.Type),
+		Director:    r.Director,
+		TitlePrefix: r.TitlePrefix,
+		MinRating:   r.MinRating,
+		MinVotes:    r.MinVotes,
+		SortBy:      repository.SortField(r.OrderBy),
+		Descending:  r.Descending,
+		Limit:       int(r.PageSize),
+	}, r.PageToken)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// DecodeListTopRated decodes GET /movies/top requests. The
+// locale parameter takes precedence over Accept-Language.
+func DecodeListTopRated(req *http.Request) (proto.Message, error) {
+	p := queryParser{req: req}
+	locale := req.FormValue("locale")
+	if locale == "" {
+		locale = req.Header.Get("Accept-Language")
+	}
+	m := &gen.ListTopRatedMoviesRequest{
+		Type:      req.FormValue("type"),
+		MinVotes:  int64(p.int("minVotes")),
+		PageSize:  int32(p.int("pageSize")),
+		PageToken: req.FormValue("pageToken"),
+		Locale:    locale,
+	}
+	return m, p.err()
+}
+
+// ListTopRated handles GET /movies/top requests.
+func (h *Handler) ListTopRated(w http.ResponseWriter, req *http.Request) {
+	m, err := validation.Request(req, DecodeListTopRated)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	r := m.(*gen.ListTopRatedMoviesRequest)
+	page, err := h.ctrl.TopRated(req.Context(), metadatamodel.RecordType(r.Type), r.MinVotes, int(r.PageSize), r.PageToken, r.Locale)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	w.Header().Set("Vary", "Accept-Language")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// DecodeListSimilarMovies decodes GET /movies/{id}/similar
+// requests.
+func DecodeListSimilarMovies(req *http.Request) (proto.Message, error) {
+	p := queryParser{req: req}
+	m := &gen.ListSimilarMoviesRequest{
+		MovieId: pathParam(req, "id"),
+		Limit:   int32(p.int("limit")),
+	}
+	return m, p.err()
+}
+
+// ListSimilarMovies handles GET /movies/{id}/similar requests.
+func (h *Handler) ListSimilarMovies(w http.ResponseWriter, req *http.Request) {
+	m, err := validation.Request(req, DecodeListSimilarMovies)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	r := m.(*gen.ListSimilarMoviesRequest)
+	similar, err := h.ctrl.Similar(req.Context(), r.MovieId, int(r.Limit))
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(similar); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// queryParser parses typed query parameters, collecting a
+// violation for every malformed value.
+type queryParser struct {
+	req        *http.Request
+	violations []errs.FieldViolation
+}
+
+func (p *queryParser) int(name string) int {
+	v := p.req.FormValue(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		p.violations = append(p.violations, errs.FieldViolation{Field: name, Description: "must be an integer"})
+	}
+	return int(n)
+}
+
+func (p *queryParser) float(name string) float64 {
+	v := p.req.FormValue(name)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		p.violations = append(p.violations, errs.FieldViolation{Field: name, Description: "must be a number"})
+	}
+	return f
+}
+
+func (p *queryParser) bool(name string) bool {
+	v := p.req.FormValue(name)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.violations = append(p.violations, errs.FieldViolation{Field: name, Description: "must be true or false"})
+	}
+	return b
+}
+
+func (p *queryParser) err() error {
+	if len(p.violations) == 0 {
+		return nil
+	}
+	return errs.InvalidArgument("invalid request", p.violations...)
+}
@@ -0,0 +1,285 @@
+package http
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/movie/pkg/model"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/openapi"
+	"github.com/phongld0308/movie-example/pkg/validation"
+)
+
+//go:generate go run ../../../cmd/openapigen -o docs/openapi.json
+
+//go:embed docs
+var docs embed.FS
+
+// route defines a movie HTTP API endpoint together with its
+// documentation.
+type route struct {
+	openapi.Route
+	decode validation.DecodeFunc
+	handle func(*Handler, http.ResponseWriter, *http.Request)
+}
+
+// routes lists the endpoints of the movie HTTP API. The
+// OpenAPI document in docs/openapi.json is generated from it.
+var routes = []route{
+	{
+		Route: openapi.Route{
+			Method:      http.MethodGet,
+			Path:        "/movie",
+			OperationID: "getMovieDetails",
+			Summary:     "Get movie details",
+			Description: "Returns the metadata of a movie together with its aggregated rating. The rating is null when the movie has not been rated yet.",
+			Tags:        []string{"movies"},
+			Request:     &gen.GetMovieDetailsRequest{},
+			Params: []openapi.Param{
+				{Name: "id", In: openapi.InQuery, Field: "movie_id", Description: "Movie ID."},
+				{Name: "locale", In: openapi.InQuery, Field: "locale", Description: "Preferred locales in Accept-Language form. Takes precedence over the Accept-Language header."},
+				{Name: "Accept-Language", In: openapi.InHeader, Field: "locale", Description: "Preferred locales. The title and description are translated to the best match, or left in the original language. The Content-Language response header names the returned translation."},
+			},
+			Response: model.MovieDetails{},
+			Errors:   []errs.Kind{errs.KindInvalidArgument, errs.KindNotFound, errs.KindUnavailable},
+		},
+		decode: DecodeGetMovieDetails,
+		handle: (*Handler).GetMovieDetails,
+	},
+	{
+		Route: openapi.Route{
+			Method:      http.MethodGet,
+			Path:        "/movies",
+			OperationID: "listMovies",
+			Summary:     "List movies",
+			Description: "Returns a page of the movie catalog. Pass nextPageToken back as pageToken, together with the same filters and order, to fetch the following page. Unrated movies count as rated 0 for sorting and filtering.",
+			Tags:        []string{"movies"},
+			Request:     &gen.ListMoviesRequest{},
+			Params: []openapi.Param{
+				{Name: "pageSize", In: openapi.InQuery, Field: "page_size", Description: "Maximum number of movies per page. Defaults to 20."},
+				{Name: "pageToken", In: openapi.InQuery, Field: "page_token", Description: "Token of the page to fetch."},
+				{Name: "orderBy", In: openapi.InQuery, Field: "order_by", Description: "Sort key. Defaults to title."},
+				{Name: "desc", In: openapi.InQuery, Field: "descending", Description: "Sort in descending order."},
+				{Name: "director", In: openapi.InQuery, Field: "director", Description: "Only movies by this director."},
+				{Name: "titlePrefix", In: openapi.InQuery, Field: "title_prefix", Description: "Only movies whose title starts with this prefix."},
+				{Name: "minRating", In: openapi.InQuery, Field: "min_rating", Description: "Minimum average rating."},
+				{Name: "minVotes", In: openapi.InQuery, Field: "min_votes", Description: "Minimum number of ratings."},
+				{Name: "type", In: openapi.InQuery, Field: "type", Description: "Record type to list: movie (default), series, season or episode."},
+			},
+			Response: model.MoviePage{},
+			Errors:   []errs.Kind{errs.KindInvalidArgument},
+		},
+		decode: DecodeListMovies,
+		handle: (*Handler).ListMovies,
+	},
+	{
+		Route: openapi.Route{
+			Method:      http.MethodGet,
+			Path:        "/movies/top",
+			OperationID: "listTopRatedMovies",
+			Summary:     "List top rated movies",
+			Description: "Returns a page of the highest rated movies with at least the given number of ratings, ranked by the aggregation the rating service is configured with for the record type. Rankings other than the mean follow new ratings at the next periodic refresh. Pass nextPageToken back as pageToken, together with the same filters, to fetch the following page.",
+			Tags:        []string{"movies"},
+			Request:     &gen.ListTopRatedMoviesRequest{},
+			Params: []openapi.Param{
+				{Name: "type", In: openapi.InQuery, Field: "type", Description: "Record type to list: movie (default), series, season or episode."},
+				{Name: "minVotes", In: openapi.InQuery, Field: "min_votes", Description: "Minimum number of ratings."},
+				{Name: "pageSize", In: openapi.InQuery, Field: "page_size", Description: "Maximum number of movies per page. Defaults to 50."},
+				{Name: "pageToken", In: openapi.InQuery, Field: "page_token", Description: "Token of the page to fetch."},
+				{Name: "locale", In: openapi.InQuery, Field: "locale", Description: "Preferred locales in Accept-Language form. Takes precedence over the Accept-Language header."},
+				{Name: "Accept-Language", In: openapi.InHeader, Field: "locale", Description: "Preferred locales of the titles and descriptions."},
+			},
+			Response: model.MoviePage{},
+			Errors:   []errs.Kind{errs.KindInvalidArgument, errs.KindUnavailable},
+		},
+		decode: DecodeListTopRated,
+		handle: (*Handler).ListTopRated,
+	},
+	{
+		Route: openapi.Route{
+			Method:      http.MethodGet,
+			Path:        "/movies/{id}/similar",
+			OperationID: "listSimilarMovies",
+			Summary:     "List similar movies",
+			Description: "Returns the records of the same type as a movie that are most alike in director, genres, title and description, most similar first. Ratings play no part, so movies nobody has rated yet are placed too.",
+			Tags:        []string{"movies"},
+			Request:     &gen.ListSimilarMoviesRequest{},
+			Params: []openapi.Param{
+				{Name: "id", In: openapi.InPath, Field: "movie_id", Description: "Movie ID."},
+				{Name: "limit", In: openapi.InQuery, Field: "limit", Description: "Maximum number of movies. Defaults to 10."},
+			},
+			Response: model.SimilarMovies{},
+			Errors:   []errs.Kind{errs.KindInvalidArgument, errs.KindNotFound, errs.KindUnavailable},
+		},
+		decode: DecodeListSimilarMovies,
+		handle: (*Handler).ListSimilarMovies,
+	},
+}
+
+// Spec returns the OpenAPI document of the movie HTTP API.
+func Spec() *openapi.Document {
+	r := make([]openapi.Route, len(routes))
+	for i := range routes {
+		r[i] = routes[i].Route
+	}
+	return openapi.Generate(openapi.Info{
+		Title:       "Movie API",
+		Description: "Movie details combining metadata and ratings.",
+		Version:     "1.0.0",
+	}, r)
+}
+
+// Register registers the API endpoints, the OpenAPI document
+// at /openapi.json and the API docs page at /docs on mux.
+// Paths with parameters are served under their static prefix
+// and matched segment by segment.
+func (h *Handler) Register(mux *http.ServeMux) {
+	var paths []string
+	byPath := map[string]map[string]http.Handler{}
+	for _, r := range routes {
+		r := r
+		var next http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r.handle(h, w, req)
+		})
+		if r.decode != nil {
+			names := map[string]string{}
+			for _, p := range r.Params {
+				if _, ok := names[p.Field]; !ok {
+					names[p.Field] = p.Name
+				}
+			}
+			next = validation.Middleware(r.decode, names, next)
+		}
+		if byPath[r.Path] == nil {
+			byPath[r.Path] = map[string]http.Handler{}
+			paths = append(paths, r.Path)
+		}
+		byPath[r.Path][r.Method] = next
+	}
+	var prefixes []string
+	templated := map[string][]pathHandler{}
+	for _, p := range paths {
+		i := strings.Index(p, "{")
+		if i < 0 {
+			mux.Handle(p, methods(byPath[p]))
+			continue
+		}
+		prefix := p[:strings.LastIndex(p[:i], "/")+1]
+		if templated[prefix] == nil {
+			prefixes = append(prefixes, prefix)
+		}
+		templated[prefix] = append(templated[prefix], pathHandler{p, methods(byPath[p])})
+	}
+	for _, prefix := range prefixes {
+		mux.Handle(prefix, matchPaths(templated[prefix]))
+	}
+
+	mux.HandleFunc("/openapi.json", serveSpec)
+	assets, err := fs.Sub(docs, "docs")
+	if err != nil {
+		panic(err)
+	}
+	mux.Handle("/docs/", http.StripPrefix("/docs/", http.FileServer(http.FS(assets))))
+}
+
+// methods dispatches requests to the handler of their method.
+func methods(handlers map[string]http.Handler) http.Handler {
+	var allowed []string
+	for m := range handlers {
+		allowed = append(allowed, m)
+	}
+	sort.Strings(allowed)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h, ok := handlers[req.Method]
+		if !ok && req.Method == http.MethodHead {
+			h, ok = handlers[http.MethodGet]
+		}
+		if !ok {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// pathHandler defines the handler of a path with parameters.
+type pathHandler struct {
+	pattern string
+	handler http.Handler
+}
+
+// matchPaths dispatches requests to the handler of the first
+// path they match, with the parameter values in the request
+// context.
+func matchPaths(handlers []pathHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, h := range handlers {
+			if params, ok := matchPath(h.pattern, req.URL.EscapedPath()); ok {
+				h.handler.ServeHTTP(w, withPathParams(req, params))
+				return
+			}
+		}
+		http.NotFound(w, req)
+	})
+}
+
+// matchPath matches an escaped request path against a path
+// whose segments in braces are parameters, and returns the
+// unescaped parameter values. Parameters only match
+// non-empty segments.
+func matchPath(pattern, path string) (map[string]string, bool) {
+	want, got := strings.Split(pattern, "/"), strings.Split(path, "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, w := range want {
+		if !strings.HasPrefix(w, "{") || !strings.HasSuffix(w, "}") {
+			if w != got[i] {
+				return nil, false
+			}
+			continue
+		}
+		v, err := url.PathUnescape(got[i])
+		if err != nil || v == "" {
+			return nil, false
+		}
+		params[w[1:len(w)-1]] = v
+	}
+	return params, true
+}
+
+type pathParamsKey struct{}
+
+// withPathParams returns req with the path parameter values
+// in its context.
+func withPathParams(req *http.Request, params map[string]string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), pathParamsKey{}, params))
+}
+
+// pathParam returns the value of a path parameter of the
+// request route.
+func pathParam(req *http.Request, name string) string {
+	params, _ := req.Context().Value(pathParamsKey{}).(map[string]string)
+	return params[name]
+}
+
+func serveSpec(w http.ResponseWriter, _ *http.Request) {
+	b, err := docs.ReadFile("docs/openapi.json")
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		log.Printf("Response write error: %v\n", err)
+	}
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/openapi"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TestSpecUpToDate fails when docs/openapi.json no longer
+// matches the route table.
+func TestSpecUpToDate(t *testing.T) {
+	want, err := openapi.Marshal(Spec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := docs.ReadFile("docs/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("docs/openapi.json is out of date; run go generate ./movie/internal/handler/http")
+	}
+}
+
+// TestRouteParams checks that every documented parameter is
+// read by the route decoder into its request field.
+func TestRouteParams(t *testing.T) {
+	for _, r := range routes {
+		if r.decode == nil {
+			continue
+		}
+		fields := r.Request.ProtoReflect().Descriptor().Fields()
+		for _, p := range r.Params {
+			fd := fields.ByName(protoreflect.Name(p.Field))
+			if fd == nil {
+				t.Errorf("%s %s: unknown field %q", r.Method, r.Path, p.Field)
+				continue
+			}
+			req := httptest.NewRequest(r.Method, r.Path, nil)
+			switch p.In {
+			case openapi.InQuery:
+				req.URL.RawQuery = url.Values{p.Name: {sampleValue(fd)}}.Encode()
+			case openapi.InHeader:
+				req.Header.Set(p.Name, sampleValue(fd))
+			case openapi.InPath:
+				req = withPathParams(req, map[string]string{p.Name: sampleValue(fd)})
+			}
+			m, err := r.decode(req)
+			if err != nil {
+				t.Errorf("%s %s: decode %s: %v", r.Method, r.Path, p.Name, err)
+				continue
+			}
+			if !m.ProtoReflect().Has(fd) {
+				t.Errorf("%s %s: parameter %s is not decoded into field %s", r.Method, r.Path, p.Name, p.Field)
+			}
+		}
+	}
+}
+
+func sampleValue(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "true"
+	case protoreflect.EnumKind:
+		return string(fd.Enum().Values().Get(fd.Enum().Values().Len() - 1).Name())
+	}
+	return "1"
+}
+
+func TestRegister(t *testing.T) {
+	mux := http.NewServeMux()
+	New(nil).Register(mux)
+
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodGet, "/openapi.json", http.StatusOK},
+		{http.MethodGet, "/docs/", http.StatusOK},
+		{http.MethodGet, "/docs/docs.js", http.StatusOK},
+		{http.MethodGet, "/docs", http.StatusMovedPermanently},
+		{http.MethodGet, "/movie", http.StatusBadRequest},
+		{http.MethodPost, "/movie", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/movies/1/similar?limit=x", http.StatusBadRequest},
+		{http.MethodGet, "/movies/1/similar?limit=500", http.StatusBadRequest},
+		{http.MethodPost, "/movies/1/similar", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/movies/1/other", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: got status %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want map[string]string
+	}{
+		{"/movies/1/similar", map[string]string{"id": "1"}},
+		{"/movies/a%2Fb/similar", map[string]string{"id": "a/b"}},
+		{"/movies//similar", nil},
+		{"/movies/1/similar/more", nil},
+		{"/movies/1", nil},
+		{"/series/1/similar", nil},
+	}
+	for _, tt := range tests {
+		got, ok := matchPath("/movies/{id}/similar", tt.path)
+		if ok != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) && tt.want != nil {
+			t.Errorf("matchPath(%q) = %v, %v, want %v", tt.path, got, ok, tt.want)
+		}
+	}
+}
+
+// TestViolationNames checks that violations name the
+// parameters clients send rather than the request fields.
+func TestViolationNames(t *testing.T) {
+	mux := http.NewServeMux()
+	New(nil).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/movies?pageSize=500", nil))
+	var env errs.Envelope
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatal(err)
+	}
+	if v := env.Error.FieldViolations; len(v) != 1 || v[0].Field != "pageSize" {
+		t.Errorf("got violations %+v, want pageSize", v)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 
 	_ "github.com/lib/pq"
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
 	"github.com/phongld0308/movie-example/movie/internal/repository"
 	"github.com/phongld0308/movie-example/movie/pkg/model"
 )
@@ -35,16 +38,17 @@ func New(host string, port int, user, password, dbname string) (*Repository, err
 	return &Repository{db: db}, nil
 }
 
-// Get retrieves movie details by ID
+// Get retrieves movie details by ID. Ratings are read from
+// the aggregates the rating service keeps up to date.
 func (r *Repository) Get(ctx context.Context, id string) (*model.MovieDetails, error) {
 	query := `
-		SELECT m.id, m.title, m.description, m.director, 
-			   COALESCE(AVG(r.value), 0) as avg_rating,
-			   COUNT(r.value) as rating_count
+		SELECT m.id, m.title, m.description, m.director, m.record_type,
+			   COALESCE(` + avgRating + `, 0) as avg_rating,
+			   COALESCE(a.rating_count, 0) as rating_count,
+			   COALESCE(a.review_count, 0) as review_count
 		FROM movies m
-		LEFT JOIN ratings r ON m.id = r.record_id AND r.record_type = 'movie'
-		WHERE m.id = $1
-		GROUP BY m.id, m.title, m.description, m.director`
+		LEFT JOIN rating_aggregates a ON a.record_id = m.id AND a.record_type = m.record_type
+		WHERE m.id = $1`
 
 	var movie model.MovieDetails
 	var avgRating float64
@@ -55,8 +59,10 @@ func (r *Repository) Get(ctx context.Context, id string) (*model.MovieDetails, e
 		&movie.Metadata.Title,
 		&movie.Metadata.Description,
 		&movie.Metadata.Director,
+		&movie.Metadata.Type,
 		&avgRating,
 		&ratingCount,
+		&movie.ReviewCount,
 	)
 
 	if err == sql.ErrNoRows {
@@ -68,6 +74,7 @@ func (r *Repository) Get(ctx context.Context, id string) (*model.MovieDetails, e
 
 	if ratingCount > 0 {
 		movie.Rating = &avgRating
+		movie.RatingCount = int64(ratingCount)
 	}
 
 	return &movie, nil
@@ -82,14 +89,16 @@ func (r *Repository) Put(ctx context.Context, movie *model.MovieDetails) error {
 	defer tx.Rollback()
 
 	// Insert movie metadata
+	recordType := recordTypeOf(movie)
 	_, err = tx.ExecContext(ctx,
-		`INSERT INTO movies (id, title, description, director)
-		 VALUES ($1, $2, $3, $4)
+		`INSERT INTO movies (id, title, description, director, record_type)
+		 VALUES ($1, $2, $3, $4, $5)
 		 ON CONFLICT (id) DO NOTHING`,
 		movie.Metadata.ID,
 		movie.Metadata.Title,
 		movie.Metadata.Description,
 		movie.Metadata.Director,
+		recordType,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert movie: %v", err)
@@ -99,8 +108,9 @@ func (r *Repository) Put(ctx context.Context, movie *model.MovieDetails) error {
 	if movie.Rating != nil {
 		_, err = tx.ExecContext(ctx,
 			`INSERT INTO ratings (record_id, record_type, value)
-			 VALUES ($1, 'movie', $2)`,
+			 VALUES ($1, $2, $3)`,
 			movie.Metadata.ID,
+			recordType,
 			*movie.Rating,
 		)
 		if err != nil {
@@ -122,7 +132,7 @@ func (r *Repository) Update(ctx context.Context, movie *model.MovieDetails) erro
 	// Update movie metadata
 	result, err := tx.ExecContext(ctx,
 		`UPDATE movies 
-		 SET title = $2, description = $3, director = $4
+		 SET title = $2, description = $3, director = $4, version = version + 1
 		 WHERE id = $1`,
 		movie.Metadata.ID,
 		movie.Metadata.Title,
@@ -144,9 +154,10 @@ func (r *Repository) Update(ctx context.Context, movie *model.MovieDetails) erro
 	// If there's a rating, update it
 	if movie.Rating != nil {
 		_, err = tx.ExecContext(ctx,
-			`UPDATE ratings 
+			`UPDATE ratings r
 			 SET value = $2
-			 WHERE record_id = $1 AND record_type = 'movie'`,
+			 FROM movies m
+			 WHERE r.record_id = $1 AND m.id = r.record_id AND r.record_type = m.record_type`,
 			movie.Metadata.ID,
 			*movie.Rating,
 		)
@@ -168,7 +179,9 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 
 	// Delete ratings first (due to foreign key constraints)
 	_, err = tx.ExecContext(ctx,
-		"DELETE FROM ratings WHERE record_id = $1 AND record_type = 'movie'",
+		`DELETE FROM ratings r
+		 USING movies m
+		 WHERE r.record_id = $1 AND m.id = r.record_id AND r.record_type = m.record_type`,
 		id,
 	)
 	if err != nil {
@@ -195,44 +208,66 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 	return tx.Commit()
 }
 
-// List returns all movies with optional pagination
-func (r *Repository) List(ctx context.Context, skip, take int) ([]model.MovieDetails, error) {
-	query := `
-		SELECT m.id, m.title, m.description, m.director, 
-			   COALESCE(AVG(r.value), 0) as avg_rating,
-			   COUNT(r.value) as rating_count
-		FROM movies m
-		LEFT JOIN ratings r ON m.id = r.record_id AND r.record_type = 'movie'
-		GROUP BY m.id, m.title, m.description, m.director
-		ORDER BY m.id
-		LIMIT $1 OFFSET $2`
+// recordTypeOf returns the record type of movie, a movie
+// unless its metadata says otherwise.
+func recordTypeOf(movie *model.MovieDetails) metadatamodel.RecordType {
+	if movie.Metadata.Type == "" {
+		return metadatamodel.RecordTypeMovie
+	}
+	return movie.Metadata.Type
+}
+
+// avgRating is the mean rating of the aggregate a, or NULL
+// if it has no ratings.
+const avgRating = "a.rating_sum::float8 / NULLIF(a.rating_count, 0)"
+
+// sortExprs maps sort fields to the SQL expressions they
+// order by. Only these expressions are ever interpolated into
+// list queries; all values are passed as arguments. The
+// rating expressions match the indexes on rating_aggregates.
+var sortExprs = map[repository.SortField]string{
+	repository.SortByTitle:       "m.title",
+	repository.SortByRating:      "COALESCE(" + avgRating + ", 0)",
+	repository.SortByRatingCount: "COALESCE(a.rating_count, 0)",
+}
+
+// List returns a page of movies matching the query. Pages
+// are addressed by keyset rather than offset, so that deep
+// pages stay cheap and stable while movies are added.
+func (r *Repository) List(ctx context.Context, query repository.ListQuery) ([]model.MovieSummary, error) {
+	q, args, err := buildListQuery(query)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, take, skip)
+	rows, err := r.db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query movies: %v", err)
 	}
 	defer rows.Close()
 
-	var movies []model.MovieDetails
+	var movies []model.MovieSummary
 	for rows.Next() {
-		var movie model.MovieDetails
-		var avgRating float64
-		var ratingCount int
+		var movie model.MovieSummary
+		var avgRating sql.NullFloat64
+		var description, director sql.NullString
 
 		err := rows.Scan(
 			&movie.Metadata.ID,
 			&movie.Metadata.Title,
-			&movie.Metadata.Description,
-			&movie.Metadata.Director,
+			&description,
+			&director,
+			&movie.Metadata.Version,
 			&avgRating,
-			&ratingCount,
+			&movie.RatingCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan movie: %v", err)
 		}
-
-		if ratingCount > 0 {
-			movie.Rating = &avgRating
+		movie.Metadata.Description = description.String
+		movie.Metadata.Director = director.String
+		if avgRating.Valid {
+			movie.Rating = &avgRating.Float64
 		}
 
 		movies = append(movies, movie)
@@ -245,6 +280,79 @@ func (r *Repository) List(ctx context.Context, skip, take int) ([]model.MovieDet
 	return movies, nil
 }
 
+func buildListQuery(query repository.ListQuery) (string, []any, error) {
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = repository.SortByTitle
+	}
+	sortExpr, ok := sortExprs[sortBy]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported sort field %q", sortBy)
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	recordType := query.RecordType
+	if recordType == "" {
+		recordType = metadatamodel.RecordTypeMovie
+	}
+	where := []string{"m.record_type = " + arg(string(recordType))}
+	if query.Director != "" {
+		where = append(where, "m.director = "+arg(query.Director))
+	}
+	if query.TitlePrefix != "" {
+		where = append(where, "m.title LIKE "+arg(escapeLike(query.TitlePrefix)+"%"))
+	}
+	if query.MinRating > 0 {
+		where = append(where, sortExprs[repository.SortByRating]+" >= "+arg(query.MinRating))
+	}
+	if query.MinVotes > 0 {
+		where = append(where, sortExprs[repository.SortByRatingCount]+" >= "+arg(query.MinVotes))
+	}
+	// Rating filters leave out unrated movies, so that the
+	// aggregates can drive the query.
+	join := "LEFT JOIN"
+	if query.MinRating > 0 || query.MinVotes > 0 {
+		join = "JOIN"
+	}
+
+	dir, cmp := "ASC", ">"
+	if query.Descending {
+		dir, cmp = "DESC", "<"
+	}
+	if c := query.After; c != nil {
+		var v any
+		switch sortBy {
+		case repository.SortByTitle:
+			v = c.Title
+		case repository.SortByRating:
+			v = c.Rating
+		case repository.SortByRatingCount:
+			v = c.RatingCount
+		}
+		where = append(where, fmt.Sprintf("(%s, m.id) %s (%s, %s)", sortExpr, cmp, arg(v), arg(c.ID)))
+	}
+
+	var b strings.Builder
+	b.WriteString(`SELECT m.id, m.title, m.description, m.director, m.version,
+		       ` + avgRating + `, COALESCE(a.rating_count, 0)
+		FROM movies m
+		` + join + ` rating_aggregates a ON a.record_id = m.id AND a.record_type = m.record_type`)
+	b.WriteString("\n\t\tWHERE " + strings.Join(where, " AND "))
+	fmt.Fprintf(&b, "\n\t\tORDER BY %s %s, m.id %s\n\t\tLIMIT %s", sortExpr, dir, dir, arg(query.Limit))
+
+	return b.String(), args, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // Close closes the database connection
 func (r *Repository) Close() error {
 	return r.db.Close()
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
+	"github.com/phongld0308/movie-example/movie/internal/repository"
+)
+
+func TestBuildListQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    repository.ListQuery
+		contains []string
+		args     []any
+	}{
+		{
+			name:     "defaults",
+			query:    repository.ListQuery{Limit: 10},
+			contains: []string{"LEFT JOIN rating_aggregates a", "WHERE m.record_type = $1", "ORDER BY m.title ASC, m.id ASC", "LIMIT $2"},
+			args:     []any{"movie", 10},
+		},
+		{
+			name:     "series",
+			query:    repository.ListQuery{RecordType: metadatamodel.RecordTypeSeries, Limit: 10},
+			contains: []string{"WHERE m.record_type = $1", "a.record_type = m.record_type"},
+			args:     []any{"series", 10},
+		},
+		{
+			name: "filters",
+			query: repository.ListQuery{
+				Director:    "Nolan",
+				TitlePrefix: "100%_",
+				MinRating:   3.5,
+				MinVotes:    10,
+				Limit:       5,
+			},
+			contains: []string{
+				"\tJOIN rating_aggregates a",
+				"WHERE m.record_type = $1 AND m.director = $2 AND m.title LIKE $3 AND COALESCE(a.rating_sum::float8 / NULLIF(a.rating_count, 0), 0) >= $4 AND COALESCE(a.rating_count, 0) >= $5",
+				"LIMIT $6",
+			},
+			args: []any{"movie", "Nolan", `100\%\_%`, 3.5, int64(10), 5},
+		},
+		{
+			name: "descending rating after cursor",
+			query: repository.ListQuery{
+				SortBy:     repository.SortByRating,
+				Descending: true,
+				Limit:      20,
+				After:      &repository.Cursor{Rating: 4.2, ID: "7"},
+			},
+			contains: []string{
+				"WHERE m.record_type = $1 AND (COALESCE(a.rating_sum::float8 / NULLIF(a.rating_count, 0), 0), m.id) < ($2, $3)",
+				"ORDER BY COALESCE(a.rating_sum::float8 / NULLIF(a.rating_count, 0), 0) DESC, m.id DESC",
+			},
+			args: []any{"movie", 4.2, "7", 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, args, err := buildListQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(q, s) {
+					t.Errorf("query %q does not contain %q", q, s)
+				}
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("got args %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+
+	if _, _, err := buildListQuery(repository.ListQuery{SortBy: "title; DROP TABLE movies"}); err == nil {
+		t.Error("expected an error for an unsupported sort field")
+	}
+}
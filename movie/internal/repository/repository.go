@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	metadatamodel "github.com/phongld0308/movie-example/metadata/pkg/model"
 	"github.com/phongld0308/movie-example/movie/pkg/model"
 )
 
@@ -26,6 +27,47 @@ type Repository interface {
 	// Delete removes a movie by ID
 	Delete(ctx context.Context, id string) error
 
-	// List returns all movies with optional pagination
-	List(ctx context.Context, skip, take int) ([]model.MovieDetails, error)
+	// List returns a page of movies matching the query
+	List(ctx context.Context, query ListQuery) ([]model.MovieSummary, error)
+}
+
+// SortField defines a key movies can be listed by.
+type SortField string
+
+// Supported sort fields.
+const (
+	SortByTitle       = SortField("title")
+	SortByRating      = SortField("rating")
+	SortByRatingCount = SortField("rating_count")
+)
+
+// ListQuery defines the filters, order and page of a movie
+// listing. Unrated movies have a rating of 0 when sorting and
+// filtering.
+type ListQuery struct {
+	// RecordType restricts the listing to one type of catalog
+	// record, movies by default.
+	RecordType  metadatamodel.RecordType
+	Director    string
+	TitlePrefix string
+	MinRating   float64
+	MinVotes    int64
+
+	SortBy     SortField
+	Descending bool
+
+	// Limit is the maximum number of movies to return.
+	Limit int
+	// After, when set, starts the page right after the movie
+	// at this position in the requested order.
+	After *Cursor
+}
+
+// Cursor defines a position in a movie listing: the sort key
+// of a movie, with its ID breaking ties.
+type Cursor struct {
+	Title       string  `json:"title,omitempty"`
+	Rating      float64 `json:"rating,omitempty"`
+	RatingCount int64   `json:"ratingCount,omitempty"`
+	ID          string  `json:"id"`
 }
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"github.com/phongld0308/movie-example/gen"
+	model "github.com/phongld0308/movie-example/metadata/pkg/model"
+)
+
+// MovieSummaryToProto converts a MovieSummary struct into a
+// generated proto counterpart.
+func MovieSummaryToProto(m *MovieSummary) *gen.MovieSummary {
+	return &gen.MovieSummary{
+		Metadata:    model.MetadataToProto(&m.Metadata),
+		Rating:      m.Rating,
+		RatingCount: m.RatingCount,
+	}
+}
@@ -4,6 +4,34 @@ import model "github.com/phongld0308/movie-example/metadata/pkg/model"
 
 // MovieDetails includes movie meta data it aggregated rating.
 type MovieDetails struct {
-	Rating   *float64       `json:"rating"`
-	Metadata model.Metadata `json:"metadata"`
+	Rating      *float64       `json:"rating"`
+	Metadata    model.Metadata `json:"metadata"`
+	RatingCount int64          `json:"ratingCount"`
+	// RatingHistogram counts the ratings of each value from 1
+	// to 5. It is empty for unrated movies.
+	RatingHistogram []int64 `json:"ratingHistogram,omitempty"`
+	// ReviewCount is the number of ratings with a written
+	// review.
+	ReviewCount int64 `json:"reviewCount"`
+}
+
+// MovieSummary defines a movie catalog entry.
+type MovieSummary struct {
+	Metadata    model.Metadata `json:"metadata"`
+	Rating      *float64       `json:"rating"`
+	RatingCount int64          `json:"ratingCount"`
+}
+
+// MoviePage defines a page of a movie catalog listing.
+type MoviePage struct {
+	Movies []MovieSummary `json:"movies"`
+	// NextPageToken fetches the following page. It is empty
+	// on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// SimilarMovies defines the movies most alike a movie in
+// their metadata, most similar first.
+type SimilarMovies struct {
+	Movies []model.SimilarMovie `json:"movies"`
 }
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := NewVerifier([]byte("secret"))
+	v.now = func() time.Time { return now }
+	sign := func(v *Verifier, c Claims) string {
+		t.Helper()
+		token, err := v.Sign(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := Claims{Subject: "mod1", Roles: []string{RoleModerator}, ExpiresAt: now.Add(time.Hour)}
+
+	c, err := v.Verify(sign(v, valid))
+	if err != nil {
+		t.Fatalf("valid token: %v", err)
+	}
+	if c.Subject != "mod1" || !c.HasRole(RoleModerator) {
+		t.Errorf("got claims %+v, want %+v", c, valid)
+	}
+
+	expired := valid
+	expired.ExpiresAt = now
+	forged := sign(NewVerifier([]byte("other")), valid)
+	for name, token := range map[string]string{
+		"empty":   "",
+		"garbage": "not.a-token",
+		"forged":  forged,
+		"expired": sign(v, expired),
+	} {
+		if _, err := v.Verify(token); err != ErrInvalidToken {
+			t.Errorf("%s token: got %v, want %v", name, err, ErrInvalidToken)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	v := NewVerifier([]byte("secret"))
+	interceptor := UnaryServerInterceptor(v, RoleModerator)
+	handler := func(ctx context.Context, req any) (any, error) {
+		c, _ := FromContext(ctx)
+		return c.Subject, nil
+	}
+	call := func(header string) (any, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, header))
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/RatingModerationService/SetModerationStatus"}, handler)
+	}
+
+	moderator, _ := v.Sign(Claims{Subject: "mod1", Roles: []string{RoleModerator}, ExpiresAt: time.Now().Add(time.Hour)})
+	user, _ := v.Sign(Claims{Subject: "user1", ExpiresAt: time.Now().Add(time.Hour)})
+	if got, err := call("Bearer " + moderator); err != nil || got != "mod1" {
+		t.Errorf("moderator: got %v, %v, want mod1", got, err)
+	}
+	if _, err := call("Bearer " + user); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("user: got %v, want code %v", err, codes.PermissionDenied)
+	}
+	if _, err := call(moderator); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no scheme: got %v, want code %v", err, codes.Unauthenticated)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	v := NewVerifier([]byte("secret"))
+	h := Middleware(v, RoleModerator, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, _ := FromContext(req.Context())
+		w.Write([]byte(c.Subject))
+	}))
+	serve := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/rating/moderation", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	moderator, _ := v.Sign(Claims{Subject: "mod1", Roles: []string{RoleModerator}, ExpiresAt: time.Now().Add(time.Hour)})
+	if w := serve("Bearer " + moderator); w.Code != http.StatusOK || w.Body.String() != "mod1" {
+		t.Errorf("moderator: status %d, body %q", w.Code, w.Body.String())
+	}
+	if w := serve(""); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Errorf("anonymous: status %d, WWW-Authenticate %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+}
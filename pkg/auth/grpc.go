@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the gRPC metadata key carrying the bearer
+// token.
+const MetadataKey = "authorization"
+
+// UnaryServerInterceptor returns a gRPC interceptor rejecting
+// requests without a valid bearer token granting role. The
+// claims of accepted requests are added to their context.
+func UnaryServerInterceptor(v *Verifier, role string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var token string
+		if values := md.Get(MetadataKey); len(values) > 0 {
+			token = bearer(values[0])
+		}
+		c, err := v.authorize(token, role)
+		if err != nil {
+			return nil, errs.ToGRPC(err)
+		}
+		return handler(NewContext(ctx, c), req)
+	}
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+)
+
+// Middleware returns an HTTP middleware rejecting requests
+// without a valid bearer token granting role in their
+// Authorization header. The claims of accepted requests are
+// added to their context.
+func Middleware(v *Verifier, role string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, err := v.authorize(bearer(req.Header.Get("Authorization")), role)
+		if err != nil {
+			if errs.KindOf(err) == errs.KindUnauthenticated {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			errs.WriteHTTP(w, err)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), c)))
+	})
+}
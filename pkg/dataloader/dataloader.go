@@ -0,0 +1,222 @@
+// Package dataloader batches and caches key lookups made
+// while resolving a single request, so that resolvers can
+// load items one by one without issuing N+1 backend calls.
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default batching settings.
+const (
+	DefaultWait     = time.Millisecond
+	DefaultMaxBatch = 100
+)
+
+// BatchFunc loads the values of keys. It must return one
+// value and one error per key, in the order of keys; a nil
+// errors slice means all keys were loaded successfully.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// Loader collects the keys requested within a short wait
+// window into a single batch call and caches the results for
+// its lifetime. Loaders are meant to be created per request.
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	cache map[K]*result[V]
+	batch *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+	timer   *time.Timer
+}
+
+// Option configures a Loader.
+type Option func(*options)
+
+type options struct {
+	wait     time.Duration
+	maxBatch int
+}
+
+// WithWait sets how long a loader waits for more keys before
+// dispatching a batch.
+func WithWait(d time.Duration) Option {
+	return func(o *options) { o.wait = d }
+}
+
+// WithMaxBatch limits the number of keys per batch call.
+func WithMaxBatch(n int) Option {
+	return func(o *options) { o.maxBatch = n }
+}
+
+// New creates a new loader fetching values with fetch.
+func New[K comparable, V any](fetch BatchFunc[K, V], opts ...Option) *Loader[K, V] {
+	o := options{wait: DefaultWait, maxBatch: DefaultMaxBatch}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     o.wait,
+		maxBatch: o.maxBatch,
+		cache:    map[K]*result[V]{},
+	}
+}
+
+// Load returns the value of key, waiting for the batch it is
+// part of. The context of the first Load of a batch is used
+// for the batch call.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	r := l.enqueue(ctx, key)
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// LoadMany returns the values of keys, loaded in as few
+// batches as possible.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, []error) {
+	results := make([]*result[V], len(keys))
+	for i, k := range keys {
+		results[i] = l.enqueue(ctx, k)
+	}
+
+	values := make([]V, len(keys))
+	errs := make([]error, len(keys))
+	for i, r := range results {
+		select {
+		case <-r.done:
+			values[i], errs[i] = r.value, r.err
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+		}
+	}
+	return values, errs
+}
+
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.cache[key]; ok {
+		return r
+	}
+	r := &result[V]{done: make(chan struct{})}
+	l.cache[key] = r
+
+	if l.batch == nil {
+		b := &batch[K, V]{}
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(ctx, b) })
+		l.batch = b
+	}
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+	if len(b.keys) >= l.maxBatch {
+		// Dispatch full batches right away. A timer that
+		// already fired finds the batch detached and returns.
+		b.timer.Stop()
+		l.batch = nil
+		go l.run(ctx, b)
+	}
+	return r
+}
+
+// dispatch runs b unless it was already dispatched as a full
+// batch.
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+	l.run(ctx, b)
+}
+
+func (l *Loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	values, errs := l.fetch(ctx, b.keys)
+	if len(values) != len(b.keys) || (errs != nil && len(errs) != len(b.keys)) {
+		err := fmt.Errorf("dataloader: batch function returned %d values and %d errors for %d keys", len(values), len(errs), len(b.keys))
+		for _, r := range b.results {
+			r.err = err
+			close(r.done)
+		}
+		return
+	}
+	for i, r := range b.results {
+		r.value = values[i]
+		if errs != nil {
+			r.err = errs[i]
+		}
+		close(r.done)
+	}
+}
+
+// Map adapts a lookup returning the values found by key to a
+// BatchFunc for backends with batch endpoints. Keys missing
+// from the result fail with missing; an error of the lookup
+// fails every key.
+func Map[K comparable, V any](missing error, fetch func(ctx context.Context, keys []K) (map[K]V, error)) BatchFunc[K, V] {
+	return func(ctx context.Context, keys []K) ([]V, []error) {
+		values := make([]V, len(keys))
+		errs := make([]error, len(keys))
+		found, err := fetch(ctx, keys)
+		for i, k := range keys {
+			v, ok := found[k]
+			switch {
+			case err != nil:
+				errs[i] = err
+			case !ok:
+				errs[i] = missing
+			default:
+				values[i] = v
+			}
+		}
+		return values, errs
+	}
+}
+
+// Each adapts a single-key lookup to a BatchFunc for backends
+// without batch endpoints. The keys of a batch are fetched
+// concurrently, at most limit at a time.
+func Each[K comparable, V any](limit int, fetch func(ctx context.Context, key K) (V, error)) BatchFunc[K, V] {
+	return func(ctx context.Context, keys []K) ([]V, []error) {
+		values := make([]V, len(keys))
+		errs := make([]error, len(keys))
+		sem := make(chan struct{}, limit)
+		var wg sync.WaitGroup
+		for i, k := range keys {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, k K) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				values[i], errs[i] = fetch(ctx, k)
+			}(i, k)
+		}
+		wg.Wait()
+		return values, errs
+	}
+}
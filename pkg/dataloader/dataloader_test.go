@@ -0,0 +1,108 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoaderBatchesAndCaches(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	l := New(func(_ context.Context, keys []int) ([]string, []error) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+		values := make([]string, len(keys))
+		errs := make([]error, len(keys))
+		for i, k := range keys {
+			if k < 0 {
+				errs[i] = errors.New("negative key")
+				continue
+			}
+			values[i] = string(rune('a' + k))
+		}
+		return values, errs
+	}, WithWait(10*time.Millisecond))
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for _, k := range []int{0, 1, 1, 2, -1} {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			v, err := l.Load(ctx, k)
+			switch {
+			case k < 0 && err == nil:
+				t.Errorf("Load(%d): expected error", k)
+			case k >= 0 && (err != nil || v != string(rune('a'+k))):
+				t.Errorf("Load(%d) = %q, %v", k, v, err)
+			}
+		}(k)
+	}
+	wg.Wait()
+
+	if len(batches) != 1 || len(batches[0]) != 4 {
+		t.Fatalf("got batches %v, want a single batch of 4 distinct keys", batches)
+	}
+
+	// Cached keys are not fetched again.
+	values, errs := l.LoadMany(ctx, []int{2, 0})
+	if values[0] != "c" || values[1] != "a" || errs[0] != nil || errs[1] != nil {
+		t.Errorf("LoadMany = %v, %v", values, errs)
+	}
+	if len(batches) != 1 {
+		t.Errorf("cached keys were fetched again: %v", batches)
+	}
+}
+
+func TestLoaderMaxBatch(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	l := New(func(_ context.Context, keys []int) ([]int, []error) {
+		mu.Lock()
+		sizes = append(sizes, len(keys))
+		mu.Unlock()
+		return keys, nil
+	}, WithMaxBatch(2), WithWait(time.Hour))
+
+	values, errs := l.LoadMany(context.Background(), []int{1, 2, 3, 4})
+	for i, v := range values {
+		if errs[i] != nil || v != i+1 {
+			t.Errorf("value %d = %d, %v", i, v, errs[i])
+		}
+	}
+	if len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 2 {
+		t.Errorf("got batch sizes %v, want [2 2]", sizes)
+	}
+}
+
+func TestLoaderBadBatchFunc(t *testing.T) {
+	l := New(func(_ context.Context, keys []int) ([]int, []error) {
+		return nil, nil
+	})
+	if _, err := l.Load(context.Background(), 1); err == nil {
+		t.Error("expected an error for a batch function returning too few values")
+	}
+}
+
+func TestMap(t *testing.T) {
+	errMissing := errors.New("missing")
+	fetch := Map(errMissing, func(_ context.Context, keys []int) (map[int]string, error) {
+		return map[int]string{1: "one"}, nil
+	})
+	values, errs := fetch(context.Background(), []int{1, 2})
+	if values[0] != "one" || errs[0] != nil || !errors.Is(errs[1], errMissing) {
+		t.Errorf("got %q, %v, want one and a missing key", values, errs)
+	}
+
+	errFailed := errors.New("failed")
+	fetch = Map(errMissing, func(_ context.Context, keys []int) (map[int]string, error) {
+		return nil, errFailed
+	})
+	if _, errs := fetch(context.Background(), []int{1, 2}); !errors.Is(errs[0], errFailed) || !errors.Is(errs[1], errFailed) {
+		t.Errorf("got errors %v, want the lookup error for every key", errs)
+	}
+}
@@ -0,0 +1,171 @@
+// Package errs defines typed domain errors shared by all
+// services, together with their gRPC and HTTP mappings.
+package errs
+
+import (
+	"errors"
+	"time"
+)
+
+// Kind defines a category of domain error.
+type Kind int
+
+// Supported error kinds.
+const (
+	KindUnknown Kind = iota
+	KindNotFound
+	KindInvalidArgument
+	KindConflict
+	KindPreconditionFailed
+	KindUnavailable
+	KindUnauthenticated
+	KindPermissionDenied
+)
+
+// String returns the wire code of an error kind.
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "NOT_FOUND"
+	case KindInvalidArgument:
+		return "INVALID_ARGUMENT"
+	case KindConflict:
+		return "CONFLICT"
+	case KindPreconditionFailed:
+		return "PRECONDITION_FAILED"
+	case KindUnavailable:
+		return "UNAVAILABLE"
+	case KindUnauthenticated:
+		return "UNAUTHENTICATED"
+	case KindPermissionDenied:
+		return "PERMISSION_DENIED"
+	}
+	return "INTERNAL"
+}
+
+// FieldViolation describes a single invalid request field.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Error defines a domain error.
+type Error struct {
+	Kind    Kind
+	Message string
+	// Violations lists invalid fields of invalid argument errors.
+	Violations []FieldViolation
+	// RetryAfter hints when an unavailable operation may be retried.
+	RetryAfter time.Duration
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+// Sentinel errors matching any error of the same kind via
+// errors.Is.
+var (
+	ErrNotFound           = &Error{Kind: KindNotFound}
+	ErrInvalidArgument    = &Error{Kind: KindInvalidArgument}
+	ErrConflict           = &Error{Kind: KindConflict}
+	ErrPreconditionFailed = &Error{Kind: KindPreconditionFailed}
+	ErrUnavailable        = &Error{Kind: KindUnavailable}
+	ErrUnauthenticated    = &Error{Kind: KindUnauthenticated}
+	ErrPermissionDenied   = &Error{Kind: KindPermissionDenied}
+)
+
+// New creates a new domain error of the given kind.
+func New(kind Kind, msg string) *Error {
+	return &Error{Kind: kind, Message: msg}
+}
+
+// NotFound creates a new not found error.
+func NotFound(msg string) *Error {
+	return &Error{Kind: KindNotFound, Message: msg}
+}
+
+// InvalidArgument creates an invalid argument error listing
+// the given field violations.
+func InvalidArgument(msg string, violations ...FieldViolation) *Error {
+	return &Error{Kind: KindInvalidArgument, Message: msg, Violations: violations}
+}
+
+// Conflict creates a new conflict error.
+func Conflict(msg string) *Error {
+	return &Error{Kind: KindConflict, Message: msg}
+}
+
+// PreconditionFailed creates a new failed precondition error.
+func PreconditionFailed(msg string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Message: msg}
+}
+
+// Unavailable creates a new unavailable error that may be
+// retried after the given delay.
+func Unavailable(msg string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindUnavailable, Message: msg, RetryAfter: retryAfter}
+}
+
+// Unauthenticated creates a new error for requests without
+// valid credentials.
+func Unauthenticated(msg string) *Error {
+	return &Error{Kind: KindUnauthenticated, Message: msg}
+}
+
+// PermissionDenied creates a new error for requests whose
+// credentials do not grant the operation.
+func PermissionDenied(msg string) *Error {
+	return &Error{Kind: KindPermissionDenied, Message: msg}
+}
+
+// Error returns the error message, including the cause.
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Kind.String()
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether e matches target. A target without a
+// message matches any error of its kind, otherwise both kind
+// and message must match.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Kind == e.Kind && (t.Message == "" || t.Message == e.Message)
+}
+
+// WithViolations returns a copy of e listing the given
+// field violations.
+func (e *Error) WithViolations(violations ...FieldViolation) *Error {
+	c := *e
+	c.Violations = append(append([]FieldViolation{}, e.Violations...), violations...)
+	return &c
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// KindOf returns the kind of the first domain error in the
+// chain of err, or KindUnknown.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindUnknown
+}
@@ -0,0 +1,85 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIs(t *testing.T) {
+	notFound := NotFound("metadata not found")
+	wrapped := fmt.Errorf("get: %w", notFound)
+
+	if !errors.Is(wrapped, ErrNotFound) {
+		t.Error("wrapped error does not match its kind sentinel")
+	}
+	if !errors.Is(NotFound("metadata not found"), notFound) {
+		t.Error("errors with equal kind and message do not match")
+	}
+	if errors.Is(NotFound("rating not found"), notFound) {
+		t.Error("errors with different messages match")
+	}
+	if errors.Is(wrapped, ErrConflict) {
+		t.Error("error matches a different kind")
+	}
+}
+
+func TestGRPCRoundTrip(t *testing.T) {
+	tests := []*Error{
+		NotFound("metadata not found"),
+		InvalidArgument("invalid request", FieldViolation{Field: "title", Description: "must not be empty"}),
+		Conflict("duplicate"),
+		PreconditionFailed("version mismatch"),
+		Unavailable("rating service unavailable", 3*time.Second),
+		Unauthenticated("missing credentials"),
+		PermissionDenied("moderator role required"),
+	}
+	for _, want := range tests {
+		t.Run(want.Kind.String(), func(t *testing.T) {
+			st, _ := status.FromError(ToGRPC(want))
+			if st.Code() != GRPCCode(want.Kind) {
+				t.Errorf("code %v, want %v", st.Code(), GRPCCode(want.Kind))
+			}
+
+			var got *Error
+			if !errors.As(FromGRPC(st.Err()), &got) {
+				t.Fatalf("FromGRPC did not return a domain error")
+			}
+			if got.Kind != want.Kind || got.Message != want.Message || got.RetryAfter != want.RetryAfter || !reflect.DeepEqual(got.Violations, want.Violations) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestToGRPCInternal(t *testing.T) {
+	st, _ := status.FromError(ToGRPC(errors.New("boom")))
+	if st.Code() != codes.Internal {
+		t.Errorf("code %v, want %v", st.Code(), codes.Internal)
+	}
+}
+
+func TestHTTPRoundTrip(t *testing.T) {
+	want := InvalidArgument("invalid request", FieldViolation{Field: "value", Description: "must be between 1 and 5"})
+
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, want)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	var got *Error
+	if !errors.As(FromHTTP(rec.Result()), &got) {
+		t.Fatalf("FromHTTP did not return a domain error")
+	}
+	if got.Kind != want.Kind || got.Message != want.Message || !reflect.DeepEqual(got.Violations, want.Violations) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package errs
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// GRPCCode returns the gRPC status code for an error kind.
+func GRPCCode(k Kind) codes.Code {
+	switch k {
+	case KindNotFound:
+		return codes.NotFound
+	case KindInvalidArgument:
+		return codes.InvalidArgument
+	case KindConflict, KindPreconditionFailed:
+		return codes.Aborted
+	case KindUnavailable:
+		return codes.Unavailable
+	case KindUnauthenticated:
+		return codes.Unauthenticated
+	case KindPermissionDenied:
+		return codes.PermissionDenied
+	}
+	return codes.Internal
+}
+
+// ToGRPC converts err into a gRPC status error. Domain errors
+// carry their field violations, retry hints and failed
+// preconditions as error details.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	st := status.New(GRPCCode(e.Kind), err.Error())
+	var details []protoadapt.MessageV1
+	if len(e.Violations) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, br)
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+	if e.Kind == KindPreconditionFailed {
+		details = append(details, &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{Type: e.Kind.String(), Description: e.Message}},
+		})
+	}
+	if len(details) > 0 {
+		if withDetails, derr := st.WithDetails(details...); derr == nil {
+			st = withDetails
+		}
+	}
+
+	return st.Err()
+}
+
+// FromGRPC converts a gRPC status error returned by a remote
+// service back into a domain error. Errors without a domain
+// mapping are returned unchanged.
+func FromGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	e := &Error{Message: st.Message()}
+	switch st.Code() {
+	case codes.NotFound:
+		e.Kind = KindNotFound
+	case codes.InvalidArgument, codes.OutOfRange:
+		e.Kind = KindInvalidArgument
+	case codes.AlreadyExists, codes.Aborted:
+		e.Kind = KindConflict
+	case codes.FailedPrecondition:
+		e.Kind = KindPreconditionFailed
+	case codes.Unavailable, codes.ResourceExhausted:
+		e.Kind = KindUnavailable
+	case codes.Unauthenticated:
+		e.Kind = KindUnauthenticated
+	case codes.PermissionDenied:
+		e.Kind = KindPermissionDenied
+	default:
+		return err
+	}
+
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				e.Violations = append(e.Violations, FieldViolation{Field: v.Field, Description: v.Description})
+			}
+		case *errdetails.RetryInfo:
+			e.RetryAfter = d.RetryDelay.AsDuration()
+		case *errdetails.PreconditionFailure:
+			e.Kind = KindPreconditionFailed
+		}
+	}
+
+	return e
+}
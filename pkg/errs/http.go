@@ -0,0 +1,115 @@
+package errs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Envelope defines the JSON body of an HTTP error response.
+type Envelope struct {
+	Error EnvelopeError `json:"error"`
+}
+
+// EnvelopeError describes an error in an HTTP error response.
+type EnvelopeError struct {
+	Code            string           `json:"code"`
+	Message         string           `json:"message"`
+	FieldViolations []FieldViolation `json:"fieldViolations,omitempty"`
+}
+
+// HTTPStatus returns the HTTP status code for an error kind.
+func HTTPStatus(k Kind) int {
+	switch k {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindInvalidArgument:
+		return http.StatusBadRequest
+	case KindConflict:
+		return http.StatusConflict
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	case KindUnauthenticated:
+		return http.StatusUnauthorized
+	case KindPermissionDenied:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// WriteHTTP writes err as a JSON error envelope with the
+// matching status code. Errors without a domain kind are
+// logged and reported as internal errors.
+func WriteHTTP(w http.ResponseWriter, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		log.Printf("Internal error: %v\n", err)
+		e = &Error{Message: "internal error"}
+	}
+
+	env := Envelope{Error: EnvelopeError{
+		Code:            e.Kind.String(),
+		Message:         e.Error(),
+		FieldViolations: e.Violations,
+	}}
+	if e.Kind == KindUnknown {
+		env.Error.Message = "internal error"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+	}
+	w.WriteHeader(HTTPStatus(e.Kind))
+	if err := json.NewEncoder(w).Encode(env); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// FromHTTP converts a non-2xx HTTP response returned by a
+// remote service into a domain error. It returns nil for
+// successful responses.
+func FromHTTP(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	e := &Error{Message: http.StatusText(resp.StatusCode)}
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		e.Kind = KindNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		e.Kind = KindInvalidArgument
+	case http.StatusConflict:
+		e.Kind = KindConflict
+	case http.StatusPreconditionFailed:
+		e.Kind = KindPreconditionFailed
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests:
+		e.Kind = KindUnavailable
+	case http.StatusUnauthorized:
+		e.Kind = KindUnauthenticated
+	case http.StatusForbidden:
+		e.Kind = KindPermissionDenied
+	default:
+		return fmt.Errorf("non-2xx response: %v", resp.Status)
+	}
+
+	var env Envelope
+	if body, err := io.ReadAll(resp.Body); err == nil && json.Unmarshal(body, &env) == nil && env.Error.Message != "" {
+		e.Message = env.Error.Message
+		e.Violations = env.Error.FieldViolations
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(secs) * time.Second
+	}
+
+	return e
+}
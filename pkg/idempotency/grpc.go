@@ -0,0 +1,87 @@
+package idempotency
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// UnaryServerInterceptor returns a gRPC interceptor applying
+// idempotency keys to the given full method names. Requests
+// without a key are passed through unchanged.
+func UnaryServerInterceptor(store Store, ttl time.Duration, methods ...string) grpc.UnaryServerInterceptor {
+	protected := map[string]bool{}
+	for _, m := range methods {
+		protected[m] = true
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		msg, ok := req.(proto.Message)
+		if !ok || !protected[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		keys := md.Get(MetadataKey)
+		if len(keys) == 0 || keys[0] == "" {
+			return handler(ctx, req)
+		}
+
+		payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, errs.ToGRPC(err)
+		}
+		key := info.FullMethod + ":" + keys[0]
+		stored, err := claim(ctx, store, key, hash([]byte(info.FullMethod), payload))
+		if err != nil {
+			return nil, errs.ToGRPC(err)
+		}
+		if stored != nil {
+			var a anypb.Any
+			if err := proto.Unmarshal(stored, &a); err != nil {
+				return nil, errs.ToGRPC(err)
+			}
+			resp, err := a.UnmarshalNew()
+			if err != nil {
+				return nil, errs.ToGRPC(err)
+			}
+			return resp, nil
+		}
+
+		resp, err := handler(ctx, req)
+		// The key is settled even if the client went away.
+		ctx = context.WithoutCancel(ctx)
+		if err != nil {
+			if rerr := store.Release(ctx, key); rerr != nil {
+				log.Printf("Failed to release idempotency key: %v\n", rerr)
+			}
+			return nil, err
+		}
+
+		if err := complete(ctx, store, key, resp, ttl); err != nil {
+			log.Printf("Failed to store idempotent response: %v\n", err)
+		}
+		return resp, nil
+	}
+}
+
+func complete(ctx context.Context, store Store, key string, resp any, ttl time.Duration) error {
+	m, ok := resp.(proto.Message)
+	if !ok {
+		return store.Release(ctx, key)
+	}
+	a, err := anypb.New(m)
+	if err != nil {
+		return err
+	}
+	b, err := proto.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return store.Complete(ctx, key, b, ttl)
+}
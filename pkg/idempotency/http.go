@@ -0,0 +1,113 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+)
+
+// ReplayedHeader marks HTTP responses replayed from the store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// storedResponse defines a recorded HTTP response.
+type storedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// conditionalHeaders are the request headers that change the
+// outcome of a request, hashed along with its payload.
+var conditionalHeaders = []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"}
+
+// Middleware returns an HTTP middleware applying idempotency
+// keys sent in the Idempotency-Key header. Only successful
+// responses are stored, for ttl; failed requests may be
+// retried with the same key. Requests are told apart by their
+// method, URI, conditional headers and body.
+func Middleware(store Store, ttl time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		k := req.Header.Get(HeaderName)
+		if k == "" || req.Method == http.MethodGet || req.Method == http.MethodHead {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			errs.WriteHTTP(w, errs.InvalidArgument("failed to read request body"))
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := req.Context()
+		key := req.Method + " " + req.URL.Path + ":" + k
+		parts := [][]byte{[]byte(req.Method), []byte(req.URL.RequestURI())}
+		for _, name := range conditionalHeaders {
+			parts = append(parts, []byte(strings.Join(req.Header.Values(name), ", ")))
+		}
+		stored, err := claim(ctx, store, key, hash(append(parts, body)...))
+		if err != nil {
+			errs.WriteHTTP(w, err)
+			return
+		}
+		if stored != nil {
+			var resp storedResponse
+			if err := json.Unmarshal(stored, &resp); err != nil {
+				errs.WriteHTTP(w, err)
+				return
+			}
+			for name, values := range resp.Header {
+				w.Header()[name] = values
+			}
+			w.Header().Set(ReplayedHeader, "true")
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		// The key is settled even if the client went away.
+		ctx = context.WithoutCancel(ctx)
+		if rec.status/100 != 2 {
+			if err := store.Release(ctx, key); err != nil {
+				log.Printf("Failed to release idempotency key: %v\n", err)
+			}
+			return
+		}
+		b, err := json.Marshal(storedResponse{Status: rec.status, Header: w.Header().Clone(), Body: rec.body.Bytes()})
+		if err == nil {
+			err = store.Complete(ctx, key, b, ttl)
+		}
+		if err != nil {
+			log.Printf("Failed to store idempotent response: %v\n", err)
+		}
+	})
+}
+
+// recorder captures the status and body written to an HTTP
+// response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
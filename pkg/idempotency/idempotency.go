@@ -0,0 +1,101 @@
+// Package idempotency makes write requests safe to retry by
+// replaying the stored response of requests carrying an
+// already used idempotency key.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+)
+
+// HeaderName is the HTTP header carrying the idempotency key.
+const HeaderName = "Idempotency-Key"
+
+// MetadataKey is the gRPC metadata key carrying the
+// idempotency key.
+const MetadataKey = "idempotency-key"
+
+// DefaultTTL defines how long responses are kept by default.
+const DefaultTTL = 24 * time.Hour
+
+// Lease defines how long a request holds its key before a
+// retry may take it over, so that a key is not stuck in
+// progress when its request dies without releasing it.
+// Requests running longer than their lease may run twice.
+const Lease = time.Minute
+
+// MaxKeyLen is the maximum length in bytes of an idempotency
+// key, including the method it is scoped to.
+const MaxKeyLen = 512
+
+// Record defines a request claimed by an idempotency key.
+type Record struct {
+	RequestHash string
+	Response    []byte
+	Completed   bool
+}
+
+// Store defines a storage of idempotency records.
+type Store interface {
+	// Reserve claims key for a request with the given hash
+	// for lease. When key is already claimed, the existing
+	// record is returned instead. Claims that expired are
+	// taken over.
+	Reserve(ctx context.Context, key string, requestHash string, lease time.Duration) (*Record, error)
+	// Complete stores the response of the request holding key
+	// for ttl.
+	Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error
+	// Release frees key after a failed request so that it can
+	// be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// ErrKeyReused is returned when an idempotency key is reused
+// with a different request payload.
+var ErrKeyReused = errs.Conflict("idempotency key reused with a different request")
+
+// ErrInProgress is returned when a request with the same
+// idempotency key is still being processed.
+var ErrInProgress = errs.Conflict("request with this idempotency key is in progress")
+
+// ErrKeyTooLong is returned when an idempotency key is longer
+// than MaxKeyLen.
+var ErrKeyTooLong = errs.InvalidArgument("idempotency key too long")
+
+// hash returns a hex encoded digest of the request parts.
+func hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// claim reserves key for a lease and checks an existing
+// record against the request hash. It returns the stored
+// response of a completed duplicate, or nil when the caller
+// holds the key.
+func claim(ctx context.Context, store Store, key string, requestHash string) ([]byte, error) {
+	if len(key) > MaxKeyLen {
+		return nil, ErrKeyTooLong
+	}
+	existing, err := store.Reserve(ctx, key, requestHash, Lease)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+	if existing.RequestHash != requestHash {
+		return nil, ErrKeyReused
+	}
+	if !existing.Completed {
+		return nil, ErrInProgress
+	}
+	return existing.Response, nil
+}
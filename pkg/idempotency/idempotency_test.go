@@ -0,0 +1,167 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/idempotency"
+	"github.com/phongld0308/movie-example/pkg/idempotency/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/MetadataService/PutMetadata"
+	interceptor := idempotency.UnaryServerInterceptor(memory.New(), time.Hour, method)
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	calls := 0
+	fail := false
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		if fail {
+			return nil, status.Error(codes.Unavailable, "try again")
+		}
+		return &gen.PutMetadataResponse{Version: int64(calls)}, nil
+	}
+	call := func(key string, title string) (any, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.MetadataKey, key))
+		return interceptor(ctx, &gen.PutMetadataRequest{Metadata: &gen.Metadata{Id: "1", Title: title}}, info, handler)
+	}
+
+	first, err := call("k1", "Heat")
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	replayed, err := call("k1", "Heat")
+	if err != nil {
+		t.Fatalf("duplicate call: %v", err)
+	}
+	if calls != 1 || !proto.Equal(first.(proto.Message), replayed.(proto.Message)) {
+		t.Errorf("duplicate was not replayed: %d handler calls, responses %v and %v", calls, first, replayed)
+	}
+
+	if _, err := call("k1", "Ronin"); status.Code(err) != codes.Aborted {
+		t.Errorf("conflicting payload: got %v, want code %v", err, codes.Aborted)
+	}
+
+	fail = true
+	if _, err := call("k2", "Heat"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("failing call: got %v", err)
+	}
+	fail = false
+	if _, err := call("k2", "Heat"); err != nil {
+		t.Errorf("retry after failure: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("got %d handler calls, want 3", calls)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	h := idempotency.Middleware(memory.New(), time.Hour, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.Header().Set("ETag", `"1"`)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+
+	do := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/metadata", strings.NewReader(body))
+		req.Header.Set(idempotency.HeaderName, "k1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	do(`{"id":"1"}`)
+	rec := do(`{"id":"1"}`)
+	if calls != 1 {
+		t.Errorf("got %d handler calls, want 1", calls)
+	}
+	if rec.Code != http.StatusCreated || rec.Body.String() != "created" || rec.Header().Get("ETag") != `"1"` || rec.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Errorf("unexpected replayed response: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	if rec := do(`{"id":"2"}`); rec.Code != http.StatusConflict {
+		t.Errorf("conflicting payload: got status %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+// ctxStore fails the calls made with a done context, as a
+// database store would.
+type ctxStore struct {
+	idempotency.Store
+}
+
+func (s ctxStore) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Store.Complete(ctx, key, response, ttl)
+}
+
+func (s ctxStore) Release(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Store.Release(ctx, key)
+}
+
+func TestClientGone(t *testing.T) {
+	const method = "/MetadataService/PutMetadata"
+	interceptor := idempotency.UnaryServerInterceptor(ctxStore{memory.New()}, time.Hour, method)
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	req := &gen.PutMetadataRequest{Metadata: &gen.Metadata{Id: "1", Title: "Heat"}}
+
+	// The client goes away while its request is handled.
+	calls := 0
+	ctx, cancel := context.WithCancel(metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.MetadataKey, "k1")))
+	handler := func(context.Context, any) (any, error) {
+		calls++
+		cancel()
+		return &gen.PutMetadataResponse{Version: 1}, nil
+	}
+	if _, err := interceptor(ctx, req, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.MetadataKey, "k1"))
+	if _, err := interceptor(ctx, req, info, handler); err != nil || calls != 1 {
+		t.Errorf("retry: got %v after %d handler calls, want the stored response", err, calls)
+	}
+
+	long := metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.MetadataKey, strings.Repeat("k", idempotency.MaxKeyLen)))
+	if _, err := interceptor(long, req, info, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("long key: got %v, want code %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestConditionalHeaders(t *testing.T) {
+	calls := 0
+	h := idempotency.Middleware(memory.New(), time.Hour, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(ifMatch string) int {
+		req := httptest.NewRequest(http.MethodPut, "/metadata", strings.NewReader(`{"id":"1"}`))
+		req.Header.Set(idempotency.HeaderName, "k1")
+		req.Header.Set("If-Match", ifMatch)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	do(`"1"`)
+	if code := do(`"2"`); code != http.StatusConflict || calls != 1 {
+		t.Errorf("other precondition: got status %d after %d handler calls, want %d", code, calls, http.StatusConflict)
+	}
+}
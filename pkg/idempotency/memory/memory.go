@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/idempotency"
+)
+
+// Store defines an in-memory idempotency record store.
+type Store struct {
+	sync.Mutex
+	records map[string]*entry
+	expiry  expiryHeap
+	now     func() time.Time
+}
+
+type entry struct {
+	key       string
+	record    idempotency.Record
+	expiresAt time.Time
+}
+
+// New creates a new in-memory idempotency record store.
+func New() *Store {
+	return &Store{records: map[string]*entry{}, now: time.Now}
+}
+
+// Reserve claims key for a request with the given hash for
+// lease. When key is already claimed, the existing record is
+// returned instead. Claims that expired are taken over.
+func (s *Store) Reserve(_ context.Context, key string, requestHash string, lease time.Duration) (*idempotency.Record, error) {
+	s.Lock()
+	defer s.Unlock()
+	now := s.now()
+	if e, ok := s.records[key]; ok && now.Before(e.expiresAt) {
+		r := e.record
+		return &r, nil
+	}
+
+	s.evictExpired(now)
+	s.put(&entry{key: key, record: idempotency.Record{RequestHash: requestHash}, expiresAt: now.Add(lease)})
+	return nil, nil
+}
+
+// Complete stores the response of the request holding key
+// for ttl.
+func (s *Store) Complete(_ context.Context, key string, response []byte, ttl time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.records[key]; ok {
+		record := e.record
+		record.Response = response
+		record.Completed = true
+		s.put(&entry{key: key, record: record, expiresAt: s.now().Add(ttl)})
+	}
+	return nil
+}
+
+// put adds or replaces the entry of a key. A replaced entry
+// is left in the expiry heap until it expires.
+func (s *Store) put(e *entry) {
+	s.records[e.key] = e
+	heap.Push(&s.expiry, e)
+}
+
+// Release frees key after a failed request so that it can be
+// retried. Its entry is left in the expiry heap until it
+// expires.
+func (s *Store) Release(_ context.Context, key string) error {
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.records[key]; ok && !e.record.Completed {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// evictExpired pops the expired entries off the expiry heap,
+// so that each entry is visited once rather than on every
+// call. Entries already released or replaced are skipped.
+func (s *Store) evictExpired(now time.Time) {
+	for len(s.expiry) > 0 && !now.Before(s.expiry[0].expiresAt) {
+		e := heap.Pop(&s.expiry).(*entry)
+		if s.records[e.key] == e {
+			delete(s.records, e.key)
+		}
+	}
+}
+
+// expiryHeap orders entries by expiry, earliest first.
+type expiryHeap []*entry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x any)        { *h = append(*h, x.(*entry)) }
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
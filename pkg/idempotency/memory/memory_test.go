@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEvictExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	s := New()
+	s.now = func() time.Time { return now }
+
+	mustReserve := func(key string, ttl time.Duration) {
+		t.Helper()
+		if r, err := s.Reserve(ctx, key, "hash", ttl); err != nil || r != nil {
+			t.Fatalf("Reserve(%s) = %v, %v, want key claimed", key, r, err)
+		}
+	}
+	mustReserve("a", time.Minute)
+	mustReserve("b", time.Hour)
+	mustReserve("c", time.Minute)
+	if err := s.Release(ctx, "c"); err != nil {
+		t.Fatal(err)
+	}
+	mustReserve("c", 2*time.Hour)
+
+	now = now.Add(30 * time.Minute)
+	mustReserve("d", time.Hour)
+	if _, ok := s.records["a"]; ok {
+		t.Error("expired key a kept")
+	}
+	for _, k := range []string{"b", "c", "d"} {
+		if _, ok := s.records[k]; !ok {
+			t.Errorf("live key %s evicted", k)
+		}
+	}
+	if got := len(s.expiry); got != 3 {
+		t.Errorf("expiry heap holds %d entries, want 3", got)
+	}
+}
+
+func TestLease(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	s := New()
+	s.now = func() time.Time { return now }
+
+	if r, err := s.Reserve(ctx, "k", "hash", time.Minute); err != nil || r != nil {
+		t.Fatalf("Reserve = %v, %v, want key claimed", r, err)
+	}
+	if r, _ := s.Reserve(ctx, "k", "hash", time.Minute); r == nil || r.Completed {
+		t.Fatalf("Reserve within the lease = %v, want the claim in progress", r)
+	}
+
+	// A request that died without releasing its key loses it
+	// when its lease expires.
+	now = now.Add(time.Minute)
+	if r, err := s.Reserve(ctx, "k", "hash", time.Minute); err != nil || r != nil {
+		t.Fatalf("Reserve after the lease = %v, %v, want key claimed", r, err)
+	}
+	// Completed responses are kept for their ttl rather than
+	// the lease.
+	if err := s.Complete(ctx, "k", []byte("response"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Minute)
+	if r, _ := s.Reserve(ctx, "k", "hash", time.Minute); r == nil || string(r.Response) != "response" {
+		t.Errorf("Reserve of a completed key = %v, want the stored response", r)
+	}
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/phongld0308/movie-example/pkg/idempotency"
+)
+
+// Store defines a PostgreSQL-based idempotency record store.
+type Store struct {
+	db *sql.DB
+}
+
+// New creates a new PostgreSQL-based idempotency record store.
+func New(host string, port int, user, password, dbname string) (*Store, error) {
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		host, port, user, password, dbname,
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	return &Store{db}, nil
+}
+
+// Reserve claims key for a request with the given hash for
+// lease. When key is already claimed, the existing record is
+// returned instead. Claims that expired are taken over.
+func (s *Store) Reserve(ctx context.Context, key string, requestHash string, lease time.Duration) (*idempotency.Record, error) {
+	// Expired records are taken over as if they did not exist.
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO idempotency_keys (key, request_hash, expires_at)
+		 VALUES ($1, $2, NOW() + make_interval(secs => $3))
+		 ON CONFLICT (key) DO UPDATE
+		 SET request_hash = EXCLUDED.request_hash, response = NULL, completed = FALSE,
+		     expires_at = EXCLUDED.expires_at
+		 WHERE idempotency_keys.expires_at <= NOW()`,
+		key, requestHash, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %v", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	} else if n > 0 {
+		return nil, nil
+	}
+
+	var r idempotency.Record
+	row := s.db.QueryRowContext(ctx,
+		"SELECT request_hash, response, completed FROM idempotency_keys WHERE key = $1",
+		key,
+	)
+	if err := row.Scan(&r.RequestHash, &r.Response, &r.Completed); err != nil {
+		return nil, fmt.Errorf("failed to scan idempotency record: %v", err)
+	}
+	return &r, nil
+}
+
+// Complete stores the response of the request holding key
+// for ttl.
+func (s *Store) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE idempotency_keys
+		 SET response = $2, completed = TRUE, expires_at = NOW() + make_interval(secs => $3)
+		 WHERE key = $1`,
+		key, response, ttl.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %v", err)
+	}
+	return nil
+}
+
+// Release frees key after a failed request so that it can be
+// retried.
+func (s *Store) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE key = $1 AND NOT completed",
+		key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %v", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired records.
+func (s *Store) DeleteExpired(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= NOW()"); err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %v", err)
+	}
+	return nil
+}
+
+// Close closes the database connection.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
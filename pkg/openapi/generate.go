@@ -0,0 +1,271 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/recordtype"
+	"github.com/phongld0308/movie-example/pkg/validation"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Parameter locations.
+const (
+	InQuery  = "query"
+	InPath   = "path"
+	InHeader = "header"
+)
+
+// Route describes an HTTP endpoint to document.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	// Request is the proto request message the endpoint
+	// decodes. Its validation rules document Params.
+	Request proto.Message
+	Params  []Param
+	// Response is a value of the Go type encoded as the JSON
+	// body of successful responses.
+	Response any
+	// Errors lists the error kinds the endpoint may return.
+	Errors []errs.Kind
+}
+
+// Param maps an HTTP parameter to a field of the request
+// message.
+type Param struct {
+	Name        string
+	In          string
+	Field       string
+	Description string
+}
+
+// Generate builds the OpenAPI document of the given routes.
+// It panics when a route refers to an unknown request field,
+// so that broken route tables are caught by tests.
+func Generate(info Info, routes []Route) *Document {
+	g := &generator{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      map[string]PathItem{},
+			Components: Components{Schemas: map[string]*Schema{}},
+		},
+		types: map[string]reflect.Type{},
+	}
+	for _, r := range routes {
+		item, ok := g.doc.Paths[r.Path]
+		if !ok {
+			item = PathItem{}
+			g.doc.Paths[r.Path] = item
+		}
+		item[strings.ToLower(r.Method)] = g.operation(r)
+	}
+	return g.doc
+}
+
+type generator struct {
+	doc   *Document
+	types map[string]reflect.Type
+}
+
+func (g *generator) operation(r Route) *Operation {
+	op := &Operation{
+		OperationID: r.OperationID,
+		Summary:     r.Summary,
+		Description: r.Description,
+		Tags:        r.Tags,
+		Responses: map[string]Response{
+			"200": {
+				Description: "Successful response.",
+				Content:     map[string]MediaType{"application/json": {Schema: g.typeSchema(reflect.TypeOf(r.Response))}},
+			},
+		},
+	}
+
+	for _, p := range r.Params {
+		fd := r.Request.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(p.Field))
+		if fd == nil {
+			panic(fmt.Sprintf("openapi: %s %s: unknown request field %q", r.Method, r.Path, p.Field))
+		}
+		s, required := fieldSchema(fd)
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    required || p.In == InPath,
+			Schema:      s,
+		})
+	}
+
+	envelope := g.typeSchema(reflect.TypeOf(errs.Envelope{}))
+	kinds := append(append([]errs.Kind{}, r.Errors...), errs.KindUnknown)
+	for _, k := range kinds {
+		status := errs.HTTPStatus(k)
+		resp := Response{
+			Description: http.StatusText(status) + ".",
+			Content:     map[string]MediaType{"application/json": {Schema: envelope}},
+		}
+		if k == errs.KindUnavailable {
+			resp.Headers = map[string]Header{
+				"Retry-After": {Description: "Seconds to wait before retrying.", Schema: &Schema{Type: "integer"}},
+			}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	return op
+}
+
+// fieldSchema returns the schema of a scalar proto field
+// together with whether its rules make it required.
+func fieldSchema(fd protoreflect.FieldDescriptor) (*Schema, bool) {
+	var s *Schema
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		s = &Schema{Type: "string"}
+	case protoreflect.BoolKind:
+		s = &Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		s = &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		s = &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.FloatKind:
+		s = &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		s = &Schema{Type: "number", Format: "double"}
+	case protoreflect.EnumKind:
+		s = &Schema{Type: "string"}
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			s.Enum = append(s.Enum, string(values.Get(i).Name()))
+		}
+	default:
+		panic(fmt.Sprintf("openapi: field %s of kind %v cannot be a parameter", fd.FullName(), fd.Kind()))
+	}
+	if fd.IsList() {
+		s = &Schema{Type: "array", Items: s}
+	}
+
+	rules := validation.Rules(fd)
+	if rules == nil {
+		return s, false
+	}
+	item := s
+	if s.Items != nil {
+		item = s.Items
+	}
+	if rules.MinLen > 0 {
+		item.MinLength = proto.Uint64(uint64(rules.MinLen))
+	}
+	if rules.MaxLen > 0 {
+		item.MaxLength = proto.Uint64(uint64(rules.MaxLen))
+	}
+	item.Minimum = rules.Gte
+	item.Maximum = rules.Lte
+	if len(rules.In) > 0 {
+		item.Enum = rules.In
+	}
+	if rules.RecordType {
+		item.Enum = recordtype.All
+	}
+	return s, rules.GetRequired()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeSchema returns the schema of the JSON encoding of t.
+// Named struct types are added to the document components
+// and referenced.
+func (g *generator) typeSchema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		s := g.typeSchema(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.Interface:
+		return &Schema{}
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %v", t))
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	if name != "" {
+		ref := &Schema{Ref: "#/components/schemas/" + name}
+		if prev, ok := g.types[name]; ok {
+			if prev != t {
+				panic(fmt.Sprintf("openapi: schema name %s used by both %v and %v", name, prev, t))
+			}
+			return ref
+		}
+		g.types[name] = t
+		// Register a placeholder first so recursive types terminate.
+		g.doc.Components.Schemas[name] = &Schema{}
+		*g.doc.Components.Schemas[name] = *g.objectSchema(t)
+		return ref
+	}
+	return g.objectSchema(t)
+}
+
+func (g *generator) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.typeSchema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
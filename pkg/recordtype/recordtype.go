@@ -0,0 +1,35 @@
+// Package recordtype defines the catalog record types. The
+// metadata and rating models and the record_type validation
+// rule of the API all derive their record types from it.
+package recordtype
+
+import "slices"
+
+// Existing record types. Seasons belong to a series and
+// episodes to a season.
+const (
+	Movie   = "movie"
+	Series  = "series"
+	Season  = "season"
+	Episode = "episode"
+)
+
+// All lists the existing record types.
+var All = []string{Movie, Series, Season, Episode}
+
+// Valid reports whether t is an existing record type.
+func Valid(t string) bool {
+	return slices.Contains(All, t)
+}
+
+// Parent returns the record type of the parent of t, or an
+// empty string if t has no parent.
+func Parent(t string) string {
+	switch t {
+	case Season:
+		return Series
+	case Episode:
+		return Season
+	}
+	return ""
+}
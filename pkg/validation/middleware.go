@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor returns a gRPC interceptor rejecting
+// requests that violate their declared validation rules.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if m, ok := req.(proto.Message); ok {
+			if err := Validate(m); err != nil {
+				return nil, errs.ToGRPC(err)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// DecodeFunc decodes an HTTP request into the proto request
+// message carrying its validation rules.
+type DecodeFunc func(req *http.Request) (proto.Message, error)
+
+type messageKey struct{}
+
+// Middleware decodes and validates HTTP requests before
+// passing them to next. Invalid requests are rejected with a
+// JSON error envelope, naming the violated fields as in
+// names, as for Rename; valid ones are available to next via
+// Request.
+func Middleware(decode DecodeFunc, names map[string]string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		m, err := decodeAndValidate(req, decode)
+		if err != nil {
+			errs.WriteHTTP(w, Rename(err, names))
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), messageKey{}, m)))
+	})
+}
+
+// Request returns the validated message of req as decoded by
+// Middleware. Without the middleware, req is decoded and
+// validated on the spot.
+func Request(req *http.Request, decode DecodeFunc) (proto.Message, error) {
+	if m, ok := req.Context().Value(messageKey{}).(proto.Message); ok {
+		return m, nil
+	}
+	return decodeAndValidate(req, decode)
+}
+
+func decodeAndValidate(req *http.Request, decode DecodeFunc) (proto.Message, error) {
+	m, err := decode(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
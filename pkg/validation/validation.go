@@ -0,0 +1,213 @@
+// Package validation enforces the request validation rules
+// declared on proto fields with the (validate.rules) option.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/recordtype"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Validate checks msg against the rules declared on its
+// fields, including nested messages. It returns an invalid
+// argument error listing every field violation.
+func Validate(msg proto.Message) error {
+	var violations []errs.FieldViolation
+	validateMessage(msg.ProtoReflect(), "", nil, &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+	return errs.InvalidArgument("invalid request", violations...)
+}
+
+// fieldSet lists the fields of a partially updated message
+// whose required rules are enforced, by their paths relative
+// to it. A nil set enforces every required rule.
+type fieldSet map[string]bool
+
+// child returns the set of the fields of a nested message,
+// name, of a message validated with s. Nested messages
+// declared partial are validated against the update mask of
+// m.
+func (s fieldSet) child(m protoreflect.Message, name string, rules *gen.FieldRules) fieldSet {
+	switch {
+	case s != nil:
+		sub := fieldSet{}
+		for p := range s {
+			if rest, ok := strings.CutPrefix(p, name+"."); ok {
+				sub[rest] = true
+			}
+		}
+		return sub
+	case rules.GetPartial():
+		return updateMask(m)
+	}
+	return nil
+}
+
+// updateMask returns the paths of the FieldMask fields of m.
+func updateMask(m protoreflect.Message) fieldSet {
+	res := fieldSet{}
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.Message().FullName() != "google.protobuf.FieldMask" || !m.Has(fd) {
+			continue
+		}
+		mask := m.Get(fd).Message()
+		paths := mask.Get(mask.Descriptor().Fields().ByName("paths")).List()
+		for j := 0; j < paths.Len(); j++ {
+			res[paths.Get(j).String()] = true
+		}
+	}
+	return res
+}
+
+func validateMessage(m protoreflect.Message, prefix string, partial fieldSet, out *[]errs.FieldViolation) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := string(fd.Name())
+		path := prefix + name
+		rules := Rules(fd)
+		required := rules.GetRequired() && (partial == nil || partial[name])
+
+		switch {
+		case fd.IsMap():
+			if required && m.Get(fd).Map().Len() == 0 {
+				addViolation(out, path, "must not be empty")
+			}
+		case fd.IsList():
+			list := m.Get(fd).List()
+			if required && list.Len() == 0 {
+				addViolation(out, path, "must not be empty")
+			}
+			if max := rules.GetMaxItems(); max > 0 && list.Len() > int(max) {
+				addViolation(out, path, fmt.Sprintf("must contain at most %d items", max))
+			}
+			for j := 0; j < list.Len(); j++ {
+				elemPath := fmt.Sprintf("%s[%d]", path, j)
+				if fd.Message() != nil {
+					validateMessage(list.Get(j).Message(), elemPath+".", partial.child(m, name, rules), out)
+				} else {
+					validateScalar(fd, rules, elemPath, list.Get(j), false, out)
+				}
+			}
+		case fd.Message() != nil:
+			if !m.Has(fd) {
+				if required {
+					addViolation(out, path, "must be set")
+				}
+				continue
+			}
+			validateMessage(m.Get(fd).Message(), path+".", partial.child(m, name, rules), out)
+		default:
+			validateScalar(fd, rules, path, m.Get(fd), required, out)
+		}
+	}
+}
+
+func validateScalar(fd protoreflect.FieldDescriptor, rules *gen.FieldRules, path string, v protoreflect.Value, required bool, out *[]errs.FieldViolation) {
+	if rules == nil {
+		return
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		s := v.String()
+		if s == "" {
+			// Optional empty strings skip the remaining rules.
+			if required {
+				addViolation(out, path, "must not be empty")
+			}
+			return
+		}
+		n := utf8.RuneCountInString(s)
+		if rules.MinLen > 0 && n < int(rules.MinLen) {
+			addViolation(out, path, fmt.Sprintf("must be at least %d characters long", rules.MinLen))
+		}
+		if rules.MaxLen > 0 && n > int(rules.MaxLen) {
+			addViolation(out, path, fmt.Sprintf("must be at most %d characters long", rules.MaxLen))
+		}
+		if len(rules.In) > 0 && !contains(rules.In, s) {
+			addViolation(out, path, "must be one of: "+strings.Join(rules.In, ", "))
+		}
+		if rules.RecordType && !recordtype.Valid(s) {
+			addViolation(out, path, "must be one of: "+strings.Join(recordtype.All, ", "))
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		checkRange(rules, path, float64(v.Int()), out)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		checkRange(rules, path, float64(v.Uint()), out)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		checkRange(rules, path, v.Float(), out)
+	}
+}
+
+func checkRange(rules *gen.FieldRules, path string, x float64, out *[]errs.FieldViolation) {
+	switch {
+	case rules.Gte != nil && rules.Lte != nil && (x < *rules.Gte || x > *rules.Lte):
+		addViolation(out, path, fmt.Sprintf("must be between %v and %v", *rules.Gte, *rules.Lte))
+	case rules.Gte != nil && x < *rules.Gte:
+		addViolation(out, path, fmt.Sprintf("must be greater than or equal to %v", *rules.Gte))
+	case rules.Lte != nil && x > *rules.Lte:
+		addViolation(out, path, fmt.Sprintf("must be less than or equal to %v", *rules.Lte))
+	}
+}
+
+// Rename returns err with the violations of the fields in
+// names reported under their names instead, such as the HTTP
+// parameters the fields are decoded from. Elements of a
+// renamed list field are renamed along. Other errors are
+// returned as is.
+func Rename(err error, names map[string]string) error {
+	var e *errs.Error
+	if !errors.As(err, &e) || len(e.Violations) == 0 {
+		return err
+	}
+	renamed := make([]errs.FieldViolation, len(e.Violations))
+	for i, v := range e.Violations {
+		if name, ok := names[v.Field]; ok {
+			v.Field = name
+		} else if j := strings.IndexByte(v.Field, '['); j > 0 {
+			if name, ok := names[v.Field[:j]]; ok {
+				v.Field = name + v.Field[j:]
+			}
+		}
+		renamed[i] = v
+	}
+	c := *e
+	c.Violations = renamed
+	return &c
+}
+
+// Rules returns the rules declared on a field, or nil.
+func Rules(fd protoreflect.FieldDescriptor) *gen.FieldRules {
+	opts := fd.Options()
+	if opts == nil || !proto.HasExtension(opts, gen.E_Rules) {
+		return nil
+	}
+	rules, _ := proto.GetExtension(opts, gen.E_Rules).(*gen.FieldRules)
+	return rules
+}
+
+func addViolation(out *[]errs.FieldViolation, field, description string) {
+	*out = append(*out, errs.FieldViolation{Field: field, Description: description})
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want []string
+	}{
+		{
+			name: "valid rating",
+			msg:  &gen.PutRatingRequest{UserId: "105", RecordId: "1", RecordType: "movie", RatingValue: 5},
+		},
+		{
+			name: "invalid rating",
+			msg:  &gen.PutRatingRequest{RecordId: "1", RatingValue: 7},
+			want: []string{"user_id", "record_type", "rating_value"},
+		},
+		{
+			name: "unknown record type",
+			msg:  &gen.PutRatingRequest{UserId: "105", RecordId: "1", RecordType: "book", RatingValue: 3},
+			want: []string{"record_type"},
+		},
+		{
+			name: "missing metadata",
+			msg:  &gen.PutMetadataRequest{},
+			want: []string{"metadata"},
+		},
+		{
+			name: "empty title and long description",
+			msg:  &gen.PutMetadataRequest{Metadata: &gen.Metadata{Id: "1", Description: strings.Repeat("a", 5001)}},
+			want: []string{"metadata.title", "metadata.description"},
+		},
+		{
+			name: "partial update skips required fields",
+			msg:  &gen.UpdateMetadataRequest{Metadata: &gen.Metadata{Id: "1", Director: "Michael Mann"}},
+		},
+		{
+			name: "partial update requires masked fields",
+			msg:  &gen.UpdateMetadataRequest{Metadata: &gen.Metadata{Id: "1"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title", "director"}}},
+			want: []string{"metadata.title"},
+		},
+		{
+			name: "too many batch items",
+			msg:  &gen.BatchGetMetadataRequest{MovieIds: make([]string, 101)},
+			want: []string{"movie_ids"},
+		},
+		{
+			name: "partial update keeps length limits",
+			msg:  &gen.UpdateMetadataRequest{Metadata: &gen.Metadata{Id: "1", Title: strings.Repeat("a", 256)}},
+			want: []string{"metadata.title"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.msg)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var e *errs.Error
+			if !errors.As(err, &e) || e.Kind != errs.KindInvalidArgument {
+				t.Fatalf("got %v, want invalid argument error", err)
+			}
+			var fields []string
+			for _, v := range e.Violations {
+				fields = append(fields, v.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("violated fields %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	err := Validate(&gen.PutMetadataRequest{Metadata: &gen.Metadata{Id: "1", Genres: []string{strings.Repeat("a", 65)}}})
+	err = Rename(err, map[string]string{"metadata.title": "title", "metadata.genres": "genres"})
+
+	var e *errs.Error
+	if !errors.As(err, &e) || e.Kind != errs.KindInvalidArgument {
+		t.Fatalf("got %v, want invalid argument error", err)
+	}
+	var fields []string
+	for _, v := range e.Violations {
+		fields = append(fields, v.Field)
+	}
+	if want := []string{"title", "genres[0]"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("violated fields %v, want %v", fields, want)
+	}
+
+	if other := errs.NotFound("missing"); Rename(other, nil) != other {
+		t.Error("error without violations was changed")
+	}
+}
@@ -6,15 +6,24 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/auth"
 	"github.com/phongld0308/movie-example/pkg/discovery"
 	"github.com/phongld0308/movie-example/pkg/discovery/consul"
+	"github.com/phongld0308/movie-example/pkg/idempotency"
+	idempotencystore "github.com/phongld0308/movie-example/pkg/idempotency/postgres"
+	"github.com/phongld0308/movie-example/pkg/validation"
+	"github.com/phongld0308/movie-example/rating/internal/abuse"
 	"github.com/phongld0308/movie-example/rating/internal/controller/rating"
+	metadatagateway "github.com/phongld0308/movie-example/rating/internal/gateway/metadata/grpc"
 	grpchandler "github.com/phongld0308/movie-example/rating/internal/handler/grpc"
+	httphandler "github.com/phongld0308/movie-example/rating/internal/handler/http"
+	"github.com/phongld0308/movie-example/rating/internal/ingester/kafka"
 	"github.com/phongld0308/movie-example/rating/internal/repository/postgres"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -23,10 +32,13 @@ import (
 const serviceName = "rating"
 
 func main() {
-	var port int
+	var port, httpPort, adminPort, adminHTTPPort int
 	flag.IntVar(&port, "port", 8082, "API handler port")
+	flag.IntVar(&httpPort, "http-port", 8092, "HTTP API handler port")
+	flag.IntVar(&adminPort, "admin-port", 8182, "moderation admin API handler port")
+	flag.IntVar(&adminHTTPPort, "admin-http-port", 8192, "moderation admin HTTP API handler port")
 	flag.Parse()
-	log.Printf("Starting the rating service on port %d", port)
+	log.Printf("Starting the rating service on port %d, HTTP on port %d", port, httpPort)
 
 	// Get configuration from environment
 	consulAddr := getEnvOrDefault("CONSUL_ADDR", "consul:8500")
@@ -78,14 +90,128 @@ func main() {
 	}
 	defer repo.Close()
 
-	ctrl := rating.New(repo)
+	// Initialize idempotency key store
+	idempotencyTTL, err := time.ParseDuration(getEnvOrDefault("IDEMPOTENCY_TTL", idempotency.DefaultTTL.String()))
+	if err != nil {
+		panic(fmt.Sprintf("invalid idempotency TTL: %v", err))
+	}
+	idempotencyStore, err := idempotencystore.New(
+		dbHost,
+		dbPortInt,
+		dbUser,
+		dbPassword,
+		dbName,
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer idempotencyStore.Close()
+
+	go func() {
+		for {
+			time.Sleep(1 * time.Hour)
+			if err := idempotencyStore.DeleteExpired(ctx); err != nil {
+				log.Println("Failed to delete expired idempotency keys: " + err.Error())
+			}
+		}
+	}()
+
+	// The PostgreSQL repository reads the parents of records
+	// from the catalog, so the metadata gateway is only used by
+	// repositories that record them.
+	ctrl, err := rating.NewWithMetadataGateway(repo, aggregationConfig(), metadatagateway.New(registry))
+	if err != nil {
+		panic(err)
+	}
+
+	// Compute the trending scores and refresh them so that
+	// they follow new ratings.
+	trendingRefresh, err := time.ParseDuration(getEnvOrDefault("TRENDING_REFRESH_INTERVAL", "5m"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid trending refresh interval: %v", err))
+	}
+	if err := ctrl.RefreshTrending(ctx); err != nil {
+		panic(err)
+	}
+	go func() {
+		for {
+			time.Sleep(trendingRefresh)
+			if err := ctrl.RefreshTrending(ctx); err != nil {
+				log.Println("Failed to refresh trending scores: " + err.Error())
+			}
+		}
+	}()
+
+	// Score the records ranked by an aggregator other than the
+	// mean and refresh them so that top-rated lists follow new
+	// ratings.
+	topRatedRefresh, err := time.ParseDuration(getEnvOrDefault("TOP_RATED_REFRESH_INTERVAL", "5m"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid top-rated refresh interval: %v", err))
+	}
+	if err := ctrl.RefreshTopRated(ctx); err != nil {
+		panic(err)
+	}
+	go func() {
+		for {
+			time.Sleep(topRatedRefresh)
+			if err := ctrl.RefreshTopRated(ctx); err != nil {
+				log.Println("Failed to refresh top-rated scores: " + err.Error())
+			}
+		}
+	}()
+
+	// Ingest the rating events published to Kafka, if
+	// configured. They are screened as the ratings put through
+	// the API are.
+	if kafkaAddr := os.Getenv("KAFKA_ADDR"); kafkaAddr != "" {
+		ingester, err := kafka.NewIngester(kafkaAddr, serviceName, getEnvOrDefault("RATING_EVENTS_TOPIC", "ratings"))
+		if err != nil {
+			panic(err)
+		}
+		events, err := ingester.Ingest(ctx)
+		if err != nil {
+			panic(err)
+		}
+		go func() {
+			for event := range events {
+				if err := ctrl.ApplyRatingEvent(ctx, &event); err != nil {
+					log.Println("Failed to ingest rating event: " + err.Error())
+				}
+			}
+		}()
+	}
+
+	// Serve the HTTP API next to the gRPC API. Writes carrying
+	// an Idempotency-Key header are applied once.
+	mux := http.NewServeMux()
+	httphandler.New(ctrl).Register(mux)
+	go func() {
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", httpPort), idempotency.Middleware(idempotencyStore, idempotencyTTL, mux)); err != nil {
+			panic(err)
+		}
+	}()
+
+	// Serve the moderation admin API on its own ports, to
+	// callers with a moderator token signed by the shared key.
+	if key := os.Getenv("MODERATOR_TOKEN_KEY"); key != "" {
+		go serveModeration(ctrl, auth.NewVerifier([]byte(key)), adminPort, adminHTTPPort)
+	} else {
+		log.Println("MODERATOR_TOKEN_KEY is not set; the moderation admin API is disabled")
+	}
+
 	h := grpchandler.New(ctrl)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%v", port))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	srv := grpc.NewServer()
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		validation.UnaryServerInterceptor(),
+		idempotency.UnaryServerInterceptor(idempotencyStore, idempotencyTTL,
+			gen.RatingService_PutRating_FullMethodName,
+		),
+	))
 	reflection.Register(srv)
 	gen.RegisterRatingServiceServer(srv, h)
 	if err := srv.Serve(lis); err != nil {
@@ -93,6 +219,110 @@ func main() {
 	}
 }
 
+// serveModeration serves the moderation admin API over gRPC
+// and HTTP. Every request must carry a bearer token granting
+// the moderator role, whose subject is recorded as the
+// moderator of status changes.
+func serveModeration(ctrl *rating.Controller, verifier *auth.Verifier, port, httpPort int) {
+	mux := http.NewServeMux()
+	httphandler.New(ctrl).RegisterModeration(mux)
+	go func() {
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", httpPort), auth.Middleware(verifier, auth.RoleModerator, mux)); err != nil {
+			panic(err)
+		}
+	}()
+
+	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%v", port))
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		auth.UnaryServerInterceptor(verifier, auth.RoleModerator),
+		validation.UnaryServerInterceptor(),
+	))
+	gen.RegisterRatingModerationServiceServer(srv, grpchandler.NewModeration(ctrl))
+	if err := srv.Serve(lis); err != nil {
+		panic(err)
+	}
+}
+
+// aggregationConfig reads the rating aggregation config from
+// the environment.
+func aggregationConfig() rating.Config {
+	cfg := rating.DefaultConfig()
+	aggregators, err := rating.ParseAggregators(os.Getenv("RATING_AGGREGATORS"))
+	if err != nil {
+		panic(err)
+	}
+	cfg.Aggregators = aggregators
+	for _, f := range []struct {
+		key string
+		dst *float64
+	}{
+		{"BAYESIAN_PRIOR", &cfg.BayesianPrior},
+		{"TRIM_FRACTION", &cfg.TrimFraction},
+		{"DEFAULT_USER_TRUST", &cfg.DefaultTrust},
+	} {
+		if v := os.Getenv(f.key); v != "" {
+			if *f.dst, err = strconv.ParseFloat(v, 64); err != nil {
+				panic(fmt.Sprintf("invalid %s: %v", f.key, err))
+			}
+		}
+	}
+	if v := os.Getenv("BAYESIAN_MIN_VOTES"); v != "" {
+		if cfg.BayesianMinVotes, err = strconv.ParseInt(v, 10, 64); err != nil {
+			panic(fmt.Sprintf("invalid BAYESIAN_MIN_VOTES: %v", err))
+		}
+	}
+	if enabled, err := strconv.ParseBool(getEnvOrDefault("ABUSE_DETECTION", "true")); err != nil {
+		panic(fmt.Sprintf("invalid ABUSE_DETECTION: %v", err))
+	} else if enabled {
+		abuseCfg := abuseConfig()
+		cfg.Abuse = &abuseCfg
+	}
+	return cfg
+}
+
+// abuseConfig reads the abuse detection thresholds from the
+// environment.
+func abuseConfig() abuse.Config {
+	cfg := abuse.DefaultConfig()
+	for _, f := range []struct {
+		key string
+		dst *time.Duration
+	}{
+		{"ABUSE_RECORD_WINDOW", &cfg.RecordWindow},
+		{"ABUSE_NEW_ACCOUNT_AGE", &cfg.NewAccountAge},
+		{"ABUSE_PATTERN_WINDOW", &cfg.PatternWindow},
+		{"ABUSE_USER_WINDOW", &cfg.UserWindow},
+	} {
+		if v := os.Getenv(f.key); v != "" {
+			var err error
+			if *f.dst, err = time.ParseDuration(v); err != nil {
+				panic(fmt.Sprintf("invalid %s: %v", f.key, err))
+			}
+		}
+	}
+	for _, f := range []struct {
+		key string
+		dst *int
+	}{
+		{"ABUSE_RECORD_LIMIT", &cfg.RecordLimit},
+		{"ABUSE_NEW_ACCOUNT_LIMIT", &cfg.NewAccountLimit},
+		{"ABUSE_PATTERN_SIZE", &cfg.PatternSize},
+		{"ABUSE_PATTERN_USERS", &cfg.PatternUsers},
+		{"ABUSE_USER_LIMIT", &cfg.UserLimit},
+	} {
+		if v := os.Getenv(f.key); v != "" {
+			var err error
+			if *f.dst, err = strconv.Atoi(v); err != nil {
+				panic(fmt.Sprintf("invalid %s: %v", f.key, err))
+			}
+		}
+	}
+	return cfg
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
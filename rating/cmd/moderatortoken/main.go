@@ -0,0 +1,35 @@
+// Command moderatortoken issues a bearer token for the rating
+// moderation admin API, signed with MODERATOR_TOKEN_KEY.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/auth"
+)
+
+func main() {
+	var moderator string
+	var ttl time.Duration
+	flag.StringVar(&moderator, "moderator", "", "ID of the moderator the token identifies")
+	flag.DurationVar(&ttl, "ttl", 8*time.Hour, "validity of the token")
+	flag.Parse()
+
+	key := os.Getenv("MODERATOR_TOKEN_KEY")
+	if key == "" || moderator == "" {
+		log.Fatal("MODERATOR_TOKEN_KEY and -moderator are required")
+	}
+	token, err := auth.NewVerifier([]byte(key)).Sign(auth.Claims{
+		Subject:   moderator,
+		Roles:     []string{auth.RoleModerator},
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		log.Fatalf("Failed to sign token: %v", err)
+	}
+	fmt.Println(token)
+}
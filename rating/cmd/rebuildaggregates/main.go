@@ -0,0 +1,53 @@
+// Command rebuildaggregates recomputes the rating aggregates
+// of every record from the raw ratings, for example after a
+// bulk import or to repair drifted totals.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository/postgres"
+)
+
+func main() {
+	var timeout time.Duration
+	flag.DurationVar(&timeout, "timeout", 10*time.Minute, "maximum duration of the rebuild")
+	flag.Parse()
+
+	dbPort, err := strconv.Atoi(getEnvOrDefault("DB_PORT", "5432"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid port number: %v", err))
+	}
+	repo, err := postgres.New(
+		getEnvOrDefault("DB_HOST", "localhost"),
+		dbPort,
+		getEnvOrDefault("DB_USER", "postgres"),
+		getEnvOrDefault("DB_PASSWORD", "password"),
+		getEnvOrDefault("DB_NAME", "movieexample"),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer repo.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	if err := repo.RebuildAggregates(ctx); err != nil {
+		log.Fatalf("Failed to rebuild rating aggregates: %v", err)
+	}
+	log.Printf("Rebuilt rating aggregates in %v", time.Since(start))
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,227 @@
+// Package abuse detects rating abuse, such as coordinated
+// bursts of ratings on a record, so that suspicious ratings
+// can be held from the aggregates until they are reviewed.
+package abuse
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// ModeratorID is the moderator that held ratings are
+// attributed to in the moderation audit trail.
+const ModeratorID = model.UserID("abuse-detector")
+
+// Signal defines a kind of suspicious rating activity.
+type Signal string
+
+// Signals, in the order Inspect reports them.
+const (
+	// SignalRecordVelocity flags a record rated unusually
+	// often.
+	SignalRecordVelocity = Signal("record_velocity")
+	// SignalNewAccountBurst flags a record rated by many new
+	// accounts.
+	SignalNewAccountBurst = Signal("new_account_burst")
+	// SignalIdenticalPattern flags a user who rated the same
+	// records with the same values as many other users.
+	SignalIdenticalPattern = Signal("identical_pattern")
+	// SignalUserRate flags a user rating unusually often.
+	SignalUserRate = Signal("user_rate")
+)
+
+// Config defines the thresholds of the checks. A check with a
+// zero limit or window is disabled.
+type Config struct {
+	// RecordWindow and RecordLimit flag the ratings of a
+	// record beyond RecordLimit within RecordWindow.
+	RecordWindow time.Duration
+	RecordLimit  int
+	// NewAccountAge and NewAccountLimit flag the ratings of a
+	// record by accounts younger than NewAccountAge beyond
+	// NewAccountLimit within RecordWindow. Accounts are dated
+	// by their first rating.
+	NewAccountAge   time.Duration
+	NewAccountLimit int
+	// PatternWindow, PatternSize and PatternUsers flag the
+	// ratings of a user whose ratings within PatternWindow,
+	// at least PatternSize of them, have the same records and
+	// values as those of PatternUsers-1 other users.
+	PatternWindow time.Duration
+	PatternSize   int
+	PatternUsers  int
+	// UserWindow and UserLimit flag the ratings of a user
+	// beyond UserLimit within UserWindow.
+	UserWindow time.Duration
+	UserLimit  int
+}
+
+// DefaultConfig returns thresholds that ordinary activity,
+// even on a popular new release, stays below.
+func DefaultConfig() Config {
+	return Config{
+		RecordWindow:    10 * time.Minute,
+		RecordLimit:     500,
+		NewAccountAge:   24 * time.Hour,
+		NewAccountLimit: 50,
+		PatternWindow:   time.Hour,
+		PatternSize:     3,
+		PatternUsers:    10,
+		UserWindow:      time.Minute,
+		UserLimit:       30,
+	}
+}
+
+// Store keeps the recent rating activity screened by the
+// detector. It is shared by every instance of the service, so
+// that each sees the ratings written by all of them.
+type Store interface {
+	// RecordActivity records a rating and returns the activity
+	// around it within the windows of the query.
+	RecordActivity(ctx context.Context, activity repository.RatingActivity, query repository.ActivityQuery) (*repository.Activity, error)
+	// SharePattern sets the rating pattern of a user as of at,
+	// or clears it when pattern is empty, and returns the
+	// number of users who showed it from since to at,
+	// including them.
+	SharePattern(ctx context.Context, userID model.UserID, pattern string, at, since time.Time) (int, error)
+	// DeleteActivity drops the ratings and patterns recorded
+	// before the given time.
+	DeleteActivity(ctx context.Context, before time.Time) error
+}
+
+// Detector defines a rating abuse detector. Its state is kept
+// in the store, so every instance of the service screens
+// ratings against the activity of all of them.
+type Detector struct {
+	cfg   Config
+	store Store
+	now   func() time.Time
+
+	mu    sync.Mutex
+	swept time.Time
+}
+
+// New creates a new abuse detector with the given thresholds.
+func New(cfg Config, store Store) *Detector {
+	return &Detector{cfg: cfg, store: store, now: time.Now}
+}
+
+// Inspect records a rating written at the given time and
+// returns the signals it raises, if any. Ratings are judged
+// by the activity up to their time, so late ratings, such as
+// ingested events, are judged as when they were written.
+// Times in the future are taken as now, so a skewed clock
+// cannot push the activity of others out of the windows.
+func (d *Detector) Inspect(ctx context.Context, rating *model.Rating, at time.Time) ([]Signal, error) {
+	now := d.now()
+	if at.After(now) {
+		at = now
+	}
+	d.sweep(ctx, now)
+
+	activity, err := d.store.RecordActivity(ctx, repository.RatingActivity{
+		UserID: rating.UserID,
+		Record: model.RecordKey{ID: rating.RecordID, Type: rating.RecordType},
+		Value:  rating.Value,
+		At:     at,
+	}, repository.ActivityQuery{
+		RecordSince:   at.Add(-d.cfg.RecordWindow),
+		UserSince:     at.Add(-max(d.cfg.PatternWindow, d.cfg.UserWindow)),
+		NewAccountAge: d.cfg.NewAccountAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var signals []Signal
+	if d.cfg.RecordWindow > 0 {
+		if d.cfg.RecordLimit > 0 && activity.RecordRatings > d.cfg.RecordLimit {
+			signals = append(signals, SignalRecordVelocity)
+		}
+		if d.cfg.NewAccountLimit > 0 && activity.NewAccount && activity.NewAccountRatings > d.cfg.NewAccountLimit {
+			signals = append(signals, SignalNewAccountBurst)
+		}
+	}
+	shared, err := d.patternShared(ctx, rating.UserID, activity.UserRatings, at)
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		signals = append(signals, SignalIdenticalPattern)
+	}
+	if d.cfg.UserLimit > 0 && d.cfg.UserWindow > 0 {
+		history := activity.UserRatings
+		if len(history)-firstAfter(history, at.Add(-d.cfg.UserWindow)) > d.cfg.UserLimit {
+			signals = append(signals, SignalUserRate)
+		}
+	}
+	return signals, nil
+}
+
+// patternShared updates the rating pattern of a user from
+// their recent ratings, oldest first, and reports whether
+// enough other users showed the same pattern within the
+// pattern window.
+func (d *Detector) patternShared(ctx context.Context, userID model.UserID, history []repository.RatingActivity, at time.Time) (bool, error) {
+	if d.cfg.PatternUsers <= 0 || d.cfg.PatternWindow <= 0 {
+		return false, nil
+	}
+
+	// The latest value of each record rated within the window
+	// makes the pattern.
+	since := at.Add(-d.cfg.PatternWindow)
+	values := map[model.RecordKey]model.RatingValue{}
+	for _, a := range history[firstAfter(history, since):] {
+		values[a.Record] = a.Value
+	}
+	var pattern string
+	if len(values) >= max(d.cfg.PatternSize, 1) {
+		parts := make([]string, 0, len(values))
+		for rec, value := range values {
+			parts = append(parts, string(rec.Type)+"/"+string(rec.ID)+"="+strconv.Itoa(int(value)))
+		}
+		sort.Strings(parts)
+		pattern = strings.Join(parts, "\n")
+	}
+
+	n, err := d.store.SharePattern(ctx, userID, pattern, at, since)
+	if err != nil {
+		return false, err
+	}
+	return pattern != "" && n >= d.cfg.PatternUsers, nil
+}
+
+// sweep drops the activity that fell out of every window, at
+// most once per the longest window of the wall clock, so
+// the times of the ratings cannot drive it. Failures are logged
+// rather than failing the rating, and retried at the next
+// sweep.
+func (d *Detector) sweep(ctx context.Context, now time.Time) {
+	window := max(d.cfg.RecordWindow, d.cfg.PatternWindow, d.cfg.UserWindow)
+	d.mu.Lock()
+	due := now.Sub(d.swept) >= window
+	if due {
+		d.swept = now
+	}
+	d.mu.Unlock()
+	if !due {
+		return
+	}
+	if err := d.store.DeleteActivity(ctx, now.Add(-window)); err != nil {
+		log.Printf("Failed to delete rating activity: %v\n", err)
+	}
+}
+
+// firstAfter returns the index of the first rating of history,
+// oldest first, after since.
+func firstAfter(history []repository.RatingActivity, since time.Time) int {
+	return sort.Search(len(history), func(i int) bool { return history[i].At.After(since) })
+}
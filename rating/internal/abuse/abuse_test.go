@@ -0,0 +1,224 @@
+package abuse
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository"
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// accounts returns a store in which the given users first
+// rated at the given times.
+func accounts(t *testing.T, firstRated map[model.UserID]time.Time) Store {
+	t.Helper()
+	store := memory.New()
+	for user, at := range firstRated {
+		_, err := store.RecordActivity(context.Background(), repository.RatingActivity{
+			UserID: user, Record: model.RecordKey{ID: "seed", Type: model.RecordTypeMovie}, Value: 3, At: at,
+		}, repository.ActivityQuery{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+var start = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// clock is the wall clock of the detectors under test. It
+// starts at start and follows the latest rating inspected.
+var clock time.Time
+
+func newDetector(cfg Config, store Store) *Detector {
+	clock = start
+	d := New(cfg, store)
+	d.now = func() time.Time { return clock }
+	return d
+}
+
+func inspect(t *testing.T, d *Detector, user model.UserID, recordID model.RecordID, value model.RatingValue, at time.Time) []Signal {
+	t.Helper()
+	if at.After(clock) {
+		clock = at
+	}
+	signals, err := d.Inspect(context.Background(), &model.Rating{RecordID: recordID, RecordType: model.RecordTypeMovie, UserID: user, Value: value}, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signals
+}
+
+func TestRecordVelocity(t *testing.T) {
+	d := newDetector(Config{RecordWindow: time.Minute, RecordLimit: 3}, accounts(t, nil))
+	for i := 0; i < 3; i++ {
+		if s := inspect(t, d, model.UserID(fmt.Sprint("u", i)), "1", 1, start.Add(time.Duration(i)*time.Second)); s != nil {
+			t.Errorf("rating %d: got %v, want none", i, s)
+		}
+	}
+	if s := inspect(t, d, "u3", "1", 1, start.Add(3*time.Second)); !reflect.DeepEqual(s, []Signal{SignalRecordVelocity}) {
+		t.Errorf("fourth rating: got %v, want %v", s, SignalRecordVelocity)
+	}
+	// Other records are counted apart.
+	if s := inspect(t, d, "u4", "2", 1, start.Add(4*time.Second)); s != nil {
+		t.Errorf("other record: got %v, want none", s)
+	}
+	// Ratings leave the window after a minute.
+	if s := inspect(t, d, "u5", "1", 1, start.Add(time.Minute+2*time.Second)); s != nil {
+		t.Errorf("after the window: got %v, want none", s)
+	}
+}
+
+func TestNewAccountBurst(t *testing.T) {
+	old := start.Add(-30 * 24 * time.Hour)
+	d := newDetector(Config{RecordWindow: time.Hour, NewAccountAge: 24 * time.Hour, NewAccountLimit: 2}, accounts(t, map[model.UserID]time.Time{
+		"old1":   old,
+		"old2":   old,
+		"recent": start.Add(-time.Hour),
+	}))
+	at := start
+	next := func() time.Time {
+		at = at.Add(time.Second)
+		return at
+	}
+	for _, user := range []model.UserID{"new1", "old1", "recent"} {
+		if s := inspect(t, d, user, "1", 1, next()); s != nil {
+			t.Errorf("%s: got %v, want none", user, s)
+		}
+	}
+	// Old accounts are never flagged, whatever the burst.
+	if s := inspect(t, d, "old2", "1", 1, next()); s != nil {
+		t.Errorf("old2: got %v, want none", s)
+	}
+	if s := inspect(t, d, "new2", "1", 1, next()); !reflect.DeepEqual(s, []Signal{SignalNewAccountBurst}) {
+		t.Errorf("new2: got %v, want %v", s, SignalNewAccountBurst)
+	}
+}
+
+func TestIdenticalPattern(t *testing.T) {
+	d := newDetector(Config{PatternWindow: time.Hour, PatternSize: 2, PatternUsers: 3}, accounts(t, nil))
+	at := start
+	next := func() time.Time {
+		at = at.Add(time.Second)
+		return at
+	}
+	rate := func(user model.UserID, ratings map[model.RecordID]model.RatingValue) []Signal {
+		var signals []Signal
+		for _, id := range []model.RecordID{"1", "2", "3"} {
+			if v, ok := ratings[id]; ok {
+				signals = inspect(t, d, user, id, v, next())
+			}
+		}
+		return signals
+	}
+
+	brigade := map[model.RecordID]model.RatingValue{"1": 1, "2": 1}
+	for _, user := range []model.UserID{"b1", "b2"} {
+		if s := rate(user, brigade); s != nil {
+			t.Errorf("%s: got %v, want none", user, s)
+		}
+	}
+	// Other values or records make other patterns.
+	if s := rate("u1", map[model.RecordID]model.RatingValue{"1": 1, "2": 5}); s != nil {
+		t.Errorf("u1: got %v, want none", s)
+	}
+	if s := rate("u2", map[model.RecordID]model.RatingValue{"1": 1, "3": 1}); s != nil {
+		t.Errorf("u2: got %v, want none", s)
+	}
+	if s := rate("b3", brigade); !reflect.DeepEqual(s, []Signal{SignalIdenticalPattern}) {
+		t.Errorf("b3: got %v, want %v", s, SignalIdenticalPattern)
+	}
+
+	// Patterns shown before the window are forgotten.
+	at = at.Add(2 * time.Hour)
+	if s := rate("b4", brigade); s != nil {
+		t.Errorf("b4 after the window: got %v, want none", s)
+	}
+}
+
+func TestUserRate(t *testing.T) {
+	d := newDetector(Config{UserWindow: time.Minute, UserLimit: 2}, accounts(t, nil))
+	for i, id := range []model.RecordID{"1", "2"} {
+		if s := inspect(t, d, "u1", id, 3, start.Add(time.Duration(i)*time.Second)); s != nil {
+			t.Errorf("rating %d: got %v, want none", i, s)
+		}
+	}
+	if s := inspect(t, d, "u1", "3", 3, start.Add(2*time.Second)); !reflect.DeepEqual(s, []Signal{SignalUserRate}) {
+		t.Errorf("third rating: got %v, want %v", s, SignalUserRate)
+	}
+	if s := inspect(t, d, "u2", "3", 3, start.Add(3*time.Second)); s != nil {
+		t.Errorf("other user: got %v, want none", s)
+	}
+	if s := inspect(t, d, "u1", "4", 3, start.Add(2*time.Minute)); s != nil {
+		t.Errorf("after the window: got %v, want none", s)
+	}
+}
+
+// sweepCounter counts the activity deletions of a store.
+type sweepCounter struct {
+	Store
+	before []time.Time
+}
+
+func (s *sweepCounter) DeleteActivity(ctx context.Context, before time.Time) error {
+	s.before = append(s.before, before)
+	return s.Store.DeleteActivity(ctx, before)
+}
+
+func TestSweep(t *testing.T) {
+	store := &sweepCounter{Store: accounts(t, nil)}
+	d := newDetector(DefaultConfig(), store)
+	for i := 0; i < 10; i++ {
+		inspect(t, d, model.UserID(fmt.Sprint("u", i)), model.RecordID(fmt.Sprint(i)), 5, start.Add(time.Duration(i)*time.Minute))
+	}
+	inspect(t, d, "u0", "0", 5, start.Add(2*time.Hour))
+	// Activity is swept at most once per the longest window,
+	// the pattern window of an hour.
+	want := []time.Time{start.Add(-time.Hour), start.Add(time.Hour)}
+	if !reflect.DeepEqual(store.before, want) {
+		t.Errorf("swept before %v, want %v", store.before, want)
+	}
+}
+
+// Ratings are judged by the activity up to their time, so a
+// late rating is not flagged by the ratings that followed it.
+func TestLateRating(t *testing.T) {
+	d := newDetector(Config{RecordWindow: time.Minute, RecordLimit: 2}, accounts(t, nil))
+	for i := 0; i < 3; i++ {
+		inspect(t, d, model.UserID(fmt.Sprint("u", i)), "1", 1, start.Add(time.Duration(i)*time.Second))
+	}
+	if s := inspect(t, d, "late", "1", 1, start.Add(-30*time.Second)); s != nil {
+		t.Errorf("late rating: got %v, want none", s)
+	}
+	if s := inspect(t, d, "u3", "1", 1, start.Add(3*time.Second)); !reflect.DeepEqual(s, []Signal{SignalRecordVelocity}) {
+		t.Errorf("next rating: got %v, want %v", s, SignalRecordVelocity)
+	}
+}
+
+// A rating from the far future neither sweeps the activity of
+// others nor counts outside the windows of the wall clock.
+func TestFutureRating(t *testing.T) {
+	store := &sweepCounter{Store: accounts(t, nil)}
+	d := New(Config{RecordWindow: time.Minute, RecordLimit: 2}, store)
+	d.now = func() time.Time { return start }
+	rate := func(user model.UserID, at time.Time) []Signal {
+		t.Helper()
+		signals, err := d.Inspect(context.Background(), &model.Rating{RecordID: "1", RecordType: model.RecordTypeMovie, UserID: user, Value: 1}, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signals
+	}
+	rate("u0", start.Add(-2*time.Second))
+	rate("u1", start.AddDate(100, 0, 0))
+	if s := rate("u2", start); !reflect.DeepEqual(s, []Signal{SignalRecordVelocity}) {
+		t.Errorf("got signals %v, want %v", s, []Signal{SignalRecordVelocity})
+	}
+	if want := []time.Time{start.Add(-time.Minute)}; !reflect.DeepEqual(store.before, want) {
+		t.Errorf("swept before %v, want %v", store.before, want)
+	}
+}
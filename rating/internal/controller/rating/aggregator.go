@@ -0,0 +1,221 @@
+package rating
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/rating/internal/abuse"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// Aggregator names.
+const (
+	AggregatorMean          = "mean"
+	AggregatorBayesian      = "bayesian"
+	AggregatorTrimmed       = "trimmed"
+	AggregatorTrustWeighted = "trust_weighted"
+)
+
+// ErrUnknownAggregator is returned when a rating is requested
+// with an aggregator that is not registered.
+var ErrUnknownAggregator = errs.InvalidArgument("unknown aggregator")
+
+// ErrRollUpNotSupported is returned when an aggregator that
+// needs individual ratings is asked for a rolled up rating.
+var ErrRollUpNotSupported = errs.InvalidArgument("aggregator does not support roll-up")
+
+// Aggregator computes the rating of a record from its ratings.
+type Aggregator interface {
+	Aggregate(ctx context.Context, r Record) (float64, error)
+}
+
+// Record gives an aggregator access to the ratings of a
+// record. Totals are already loaded; the other methods query
+// the repository, so aggregators call only what they need.
+type Record interface {
+	// Totals returns the running totals of the ratings.
+	Totals() *model.Aggregate
+	// GlobalMean returns the mean rating of all records of
+	// the same type.
+	GlobalMean(ctx context.Context) (float64, error)
+	// Ratings returns the individual ratings.
+	Ratings(ctx context.Context) ([]model.Rating, error)
+	// UserTrust returns the trust weights of the given users.
+	// Users without a weight are missing from the result.
+	UserTrust(ctx context.Context, users []model.UserID) (map[model.UserID]float64, error)
+}
+
+// Mean rates a record with the plain average of its ratings.
+type Mean struct{}
+
+// Aggregate returns the mean rating of r.
+func (Mean) Aggregate(_ context.Context, r Record) (float64, error) {
+	return r.Totals().Mean(), nil
+}
+
+// Bayesian rates a record with the average of its ratings
+// shrunk towards a prior: as if MinVotes extra ratings of the
+// prior value had been given. Records with few ratings stay
+// close to the prior until they earn enough votes.
+type Bayesian struct {
+	// Prior is the rating assumed without evidence. When 0,
+	// the mean rating of all records of the type is used.
+	Prior float64
+	// MinVotes is the weight of the prior, in ratings.
+	MinVotes int64
+}
+
+// Aggregate returns the Bayesian average rating of r.
+func (b Bayesian) Aggregate(ctx context.Context, r Record) (float64, error) {
+	prior := b.Prior
+	if prior == 0 {
+		m, err := r.GlobalMean(ctx)
+		if err != nil {
+			return 0, err
+		}
+		prior = m
+	}
+	t := r.Totals()
+	return (float64(b.MinVotes)*prior + float64(t.Sum)) / float64(b.MinVotes+t.Count), nil
+}
+
+// Trimmed rates a record with the average of its ratings
+// after dropping the given fraction of the lowest and of the
+// highest ones, which blunts review bombing and vote stuffing.
+type Trimmed struct {
+	// Fraction of ratings dropped at each end, below 0.5.
+	Fraction float64
+}
+
+// Aggregate returns the trimmed mean rating of r. Rating
+// values are discrete, so it is computed from the histogram.
+func (tr Trimmed) Aggregate(_ context.Context, r Record) (float64, error) {
+	t := r.Totals()
+	drop := int64(float64(t.Count) * tr.Fraction)
+	keep := t.Count - 2*drop
+	if keep <= 0 {
+		return t.Mean(), nil
+	}
+
+	var sum float64
+	skip, left := drop, keep
+	for i, n := range t.Histogram {
+		d := min(n, skip)
+		skip -= d
+		n = min(n-d, left)
+		left -= n
+		sum += float64(n) * float64(model.MinRatingValue+model.RatingValue(i))
+	}
+	return sum / float64(keep), nil
+}
+
+// TrustWeighted rates a record with the average of its
+// ratings weighted by the trust of their users, so that new
+// or suspicious accounts count less.
+type TrustWeighted struct {
+	// DefaultTrust is the weight of users without a trust
+	// weight.
+	DefaultTrust float64
+}
+
+// Aggregate returns the trust-weighted mean rating of r.
+func (tw TrustWeighted) Aggregate(ctx context.Context, r Record) (float64, error) {
+	ratings, err := r.Ratings(ctx)
+	if err != nil {
+		return 0, err
+	}
+	users := make([]model.UserID, len(ratings))
+	for i, rating := range ratings {
+		users[i] = rating.UserID
+	}
+	trust, err := r.UserTrust(ctx, users)
+	if err != nil {
+		return 0, err
+	}
+
+	var sum, weights float64
+	for _, rating := range ratings {
+		w, ok := trust[rating.UserID]
+		if !ok {
+			w = tw.DefaultTrust
+		}
+		sum += w * float64(rating.Value)
+		weights += w
+	}
+	if weights == 0 {
+		return r.Totals().Mean(), nil
+	}
+	return sum / weights, nil
+}
+
+// Config defines how ratings are aggregated and screened.
+type Config struct {
+	// Aggregators maps record types to the name of the
+	// aggregator used when a request names none. Other types
+	// use the mean.
+	Aggregators map[model.RecordType]string
+	// BayesianPrior and BayesianMinVotes configure the
+	// Bayesian aggregator.
+	BayesianPrior    float64
+	BayesianMinVotes int64
+	// TrimFraction configures the trimmed aggregator.
+	TrimFraction float64
+	// DefaultTrust configures the trust-weighted aggregator.
+	DefaultTrust float64
+	// Abuse, when set, configures the abuse detection of
+	// written ratings.
+	Abuse *abuse.Config
+}
+
+// DefaultConfig returns the default aggregation config, which
+// rates every record type with the mean.
+func DefaultConfig() Config {
+	return Config{
+		BayesianMinVotes: 25,
+		TrimFraction:     0.1,
+		DefaultTrust:     1,
+	}
+}
+
+// aggregators returns the aggregators of cfg by name.
+func (cfg Config) aggregators() map[string]Aggregator {
+	return map[string]Aggregator{
+		AggregatorMean:          Mean{},
+		AggregatorBayesian:      Bayesian{Prior: cfg.BayesianPrior, MinVotes: cfg.BayesianMinVotes},
+		AggregatorTrimmed:       Trimmed{Fraction: cfg.TrimFraction},
+		AggregatorTrustWeighted: TrustWeighted{DefaultTrust: cfg.DefaultTrust},
+	}
+}
+
+// ParseAggregators parses per record type aggregator names
+// given as comma separated type:name pairs, such as
+// "movie:bayesian,episode:mean".
+func ParseAggregators(s string) (map[model.RecordType]string, error) {
+	res := map[model.RecordType]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		typ, name, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid aggregator %q: want type:name", pair)
+		}
+		res[model.RecordType(typ)] = name
+	}
+	return res, nil
+}
+
+// AggregatorNames returns the names of the registered
+// aggregators in order.
+func AggregatorNames() []string {
+	var names []string
+	for name := range DefaultConfig().aggregators() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
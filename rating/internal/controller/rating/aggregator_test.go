@@ -0,0 +1,206 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// fixedRecord implements Record over a fixed set of ratings.
+type fixedRecord struct {
+	ratings    []model.Rating
+	globalMean float64
+	trust      map[model.UserID]float64
+}
+
+func (r *fixedRecord) Totals() *model.Aggregate {
+	agg := model.NewAggregate("1", model.RecordTypeMovie)
+	for _, rating := range r.ratings {
+		agg.Add(rating.Value)
+	}
+	return agg
+}
+
+func (r *fixedRecord) GlobalMean(context.Context) (float64, error) {
+	return r.globalMean, nil
+}
+
+func (r *fixedRecord) Ratings(context.Context) ([]model.Rating, error) {
+	return r.ratings, nil
+}
+
+func (r *fixedRecord) UserTrust(context.Context, []model.UserID) (map[model.UserID]float64, error) {
+	return r.trust, nil
+}
+
+// ratings returns ratings of the given values by users u0,
+// u1 and so on.
+func ratings(values ...model.RatingValue) []model.Rating {
+	var res []model.Rating
+	for i, v := range values {
+		res = append(res, model.Rating{UserID: model.UserID(fmt.Sprintf("u%d", i)), Value: v})
+	}
+	return res
+}
+
+func TestAggregators(t *testing.T) {
+	// A single 5-star vote, a classic with many votes, and a
+	// record bombed with 1-star votes.
+	single := &fixedRecord{ratings: ratings(5), globalMean: 3}
+	classic := &fixedRecord{ratings: ratings(5, 5, 4, 5, 4, 5, 4, 5, 5, 3), globalMean: 3}
+	bombed := &fixedRecord{ratings: ratings(1, 1, 4, 4, 4, 5, 4, 4, 1, 1), globalMean: 3}
+
+	tests := []struct {
+		name string
+		agg  Aggregator
+		r    Record
+		want float64
+	}{
+		{"mean single", Mean{}, single, 5},
+		{"mean classic", Mean{}, classic, 4.5},
+
+		// (2*3 + 5) / (2 + 1)
+		{"bayesian single", Bayesian{MinVotes: 2}, single, 11.0 / 3},
+		// (2*3 + 45) / (2 + 10)
+		{"bayesian classic", Bayesian{MinVotes: 2}, classic, 51.0 / 12},
+		// (2*4 + 5) / (2 + 1)
+		{"bayesian fixed prior", Bayesian{Prior: 4, MinVotes: 2}, single, 13.0 / 3},
+		{"bayesian without prior weight", Bayesian{MinVotes: 0}, single, 5},
+
+		{"trimmed bombed", Trimmed{Fraction: 0.2}, bombed, 3},
+		{"trimmed classic", Trimmed{Fraction: 0.1}, classic, 4.625},
+		{"trimmed too few", Trimmed{Fraction: 0.2}, single, 5},
+		{"trimmed nothing", Trimmed{}, bombed, 2.9},
+
+		// The 1-star voters are untrusted new accounts:
+		// (4+4+4+5+4+4 + 4*0.1*1) / (6 + 4*0.1)
+		{"trust weighted", TrustWeighted{DefaultTrust: 1}, &fixedRecord{
+			ratings: bombed.ratings,
+			trust:   map[model.UserID]float64{"u0": 0.1, "u1": 0.1, "u8": 0.1, "u9": 0.1},
+		}, 25.4 / 6.4},
+		{"trust weighted by default", TrustWeighted{DefaultTrust: 1}, classic, 4.5},
+		{"trust weighted without trust", TrustWeighted{DefaultTrust: 0}, classic, 4.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.agg.Aggregate(context.Background(), tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregatorSelection(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	cfg := DefaultConfig()
+	cfg.BayesianMinVotes = 3
+	cfg.Aggregators = map[model.RecordType]string{model.RecordTypeMovie: AggregatorBayesian}
+	ctrl, err := NewWithConfig(repo, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range []struct {
+		id    model.RecordID
+		typ   model.RecordType
+		value model.RatingValue
+	}{
+		{"1", model.RecordTypeMovie, 5},
+		{"2", model.RecordTypeMovie, 1},
+		{"3", model.RecordTypeSeries, 5},
+	} {
+		if err := ctrl.PutRating(ctx, r.id, r.typ, &model.Rating{UserID: "u", Value: r.value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		id         model.RecordID
+		typ        model.RecordType
+		aggregator string
+		want       float64
+	}{
+		// Movies default to Bayesian with the movie mean of 3:
+		// (3*3 + 5) / (3 + 1).
+		{"1", model.RecordTypeMovie, "", 3.5},
+		{"1", model.RecordTypeMovie, AggregatorMean, 5},
+		// Other types default to the mean.
+		{"3", model.RecordTypeSeries, "", 5},
+	}
+	for _, tt := range tests {
+		got, err := ctrl.GetAggregateRating(ctx, tt.id, tt.typ, false, tt.aggregator)
+		if err != nil {
+			t.Fatalf("aggregate %s with %q: %v", tt.id, tt.aggregator, err)
+		}
+		if got != tt.want {
+			t.Errorf("aggregate %s with %q = %v, want %v", tt.id, tt.aggregator, got, tt.want)
+		}
+	}
+
+	// Batches use the configured aggregators too, count the
+	// ratings and leave out unrated records.
+	batch, err := ctrl.GetAggregateRatings(ctx, []model.RecordKey{
+		{ID: "1", Type: model.RecordTypeMovie},
+		{ID: "3", Type: model.RecordTypeSeries},
+		{ID: "3", Type: model.RecordTypeMovie},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[model.RecordKey]model.AggregatedRating{
+		{ID: "1", Type: model.RecordTypeMovie}:  {Value: 3.5, Count: 1},
+		{ID: "3", Type: model.RecordTypeSeries}: {Value: 5, Count: 1},
+	}; !reflect.DeepEqual(batch, want) {
+		t.Errorf("batch aggregates = %v, want %v", batch, want)
+	}
+	stats, err := ctrl.GetRatingStats(ctx, "1", model.RecordTypeMovie, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Rating != 3.5 || stats.Mean != 5 {
+		t.Errorf("stats rating = %v and mean = %v, want the Bayesian 3.5 and mean 5", stats.Rating, stats.Mean)
+	}
+	mine, err := ctrl.GetUserRatings(ctx, "u", []model.RecordKey{{ID: "2", Type: model.RecordTypeMovie}, {ID: "2", Type: model.RecordTypeSeries}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mine) != 1 || mine[model.RecordKey{ID: "2", Type: model.RecordTypeMovie}].Value != 1 {
+		t.Errorf("user ratings = %v, want movie 2 rated 1", mine)
+	}
+
+	if _, err := ctrl.GetAggregateRating(ctx, "1", model.RecordTypeMovie, false, "median"); !errors.Is(err, ErrUnknownAggregator) {
+		t.Errorf("unknown aggregator: got %v, want %v", err, ErrUnknownAggregator)
+	}
+	if _, err := ctrl.GetAggregateRating(ctx, "1", model.RecordTypeMovie, true, AggregatorTrustWeighted); !errors.Is(err, ErrRollUpNotSupported) {
+		t.Errorf("trust weighted roll-up: got %v, want %v", err, ErrRollUpNotSupported)
+	}
+
+	cfg.Aggregators = map[model.RecordType]string{model.RecordTypeMovie: "median"}
+	if _, err := NewWithConfig(repo, cfg); err == nil {
+		t.Error("expected an error for an unknown configured aggregator")
+	}
+}
+
+func TestParseAggregators(t *testing.T) {
+	got, err := ParseAggregators(" movie:bayesian, episode:mean,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[model.RecordTypeMovie] != AggregatorBayesian || got[model.RecordTypeEpisode] != AggregatorMean {
+		t.Errorf("got %v", got)
+	}
+	if _, err := ParseAggregators("movie"); err == nil {
+		t.Error("expected an error for a pair without a name")
+	}
+}
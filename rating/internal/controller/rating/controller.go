@@ -3,50 +3,416 @@ package rating
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/rating/internal/abuse"
 	"github.com/phongld0308/movie-example/rating/internal/repository"
 	model "github.com/phongld0308/movie-example/rating/pkg/model"
 )
 
 // ErrNotFound is returned when no ratings are found for a record.
-var ErrNotFound = errors.New("ratings not found for a record")
+var ErrNotFound = errs.NotFound("ratings not found for a record")
+
+// ErrUnknownRecordType is returned when a rating is written
+// for a record type that is not registered.
+var ErrUnknownRecordType = errs.InvalidArgument("unknown record type")
+
+// ErrInvalidRatingValue is returned when a rating value is
+// out of range.
+var ErrInvalidRatingValue = errs.InvalidArgument("invalid rating value")
+
+// ErrUnknownRatingEventType is returned when a rating event
+// is neither a put nor a delete.
+var ErrUnknownRatingEventType = errs.InvalidArgument("unknown rating event type")
 
 type ratingRepository interface {
 	Get(ctx context.Context, recordID model.RecordID, recordType model.RecordType) ([]model.Rating, error)
 	Put(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rating *model.Rating) error
+	Delete(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) error
+	RecordActivity(ctx context.Context, activity repository.RatingActivity, query repository.ActivityQuery) (*repository.Activity, error)
+	SharePattern(ctx context.Context, userID model.UserID, pattern string, at, since time.Time) (int, error)
+	DeleteActivity(ctx context.Context, before time.Time) error
+	GetAggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error)
+	GetAggregates(ctx context.Context, keys []model.RecordKey) ([]*model.Aggregate, error)
+	GetUserRatings(ctx context.Context, userID model.UserID, keys []model.RecordKey) ([]model.Rating, error)
+	GetRollupAggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error)
+	GetTypeAggregate(ctx context.Context, recordType model.RecordType) (*model.Aggregate, error)
+	UserTrust(ctx context.Context, users []model.UserID) (map[model.UserID]float64, error)
+	RefreshTrending(ctx context.Context, now time.Time) error
+	ListTrending(ctx context.Context, recordType model.RecordType, window model.TrendingWindow, limit int) ([]model.TrendingScore, error)
+	ListTopRated(ctx context.Context, query repository.TopRatedQuery) ([]model.RankedRecord, error)
+	ListAggregates(ctx context.Context, recordType model.RecordType) ([]*model.Aggregate, error)
+	PutTopRatedScores(ctx context.Context, recordType model.RecordType, records []model.RankedRecord, now time.Time) error
+	PutReview(ctx context.Context, review *model.Review, now time.Time) error
+	GetReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (*model.Review, error)
+	ListReviews(ctx context.Context, query repository.ReviewQuery) ([]model.Review, error)
+	VoteReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID, helpful bool) error
+	DeleteReviewVote(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID) error
+	SetModeration(ctx context.Context, kind model.ModerationKind, recordID model.RecordID, recordType model.RecordType, userID model.UserID, m model.Moderation) (*model.ModerationAction, error)
+	ReportReview(ctx context.Context, report *model.ReviewReport) error
+	ListFlagged(ctx context.Context, query repository.ModerationQuery) ([]model.FlaggedItem, error)
+	ListModerationActions(ctx context.Context, query repository.ModerationActionQuery) ([]model.ModerationAction, error)
 }
 
+// parentRecorder is implemented by repositories that keep the
+// parents of records for roll-ups themselves, rather than read
+// them from the catalog.
+type parentRecorder interface {
+	SetParent(ctx context.Context, child, parent model.RecordKey) error
+}
+
+type metadataGateway interface {
+	GetParent(ctx context.Context, key model.RecordKey) (model.RecordKey, error)
+}
+
+// globalMeanTTL is how long the mean rating of a record type
+// is cached for the Bayesian aggregator.
+const globalMeanTTL = time.Minute
+
 // Controller defines a rating service controller.
 type Controller struct {
-	repo ratingRepository
+	repo        ratingRepository
+	aggregators map[string]Aggregator
+	defaults    map[model.RecordType]string
+	// detector, when set, screens written ratings for abuse.
+	detector *abuse.Detector
+	// metadataGateway, when set, provides the parents of rated
+	// records to repositories that record them.
+	metadataGateway metadataGateway
+
+	mu          sync.Mutex
+	globalMeans map[model.RecordType]cachedMean
+	now         func() time.Time
+}
+
+type cachedMean struct {
+	mean float64
+	at   time.Time
 }
 
-// New creates a new rating service controller.
+// New creates a new rating service controller that
+// aggregates ratings with the default config.
 func New(repo ratingRepository) *Controller {
-	return &Controller{repo}
+	c, _ := NewWithConfig(repo, DefaultConfig())
+	return c
+}
+
+// NewWithConfig creates a new rating service controller that
+// aggregates ratings as configured.
+func NewWithConfig(repo ratingRepository, cfg Config) (*Controller, error) {
+	c := &Controller{
+		repo:        repo,
+		aggregators: cfg.aggregators(),
+		defaults:    map[model.RecordType]string{},
+		globalMeans: map[model.RecordType]cachedMean{},
+		now:         time.Now,
+	}
+	for typ, name := range cfg.Aggregators {
+		if !typ.IsRegistered() {
+			return nil, fmt.Errorf("aggregator for unknown record type %q", typ)
+		}
+		if _, ok := c.aggregators[name]; !ok {
+			return nil, fmt.Errorf("unknown aggregator %q for record type %q", name, typ)
+		}
+		c.defaults[typ] = name
+	}
+	if cfg.Abuse != nil {
+		c.detector = abuse.New(*cfg.Abuse, repo)
+	}
+	return c, nil
+}
+
+// NewWithMetadataGateway creates a new rating service
+// controller as NewWithConfig does, which looks up the parents
+// of rated records in the metadata service when the repository
+// records them.
+func NewWithMetadataGateway(repo ratingRepository, cfg Config, metadataGateway metadataGateway) (*Controller, error) {
+	c, err := NewWithConfig(repo, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.metadataGateway = metadataGateway
+	return c, nil
 }
 
 // GetAggregateRating returns aggregated rating for a
-// record or ErrNotFound if there are no ratings for it.
-func (c *Controller) GetAggregateRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (float64, error) {
-	ratings, err := c.repo.Get(ctx, recordID, recordType)
+// record or ErrNotFound if there are no ratings for it. With
+// rollUp, the ratings of child records are aggregated too,
+// so that a series is rated by its seasons and episodes. The
+// named aggregator is used, or the one configured for the
+// record type if aggregator is empty.
+func (c *Controller) GetAggregateRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rollUp bool, aggregator string) (float64, error) {
+	a, err := c.aggregator(recordType, aggregator)
+	if err != nil {
+		return 0, err
+	}
+
+	agg, err := c.aggregate(ctx, recordID, recordType, rollUp)
+	if err != nil {
+		return 0, err
+	}
+	return a.Aggregate(ctx, &record{c: c, totals: agg, rollUp: rollUp})
+}
+
+// GetAggregateRatings returns the ratings of the given
+// records by the aggregators configured for their types,
+// with their rating counts. Records without ratings are left
+// out. The totals of all
+// records are read at once; only the aggregators needing the
+// individual ratings read them record by record.
+func (c *Controller) GetAggregateRatings(ctx context.Context, keys []model.RecordKey) (map[model.RecordKey]model.AggregatedRating, error) {
+	aggs, err := c.repo.GetAggregates(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[model.RecordKey]model.AggregatedRating, len(aggs))
+	for _, agg := range aggs {
+		a, err := c.aggregator(agg.RecordType, "")
+		if err != nil {
+			return nil, err
+		}
+		v, err := a.Aggregate(ctx, &record{c: c, totals: agg})
+		if err != nil {
+			return nil, err
+		}
+		res[model.RecordKey{ID: agg.RecordID, Type: agg.RecordType}] = model.AggregatedRating{Value: v, Count: agg.Count}
+	}
+	return res, nil
+}
+
+// aggregator returns the named aggregator, or the one
+// configured for the record type if name is empty.
+func (c *Controller) aggregator(recordType model.RecordType, name string) (Aggregator, error) {
+	if name == "" {
+		name = c.defaults[recordType]
+	}
+	if name == "" {
+		name = AggregatorMean
+	}
+	a, ok := c.aggregators[name]
+	if !ok {
+		return nil, ErrUnknownAggregator.WithViolations(errs.FieldViolation{Field: "aggregator", Description: fmt.Sprintf("must be one of %v", AggregatorNames())})
+	}
+	return a, nil
+}
+
+// record implements Record over the repository.
+type record struct {
+	c      *Controller
+	totals *model.Aggregate
+	rollUp bool
+}
+
+func (r *record) Totals() *model.Aggregate {
+	return r.totals
+}
+
+func (r *record) GlobalMean(ctx context.Context) (float64, error) {
+	return r.c.globalMean(ctx, r.totals.RecordType)
+}
+
+func (r *record) Ratings(ctx context.Context) ([]model.Rating, error) {
+	if r.rollUp {
+		return nil, ErrRollUpNotSupported
+	}
+	ratings, err := r.c.repo.Get(ctx, r.totals.RecordID, r.totals.RecordType)
+	if err != nil {
+		return nil, err
+	}
+	// Hidden and removed ratings are left out, as from the
+	// totals.
+	public := ratings[:0]
+	for _, rating := range ratings {
+		if rating.Moderation.Status.Public() {
+			public = append(public, rating)
+		}
+	}
+	return public, nil
+}
+
+func (r *record) UserTrust(ctx context.Context, users []model.UserID) (map[model.UserID]float64, error) {
+	return r.c.repo.UserTrust(ctx, users)
+}
+
+// globalMean returns the mean rating of all records of a
+// type, cached for globalMeanTTL.
+func (c *Controller) globalMean(ctx context.Context, recordType model.RecordType) (float64, error) {
+	c.mu.Lock()
+	cached, ok := c.globalMeans[recordType]
+	c.mu.Unlock()
+	if ok && c.now().Sub(cached.at) < globalMeanTTL {
+		return cached.mean, nil
+	}
+
+	agg, err := c.repo.GetTypeAggregate(ctx, recordType)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.globalMeans[recordType] = cachedMean{agg.Mean(), c.now()}
+	c.mu.Unlock()
+	return agg.Mean(), nil
+}
+
+// GetRatingStats returns the rating statistics of a record,
+// including child records with rollUp, or ErrNotFound if
+// there are no ratings for it. The statistics include the
+// rating by the aggregator configured for the record type.
+func (c *Controller) GetRatingStats(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rollUp bool) (*model.Stats, error) {
+	a, err := c.aggregator(recordType, "")
+	if err != nil {
+		return nil, err
+	}
+	agg, err := c.aggregate(ctx, recordID, recordType, rollUp)
+	if err != nil {
+		return nil, err
+	}
+	stats := agg.Stats()
+	if stats.Rating, err = a.Aggregate(ctx, &record{c: c, totals: agg, rollUp: rollUp}); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *Controller) aggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rollUp bool) (*model.Aggregate, error) {
+	get := c.repo.GetAggregate
+	if rollUp {
+		get = c.repo.GetRollupAggregate
+	}
+	agg, err := get(ctx, recordID, recordType)
 
 	if err != nil && err == repository.ErrNotFound {
-		return 0, ErrNotFound
+		return nil, ErrNotFound
 	} else if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	sum := float64(0)
+	return agg, nil
+}
 
-	for _, r := range ratings {
-		sum += float64(r.Value)
+// GetRating returns the rating a user gave to a record or
+// ErrNotFound if the user has not rated it.
+func (c *Controller) GetRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (*model.Rating, error) {
+	ratings, err := c.repo.GetUserRatings(ctx, userID, []model.RecordKey{{ID: recordID, Type: recordType}})
+	if err != nil {
+		return nil, err
 	}
+	if len(ratings) == 0 {
+		return nil, ErrNotFound
+	}
+	return &ratings[0], nil
+}
 
-	return sum / float64(len(ratings)), nil
+// GetUserRatings returns the ratings a user gave to the given
+// records. Records the user has not rated are left out.
+func (c *Controller) GetUserRatings(ctx context.Context, userID model.UserID, keys []model.RecordKey) (map[model.RecordKey]*model.Rating, error) {
+	ratings, err := c.repo.GetUserRatings(ctx, userID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[model.RecordKey]*model.Rating, len(ratings))
+	for i := range ratings {
+		res[model.RecordKey{ID: ratings[i].RecordID, Type: ratings[i].RecordType}] = &ratings[i]
+	}
+	return res, nil
 }
 
-// PutRating writes a rating for a given record.
+// PutRating writes a rating for a given record, dated by its
+// UpdatedAt or else the current time. With abuse detection
+// configured, suspicious ratings are held from the aggregates
+// until a moderator reviews them.
 func (c *Controller) PutRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rating *model.Rating) error {
-	return c.repo.Put(ctx, recordID, recordType, rating)
+	if !recordType.IsRegistered() {
+		return ErrUnknownRecordType.WithViolations(errs.FieldViolation{Field: "record_type", Description: fmt.Sprintf("must be one of %v", model.RecordTypes)})
+	}
+	if !rating.Value.Valid() {
+		return ErrInvalidRatingValue.WithViolations(errs.FieldViolation{Field: "rating_value", Description: fmt.Sprintf("must be between %d and %d", model.MinRatingValue, model.MaxRatingValue)})
+	}
+
+	if err := c.recordParents(ctx, model.RecordKey{ID: recordID, Type: recordType}); err != nil {
+		return err
+	}
+
+	r := *rating
+	r.RecordID, r.RecordType = recordID, recordType
+	r.Moderation = model.Moderation{}
+	if r.UpdatedAt.IsZero() {
+		r.UpdatedAt = c.now()
+	}
+	if c.detector != nil {
+		signals, err := c.detector.Inspect(ctx, &r, r.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if len(signals) > 0 {
+			names := make([]string, len(signals))
+			for i, s := range signals {
+				names[i] = string(s)
+			}
+			r.Moderation = model.Moderation{
+				Status:      model.ModerationStatusHeld,
+				Reason:      "suspected abuse: " + strings.Join(names, ", "),
+				ModeratorID: abuse.ModeratorID,
+				UpdatedAt:   r.UpdatedAt,
+			}
+		}
+	}
+	return c.repo.Put(ctx, recordID, recordType, &r)
+}
+
+// recordParents records the parents of a record up to the
+// top of its hierarchy, such as the season and series of an
+// episode, if the repository keeps them. Records missing from
+// the catalog have no parents.
+func (c *Controller) recordParents(ctx context.Context, key model.RecordKey) error {
+	recorder, ok := c.repo.(parentRecorder)
+	if !ok || c.metadataGateway == nil {
+		return nil
+	}
+	for key.Type.ParentType() != "" {
+		parent, err := c.metadataGateway.GetParent(ctx, key)
+		if err != nil && errors.Is(err, errs.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if parent.ID == "" {
+			return nil
+		}
+		if err := recorder.SetParent(ctx, key, parent); err != nil {
+			return err
+		}
+		key = parent
+	}
+	return nil
+}
+
+// ApplyRatingEvent writes or deletes the rating of an
+// ingested event, as PutRating and DeleteRating do. Written
+// ratings are dated and screened by the event timestamp.
+func (c *Controller) ApplyRatingEvent(ctx context.Context, event *model.RatingEvent) error {
+	switch event.RatingEventType {
+	case model.RatingEventTypePut:
+		return c.PutRating(ctx, event.RecordID, event.RecordType, &model.Rating{UserID: event.UserID, Value: event.Value, UpdatedAt: event.Timestamp})
+	case model.RatingEventTypeDelete:
+		return c.DeleteRating(ctx, event.RecordID, event.RecordType, event.UserID)
+	}
+	return ErrUnknownRatingEventType.WithViolations(errs.FieldViolation{Field: "eventType", Description: "must be put or delete"})
+}
+
+// DeleteRating removes the rating a user gave to a record,
+// and its review, or returns ErrNotFound if the user has not
+// rated it.
+func (c *Controller) DeleteRating(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) error {
+	err := c.repo.Delete(ctx, recordID, recordType, userID)
+	if err != nil && err == repository.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
 }
@@ -0,0 +1,263 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/abuse"
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// fakeMetadataGateway maps records to their parents.
+type fakeMetadataGateway map[model.RecordKey]model.RecordKey
+
+func (g fakeMetadataGateway) GetParent(_ context.Context, key model.RecordKey) (model.RecordKey, error) {
+	return g[key], nil
+}
+
+func TestRollUp(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	// Parents are recorded as episodes are rated, including
+	// the series of the unrated season.
+	ctrl, err := NewWithMetadataGateway(repo, DefaultConfig(), fakeMetadataGateway{
+		{ID: "s1", Type: model.RecordTypeSeason}:    {ID: "show", Type: model.RecordTypeSeries},
+		{ID: "s1e1", Type: model.RecordTypeEpisode}: {ID: "s1", Type: model.RecordTypeSeason},
+		{ID: "s1e2", Type: model.RecordTypeEpisode}: {ID: "s1", Type: model.RecordTypeSeason},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range []struct {
+		id    model.RecordID
+		typ   model.RecordType
+		user  model.UserID
+		value model.RatingValue
+	}{
+		{"show", model.RecordTypeSeries, "u1", 5},
+		{"s1e1", model.RecordTypeEpisode, "u1", 4},
+		{"s1e1", model.RecordTypeEpisode, "u2", 2},
+		{"s1e2", model.RecordTypeEpisode, "u1", 1},
+	} {
+		if err := ctrl.PutRating(ctx, r.id, r.typ, &model.Rating{UserID: r.user, Value: r.value}); err != nil {
+			t.Fatalf("put rating: %v", err)
+		}
+	}
+
+	tests := []struct {
+		id     model.RecordID
+		typ    model.RecordType
+		rollUp bool
+		want   float64
+	}{
+		{"show", model.RecordTypeSeries, false, 5},
+		{"show", model.RecordTypeSeries, true, 3},
+		{"s1", model.RecordTypeSeason, true, 7.0 / 3},
+		{"s1e1", model.RecordTypeEpisode, true, 3},
+	}
+	for _, tt := range tests {
+		got, err := ctrl.GetAggregateRating(ctx, tt.id, tt.typ, tt.rollUp, "")
+		if err != nil {
+			t.Fatalf("aggregate %s (rollUp %v): %v", tt.id, tt.rollUp, err)
+		}
+		if got != tt.want {
+			t.Errorf("aggregate %s (rollUp %v) = %v, want %v", tt.id, tt.rollUp, got, tt.want)
+		}
+	}
+
+	if _, err := ctrl.GetAggregateRating(ctx, "s1", model.RecordTypeSeason, false, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("season without own ratings: got %v, want %v", err, ErrNotFound)
+	}
+	if err := ctrl.PutRating(ctx, "x", "book", &model.Rating{UserID: "u", Value: 3}); !errors.Is(err, ErrUnknownRecordType) {
+		t.Errorf("put unknown type: got %v, want %v", err, ErrUnknownRecordType)
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	ctrl := New(repo)
+	put := func(user model.UserID, v model.RatingValue) {
+		t.Helper()
+		if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: user, Value: v}); err != nil {
+			t.Fatalf("put rating: %v", err)
+		}
+	}
+	check := func(want float64, histogram []int64) {
+		t.Helper()
+		got, err := ctrl.GetAggregateRating(ctx, "1", model.RecordTypeMovie, false, "")
+		if err != nil {
+			t.Fatalf("aggregate: %v", err)
+		}
+		if got != want {
+			t.Errorf("aggregate = %v, want %v", got, want)
+		}
+		agg, err := repo.GetAggregate(ctx, "1", model.RecordTypeMovie)
+		if err != nil {
+			t.Fatalf("get aggregate: %v", err)
+		}
+		if !reflect.DeepEqual(agg.Histogram, histogram) {
+			t.Errorf("histogram = %v, want %v", agg.Histogram, histogram)
+		}
+	}
+
+	put("u1", 5)
+	put("u2", 3)
+	check(4, []int64{0, 0, 1, 0, 1})
+
+	// A user's new rating replaces the previous one.
+	put("u2", 1)
+	check(3, []int64{1, 0, 0, 0, 1})
+
+	if err := ctrl.DeleteRating(ctx, "1", model.RecordTypeMovie, "u1"); err != nil {
+		t.Fatalf("delete rating: %v", err)
+	}
+	check(1, []int64{1, 0, 0, 0, 0})
+	if err := ctrl.DeleteRating(ctx, "1", model.RecordTypeMovie, "u1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete missing rating: got %v, want %v", err, ErrNotFound)
+	}
+
+	if err := repo.RebuildAggregates(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	check(1, []int64{1, 0, 0, 0, 0})
+
+	if err := ctrl.DeleteRating(ctx, "1", model.RecordTypeMovie, "u2"); err != nil {
+		t.Fatalf("delete rating: %v", err)
+	}
+	if _, err := ctrl.GetAggregateRating(ctx, "1", model.RecordTypeMovie, false, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("aggregate without ratings: got %v, want %v", err, ErrNotFound)
+	}
+	if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: "u", Value: 6}); !errors.Is(err, ErrInvalidRatingValue) {
+		t.Errorf("put out of range value: got %v, want %v", err, ErrInvalidRatingValue)
+	}
+}
+
+func TestRatingStats(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	for i, v := range []model.RatingValue{5, 4, 4, 2, 5, 1} {
+		if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: model.UserID(fmt.Sprint(i)), Value: v}); err != nil {
+			t.Fatalf("put rating: %v", err)
+		}
+	}
+
+	got, err := ctrl.GetRatingStats(ctx, "1", model.RecordTypeMovie, false)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if got.UpdatedAt.IsZero() {
+		t.Error("stats have no update time")
+	}
+	got.UpdatedAt = time.Time{}
+	want := &model.Stats{
+		Count:     6,
+		Histogram: []int64{1, 1, 0, 2, 2},
+		Mean:      3.5,
+		Median:    4,
+		StdDev:    1.5,
+		Rating:    3.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	if _, err := ctrl.GetRatingStats(ctx, "2", model.RecordTypeMovie, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("stats without ratings: got %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestAbuseDetection(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.Abuse = &abuse.Config{RecordWindow: time.Hour, RecordLimit: 2}
+	ctrl, err := NewWithConfig(memory.New(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctrl.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	wantCount := func(name string, count int64) {
+		t.Helper()
+		stats, err := ctrl.GetRatingStats(ctx, "1", model.RecordTypeMovie, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Count != count {
+			t.Errorf("%s: got %d ratings, want %d", name, stats.Count, count)
+		}
+	}
+
+	for _, user := range []model.UserID{"u1", "u2", "u3"} {
+		if err := ctrl.ApplyRatingEvent(ctx, &model.RatingEvent{UserID: user, RecordID: "1", RecordType: model.RecordTypeMovie, Value: 1, RatingEventType: model.RatingEventTypePut}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The third rating within the hour is held from the
+	// aggregate, and stays held when rated again.
+	wantCount("burst", 2)
+	if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: "u3", Value: 2}); err != nil {
+		t.Fatal(err)
+	}
+	wantCount("held rating rated again", 2)
+	r, err := ctrl.GetRating(ctx, "1", model.RecordTypeMovie, "u3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Moderation.Status != model.ModerationStatusHeld || r.Moderation.ModeratorID != abuse.ModeratorID {
+		t.Errorf("held rating moderation = %+v", r.Moderation)
+	}
+	page, err := ctrl.ListFlagged(ctx, model.ModerationKindRating, model.ModerationStatusHeld, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].UserID != "u3" || page.Items[0].Moderation.Reason != "suspected abuse: record_velocity" {
+		t.Errorf("held queue = %+v", page.Items)
+	}
+	actions, err := ctrl.ListModerationActions(ctx, "1", model.RecordTypeMovie, "", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions.Actions) != 1 || actions.Actions[0].To != model.ModerationStatusHeld {
+		t.Errorf("audit trail = %+v", actions.Actions)
+	}
+
+	// A moderator releases the rating into the aggregate.
+	if _, err := ctrl.SetModerationStatus(ctx, model.ModerationKindRating, "1", model.RecordTypeMovie, "u3", model.ModerationStatusVisible, "", "mod"); err != nil {
+		t.Fatal(err)
+	}
+	wantCount("released rating", 3)
+
+	if err := ctrl.ApplyRatingEvent(ctx, &model.RatingEvent{UserID: "u1", RecordID: "1", RecordType: model.RecordTypeMovie, RatingEventType: model.RatingEventTypeDelete}); err != nil {
+		t.Fatal(err)
+	}
+	wantCount("deleted by event", 2)
+	if err := ctrl.ApplyRatingEvent(ctx, &model.RatingEvent{UserID: "u1", RecordID: "1", RecordType: model.RecordTypeMovie, RatingEventType: "update"}); !errors.Is(err, ErrUnknownRatingEventType) {
+		t.Errorf("unknown event type: got %v, want %v", err, ErrUnknownRatingEventType)
+	}
+
+	// Events are dated, and judged, by their timestamp rather
+	// than the time they are consumed: a day-old rating does
+	// not count toward the current burst.
+	yesterday := now.Add(-24 * time.Hour)
+	if err := ctrl.ApplyRatingEvent(ctx, &model.RatingEvent{UserID: "u4", RecordID: "1", RecordType: model.RecordTypeMovie, Value: 4, RatingEventType: model.RatingEventTypePut, Timestamp: yesterday}); err != nil {
+		t.Fatal(err)
+	}
+	r, err = ctrl.GetRating(ctx, "1", model.RecordTypeMovie, "u4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.UpdatedAt.Equal(yesterday) || r.Moderation.Status != model.ModerationStatusVisible {
+		t.Errorf("late event: updated at %v with moderation %+v, want %v and visible", r.UpdatedAt, r.Moderation, yesterday)
+	}
+	wantCount("late event", 3)
+}
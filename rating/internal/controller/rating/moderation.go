@@ -0,0 +1,230 @@
+package rating
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/rating/internal/repository"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// MaxModerationReasonLength is the maximum length of a
+// moderation or report reason, in characters after
+// sanitization.
+const MaxModerationReasonLength = 500
+
+// Moderation listing page sizes.
+const (
+	DefaultModerationPageSize = 50
+	MaxModerationPageSize     = 200
+)
+
+// ErrInvalidModeration is returned when a moderation request
+// names an unknown kind or status, or lacks a moderator or
+// reason.
+var ErrInvalidModeration = errs.InvalidArgument("invalid moderation request")
+
+// ErrModerationFinal is returned when the status of a removed
+// rating or review is changed.
+var ErrModerationFinal = errs.PreconditionFailed("removed items cannot change status")
+
+// ErrSelfReport is returned when a user reports their own
+// review.
+var ErrSelfReport = errs.InvalidArgument("users cannot report their own review",
+	errs.FieldViolation{Field: "reporter_id", Description: "must differ from the review author"})
+
+// flaggedToken defines the decoded form of a moderation queue
+// page token. It pins the queue it was issued for.
+type flaggedToken struct {
+	List  string                       `json:"l"`
+	After *repository.ModerationCursor `json:"a"`
+}
+
+// actionToken defines the decoded form of an audit trail page
+// token. It pins the filters it was issued for.
+type actionToken struct {
+	List   string `json:"l"`
+	Before int64  `json:"b"`
+}
+
+// SetModerationStatus changes the moderation status of the
+// rating or review of a user for a record and returns the
+// audited action. A reason is required unless the item is
+// made visible. Hidden and removed ratings leave the
+// aggregates, and removed items can no longer change status.
+func (c *Controller) SetModerationStatus(ctx context.Context, kind model.ModerationKind, recordID model.RecordID, recordType model.RecordType, userID model.UserID, status model.ModerationStatus, reason string, moderatorID model.UserID) (*model.ModerationAction, error) {
+	reason = sanitizeReviewText(reason, false)
+	var violations []errs.FieldViolation
+	if !kind.Valid() {
+		violations = append(violations, errs.FieldViolation{Field: "kind", Description: "must be rating or review"})
+	}
+	if !status.Valid() {
+		violations = append(violations, errs.FieldViolation{Field: "status", Description: fmt.Sprintf("must be one of %v", model.ModerationStatuses)})
+	}
+	if moderatorID == "" {
+		violations = append(violations, errs.FieldViolation{Field: "moderator_id", Description: "must not be empty"})
+	}
+	if reason == "" && status != model.ModerationStatusVisible {
+		violations = append(violations, errs.FieldViolation{Field: "reason", Description: "must not be empty"})
+	}
+	if utf8.RuneCountInString(reason) > MaxModerationReasonLength {
+		violations = append(violations, errs.FieldViolation{Field: "reason", Description: fmt.Sprintf("must be at most %d characters long", MaxModerationReasonLength)})
+	}
+	if len(violations) > 0 {
+		return nil, ErrInvalidModeration.WithViolations(violations...)
+	}
+
+	action, err := c.repo.SetModeration(ctx, kind, recordID, recordType, userID, model.Moderation{
+		Status:      status,
+		Reason:      reason,
+		ModeratorID: moderatorID,
+		UpdatedAt:   c.now(),
+	})
+	switch {
+	case err == repository.ErrNotFound:
+		return nil, ErrNotFound
+	case err == repository.ErrFinalStatus:
+		return nil, ErrModerationFinal
+	case err != nil:
+		return nil, err
+	}
+	return action, nil
+}
+
+// ReportReview records a user's report of the review of
+// another user. Reporting a visible review flags it as
+// pending for the moderators; it stays listed until they
+// decide. It returns ErrNotFound if there is no such review.
+func (c *Controller) ReportReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, reporterID model.UserID, reason string) error {
+	if authorID == reporterID {
+		return ErrSelfReport
+	}
+	reason = sanitizeReviewText(reason, false)
+	if utf8.RuneCountInString(reason) > MaxModerationReasonLength {
+		return ErrInvalidModeration.WithViolations(errs.FieldViolation{Field: "reason", Description: fmt.Sprintf("must be at most %d characters long", MaxModerationReasonLength)})
+	}
+
+	err := c.repo.ReportReview(ctx, &model.ReviewReport{
+		RecordID:   recordID,
+		RecordType: recordType,
+		AuthorID:   authorID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		CreatedAt:  c.now(),
+	})
+	if err != nil && err == repository.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ListFlagged returns a page of the ratings or reviews with a
+// moderation status other than visible, pending by default,
+// least recently moderated first, and the token of the next
+// page, which is empty on the last page.
+func (c *Controller) ListFlagged(ctx context.Context, kind model.ModerationKind, status model.ModerationStatus, limit int, token string) (*model.FlaggedPage, error) {
+	if status == "" {
+		status = model.ModerationStatusPending
+	}
+	var violations []errs.FieldViolation
+	if !kind.Valid() {
+		violations = append(violations, errs.FieldViolation{Field: "kind", Description: "must be rating or review"})
+	}
+	if !status.Valid() || status == model.ModerationStatusVisible {
+		violations = append(violations, errs.FieldViolation{Field: "status", Description: "must be pending, held, hidden or removed"})
+	}
+	if len(violations) > 0 {
+		return nil, ErrInvalidModeration.WithViolations(violations...)
+	}
+	if limit <= 0 {
+		limit = DefaultModerationPageSize
+	}
+	if limit > MaxModerationPageSize {
+		limit = MaxModerationPageSize
+	}
+
+	q := repository.ModerationQuery{Kind: kind, Status: status, Limit: limit + 1}
+	list := fmt.Sprintf("%s|%s", kind, status)
+	if token != "" {
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, ErrInvalidPageToken
+		}
+		var t flaggedToken
+		if err := json.Unmarshal(b, &t); err != nil || t.List != list || t.After == nil {
+			return nil, ErrInvalidPageToken
+		}
+		q.After = t.After
+	}
+
+	// One extra item tells whether another page follows.
+	items, err := c.repo.ListFlagged(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	page := &model.FlaggedPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		b, err := json.Marshal(flaggedToken{List: list, After: repository.CursorOf(&page.Items[limit-1])})
+		if err != nil {
+			return nil, err
+		}
+		page.NextPageToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+	if page.Items == nil {
+		page.Items = []model.FlaggedItem{}
+	}
+	return page, nil
+}
+
+// ListModerationActions returns a page of the moderation audit
+// trail, newest first, optionally limited to the items of a
+// record or user, and the token of the next page, which is
+// empty on the last page.
+func (c *Controller) ListModerationActions(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID, limit int, token string) (*model.ModerationActionPage, error) {
+	if (recordID == "") != (recordType == "") {
+		return nil, ErrInvalidModeration.WithViolations(errs.FieldViolation{Field: "record_type", Description: "must be set together with record_id"})
+	}
+	if limit <= 0 {
+		limit = DefaultModerationPageSize
+	}
+	if limit > MaxModerationPageSize {
+		limit = MaxModerationPageSize
+	}
+
+	q := repository.ModerationActionQuery{RecordID: recordID, RecordType: recordType, UserID: userID, Limit: limit + 1}
+	list := fmt.Sprintf("%s|%s|%s", recordType, recordID, userID)
+	if token != "" {
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, ErrInvalidPageToken
+		}
+		var t actionToken
+		if err := json.Unmarshal(b, &t); err != nil || t.List != list || t.Before <= 0 {
+			return nil, ErrInvalidPageToken
+		}
+		q.BeforeID = t.Before
+	}
+
+	actions, err := c.repo.ListModerationActions(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	page := &model.ModerationActionPage{Actions: actions}
+	if len(actions) > limit {
+		page.Actions = actions[:limit]
+		b, err := json.Marshal(actionToken{List: list, Before: page.Actions[limit-1].ID})
+		if err != nil {
+			return nil, err
+		}
+		page.NextPageToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+	if page.Actions == nil {
+		page.Actions = []model.ModerationAction{}
+	}
+	return page, nil
+}
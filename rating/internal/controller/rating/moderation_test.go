@@ -0,0 +1,145 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+func TestModeration(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := New(memory.New())
+	ctrl.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	for user, v := range map[model.UserID]model.RatingValue{"u1": 5, "u2": 1, "u3": 4} {
+		if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: user, Value: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setStatus := func(kind model.ModerationKind, user model.UserID, status model.ModerationStatus, reason string) error {
+		_, err := ctrl.SetModerationStatus(ctx, kind, "1", model.RecordTypeMovie, user, status, reason, "mod")
+		return err
+	}
+	wantCount := func(name string, count int64, reviews int64) {
+		t.Helper()
+		stats, err := ctrl.GetRatingStats(ctx, "1", model.RecordTypeMovie, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Count != count || stats.ReviewCount != reviews {
+			t.Errorf("%s: got %d ratings and %d reviews, want %d and %d", name, stats.Count, stats.ReviewCount, count, reviews)
+		}
+	}
+
+	if err := setStatus(model.ModerationKindRating, "u2", model.ModerationStatusHidden, " "); !errors.Is(err, ErrInvalidModeration) {
+		t.Errorf("hide without reason: got %v, want %v", err, ErrInvalidModeration)
+	}
+	if err := setStatus(model.ModerationKindRating, "u4", model.ModerationStatusHidden, "spam"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("hide missing rating: got %v, want %v", err, ErrNotFound)
+	}
+
+	// Hidden ratings leave the aggregates until restored.
+	if err := setStatus(model.ModerationKindRating, "u2", model.ModerationStatusHidden, "fraud"); err != nil {
+		t.Fatal(err)
+	}
+	wantCount("hidden rating", 2, 0)
+	if v, err := ctrl.GetAggregateRating(ctx, "1", model.RecordTypeMovie, false, AggregatorTrimmed); err != nil || v != 4.5 {
+		t.Errorf("trimmed mean without hidden rating: got %v, %v, want 4.5", v, err)
+	}
+	if err := setStatus(model.ModerationKindRating, "u2", model.ModerationStatusVisible, ""); err != nil {
+		t.Fatal(err)
+	}
+	wantCount("restored rating", 3, 0)
+
+	// Removed ratings stay out, even when rated again.
+	if err := setStatus(model.ModerationKindRating, "u2", model.ModerationStatusRemoved, "fraud"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setStatus(model.ModerationKindRating, "u2", model.ModerationStatusVisible, ""); !errors.Is(err, ErrModerationFinal) {
+		t.Errorf("restore removed rating: got %v, want %v", err, ErrModerationFinal)
+	}
+	if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: "u2", Value: 2}); err != nil {
+		t.Fatal(err)
+	}
+	wantCount("rerated removed rating", 2, 0)
+	if r, err := ctrl.GetRating(ctx, "1", model.RecordTypeMovie, "u2"); err != nil || r.Moderation.Status != model.ModerationStatusRemoved {
+		t.Errorf("own removed rating: got %+v, %v", r, err)
+	}
+
+	// Reported reviews stay listed until a moderator hides them.
+	if err := ctrl.PutReview(ctx, &model.Review{RecordID: "1", RecordType: model.RecordTypeMovie, UserID: "u1", Body: "Abusive"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.ReportReview(ctx, "1", model.RecordTypeMovie, "u1", "u1", "mine"); !errors.Is(err, ErrSelfReport) {
+		t.Errorf("self report: got %v, want %v", err, ErrSelfReport)
+	}
+	for _, reporter := range []model.UserID{"u3", "u4", "u3"} {
+		if err := ctrl.ReportReview(ctx, "1", model.RecordTypeMovie, "u1", reporter, "<b>insults</b>"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flagged, err := ctrl.ListFlagged(ctx, model.ModerationKindReview, "", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flagged.Items) != 1 || flagged.Items[0].UserID != "u1" || flagged.Items[0].Reports != 2 || flagged.Items[0].Moderation.Reason != "insults" {
+		t.Errorf("flagged reviews: got %+v", flagged.Items)
+	}
+	if page, _ := ctrl.ListReviews(ctx, "1", model.RecordTypeMovie, "", 0, ""); len(page.Reviews) != 1 {
+		t.Errorf("pending review: got %d listed reviews, want 1", len(page.Reviews))
+	}
+	wantCount("pending review", 2, 1)
+
+	if err := setStatus(model.ModerationKindReview, "u1", model.ModerationStatusHidden, "insults"); err != nil {
+		t.Fatal(err)
+	}
+	if page, _ := ctrl.ListReviews(ctx, "1", model.RecordTypeMovie, "", 0, ""); len(page.Reviews) != 0 {
+		t.Errorf("hidden review: got %d listed reviews, want 0", len(page.Reviews))
+	}
+	wantCount("hidden review", 2, 0)
+	if flagged, _ := ctrl.ListFlagged(ctx, model.ModerationKindReview, "", 0, ""); len(flagged.Items) != 0 {
+		t.Errorf("flagged reviews after decision: got %+v", flagged.Items)
+	}
+	if _, err := ctrl.ListFlagged(ctx, model.ModerationKindRating, model.ModerationStatusVisible, 0, ""); !errors.Is(err, ErrInvalidModeration) {
+		t.Errorf("visible queue: got %v, want %v", err, ErrInvalidModeration)
+	}
+
+	// Every status change is audited, newest first.
+	var got []model.ModerationStatus
+	token := ""
+	for pages := 0; pages < 5; pages++ {
+		page, err := ctrl.ListModerationActions(ctx, "1", model.RecordTypeMovie, "", 2, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range page.Actions {
+			got = append(got, a.To)
+		}
+		if token = page.NextPageToken; token == "" {
+			break
+		}
+	}
+	want := []model.ModerationStatus{"hidden", "pending", "removed", "visible", "hidden"}
+	if len(got) != len(want) {
+		t.Fatalf("audit trail: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("audit trail: got %v, want %v", got, want)
+		}
+	}
+	page, err := ctrl.ListModerationActions(ctx, "", "", "u1", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Actions) != 2 || page.Actions[1].ActorID != "u3" || page.Actions[0].ActorID != "mod" {
+		t.Errorf("audit trail of u1: got %+v", page.Actions)
+	}
+}
@@ -0,0 +1,225 @@
+package rating
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/rating/internal/repository"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// Review text limits, in characters after sanitization.
+const (
+	MaxReviewTitleLength = 200
+	MaxReviewBodyLength  = 10000
+)
+
+// Review listing page sizes.
+const (
+	DefaultReviewPageSize = 20
+	MaxReviewPageSize     = 100
+)
+
+// ErrInvalidReview is returned when a review is empty or too
+// long.
+var ErrInvalidReview = errs.InvalidArgument("invalid review")
+
+// ErrRatingRequired is returned when a user reviews a record
+// they have not rated.
+var ErrRatingRequired = errs.PreconditionFailed("the record must be rated before it is reviewed")
+
+// ErrSelfVote is returned when a user votes on their own
+// review.
+var ErrSelfVote = errs.InvalidArgument("users cannot vote on their own review",
+	errs.FieldViolation{Field: "voter_id", Description: "must differ from the review author"})
+
+// reviewToken defines the decoded form of a review listing
+// page token. It pins the record and order it was issued for.
+type reviewToken struct {
+	List  string                   `json:"l"`
+	After *repository.ReviewCursor `json:"a"`
+}
+
+// PutReview writes the review of a user's rating of a record,
+// replacing their earlier review. The title and body are
+// sanitized to plain text first.
+func (c *Controller) PutReview(ctx context.Context, review *model.Review) error {
+	if !review.RecordType.IsRegistered() {
+		return ErrUnknownRecordType.WithViolations(errs.FieldViolation{Field: "record_type", Description: fmt.Sprintf("must be one of %v", model.RecordTypes)})
+	}
+	r := *review
+	r.Title = sanitizeReviewText(r.Title, false)
+	r.Body = sanitizeReviewText(r.Body, true)
+
+	var violations []errs.FieldViolation
+	if r.Title == "" && r.Body == "" {
+		violations = append(violations, errs.FieldViolation{Field: "body", Description: "a title or body is required"})
+	}
+	if n := utf8.RuneCountInString(r.Title); n > MaxReviewTitleLength {
+		violations = append(violations, errs.FieldViolation{Field: "title", Description: fmt.Sprintf("must be at most %d characters long", MaxReviewTitleLength)})
+	}
+	if n := utf8.RuneCountInString(r.Body); n > MaxReviewBodyLength {
+		violations = append(violations, errs.FieldViolation{Field: "body", Description: fmt.Sprintf("must be at most %d characters long", MaxReviewBodyLength)})
+	}
+	if len(violations) > 0 {
+		return ErrInvalidReview.WithViolations(violations...)
+	}
+
+	err := c.repo.PutReview(ctx, &r, c.now())
+	if err != nil && err == repository.ErrNotFound {
+		return ErrRatingRequired
+	}
+	return err
+}
+
+// GetReview returns the review of a user for a record or
+// ErrNotFound if there is none.
+func (c *Controller) GetReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (*model.Review, error) {
+	review, err := c.repo.GetReview(ctx, recordID, recordType, userID)
+	if err != nil && err == repository.ErrNotFound {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListReviews returns a page of the reviews of a record,
+// newest or most helpful first, and the token of the next
+// page, which is empty on the last page.
+func (c *Controller) ListReviews(ctx context.Context, recordID model.RecordID, recordType model.RecordType, sortBy model.ReviewSort, limit int, token string) (*model.ReviewPage, error) {
+	if sortBy == "" {
+		sortBy = model.ReviewSortNewest
+	}
+	if limit <= 0 {
+		limit = DefaultReviewPageSize
+	}
+	if limit > MaxReviewPageSize {
+		limit = MaxReviewPageSize
+	}
+
+	q := repository.ReviewQuery{RecordID: recordID, RecordType: recordType, SortBy: sortBy, Limit: limit + 1}
+	list := fmt.Sprintf("%s|%s|%s", recordType, recordID, sortBy)
+	if token != "" {
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, ErrInvalidPageToken
+		}
+		var t reviewToken
+		if err := json.Unmarshal(b, &t); err != nil || t.List != list || t.After == nil {
+			return nil, ErrInvalidPageToken
+		}
+		q.After = t.After
+	}
+
+	// One extra review tells whether another page follows.
+	reviews, err := c.repo.ListReviews(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	page := &model.ReviewPage{Reviews: reviews}
+	if len(reviews) > limit {
+		page.Reviews = reviews[:limit]
+		b, err := json.Marshal(reviewToken{List: list, After: q.CursorOf(&page.Reviews[limit-1])})
+		if err != nil {
+			return nil, err
+		}
+		page.NextPageToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+	if page.Reviews == nil {
+		page.Reviews = []model.Review{}
+	}
+	return page, nil
+}
+
+// VoteReview records whether a user found the review of a
+// record by another user helpful. Each user has one vote per
+// review, which replaces their earlier one. It returns
+// ErrNotFound if there is no such review.
+func (c *Controller) VoteReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID, helpful bool) error {
+	if authorID == voterID {
+		return ErrSelfVote
+	}
+	err := c.repo.VoteReview(ctx, recordID, recordType, authorID, voterID, helpful)
+	if err != nil && err == repository.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// DeleteReviewVote withdraws the vote of a user on a review.
+// It returns ErrNotFound if the user has not voted on it.
+func (c *Controller) DeleteReviewVote(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID) error {
+	err := c.repo.DeleteReviewVote(ctx, recordID, recordType, authorID, voterID)
+	if err != nil && err == repository.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// sanitizeReviewText returns s as plain text: valid UTF-8
+// without HTML tags, control or invisible formatting
+// characters, and with surrounding whitespace trimmed. Bodies
+// keep single blank lines between paragraphs; titles are
+// folded to one line.
+func sanitizeReviewText(s string, multiline bool) string {
+	s = stripTags(strings.ToValidUTF8(s, ""))
+	s = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", " ").Replace(s)
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || !(unicode.IsControl(r) || unicode.Is(unicode.Cf, r)) {
+			return r
+		}
+		return -1
+	}, s)
+	if !multiline {
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	var b strings.Builder
+	blank := 0
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			blank++
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(strings.Repeat("\n", min(blank, 1)+1))
+		}
+		b.WriteString(line)
+		blank = 0
+	}
+	return b.String()
+}
+
+// stripTags removes HTML tags and comments from s. A '<' that
+// does not start a tag, or is never closed, is kept as text.
+func stripTags(s string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '<')
+		if i < 0 || i+1 == len(s) {
+			b.WriteString(s)
+			return b.String()
+		}
+		c := s[i+1]
+		if !(c == '/' || c == '!' || c == '?' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			b.WriteString(s[:i+1])
+			s = s[i+1:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		s = s[i+end+1:]
+	}
+}
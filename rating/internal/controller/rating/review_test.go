@@ -0,0 +1,220 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+func TestSanitizeReviewText(t *testing.T) {
+	tests := []struct {
+		in        string
+		multiline bool
+		want      string
+	}{
+		{"  Great\tmovie \n", false, "Great movie"},
+		{"<b>Great</b> <script>alert(1)</script>movie", false, "Great alert(1)movie"},
+		{"3 < 5 and <!-- hidden -->a <em>gem", false, "3 < 5 and a gem"},
+		{"never <closed", false, "never <closed"},
+		{"bad\x00 bytes\xff and \u202eoverride", false, "bad bytes and override"},
+		{"First line\r\nsecond line\n\n\n\nNew paragraph  \n", true, "First line\nsecond line\n\nNew paragraph"},
+		{"<p>One</p>\n<p>Two</p>", true, "One\nTwo"},
+		{"Title\non two lines", false, "Title on two lines"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeReviewText(tt.in, tt.multiline); got != tt.want {
+			t.Errorf("sanitize %q: got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReviews(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := New(memory.New())
+	ctrl.now = func() time.Time { return now }
+
+	review := func(user model.UserID, title, body string) *model.Review {
+		return &model.Review{RecordID: "1", RecordType: model.RecordTypeMovie, UserID: user, Title: title, Body: body}
+	}
+	if err := ctrl.PutReview(ctx, review("u1", "Great", "")); !errors.Is(err, ErrRatingRequired) {
+		t.Fatalf("review without rating: got %v, want %v", err, ErrRatingRequired)
+	}
+
+	for i, user := range []model.UserID{"u1", "u2", "u3"} {
+		if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: user, Value: 4}); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+		if err := ctrl.PutReview(ctx, review(user, "Review "+string(user), "<i>Body</i> "+string(rune('a'+i)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		title, body string
+	}{
+		{"empty", "", " <br> "},
+		{"long title", strings.Repeat("x", MaxReviewTitleLength+1), ""},
+		{"long body", "", strings.Repeat("x", MaxReviewBodyLength+1)},
+	} {
+		if err := ctrl.PutReview(ctx, review("u1", tt.title, tt.body)); !errors.Is(err, ErrInvalidReview) {
+			t.Errorf("%s review: got %v, want %v", tt.name, err, ErrInvalidReview)
+		}
+	}
+
+	// Editing keeps the creation time.
+	created := now.Add(-2 * time.Minute)
+	now = now.Add(time.Hour)
+	if err := ctrl.PutReview(ctx, review("u1", "Still great", "")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ctrl.GetReview(ctx, "1", model.RecordTypeMovie, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Still great" || got.Body != "" || !got.CreatedAt.Equal(created) || !got.EditedAt.Equal(now) {
+		t.Errorf("edited review: got %+v", got)
+	}
+	if got, _ := ctrl.GetReview(ctx, "1", model.RecordTypeMovie, "u2"); got.Body != "Body b" || !got.EditedAt.IsZero() {
+		t.Errorf("sanitized review: got %+v", got)
+	}
+
+	for _, sortBy := range []model.ReviewSort{"", model.ReviewSortHelpful} {
+		var users []model.UserID
+		token := ""
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("listing did not terminate")
+			}
+			page, err := ctrl.ListReviews(ctx, "1", model.RecordTypeMovie, sortBy, 2, token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range page.Reviews {
+				users = append(users, r.UserID)
+			}
+			if page.NextPageToken == "" {
+				break
+			}
+			token = page.NextPageToken
+		}
+		// Without helpful votes, both orders are newest first.
+		if len(users) != 3 || users[0] != "u3" || users[1] != "u2" || users[2] != "u1" {
+			t.Errorf("reviews by %q: got %v, want u3, u2, u1", sortBy, users)
+		}
+	}
+	page, err := ctrl.ListReviews(ctx, "1", model.RecordTypeMovie, "", 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.ListReviews(ctx, "1", model.RecordTypeMovie, model.ReviewSortHelpful, 2, page.NextPageToken); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("token of another order: got %v, want %v", err, ErrInvalidPageToken)
+	}
+
+	// Reviews are counted with the ratings and go with them.
+	stats, err := ctrl.GetRatingStats(ctx, "1", model.RecordTypeMovie, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ReviewCount != 3 {
+		t.Errorf("got %d reviews, want 3", stats.ReviewCount)
+	}
+	if err := ctrl.DeleteRating(ctx, "1", model.RecordTypeMovie, "u2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.GetReview(ctx, "1", model.RecordTypeMovie, "u2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("review of deleted rating: got %v, want %v", err, ErrNotFound)
+	}
+	if stats, _ := ctrl.GetRatingStats(ctx, "1", model.RecordTypeMovie, false); stats.ReviewCount != 2 {
+		t.Errorf("after delete: got %d reviews, want 2", stats.ReviewCount)
+	}
+}
+
+func TestReviewVotes(t *testing.T) {
+	ctx := context.Background()
+	ctrl := New(memory.New())
+	for _, user := range []model.UserID{"u1", "u2", "u3"} {
+		if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: user, Value: 4}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ctrl.PutReview(ctx, &model.Review{RecordID: "1", RecordType: model.RecordTypeMovie, UserID: user, Body: "Review"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vote := func(author model.UserID, voter string, helpful bool) error {
+		return ctrl.VoteReview(ctx, "1", model.RecordTypeMovie, author, model.UserID(voter), helpful)
+	}
+
+	if err := vote("u1", "u1", true); !errors.Is(err, ErrSelfVote) {
+		t.Errorf("self vote: got %v, want %v", err, ErrSelfVote)
+	}
+	if err := vote("u4", "v1", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("vote on missing review: got %v, want %v", err, ErrNotFound)
+	}
+
+	// Voting again replaces the earlier vote.
+	for _, helpful := range []bool{false, true, true} {
+		if err := vote("u1", "v1", helpful); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 9 helpful votes out of 10 outrank a single one, which
+	// outranks an even split.
+	for i := 0; i < 10; i++ {
+		if err := vote("u2", fmt.Sprintf("v%d", i), i > 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		if err := vote("u3", fmt.Sprintf("v%d", i), i%2 == 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ctrl.GetReview(ctx, "1", model.RecordTypeMovie, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.HelpfulVotes != 1 || got.UnhelpfulVotes != 0 {
+		t.Errorf("after revote: got %d/%d helpful/unhelpful votes, want 1/0", got.HelpfulVotes, got.UnhelpfulVotes)
+	}
+
+	helpfulOrder := func() []model.UserID {
+		var users []model.UserID
+		token := ""
+		for pages := 0; pages < 3; pages++ {
+			page, err := ctrl.ListReviews(ctx, "1", model.RecordTypeMovie, model.ReviewSortHelpful, 2, token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range page.Reviews {
+				users = append(users, r.UserID)
+			}
+			if token = page.NextPageToken; token == "" {
+				break
+			}
+		}
+		return users
+	}
+	if users := helpfulOrder(); fmt.Sprint(users) != "[u2 u1 u3]" {
+		t.Errorf("most helpful: got %v, want [u2 u1 u3]", users)
+	}
+
+	if err := ctrl.DeleteReviewVote(ctx, "1", model.RecordTypeMovie, "u1", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.DeleteReviewVote(ctx, "1", model.RecordTypeMovie, "u1", "v1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete missing vote: got %v, want %v", err, ErrNotFound)
+	}
+	if users := helpfulOrder(); fmt.Sprint(users) != "[u2 u3 u1]" {
+		t.Errorf("most helpful after withdrawal: got %v, want [u2 u3 u1]", users)
+	}
+}
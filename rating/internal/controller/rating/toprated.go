@@ -0,0 +1,120 @@
+package rating
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/rating/internal/repository"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// Top-rated list page sizes.
+const (
+	DefaultTopRatedLimit = 50
+	MaxTopRatedLimit     = 250
+)
+
+// ErrInvalidPageToken is returned when a page token is
+// malformed or was issued for a different list.
+var ErrInvalidPageToken = errs.InvalidArgument("invalid page token",
+	errs.FieldViolation{Field: "page_token", Description: "must be a token returned for the same list"})
+
+// topRatedToken defines the decoded form of a top-rated page
+// token. It pins the list it was issued for, so that a token
+// is not reused for another list.
+type topRatedToken struct {
+	List  string                     `json:"l"`
+	After *repository.TopRatedCursor `json:"a"`
+}
+
+// RefreshTopRated recomputes the top-rated scores of the
+// record types not ranked by their mean rating, with the
+// aggregator configured for each type. Mean rankings are
+// served live from the rating aggregates.
+func (c *Controller) RefreshTopRated(ctx context.Context) error {
+	for _, recordType := range model.RecordTypes {
+		a, err := c.aggregator(recordType, "")
+		if err != nil {
+			return err
+		}
+		if _, ok := a.(Mean); ok {
+			continue
+		}
+		aggs, err := c.repo.ListAggregates(ctx, recordType)
+		if err != nil {
+			return err
+		}
+		records := make([]model.RankedRecord, 0, len(aggs))
+		for _, agg := range aggs {
+			rating, err := a.Aggregate(ctx, &record{c: c, totals: agg})
+			if err != nil {
+				return fmt.Errorf("failed to rate %s %s: %w", recordType, agg.RecordID, err)
+			}
+			records = append(records, model.RankedRecord{RecordID: agg.RecordID, RecordType: recordType, Rating: rating, RatingCount: agg.Count})
+		}
+		if err := c.repo.PutTopRatedScores(ctx, recordType, records, c.now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTopRated returns a page of the records of a type with
+// at least minVotes ratings, ranked by the aggregator
+// configured for the type, and the token of the next page.
+// Types not ranked by the mean are listed as of the last
+// RefreshTopRated.
+func (c *Controller) ListTopRated(ctx context.Context, recordType model.RecordType, minVotes int64, limit int, token string) (*model.TopRatedPage, error) {
+	if !recordType.IsRegistered() {
+		return nil, ErrUnknownRecordType.WithViolations(errs.FieldViolation{Field: "record_type", Description: fmt.Sprintf("must be one of %v", model.RecordTypes)})
+	}
+	a, err := c.aggregator(recordType, "")
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultTopRatedLimit
+	}
+	if limit > MaxTopRatedLimit {
+		limit = MaxTopRatedLimit
+	}
+
+	q := repository.TopRatedQuery{RecordType: recordType, MinVotes: minVotes, Limit: limit + 1}
+	_, mean := a.(Mean)
+	q.Scored = !mean
+	list := fmt.Sprintf("%s|%d|%t", recordType, minVotes, q.Scored)
+	if token != "" {
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, ErrInvalidPageToken
+		}
+		var t topRatedToken
+		if err := json.Unmarshal(b, &t); err != nil || t.List != list || t.After == nil {
+			return nil, ErrInvalidPageToken
+		}
+		q.After = t.After
+	}
+
+	// One extra record tells whether another page follows.
+	records, err := c.repo.ListTopRated(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	page := &model.TopRatedPage{Records: records}
+	if len(records) > limit {
+		page.Records = records[:limit]
+		last := page.Records[limit-1]
+		b, err := json.Marshal(topRatedToken{List: list, After: &repository.TopRatedCursor{Rating: last.Rating, ID: last.RecordID}})
+		if err != nil {
+			return nil, err
+		}
+		page.NextPageToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+	if page.Records == nil {
+		page.Records = []model.RankedRecord{}
+	}
+	return page, nil
+}
@@ -0,0 +1,130 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+func TestTopRated(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	cfg := DefaultConfig()
+	cfg.BayesianPrior = 3
+	cfg.BayesianMinVotes = 2
+	cfg.Aggregators = map[model.RecordType]string{
+		model.RecordTypeSeries:  AggregatorBayesian,
+		model.RecordTypeEpisode: AggregatorTrimmed,
+	}
+	ctrl, err := NewWithConfig(repo, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rate := func(id model.RecordID, typ model.RecordType, values ...model.RatingValue) {
+		for i, v := range values {
+			if err := ctrl.PutRating(ctx, id, typ, &model.Rating{UserID: model.UserID(fmt.Sprintf("u%d", i)), Value: v}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	rate("a", model.RecordTypeMovie, 5, 4, 4)
+	rate("b", model.RecordTypeMovie, 5)
+	rate("c", model.RecordTypeMovie, 3, 3, 3, 3)
+	rate("d", model.RecordTypeMovie, 5, 3, 4)
+	rate("e", model.RecordTypeMovie, 2, 2)
+	// Rerating moves e up the rating index without a new vote.
+	rate("e", model.RecordTypeMovie, 5, 5)
+	rate("x", model.RecordTypeSeries, 5)
+	rate("y", model.RecordTypeSeries, 4, 4, 4, 4, 4, 4)
+	rate("p", model.RecordTypeEpisode, 1, 4, 4, 4, 4, 4, 4, 4, 4, 5)
+	rate("q", model.RecordTypeEpisode, 1, 1, 4, 4, 4, 4, 4, 4, 5, 5)
+	rate("r", model.RecordTypeEpisode, 5, 5)
+	if err := ctrl.RefreshTopRated(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Ratings since the refresh do not change scored lists.
+	rate("x", model.RecordTypeSeries, 1, 1, 1, 1)
+
+	list := func(typ model.RecordType, minVotes int64, limit int) []model.RankedRecord {
+		t.Helper()
+		var res []model.RankedRecord
+		token := ""
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatal("listing did not terminate")
+			}
+			page, err := ctrl.ListTopRated(ctx, typ, minVotes, limit, token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res = append(res, page.Records...)
+			if page.NextPageToken == "" {
+				return res
+			}
+			token = page.NextPageToken
+		}
+	}
+	ids := func(records []model.RankedRecord) string {
+		var s string
+		for _, r := range records {
+			s += string(r.RecordID)
+		}
+		return s
+	}
+
+	tests := []struct {
+		typ      model.RecordType
+		minVotes int64
+		limit    int
+		want     string
+	}{
+		// Movies are ranked by the mean, ties by ID.
+		{model.RecordTypeMovie, 0, 0, "beadc"},
+		{model.RecordTypeMovie, 0, 2, "beadc"},
+		{model.RecordTypeMovie, 3, 1, "adc"},
+		{model.RecordTypeMovie, 5, 0, ""},
+		// Series are ranked by the Bayesian average: x has
+		// (2*3+5)/3 = 3.67 and y (2*3+24)/8 = 3.75.
+		{model.RecordTypeSeries, 0, 0, "yx"},
+		{model.RecordTypeSeries, 0, 1, "yx"},
+		{model.RecordTypeSeries, 2, 0, "y"},
+		// Episodes are ranked by the trimmed mean: dropping a
+		// tenth at each end leaves p at 4, q at 3.75 and r at 5.
+		{model.RecordTypeEpisode, 0, 0, "rpq"},
+		{model.RecordTypeEpisode, 0, 2, "rpq"},
+		{model.RecordTypeEpisode, 3, 0, "pq"},
+	}
+	for _, tt := range tests {
+		if got := ids(list(tt.typ, tt.minVotes, tt.limit)); got != tt.want {
+			t.Errorf("%s with %d votes by %d: got %q, want %q", tt.typ, tt.minVotes, tt.limit, got, tt.want)
+		}
+	}
+
+	page, err := ctrl.ListTopRated(ctx, model.RecordTypeMovie, 0, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.ListTopRated(ctx, model.RecordTypeMovie, 3, 2, page.NextPageToken); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("token of another list: got %v, want %v", err, ErrInvalidPageToken)
+	}
+	page, err = ctrl.ListTopRated(ctx, model.RecordTypeEpisode, 0, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.ListTopRated(ctx, model.RecordTypeMovie, 0, 2, page.NextPageToken); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("token of a scored list: got %v, want %v", err, ErrInvalidPageToken)
+	}
+
+	// Rebuilding the aggregates keeps the ranking.
+	if err := repo.RebuildAggregates(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(list(model.RecordTypeMovie, 0, 0)); got != "beadc" {
+		t.Errorf("after rebuild: got %q, want %q", got, "beadc")
+	}
+}
@@ -0,0 +1,39 @@
+package rating
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// DefaultTrendingLimit is the number of trending records
+// returned when none is requested.
+const DefaultTrendingLimit = 10
+
+// ErrUnknownTrendingWindow is returned when trending records
+// are requested for a window that is not supported.
+var ErrUnknownTrendingWindow = errs.InvalidArgument("unknown trending window")
+
+// RefreshTrending recomputes the trending scores of all
+// records, which ListTrending serves until the next refresh.
+func (c *Controller) RefreshTrending(ctx context.Context) error {
+	return c.repo.RefreshTrending(ctx, c.now())
+}
+
+// ListTrending returns up to limit records of a type with the
+// highest trending scores in a window, as of the last
+// RefreshTrending.
+func (c *Controller) ListTrending(ctx context.Context, recordType model.RecordType, window model.TrendingWindow, limit int) ([]model.TrendingScore, error) {
+	if !recordType.IsRegistered() {
+		return nil, ErrUnknownRecordType.WithViolations(errs.FieldViolation{Field: "record_type", Description: fmt.Sprintf("must be one of %v", model.RecordTypes)})
+	}
+	if window.Duration() == 0 {
+		return nil, ErrUnknownTrendingWindow.WithViolations(errs.FieldViolation{Field: "window", Description: fmt.Sprintf("must be one of %v", model.TrendingWindows)})
+	}
+	if limit <= 0 {
+		limit = DefaultTrendingLimit
+	}
+	return c.repo.ListTrending(ctx, recordType, window, limit)
+}
@@ -0,0 +1,90 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+func TestTrending(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := New(memory.New())
+	ctrl.now = func() time.Time { return now }
+
+	for _, r := range []struct {
+		id    model.RecordID
+		typ   model.RecordType
+		user  model.UserID
+		value model.RatingValue
+		age   time.Duration
+	}{
+		// An old classic with many ratings, a new release and
+		// a well rated episode.
+		{"classic", model.RecordTypeMovie, "u1", 5, 20 * 24 * time.Hour},
+		{"classic", model.RecordTypeMovie, "u2", 5, 20 * 24 * time.Hour},
+		{"classic", model.RecordTypeMovie, "u3", 5, 20 * 24 * time.Hour},
+		{"new", model.RecordTypeMovie, "u1", 4, 0},
+		{"new", model.RecordTypeMovie, "u2", 4, 6 * time.Hour},
+		{"ep", model.RecordTypeEpisode, "u1", 5, time.Hour},
+	} {
+		rating := &model.Rating{UserID: r.user, Value: r.value, UpdatedAt: now.Add(-r.age)}
+		if err := ctrl.PutRating(ctx, r.id, r.typ, rating); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Scores are only visible after a refresh.
+	if got, err := ctrl.ListTrending(ctx, model.RecordTypeMovie, model.TrendingWindowDay, 0); err != nil || len(got) != 0 {
+		t.Fatalf("before refresh: got %v, %v, want no records", got, err)
+	}
+	if err := ctrl.RefreshTrending(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		window model.TrendingWindow
+		want   []model.RecordID
+		scores []float64
+	}{
+		// 4*1 + 4*2^(-6h/6h)
+		{model.TrendingWindowDay, []model.RecordID{"new"}, []float64{6}},
+		// The classic's ratings are 20 days old, out of the
+		// week.
+		{model.TrendingWindowWeek, []model.RecordID{"new"}, []float64{4 + 4*math.Exp2(-6.0/42)}},
+		// 3*5*2^(-20d/7.5d) is less than the new release's.
+		{model.TrendingWindowMonth, []model.RecordID{"new", "classic"}, []float64{4 + 4*math.Exp2(-6.0/180), 15 * math.Exp2(-20/7.5)}},
+	}
+	for _, tt := range tests {
+		got, err := ctrl.ListTrending(ctx, model.RecordTypeMovie, tt.window, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.window, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: got %v, want %v", tt.window, got, tt.want)
+		}
+		for i, s := range got {
+			if s.RecordID != tt.want[i] || math.Abs(s.Score-tt.scores[i]) > 1e-9 {
+				t.Errorf("%s: record %d = %s with %v, want %s with %v", tt.window, i, s.RecordID, s.Score, tt.want[i], tt.scores[i])
+			}
+			if !s.ComputedAt.Equal(now) {
+				t.Errorf("%s: computed at %v, want %v", tt.window, s.ComputedAt, now)
+			}
+		}
+	}
+
+	if got, _ := ctrl.ListTrending(ctx, model.RecordTypeMovie, model.TrendingWindowMonth, 1); len(got) != 1 {
+		t.Errorf("limit 1: got %d records", len(got))
+	}
+	if got, _ := ctrl.ListTrending(ctx, model.RecordTypeEpisode, model.TrendingWindowDay, 0); len(got) != 1 || got[0].RecordID != "ep" {
+		t.Errorf("episodes: got %v, want ep", got)
+	}
+	if _, err := ctrl.ListTrending(ctx, model.RecordTypeMovie, "1y", 0); !errors.Is(err, ErrUnknownTrendingWindow) {
+		t.Errorf("unknown window: got %v, want %v", err, ErrUnknownTrendingWindow)
+	}
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/internal/grpcutil"
+	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// Gateway defines a gRPC gateway for the metadata service.
+type Gateway struct {
+	registry discovery.Registry
+}
+
+// New creates a new gRPC gateway for the metadata service.
+func New(registry discovery.Registry) *Gateway {
+	return &Gateway{registry: registry}
+}
+
+// GetParent returns the parent of a record in the catalog,
+// such as the season of an episode, or a zero key if it has
+// none. It returns errs.ErrNotFound if the record is not in
+// the catalog.
+func (g *Gateway) GetParent(ctx context.Context, key model.RecordKey) (model.RecordKey, error) {
+	conn, err := grpcutil.ServiceConnection(ctx, "metadata", g.registry)
+	if err != nil {
+		return model.RecordKey{}, err
+	}
+
+	defer conn.Close()
+	client := gen.NewMetadataServiceClient(conn)
+
+	resp, err := client.GetMetadata(ctx, &gen.GetMetadataRequest{MovieId: string(key.ID)})
+	if err != nil {
+		return model.RecordKey{}, errs.FromGRPC(err)
+	}
+
+	m := resp.Metadata
+	if model.RecordType(m.Type) != key.Type || m.ParentId == "" {
+		return model.RecordKey{}, nil
+	}
+	return model.RecordKey{ID: model.RecordID(m.ParentId), Type: key.Type.ParentType()}, nil
+}
@@ -2,16 +2,15 @@ package grpc
 
 import (
 	"context"
-	"errors"
 
 	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/errs"
 	"github.com/phongld0308/movie-example/rating/internal/controller/rating"
 	model "github.com/phongld0308/movie-example/rating/pkg/model"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
-// Handler defines a gRPC API handler.
+// Handler defines a gRPC API handler. Requests are expected
+// to be validated by the validation interceptor.
 type Handler struct {
 	gen.UnimplementedRatingServiceServer
 	ctrl *rating.Controller
@@ -24,26 +23,180 @@ func New(ctrl *rating.Controller) *Handler {
 
 // GetAggregatedRating returns the aggregated rating for a recod.
 func (h *Handler) GetAggregatedRating(ctx context.Context, req *gen.GetAggregatedRatingRequest) (*gen.GetAggregatedRatingResponse, error) {
-	if req == nil || req.RecordId == "" || req.RecordType == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "nil req or empty id")
-	}
-	v, err := h.ctrl.GetAggregateRating(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType))
-	if err != nil && errors.Is(err, rating.ErrNotFound) {
-		return nil, status.Errorf(codes.NotFound, err.Error())
-	} else if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	v, err := h.ctrl.GetAggregateRating(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), req.RollUp, req.Aggregator)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
 	}
 
 	return &gen.GetAggregatedRatingResponse{RatingValue: v}, nil
 }
 
+// BatchGetAggregatedRatings returns the aggregated ratings of
+// the rated records, in request order.
+func (h *Handler) BatchGetAggregatedRatings(ctx context.Context, req *gen.BatchGetAggregatedRatingsRequest) (*gen.BatchGetAggregatedRatingsResponse, error) {
+	keys := recordKeys(req.Records)
+	values, err := h.ctrl.GetAggregateRatings(ctx, keys)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+
+	resp := &gen.BatchGetAggregatedRatingsResponse{}
+	for _, k := range keys {
+		if v, ok := values[k]; ok {
+			resp.Ratings = append(resp.Ratings, &gen.AggregatedRating{RecordId: string(k.ID), RecordType: string(k.Type), RatingValue: v.Value, RatingCount: v.Count})
+		}
+	}
+	return resp, nil
+}
+
+// recordKeys returns the distinct keys of records in order.
+func recordKeys(records []*gen.RecordRef) []model.RecordKey {
+	keys := make([]model.RecordKey, 0, len(records))
+	seen := map[model.RecordKey]bool{}
+	for _, r := range records {
+		if k := model.RecordKeyFromProto(r); !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
 // PutRating writes a rating for a given record.
 func (h *Handler) PutRating(ctx context.Context, req *gen.PutRatingRequest) (*gen.PutRatingResponse, error) {
-	if req == nil || req.RecordId == "" || req.UserId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "nil req or empty user id or record id")
-	}
 	if err := h.ctrl.PutRating(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), &model.Rating{UserID: model.UserID(req.UserId), Value: model.RatingValue(req.RatingValue)}); err != nil {
-		return nil, err
+		return nil, errs.ToGRPC(err)
 	}
 	return &gen.PutRatingResponse{}, nil
 }
+
+// GetRating returns the rating a user gave to a record.
+func (h *Handler) GetRating(ctx context.Context, req *gen.GetRatingRequest) (*gen.GetRatingResponse, error) {
+	r, err := h.ctrl.GetRating(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.UserID(req.UserId))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.GetRatingResponse{RatingValue: int32(r.Value), Moderation: model.ModerationToProto(&r.Moderation)}, nil
+}
+
+// BatchGetRatings returns the ratings a user gave to the
+// given records, in request order.
+func (h *Handler) BatchGetRatings(ctx context.Context, req *gen.BatchGetRatingsRequest) (*gen.BatchGetRatingsResponse, error) {
+	keys := recordKeys(req.Records)
+	ratings, err := h.ctrl.GetUserRatings(ctx, model.UserID(req.UserId), keys)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+
+	resp := &gen.BatchGetRatingsResponse{}
+	for _, k := range keys {
+		if r, ok := ratings[k]; ok {
+			resp.Ratings = append(resp.Ratings, &gen.UserRating{RecordId: string(k.ID), RecordType: string(k.Type), RatingValue: int32(r.Value)})
+		}
+	}
+	return resp, nil
+}
+
+// DeleteRating removes the rating a user gave to a record.
+func (h *Handler) DeleteRating(ctx context.Context, req *gen.DeleteRatingRequest) (*gen.DeleteRatingResponse, error) {
+	if err := h.ctrl.DeleteRating(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.UserID(req.UserId)); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.DeleteRatingResponse{}, nil
+}
+
+// GetRatingStats returns the rating statistics of a record.
+func (h *Handler) GetRatingStats(ctx context.Context, req *gen.GetRatingStatsRequest) (*gen.GetRatingStatsResponse, error) {
+	stats, err := h.ctrl.GetRatingStats(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), req.RollUp)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.GetRatingStatsResponse{Stats: model.StatsToProto(stats)}, nil
+}
+
+// ListTrending returns the records with the highest trending
+// scores.
+func (h *Handler) ListTrending(ctx context.Context, req *gen.ListTrendingRequest) (*gen.ListTrendingResponse, error) {
+	scores, err := h.ctrl.ListTrending(ctx, model.RecordType(req.RecordType), model.TrendingWindow(req.Window), int(req.Limit))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListTrendingResponse{}
+	for i := range scores {
+		resp.Records = append(resp.Records, model.TrendingScoreToProto(&scores[i]))
+	}
+	return resp, nil
+}
+
+// ListTopRated returns a page of the highest rated records.
+func (h *Handler) ListTopRated(ctx context.Context, req *gen.ListTopRatedRequest) (*gen.ListTopRatedResponse, error) {
+	page, err := h.ctrl.ListTopRated(ctx, model.RecordType(req.RecordType), req.MinVotes, int(req.Limit), req.PageToken)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListTopRatedResponse{NextPageToken: page.NextPageToken}
+	for i := range page.Records {
+		resp.Records = append(resp.Records, model.RankedRecordToProto(&page.Records[i]))
+	}
+	return resp, nil
+}
+
+// PutReview writes the review of a user's rating.
+func (h *Handler) PutReview(ctx context.Context, req *gen.PutReviewRequest) (*gen.PutReviewResponse, error) {
+	if err := h.ctrl.PutReview(ctx, &model.Review{
+		RecordID:   model.RecordID(req.RecordId),
+		RecordType: model.RecordType(req.RecordType),
+		UserID:     model.UserID(req.UserId),
+		Title:      req.Title,
+		Body:       req.Body,
+	}); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.PutReviewResponse{}, nil
+}
+
+// GetReview returns the review of a user for a record.
+func (h *Handler) GetReview(ctx context.Context, req *gen.GetReviewRequest) (*gen.GetReviewResponse, error) {
+	review, err := h.ctrl.GetReview(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.UserID(req.UserId))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.GetReviewResponse{Review: model.ReviewToProto(review)}, nil
+}
+
+// ListReviews returns a page of the reviews of a record.
+func (h *Handler) ListReviews(ctx context.Context, req *gen.ListReviewsRequest) (*gen.ListReviewsResponse, error) {
+	page, err := h.ctrl.ListReviews(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.ReviewSort(req.OrderBy), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListReviewsResponse{NextPageToken: page.NextPageToken}
+	for i := range page.Reviews {
+		resp.Reviews = append(resp.Reviews, model.ReviewToProto(&page.Reviews[i]))
+	}
+	return resp, nil
+}
+
+// VoteReview records whether a user found a review helpful.
+func (h *Handler) VoteReview(ctx context.Context, req *gen.VoteReviewRequest) (*gen.VoteReviewResponse, error) {
+	if err := h.ctrl.VoteReview(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.UserID(req.AuthorId), model.UserID(req.VoterId), req.Helpful); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.VoteReviewResponse{}, nil
+}
+
+// DeleteReviewVote withdraws the vote of a user on a review.
+func (h *Handler) DeleteReviewVote(ctx context.Context, req *gen.DeleteReviewVoteRequest) (*gen.DeleteReviewVoteResponse, error) {
+	if err := h.ctrl.DeleteReviewVote(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.UserID(req.AuthorId), model.UserID(req.VoterId)); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.DeleteReviewVoteResponse{}, nil
+}
+
+// ReportReview reports the review of a user as abusive.
+func (h *Handler) ReportReview(ctx context.Context, req *gen.ReportReviewRequest) (*gen.ReportReviewResponse, error) {
+	if err := h.ctrl.ReportReview(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.UserID(req.AuthorId), model.UserID(req.ReporterId), req.Reason); err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.ReportReviewResponse{}, nil
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/auth"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/rating/internal/controller/rating"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// ModerationHandler defines the gRPC handler of the rating
+// moderation admin API. Requests are expected to be validated
+// by the validation interceptor and authenticated by the auth
+// interceptor.
+type ModerationHandler struct {
+	gen.UnimplementedRatingModerationServiceServer
+	ctrl *rating.Controller
+}
+
+// NewModeration creates a rating moderation gRPC handler.
+func NewModeration(ctrl *rating.Controller) *ModerationHandler {
+	return &ModerationHandler{ctrl: ctrl}
+}
+
+// ListFlaggedItems returns a page of a moderation queue.
+func (h *ModerationHandler) ListFlaggedItems(ctx context.Context, req *gen.ListFlaggedItemsRequest) (*gen.ListFlaggedItemsResponse, error) {
+	page, err := h.ctrl.ListFlagged(ctx, model.ModerationKind(req.Kind), model.ModerationStatus(req.Status), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListFlaggedItemsResponse{NextPageToken: page.NextPageToken}
+	for i := range page.Items {
+		resp.Items = append(resp.Items, model.FlaggedItemToProto(&page.Items[i]))
+	}
+	return resp, nil
+}
+
+// SetModerationStatus changes the moderation status of a
+// rating or review on behalf of the authenticated moderator.
+func (h *ModerationHandler) SetModerationStatus(ctx context.Context, req *gen.SetModerationStatusRequest) (*gen.SetModerationStatusResponse, error) {
+	moderator, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, errs.ToGRPC(auth.ErrInvalidToken)
+	}
+	action, err := h.ctrl.SetModerationStatus(ctx, model.ModerationKind(req.Kind), model.RecordID(req.RecordId), model.RecordType(req.RecordType),
+		model.UserID(req.UserId), model.ModerationStatus(req.Status), req.Reason, model.UserID(moderator.Subject))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	return &gen.SetModerationStatusResponse{Action: model.ModerationActionToProto(action)}, nil
+}
+
+// ListModerationActions returns a page of the moderation audit
+// trail.
+func (h *ModerationHandler) ListModerationActions(ctx context.Context, req *gen.ListModerationActionsRequest) (*gen.ListModerationActionsResponse, error) {
+	page, err := h.ctrl.ListModerationActions(ctx, model.RecordID(req.RecordId), model.RecordType(req.RecordType), model.UserID(req.UserId), int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.ListModerationActionsResponse{NextPageToken: page.NextPageToken}
+	for i := range page.Actions {
+		resp.Actions = append(resp.Actions, model.ModerationActionToProto(&page.Actions[i]))
+	}
+	return resp, nil
+}
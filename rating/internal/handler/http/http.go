@@ -2,13 +2,16 @@ package http
 
 import (
 	"encoding/json"
-	"errors"
 	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/validation"
 	"github.com/phongld0308/movie-example/rating/internal/controller/rating"
 	model "github.com/phongld0308/movie-example/rating/pkg/model"
+	"google.golang.org/protobuf/proto"
 )
 
 // Handler defines a rating service controller.
@@ -21,29 +24,396 @@ func New(ctrl *rating.Controller) *Handler {
 	return &Handler{ctrl}
 }
 
-// Handle handles PUT and GET /rating requests.
-func (h *Handler) Handle(w http.ResponseWriter, req *http.Request) {
-	recordID := model.RecordID(req.FormValue("id"))
+// Register registers the rating API handlers on mux.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/rating", h.Handle)
+	mux.HandleFunc("/rating/stats", h.HandleStats)
+	mux.HandleFunc("/rating/trending", h.HandleTrending)
+	mux.HandleFunc("/rating/top", h.HandleTopRated)
+	mux.HandleFunc("/rating/reviews", h.HandleReviews)
+	mux.HandleFunc("/rating/reviews/votes", h.HandleReviewVotes)
+	mux.HandleFunc("/rating/reviews/reports", h.HandleReports)
+}
+
+// queryParams maps request message fields to the query
+// parameters they are decoded from.
+var queryParams = map[string]string{
+	"record_id":    "id",
+	"record_type":  "type",
+	"user_id":      "userId",
+	"rating_value": "value",
+	"roll_up":      "rollUp",
+	"min_votes":    "minVotes",
+	"order_by":     "orderBy",
+	"page_size":    "pageSize",
+	"page_token":   "pageToken",
+	"author_id":    "authorId",
+	"voter_id":     "voterId",
+	"reporter_id":  "reporterId",
+}
 
-	if recordID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+// request returns the validated message of req, reporting
+// violations under the query parameter names.
+func request(req *http.Request, decode validation.DecodeFunc) (proto.Message, error) {
+	m, err := validation.Request(req, decode)
+	if err != nil {
+		return nil, validation.Rename(err, queryParams)
+	}
+	return m, nil
+}
 
+// DecodeRequest decodes GET, PUT and DELETE /rating requests into
+// their validated proto counterparts. A GET request with a
+// userId asks for the rating of that user, otherwise rollUp
+// includes the ratings of child records in the aggregate and
+// aggregator picks the aggregation strategy.
+func DecodeRequest(req *http.Request) (proto.Message, error) {
+	switch req.Method {
+	case http.MethodGet:
+		if userID := req.FormValue("userId"); userID != "" {
+			return &gen.GetRatingRequest{
+				UserId:     userID,
+				RecordId:   req.FormValue("id"),
+				RecordType: req.FormValue("type"),
+			}, nil
+		}
+		rollUp, err := rollUpParam(req)
+		if err != nil {
+			return nil, err
+		}
+		return &gen.GetAggregatedRatingRequest{
+			RecordId:   req.FormValue("id"),
+			RecordType: req.FormValue("type"),
+			RollUp:     rollUp,
+			Aggregator: req.FormValue("aggregator"),
+		}, nil
+	case http.MethodPut:
+		v, err := int32Param(req, "value")
+		if err != nil {
+			return nil, err
+		}
+		return &gen.PutRatingRequest{
+			UserId:      req.FormValue("userId"),
+			RecordId:    req.FormValue("id"),
+			RecordType:  req.FormValue("type"),
+			RatingValue: v,
+		}, nil
+	case http.MethodDelete:
+		return &gen.DeleteRatingRequest{
+			UserId:     req.FormValue("userId"),
+			RecordId:   req.FormValue("id"),
+			RecordType: req.FormValue("type"),
+		}, nil
+	}
+	return nil, errs.InvalidArgument("unsupported method " + req.Method)
+}
+
+// int32Param parses the optional integer query parameter
+// name. Values out of the int32 range are rejected rather
+// than wrapped into it.
+func int32Param(req *http.Request, name string) (int32, error) {
+	v := req.FormValue(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		return 0, errs.InvalidArgument("invalid request", errs.FieldViolation{Field: name, Description: "must be an integer"})
+	}
+	return int32(n), nil
+}
+
+// rollUpParam parses the optional rollUp query parameter.
+func rollUpParam(req *http.Request) (bool, error) {
+	v := req.FormValue("rollUp")
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errs.InvalidArgument("invalid request", errs.FieldViolation{Field: "rollUp", Description: "must be true or false"})
+	}
+	return b, nil
+}
+
+// DecodeStatsRequest decodes GET /rating/stats requests.
+func DecodeStatsRequest(req *http.Request) (proto.Message, error) {
+	rollUp, err := rollUpParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &gen.GetRatingStatsRequest{
+		RecordId:   req.FormValue("id"),
+		RecordType: req.FormValue("type"),
+		RollUp:     rollUp,
+	}, nil
+}
+
+// HandleStats handles GET /rating/stats requests.
+func (h *Handler) HandleStats(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeStatsRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
 		return
 	}
 
-	recordType := model.RecordType(req.FormValue("type"))
-	if recordType == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	r := m.(*gen.GetRatingStatsRequest)
+	stats, err := h.ctrl.GetRatingStats(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), r.RollUp)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// DecodeTrendingRequest decodes GET /rating/trending
+// requests.
+func DecodeTrendingRequest(req *http.Request) (proto.Message, error) {
+	limit, err := int32Param(req, "limit")
+	if err != nil {
+		return nil, err
+	}
+	return &gen.ListTrendingRequest{
+		RecordType: req.FormValue("type"),
+		Window:     req.FormValue("window"),
+		Limit:      limit,
+	}, nil
+}
+
+// HandleTrending handles GET /rating/trending requests.
+func (h *Handler) HandleTrending(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeTrendingRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	r := m.(*gen.ListTrendingRequest)
+	scores, err := h.ctrl.ListTrending(req.Context(), model.RecordType(r.RecordType), model.TrendingWindow(r.Window), int(r.Limit))
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(scores); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// DecodeTopRatedRequest decodes GET /rating/top requests.
+func DecodeTopRatedRequest(req *http.Request) (proto.Message, error) {
+	r := &gen.ListTopRatedRequest{
+		RecordType: req.FormValue("type"),
+		PageToken:  req.FormValue("pageToken"),
+	}
+	var violations []errs.FieldViolation
+	if v := req.FormValue("minVotes"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			violations = append(violations, errs.FieldViolation{Field: "minVotes", Description: "must be an integer"})
+		}
+		r.MinVotes = n
+	}
+	if v := req.FormValue("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			violations = append(violations, errs.FieldViolation{Field: "limit", Description: "must be an integer"})
+		}
+		r.Limit = int32(n)
+	}
+	if len(violations) > 0 {
+		return nil, errs.InvalidArgument("invalid request", violations...)
+	}
+	return r, nil
+}
+
+// HandleTopRated handles GET /rating/top requests.
+func (h *Handler) HandleTopRated(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeTopRatedRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
 
+	r := m.(*gen.ListTopRatedRequest)
+	page, err := h.ctrl.ListTopRated(req.Context(), model.RecordType(r.RecordType), r.MinVotes, int(r.Limit), r.PageToken)
+	if err != nil {
+		errs.WriteHTTP(w, err)
 		return
 	}
 
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// DecodeReviewRequest decodes GET and PUT /rating/reviews
+// requests. A GET request with a userId asks for the review
+// of that user, otherwise for a page of the record's reviews.
+func DecodeReviewRequest(req *http.Request) (proto.Message, error) {
 	switch req.Method {
 	case http.MethodGet:
-		v, err := h.ctrl.GetAggregateRating(req.Context(), recordID, recordType)
+		if userID := req.FormValue("userId"); userID != "" {
+			return &gen.GetReviewRequest{
+				UserId:     userID,
+				RecordId:   req.FormValue("id"),
+				RecordType: req.FormValue("type"),
+			}, nil
+		}
+		pageSize, err := int32Param(req, "pageSize")
+		if err != nil {
+			return nil, err
+		}
+		return &gen.ListReviewsRequest{
+			RecordId:   req.FormValue("id"),
+			RecordType: req.FormValue("type"),
+			OrderBy:    req.FormValue("orderBy"),
+			PageSize:   pageSize,
+			PageToken:  req.FormValue("pageToken"),
+		}, nil
+	case http.MethodPut:
+		return &gen.PutReviewRequest{
+			UserId:     req.FormValue("userId"),
+			RecordId:   req.FormValue("id"),
+			RecordType: req.FormValue("type"),
+			Title:      req.FormValue("title"),
+			Body:       req.FormValue("body"),
+		}, nil
+	}
+	return nil, errs.InvalidArgument("unsupported method " + req.Method)
+}
 
-		if err != nil && errors.Is(err, rating.ErrNotFound) {
-			w.WriteHeader(http.StatusNotFound)
+// HandleReviews handles GET and PUT /rating/reviews requests.
+func (h *Handler) HandleReviews(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeReviewRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	var resp any
+	switch r := m.(type) {
+	case *gen.GetReviewRequest:
+		resp, err = h.ctrl.GetReview(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.UserID(r.UserId))
+	case *gen.ListReviewsRequest:
+		resp, err = h.ctrl.ListReviews(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.ReviewSort(r.OrderBy), int(r.PageSize), r.PageToken)
+	case *gen.PutReviewRequest:
+		err = h.ctrl.PutReview(req.Context(), &model.Review{
+			RecordID:   model.RecordID(r.RecordId),
+			RecordType: model.RecordType(r.RecordType),
+			UserID:     model.UserID(r.UserId),
+			Title:      r.Title,
+			Body:       r.Body,
+		})
+	}
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+	if resp == nil {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// DecodeReviewVoteRequest decodes PUT and DELETE
+// /rating/reviews/votes requests. A PUT request records the
+// helpful vote of voterId on the review of authorId.
+func DecodeReviewVoteRequest(req *http.Request) (proto.Message, error) {
+	switch req.Method {
+	case http.MethodPut:
+		helpful, err := strconv.ParseBool(req.FormValue("helpful"))
+		if err != nil {
+			return nil, errs.InvalidArgument("invalid request", errs.FieldViolation{Field: "helpful", Description: "must be true or false"})
+		}
+		return &gen.VoteReviewRequest{
+			RecordId:   req.FormValue("id"),
+			RecordType: req.FormValue("type"),
+			AuthorId:   req.FormValue("authorId"),
+			VoterId:    req.FormValue("voterId"),
+			Helpful:    helpful,
+		}, nil
+	case http.MethodDelete:
+		return &gen.DeleteReviewVoteRequest{
+			RecordId:   req.FormValue("id"),
+			RecordType: req.FormValue("type"),
+			AuthorId:   req.FormValue("authorId"),
+			VoterId:    req.FormValue("voterId"),
+		}, nil
+	}
+	return nil, errs.InvalidArgument("unsupported method " + req.Method)
+}
+
+// HandleReviewVotes handles PUT and DELETE
+// /rating/reviews/votes requests.
+func (h *Handler) HandleReviewVotes(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeReviewVoteRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	switch r := m.(type) {
+	case *gen.VoteReviewRequest:
+		err = h.ctrl.VoteReview(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.UserID(r.AuthorId), model.UserID(r.VoterId), r.Helpful)
+	case *gen.DeleteReviewVoteRequest:
+		err = h.ctrl.DeleteReviewVote(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.UserID(r.AuthorId), model.UserID(r.VoterId))
+	}
+	if err != nil {
+		errs.WriteHTTP(w, err)
+	}
+}
+
+// DecodeReportRequest decodes POST /rating/reviews/reports
+// requests, by which reporterId reports the review of
+// authorId.
+func DecodeReportRequest(req *http.Request) (proto.Message, error) {
+	if req.Method != http.MethodPost {
+		return nil, errs.InvalidArgument("unsupported method " + req.Method)
+	}
+	return &gen.ReportReviewRequest{
+		RecordId:   req.FormValue("id"),
+		RecordType: req.FormValue("type"),
+		AuthorId:   req.FormValue("authorId"),
+		ReporterId: req.FormValue("reporterId"),
+		Reason:     req.FormValue("reason"),
+	}, nil
+}
+
+// HandleReports handles POST /rating/reviews/reports requests.
+func (h *Handler) HandleReports(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeReportRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	r := m.(*gen.ReportReviewRequest)
+	if err := h.ctrl.ReportReview(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.UserID(r.AuthorId), model.UserID(r.ReporterId), r.Reason); err != nil {
+		errs.WriteHTTP(w, err)
+	}
+}
+
+// Handle handles GET, PUT and DELETE /rating requests.
+func (h *Handler) Handle(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+
+		return
+	}
+
+	switch r := m.(type) {
+	case *gen.GetAggregatedRatingRequest:
+		v, err := h.ctrl.GetAggregateRating(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), r.RollUp, r.Aggregator)
+		if err != nil {
+			errs.WriteHTTP(w, err)
 
 			return
 		}
@@ -52,24 +422,27 @@ func (h *Handler) Handle(w http.ResponseWriter, req *http.Request) {
 			log.Printf("Response encode error: %v\n", err)
 		}
 
-	case http.MethodPut:
-		userID := model.UserID(req.FormValue("userId"))
-
-		v, err := strconv.ParseFloat(req.FormValue("value"), 64)
-
+	case *gen.GetRatingRequest:
+		rating, err := h.ctrl.GetRating(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.UserID(r.UserId))
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			errs.WriteHTTP(w, err)
 
 			return
 		}
 
-		if err := h.ctrl.PutRating(req.Context(), recordID, recordType, &model.Rating{UserID: userID, Value: model.RatingValue(v)}); err != nil {
-			log.Printf("Repository put error: %v\n", err)
+		if err := json.NewEncoder(w).Encode(rating.Value); err != nil {
+			log.Printf("Response encode error: %v\n", err)
+		}
 
-			w.WriteHeader(http.StatusInternalServerError)
+	case *gen.PutRatingRequest:
+		rating := &model.Rating{UserID: model.UserID(r.UserId), Value: model.RatingValue(r.RatingValue)}
+		if err := h.ctrl.PutRating(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), rating); err != nil {
+			errs.WriteHTTP(w, err)
 		}
 
-	default:
-		w.WriteHeader(http.StatusBadRequest)
+	case *gen.DeleteRatingRequest:
+		if err := h.ctrl.DeleteRating(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.UserID(r.UserId)); err != nil {
+			errs.WriteHTTP(w, err)
+		}
 	}
 }
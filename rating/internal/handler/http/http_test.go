@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/auth"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	"github.com/phongld0308/movie-example/pkg/idempotency"
+	idempotencystore "github.com/phongld0308/movie-example/pkg/idempotency/memory"
+	"github.com/phongld0308/movie-example/pkg/validation"
+	"github.com/phongld0308/movie-example/rating/internal/controller/rating"
+	"github.com/phongld0308/movie-example/rating/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// violations returns the fields reported invalid for a
+// request to target, or nil if it is valid.
+func violations(t *testing.T, method, target string, decode validation.DecodeFunc) []string {
+	t.Helper()
+	_, err := request(httptest.NewRequest(method, target, nil), decode)
+	if err == nil {
+		return nil
+	}
+	var e *errs.Error
+	if !errors.As(err, &e) || e.Kind != errs.KindInvalidArgument {
+		t.Fatalf("%s %s: got %v, want invalid argument error", method, target, err)
+	}
+	var fields []string
+	for _, v := range e.Violations {
+		fields = append(fields, v.Field)
+	}
+	return fields
+}
+
+func TestDecodeParams(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		decode validation.DecodeFunc
+		want   []string
+	}{
+		{http.MethodPut, "/rating?id=1&type=movie&userId=u1&value=5", DecodeRequest, nil},
+		// 4294967301 wraps to 5 in an int32.
+		{http.MethodPut, "/rating?id=1&type=movie&userId=u1&value=4294967301", DecodeRequest, []string{"value"}},
+		{http.MethodPut, "/rating?id=1&type=movie&userId=u1&value=11", DecodeRequest, []string{"value"}},
+		// Violations name the query parameters.
+		{http.MethodPut, "/rating?type=book&value=5", DecodeRequest, []string{"userId", "id", "type"}},
+		{http.MethodGet, "/rating/trending?type=movie&window=day&limit=4294967306", DecodeTrendingRequest, []string{"limit"}},
+		{http.MethodGet, "/rating/top?type=movie&limit=4294967306", DecodeTopRatedRequest, []string{"limit"}},
+		{http.MethodGet, "/rating/top?type=movie&minVotes=x&limit=x", DecodeTopRatedRequest, []string{"minVotes", "limit"}},
+		{http.MethodGet, "/rating/reviews?id=1&type=movie&pageSize=4294967306", DecodeReviewRequest, []string{"pageSize"}},
+		{http.MethodGet, "/rating/moderation?kind=review&pageSize=4294967306", DecodeModerationRequest, []string{"pageSize"}},
+	}
+	for _, tt := range tests {
+		if got := violations(t, tt.method, tt.target, tt.decode); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: violations %v, want %v", tt.method, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestIdempotentPut(t *testing.T) {
+	mux := http.NewServeMux()
+	New(rating.New(memory.New())).Register(mux)
+	h := idempotency.Middleware(idempotencystore.New(), time.Hour, mux)
+
+	put := func(value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/rating?id=1&type=movie&userId=u1&value="+value, nil)
+		req.Header.Set(idempotency.HeaderName, "k1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	if w := put("5"); w.Code != http.StatusOK || w.Header().Get(idempotency.ReplayedHeader) != "" {
+		t.Fatalf("first PUT: status %d, replayed %q", w.Code, w.Header().Get(idempotency.ReplayedHeader))
+	}
+	if w := put("5"); w.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Errorf("retried PUT not replayed: status %d", w.Code)
+	}
+	if w := put("3"); w.Code != http.StatusConflict {
+		t.Errorf("PUT reusing the key: status %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func TestModerationAuth(t *testing.T) {
+	ctrl := rating.New(memory.New())
+	ctx := context.Background()
+	if err := ctrl.PutRating(ctx, "1", model.RecordTypeMovie, &model.Rating{UserID: "u1", Value: 1}); err != nil {
+		t.Fatal(err)
+	}
+	verifier := auth.NewVerifier([]byte("secret"))
+	mux := http.NewServeMux()
+	New(ctrl).RegisterModeration(mux)
+	h := auth.Middleware(verifier, auth.RoleModerator, mux)
+
+	put := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/rating/moderation?kind=rating&id=1&type=movie&userId=u1&status=hidden&reason=spam&moderatorId=forged", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	if w := put(""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous PUT: status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	token, err := verifier.Sign(auth.Claims{Subject: "mod1", Roles: []string{auth.RoleModerator}, ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := put(token); w.Code != http.StatusOK {
+		t.Fatalf("moderator PUT: status %d, body %s", w.Code, w.Body)
+	}
+
+	// The moderator is taken from the token, not the request.
+	page, err := ctrl.ListModerationActions(ctx, "1", model.RecordTypeMovie, "", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Actions) != 1 || page.Actions[0].ActorID != "mod1" {
+		t.Errorf("got actions %+v, want one by mod1", page.Actions)
+	}
+}
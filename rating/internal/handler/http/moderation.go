@@ -0,0 +1,120 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/auth"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// DecodeModerationRequest decodes GET and PUT /rating/moderation
+// requests. A GET request lists a moderation queue and a PUT
+// request changes the status of an item.
+func DecodeModerationRequest(req *http.Request) (proto.Message, error) {
+	switch req.Method {
+	case http.MethodGet:
+		pageSize, err := int32Param(req, "pageSize")
+		if err != nil {
+			return nil, err
+		}
+		return &gen.ListFlaggedItemsRequest{
+			Kind:      req.FormValue("kind"),
+			Status:    req.FormValue("status"),
+			PageSize:  pageSize,
+			PageToken: req.FormValue("pageToken"),
+		}, nil
+	case http.MethodPut:
+		return &gen.SetModerationStatusRequest{
+			Kind:       req.FormValue("kind"),
+			RecordId:   req.FormValue("id"),
+			RecordType: req.FormValue("type"),
+			UserId:     req.FormValue("userId"),
+			Status:     req.FormValue("status"),
+			Reason:     req.FormValue("reason"),
+		}, nil
+	}
+	return nil, errs.InvalidArgument("unsupported method " + req.Method)
+}
+
+// RegisterModeration registers the moderation admin API
+// handlers on mux. They expect requests authenticated by the
+// auth middleware, and must not be served on the public mux.
+func (h *Handler) RegisterModeration(mux *http.ServeMux) {
+	mux.HandleFunc("/rating/moderation", h.HandleModeration)
+	mux.HandleFunc("/rating/moderation/actions", h.HandleModerationActions)
+}
+
+// HandleModeration handles GET and PUT /rating/moderation
+// requests. Status changes are made on behalf of the
+// authenticated moderator.
+func (h *Handler) HandleModeration(w http.ResponseWriter, req *http.Request) {
+	moderator, ok := auth.FromContext(req.Context())
+	if !ok {
+		errs.WriteHTTP(w, auth.ErrInvalidToken)
+		return
+	}
+	m, err := request(req, DecodeModerationRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	var resp any
+	switch r := m.(type) {
+	case *gen.ListFlaggedItemsRequest:
+		resp, err = h.ctrl.ListFlagged(req.Context(), model.ModerationKind(r.Kind), model.ModerationStatus(r.Status), int(r.PageSize), r.PageToken)
+	case *gen.SetModerationStatusRequest:
+		resp, err = h.ctrl.SetModerationStatus(req.Context(), model.ModerationKind(r.Kind), model.RecordID(r.RecordId), model.RecordType(r.RecordType),
+			model.UserID(r.UserId), model.ModerationStatus(r.Status), r.Reason, model.UserID(moderator.Subject))
+	}
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
+
+// DecodeModerationActionsRequest decodes GET
+// /rating/moderation/actions requests.
+func DecodeModerationActionsRequest(req *http.Request) (proto.Message, error) {
+	pageSize, err := int32Param(req, "pageSize")
+	if err != nil {
+		return nil, err
+	}
+	return &gen.ListModerationActionsRequest{
+		RecordId:   req.FormValue("id"),
+		RecordType: req.FormValue("type"),
+		UserId:     req.FormValue("userId"),
+		PageSize:   pageSize,
+		PageToken:  req.FormValue("pageToken"),
+	}, nil
+}
+
+// HandleModerationActions handles GET /rating/moderation/actions
+// requests.
+func (h *Handler) HandleModerationActions(w http.ResponseWriter, req *http.Request) {
+	m, err := request(req, DecodeModerationActionsRequest)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	r := m.(*gen.ListModerationActionsRequest)
+	page, err := h.ctrl.ListModerationActions(req.Context(), model.RecordID(r.RecordId), model.RecordType(r.RecordType), model.UserID(r.UserId), int(r.PageSize), r.PageToken)
+	if err != nil {
+		errs.WriteHTTP(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Printf("Response encode error: %v\n", err)
+	}
+}
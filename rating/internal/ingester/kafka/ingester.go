@@ -17,7 +17,7 @@ type Ingester struct {
 
 // NewIngester creates a new Kafka ingester.
 func NewIngester(addr string, groupID string, topic string) (*Ingester, error) {
-	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{"bootstrap.servers": "localhost", "group.id": groupID, "auto.offset.reset": "earliest"})
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{"bootstrap.servers": addr, "group.id": groupID, "auto.offset.reset": "earliest"})
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +54,11 @@ func (i *Ingester) Ingest(ctx context.Context) (chan model.RatingEvent, error) {
 				continue
 
 			}
+			// Events without a timestamp are dated by their
+			// message.
+			if event.Timestamp.IsZero() {
+				event.Timestamp = msg.Timestamp
+			}
 
 			ch <- event
 		}
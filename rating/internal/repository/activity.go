@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// RatingActivity defines a rating written at a time, as seen
+// by abuse detection.
+type RatingActivity struct {
+	UserID model.UserID
+	Record model.RecordKey
+	Value  model.RatingValue
+	At     time.Time
+}
+
+// ActivityQuery defines the windows of the activity returned
+// when a rating is recorded. Windows end at the time of the
+// rating, so that late ratings are judged by what preceded
+// them.
+type ActivityQuery struct {
+	// RecordSince starts the window of the ratings of the
+	// record.
+	RecordSince time.Time
+	// UserSince starts the window of the ratings of the user.
+	UserSince time.Time
+	// NewAccountAge is the age below which the account of a
+	// user is new. Accounts are dated by their first rating,
+	// even if it was deleted since.
+	NewAccountAge time.Duration
+}
+
+// Activity defines the recent activity around a recorded
+// rating, including it.
+type Activity struct {
+	// NewAccount reports whether the user of the rating has a
+	// new account.
+	NewAccount bool
+	// RecordRatings counts the ratings of the record, and
+	// NewAccountRatings those by new accounts.
+	RecordRatings     int
+	NewAccountRatings int
+	// UserRatings lists the ratings of the user, oldest first.
+	UserRatings []RatingActivity
+}
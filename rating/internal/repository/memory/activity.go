@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/phongld0308/movie-example/rating/internal/repository"
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// RecordActivity records a rating for abuse detection and
+// returns the activity around it within the windows of the
+// query. The account of the user is dated by their first
+// recorded rating.
+func (r *Repository) RecordActivity(ctx context.Context, a repository.RatingActivity, q repository.ActivityQuery) (*repository.Activity, error) {
+	r.Lock()
+	defer r.Unlock()
+	first, ok := r.firstRated[a.UserID]
+	if !ok || a.At.Before(first) {
+		first = a.At
+		r.firstRated[a.UserID] = first
+	}
+	res := &repository.Activity{NewAccount: a.At.Sub(first) < q.NewAccountAge}
+
+	rec := record{a.Record.ID, a.Record.Type}
+	events := r.recordActivity[rec]
+	i := sort.Search(len(events), func(i int) bool { return events[i].at.After(a.At) })
+	events = slices.Insert(events, i, recordActivity{at: a.At, newAccount: res.NewAccount})
+	r.recordActivity[rec] = events
+	for _, e := range events[:i+1] {
+		if e.at.After(q.RecordSince) {
+			res.RecordRatings++
+			if e.newAccount {
+				res.NewAccountRatings++
+			}
+		}
+	}
+
+	history := r.userActivity[a.UserID]
+	i = sort.Search(len(history), func(i int) bool { return history[i].At.After(a.At) })
+	history = slices.Insert(history, i, a)
+	r.userActivity[a.UserID] = history
+	for _, h := range history[:i+1] {
+		if h.At.After(q.UserSince) {
+			res.UserRatings = append(res.UserRatings, h)
+		}
+	}
+	return res, nil
+}
+
+// SharePattern sets the rating pattern of a user as of at, or
+// clears it when pattern is empty, and returns the number of
+// users who showed it from since to at, including them.
+func (r *Repository) SharePattern(ctx context.Context, userID model.UserID, pattern string, at, since time.Time) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+	if old, ok := r.userPatterns[userID]; ok {
+		delete(r.patterns[old], userID)
+		if len(r.patterns[old]) == 0 {
+			delete(r.patterns, old)
+		}
+		delete(r.userPatterns, userID)
+	}
+	if pattern == "" {
+		return 0, nil
+	}
+
+	users := r.patterns[pattern]
+	if users == nil {
+		users = map[model.UserID]time.Time{}
+		r.patterns[pattern] = users
+	}
+	users[userID] = at
+	r.userPatterns[userID] = pattern
+	n := 0
+	for _, shown := range users {
+		if !shown.Before(since) && !shown.After(at) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteActivity drops the ratings and patterns recorded
+// before the given time.
+func (r *Repository) DeleteActivity(ctx context.Context, before time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+	for rec, events := range r.recordActivity {
+		i := sort.Search(len(events), func(i int) bool { return events[i].at.After(before) })
+		if i == len(events) {
+			delete(r.recordActivity, rec)
+		} else {
+			r.recordActivity[rec] = events[i:]
+		}
+	}
+	for userID, history := range r.userActivity {
+		i := sort.Search(len(history), func(i int) bool { return history[i].At.After(before) })
+		if i == len(history) {
+			delete(r.userActivity, userID)
+		} else {
+			r.userActivity[userID] = history[i:]
+		}
+	}
+	for pattern, users := range r.patterns {
+		for userID, shown := range users {
+			if shown.Before(before) {
+				delete(users, userID)
+				delete(r.userPatterns, userID)
+			}
+		}
+		if len(users) == 0 {
+			delete(r.patterns, pattern)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,10 @@ package memory
 
 import (
 	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/phongld0308/movie-example/rating/internal/repository"
 	model "github.com/phongld0308/movie-example/rating/pkg/model"
@@ -9,16 +13,85 @@ import (
 
 // Repository defines a rating repository.
 type Repository struct {
-	data map[model.RecordType]map[model.RecordID][]model.Rating
+	sync.RWMutex
+	data       map[model.RecordType]map[model.RecordID][]model.Rating
+	aggregates map[record]*model.Aggregate
+	// byRating lists the aggregates of each record type by
+	// decreasing mean rating, then record ID, for top-rated
+	// lists.
+	byRating map[model.RecordType][]*model.Aggregate
+	// scores lists the records of each type in their order
+	// as of the last PutTopRatedScores.
+	scores map[model.RecordType][]model.RankedRecord
+	// parents maps records to their parent record, and children
+	// maps records to their child records, as recorded from the
+	// metadata service by SetParent.
+	parents  map[record]record
+	children map[record][]record
+	trust    map[model.UserID]float64
+	trending map[model.TrendingWindow][]model.TrendingScore
+	reviews  map[record]map[model.UserID]*model.Review
+	// votes maps reviews to the helpfulness votes of each
+	// voter.
+	votes   map[reviewKey]map[model.UserID]bool
+	reports map[reviewKey]map[model.UserID]model.ReviewReport
+	actions []model.ModerationAction
+	// firstRated keeps when each user first rated a record,
+	// as recorded by RecordActivity, even if the rating was
+	// deleted since.
+	firstRated map[model.UserID]time.Time
+	// recordActivity and userActivity list the recent ratings
+	// of each record and user, oldest first.
+	recordActivity map[record][]recordActivity
+	userActivity   map[model.UserID][]repository.RatingActivity
+	// patterns maps the rating patterns of users to the users
+	// who last showed them, and when.
+	patterns     map[string]map[model.UserID]time.Time
+	userPatterns map[model.UserID]string
+}
+
+type record struct {
+	id  model.RecordID
+	typ model.RecordType
+}
+
+type recordActivity struct {
+	at         time.Time
+	newAccount bool
+}
+
+type reviewKey struct {
+	record
+	author model.UserID
 }
 
 // New creates a new memory repository.
 func New() *Repository {
-	return &Repository{map[model.RecordType]map[model.RecordID][]model.Rating{}}
+	return &Repository{
+		data:       map[model.RecordType]map[model.RecordID][]model.Rating{},
+		aggregates: map[record]*model.Aggregate{},
+		byRating:   map[model.RecordType][]*model.Aggregate{},
+		scores:     map[model.RecordType][]model.RankedRecord{},
+		parents:    map[record]record{},
+		children:   map[record][]record{},
+		trust:      map[model.UserID]float64{},
+		firstRated: map[model.UserID]time.Time{},
+
+		recordActivity: map[record][]recordActivity{},
+		userActivity:   map[model.UserID][]repository.RatingActivity{},
+		patterns:       map[string]map[model.UserID]time.Time{},
+		userPatterns:   map[model.UserID]string{},
+		trending:       map[model.TrendingWindow][]model.TrendingScore{},
+		reviews:        map[record]map[model.UserID]*model.Review{},
+		votes:          map[reviewKey]map[model.UserID]bool{},
+		reports:        map[reviewKey]map[model.UserID]model.ReviewReport{},
+	}
 }
 
 // Get retrivies all rating for a given record.
 func (r *Repository) Get(ctx context.Context, recordID model.RecordID, recordType model.RecordType) ([]model.Rating, error) {
+	r.RLock()
+	defer r.RUnlock()
 	if _, ok := r.data[recordType]; !ok {
 		return nil, repository.ErrNotFound
 	}
@@ -27,16 +100,651 @@ func (r *Repository) Get(ctx context.Context, recordID model.RecordID, recordTyp
 		return nil, repository.ErrNotFound
 	}
 
-	return r.data[recordType][recordID], nil
+	return append([]model.Rating(nil), r.data[recordType][recordID]...), nil
 }
 
-// Put adds a rating for given record.
+// Put adds a rating for given record, replacing an earlier
+// rating by the same user and keeping its moderation status.
+// A rating put with the held status is held instead, unless
+// the rating it replaces is already withheld, and the change
+// is audited. Ratings without UpdatedAt are stamped with the
+// current time. Only public ratings are aggregated.
 func (r *Repository) Put(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rating *model.Rating) error {
+	r.Lock()
+	defer r.Unlock()
 	if _, ok := r.data[recordType]; !ok {
 		r.data[recordType] = map[model.RecordID][]model.Rating{}
 	}
 
-	r.data[recordType][recordID] = append(r.data[recordType][recordID], *rating)
+	agg := r.aggregate(record{recordID, recordType})
+	r.unindex(agg)
+	defer r.index(agg)
+	ratings := r.data[recordType][recordID]
+	stored := *rating
+	stored.RecordID, stored.RecordType = recordID, recordType
+	stored.Moderation = model.Moderation{Status: model.ModerationStatusVisible}
+	if i := indexOf(ratings, rating.UserID); i >= 0 {
+		stored.Moderation = ratings[i].Moderation
+		if stored.Moderation.Status.Public() {
+			agg.Remove(ratings[i].Value)
+		}
+		ratings = append(ratings[:i:i], ratings[i+1:]...)
+	}
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = time.Now()
+	}
+	if rating.Moderation.Status == model.ModerationStatusHeld && stored.Moderation.Status.Public() {
+		m := rating.Moderation
+		r.audit(model.ModerationAction{
+			Kind: model.ModerationKindRating, RecordID: recordID, RecordType: recordType, UserID: rating.UserID,
+			From: stored.Moderation.Status, To: m.Status, Reason: m.Reason, ActorID: m.ModeratorID, CreatedAt: m.UpdatedAt,
+		})
+		stored.Moderation = m
+	}
+	r.data[recordType][recordID] = append(ratings, stored)
+	if stored.Moderation.Status.Public() {
+		agg.Add(rating.Value)
+	}
+	agg.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// Delete removes the rating of a user for a given record,
+// together with its review.
+func (r *Repository) Delete(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) error {
+	r.Lock()
+	defer r.Unlock()
+	ratings := r.data[recordType][recordID]
+	i := indexOf(ratings, userID)
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+
+	agg := r.aggregate(record{recordID, recordType})
+	r.unindex(agg)
+	defer r.index(agg)
+	if ratings[i].Moderation.Status.Public() {
+		agg.Remove(ratings[i].Value)
+	}
+	rec := record{recordID, recordType}
+	if review, ok := r.reviews[rec][userID]; ok {
+		delete(r.reviews[rec], userID)
+		delete(r.votes, reviewKey{rec, userID})
+		delete(r.reports, reviewKey{rec, userID})
+		if review.Moderation.Status.Public() {
+			agg.ReviewCount--
+		}
+	}
+	agg.UpdatedAt = time.Now()
+	r.data[recordType][recordID] = append(ratings[:i:i], ratings[i+1:]...)
+	return nil
+}
+
+func indexOf(ratings []model.Rating, userID model.UserID) int {
+	for i, rating := range ratings {
+		if rating.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// aggregate returns the aggregate of rec, creating and
+// indexing it if needed. The caller must hold the write lock.
+func (r *Repository) aggregate(rec record) *model.Aggregate {
+	agg, ok := r.aggregates[rec]
+	if !ok {
+		agg = model.NewAggregate(rec.id, rec.typ)
+		r.aggregates[rec] = agg
+		r.index(agg)
+	}
+	return agg
+}
+
+// higherRated reports whether a comes before b in byRating.
+func higherRated(a, b *model.Aggregate) bool {
+	if a.Mean() != b.Mean() {
+		return a.Mean() > b.Mean()
+	}
+	return a.RecordID < b.RecordID
+}
+
+// index inserts agg into byRating at its position.
+func (r *Repository) index(agg *model.Aggregate) {
+	aggs := r.byRating[agg.RecordType]
+	i := sort.Search(len(aggs), func(i int) bool { return !higherRated(aggs[i], agg) })
+	aggs = append(aggs, nil)
+	copy(aggs[i+1:], aggs[i:])
+	aggs[i] = agg
+	r.byRating[agg.RecordType] = aggs
+}
+
+// unindex removes agg from byRating before its totals change.
+func (r *Repository) unindex(agg *model.Aggregate) {
+	aggs := r.byRating[agg.RecordType]
+	i := sort.Search(len(aggs), func(i int) bool { return !higherRated(aggs[i], agg) })
+	if i < len(aggs) && aggs[i] == agg {
+		r.byRating[agg.RecordType] = append(aggs[:i], aggs[i+1:]...)
+	}
+}
+
+// GetAggregate returns the rating aggregate of a record.
+func (r *Repository) GetAggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	agg, ok := r.aggregates[record{recordID, recordType}]
+	if !ok || agg.Count == 0 {
+		return nil, repository.ErrNotFound
+	}
+	res := *agg
+	res.Histogram = append([]int64(nil), agg.Histogram...)
+	return &res, nil
+}
+
+// GetAggregates returns the rating aggregates of the given
+// records that have ratings, in no particular order.
+func (r *Repository) GetAggregates(ctx context.Context, keys []model.RecordKey) ([]*model.Aggregate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []*model.Aggregate
+	seen := map[record]bool{}
+	for _, k := range keys {
+		rec := record{k.ID, k.Type}
+		agg, ok := r.aggregates[rec]
+		if !ok || agg.Count == 0 || seen[rec] {
+			continue
+		}
+		seen[rec] = true
+		c := *agg
+		c.Histogram = append([]int64(nil), agg.Histogram...)
+		res = append(res, &c)
+	}
+	return res, nil
+}
+
+// GetUserRatings returns the ratings a user gave to the given
+// records, in no particular order.
+func (r *Repository) GetUserRatings(ctx context.Context, userID model.UserID, keys []model.RecordKey) ([]model.Rating, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []model.Rating
+	seen := map[record]bool{}
+	for _, k := range keys {
+		rec := record{k.ID, k.Type}
+		if seen[rec] {
+			continue
+		}
+		seen[rec] = true
+		ratings := r.data[k.Type][k.ID]
+		if i := indexOf(ratings, userID); i >= 0 {
+			res = append(res, ratings[i])
+		}
+	}
+	return res, nil
+}
+
+// SetParent records that a record is a child of another,
+// such as an episode of a season, for GetRollupAggregate. It
+// replaces the previous parent of the record, if any.
+func (r *Repository) SetParent(_ context.Context, child, parent model.RecordKey) error {
+	r.Lock()
+	defer r.Unlock()
+	rec, p := record{child.ID, child.Type}, record{parent.ID, parent.Type}
+	if old, ok := r.parents[rec]; ok {
+		if old == p {
+			return nil
+		}
+		siblings := r.children[old]
+		if i := slices.Index(siblings, rec); i >= 0 {
+			r.children[old] = slices.Delete(siblings, i, i+1)
+		}
+	}
+	r.parents[rec] = p
+	r.children[p] = append(r.children[p], rec)
+	return nil
+}
+
+// GetRollupAggregate returns the rating aggregate of a
+// record and all its descendants.
+func (r *Repository) GetRollupAggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	res := model.NewAggregate(recordID, recordType)
+	queue := []record{{recordID, recordType}}
+	for len(queue) > 0 {
+		rec := queue[0]
+		queue = append(queue[1:], r.children[rec]...)
+		if agg, ok := r.aggregates[rec]; ok {
+			res.Merge(agg)
+		}
+	}
+	if res.Count == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
+
+// RebuildAggregates recomputes every rating aggregate from
+// the stored public ratings and reviews.
+func (r *Repository) RebuildAggregates(ctx context.Context) error {
+	r.Lock()
+	defer r.Unlock()
+	now := time.Now()
+	r.aggregates = map[record]*model.Aggregate{}
+	r.byRating = map[model.RecordType][]*model.Aggregate{}
+	for recordType, records := range r.data {
+		for recordID, ratings := range records {
+			agg := model.NewAggregate(recordID, recordType)
+			for _, rating := range ratings {
+				if rating.Moderation.Status.Public() {
+					agg.Add(rating.Value)
+				}
+			}
+			for _, review := range r.reviews[record{recordID, recordType}] {
+				if review.Moderation.Status.Public() {
+					agg.ReviewCount++
+				}
+			}
+			agg.UpdatedAt = now
+			r.aggregates[record{recordID, recordType}] = agg
+			r.byRating[recordType] = append(r.byRating[recordType], agg)
+		}
+	}
+	for _, aggs := range r.byRating {
+		sort.Slice(aggs, func(i, j int) bool { return higherRated(aggs[i], aggs[j]) })
+	}
+	return nil
+}
+
+// ListTopRated returns a page of the records of a type with
+// at least the minimum number of ratings, highest rated first.
+func (r *Repository) ListTopRated(ctx context.Context, q repository.TopRatedQuery) ([]model.RankedRecord, error) {
+	r.RLock()
+	defer r.RUnlock()
+	minVotes := max(q.MinVotes, 1)
+	var res []model.RankedRecord
+	if q.Scored {
+		scores := r.scores[q.RecordType]
+		i := 0
+		if a := q.After; a != nil {
+			i = sort.Search(len(scores), func(i int) bool { return !a.Before(scores[i].Rating, scores[i].RecordID) })
+		}
+		for ; i < len(scores) && len(res) < q.Limit; i++ {
+			if scores[i].RatingCount >= minVotes {
+				res = append(res, scores[i])
+			}
+		}
+		return res, nil
+	}
+
+	aggs := r.byRating[q.RecordType]
+	i := 0
+	if a := q.After; a != nil {
+		i = sort.Search(len(aggs), func(i int) bool { return !a.Before(aggs[i].Mean(), aggs[i].RecordID) })
+	}
+	for ; i < len(aggs) && len(res) < q.Limit; i++ {
+		if agg := aggs[i]; agg.Count >= minVotes {
+			res = append(res, model.RankedRecord{RecordID: agg.RecordID, RecordType: agg.RecordType, Rating: agg.Mean(), RatingCount: agg.Count})
+		}
+	}
+	return res, nil
+}
+
+// ListAggregates returns the rating aggregates of the rated
+// records of a type, in no particular order.
+func (r *Repository) ListAggregates(ctx context.Context, recordType model.RecordType) ([]*model.Aggregate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []*model.Aggregate
+	for _, agg := range r.byRating[recordType] {
+		if agg.Count > 0 {
+			copied := *agg
+			copied.Histogram = slices.Clone(agg.Histogram)
+			res = append(res, &copied)
+		}
+	}
+	return res, nil
+}
+
+// PutTopRatedScores replaces the top-rated scores of a type.
+func (r *Repository) PutTopRatedScores(ctx context.Context, recordType model.RecordType, records []model.RankedRecord, now time.Time) error {
+	scores := slices.Clone(records)
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Rating != scores[j].Rating {
+			return scores[i].Rating > scores[j].Rating
+		}
+		return scores[i].RecordID < scores[j].RecordID
+	})
+	r.Lock()
+	defer r.Unlock()
+	r.scores[recordType] = scores
+	return nil
+}
+
+// GetTypeAggregate returns the rating aggregate of all
+// records of a type.
+func (r *Repository) GetTypeAggregate(ctx context.Context, recordType model.RecordType) (*model.Aggregate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	res := model.NewAggregate("", recordType)
+	for rec, agg := range r.aggregates {
+		if rec.typ == recordType {
+			res.Merge(agg)
+		}
+	}
+	return res, nil
+}
+
+// SetUserTrust sets the trust weight of a user's ratings.
+func (r *Repository) SetUserTrust(userID model.UserID, trust float64) {
+	r.Lock()
+	defer r.Unlock()
+	r.trust[userID] = trust
+}
+
+// UserTrust returns the trust weights of the given users that
+// have one.
+func (r *Repository) UserTrust(ctx context.Context, users []model.UserID) (map[model.UserID]float64, error) {
+	r.RLock()
+	defer r.RUnlock()
+	res := map[model.UserID]float64{}
+	for _, u := range users {
+		if w, ok := r.trust[u]; ok {
+			res[u] = w
+		}
+	}
+	return res, nil
+}
+
+// RefreshTrending recomputes the trending scores of every
+// window from the public ratings as of now.
+func (r *Repository) RefreshTrending(ctx context.Context, now time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+	var all []model.Rating
+	for _, records := range r.data {
+		for _, ratings := range records {
+			for _, rating := range ratings {
+				if rating.Moderation.Status.Public() {
+					all = append(all, rating)
+				}
+			}
+		}
+	}
+	for _, w := range model.TrendingWindows {
+		r.trending[w] = model.RankTrending(all, w, now)
+	}
+	return nil
+}
+
+// ListTrending returns up to limit records of a type with the
+// highest trending scores in a window, as of the last refresh.
+func (r *Repository) ListTrending(ctx context.Context, recordType model.RecordType, window model.TrendingWindow, limit int) ([]model.TrendingScore, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []model.TrendingScore
+	for _, s := range r.trending[window] {
+		if len(res) == limit {
+			break
+		}
+		if s.RecordType == recordType {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+// PutReview writes the review of a rating, keeping its
+// creation time if it is edited. It returns ErrNotFound if
+// the user has not rated the record.
+func (r *Repository) PutReview(ctx context.Context, review *model.Review, now time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+	rec := record{review.RecordID, review.RecordType}
+	if indexOf(r.data[rec.typ][rec.id], review.UserID) < 0 {
+		return repository.ErrNotFound
+	}
+
+	if old, ok := r.reviews[rec][review.UserID]; ok {
+		old.Title, old.Body, old.EditedAt = review.Title, review.Body, now
+		return nil
+	}
+	if r.reviews[rec] == nil {
+		r.reviews[rec] = map[model.UserID]*model.Review{}
+	}
+	stored := *review
+	stored.HelpfulVotes, stored.UnhelpfulVotes = 0, 0
+	stored.CreatedAt, stored.EditedAt = now, time.Time{}
+	stored.Moderation = model.Moderation{Status: model.ModerationStatusVisible}
+	r.reviews[rec][review.UserID] = &stored
+	r.aggregate(rec).ReviewCount++
+	return nil
+}
+
+// GetReview returns the review of a user for a record.
+func (r *Repository) GetReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (*model.Review, error) {
+	r.RLock()
+	defer r.RUnlock()
+	review, ok := r.reviews[record{recordID, recordType}][userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	res := *review
+	return &res, nil
+}
+
+// ListReviews returns a page of the public reviews of a
+// record.
+func (r *Repository) ListReviews(ctx context.Context, q repository.ReviewQuery) ([]model.Review, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []model.Review
+	for _, review := range r.reviews[record{q.RecordID, q.RecordType}] {
+		if !review.Moderation.Status.Public() || q.After != nil && !q.Before(q.After, q.CursorOf(review)) {
+			continue
+		}
+		res = append(res, *review)
+	}
+	sort.Slice(res, func(i, j int) bool { return q.Before(q.CursorOf(&res[i]), q.CursorOf(&res[j])) })
+	if len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+	return res, nil
+}
+
+// VoteReview records whether a user found a review helpful,
+// replacing their previous vote. It returns ErrNotFound if
+// the review does not exist.
+func (r *Repository) VoteReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID, helpful bool) error {
+	r.Lock()
+	defer r.Unlock()
+	key := reviewKey{record{recordID, recordType}, authorID}
+	review, ok := r.reviews[key.record][authorID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if old, ok := r.votes[key][voterID]; ok {
+		countVote(review, old, -1)
+	}
+	if r.votes[key] == nil {
+		r.votes[key] = map[model.UserID]bool{}
+	}
+	r.votes[key][voterID] = helpful
+	countVote(review, helpful, 1)
+	return nil
+}
+
+// DeleteReviewVote removes the vote of a user on a review. It
+// returns ErrNotFound if the user has not voted on the review.
+func (r *Repository) DeleteReviewVote(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID) error {
+	r.Lock()
+	defer r.Unlock()
+	key := reviewKey{record{recordID, recordType}, authorID}
+	old, ok := r.votes[key][voterID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.votes[key], voterID)
+	countVote(r.reviews[key.record][authorID], old, -1)
+	return nil
+}
+
+// countVote adds delta to the count of a vote.
+func countVote(review *model.Review, helpful bool, delta int64) {
+	if helpful {
+		review.HelpfulVotes += delta
+	} else {
+		review.UnhelpfulVotes += delta
+	}
+}
+
+// SetModeration changes the moderation status of a rating or
+// review, updating the aggregate of the record when the item
+// joins or leaves it, and audits the change. It returns
+// ErrNotFound if there is no such item and ErrFinalStatus if
+// it was removed.
+func (r *Repository) SetModeration(ctx context.Context, kind model.ModerationKind, recordID model.RecordID, recordType model.RecordType, userID model.UserID, m model.Moderation) (*model.ModerationAction, error) {
+	r.Lock()
+	defer r.Unlock()
+	rec := record{recordID, recordType}
+	var cur *model.Moderation
+	var value model.RatingValue
+	switch kind {
+	case model.ModerationKindRating:
+		ratings := r.data[recordType][recordID]
+		i := indexOf(ratings, userID)
+		if i < 0 {
+			return nil, repository.ErrNotFound
+		}
+		cur, value = &ratings[i].Moderation, ratings[i].Value
+	case model.ModerationKindReview:
+		review, ok := r.reviews[rec][userID]
+		if !ok {
+			return nil, repository.ErrNotFound
+		}
+		cur = &review.Moderation
+	}
+	if cur.Status.Final() {
+		return nil, repository.ErrFinalStatus
+	}
+
+	from := cur.Status
+	if from.Public() != m.Status.Public() {
+		agg := r.aggregate(rec)
+		r.unindex(agg)
+		defer r.index(agg)
+		switch {
+		case kind == model.ModerationKindReview && m.Status.Public():
+			agg.ReviewCount++
+		case kind == model.ModerationKindReview:
+			agg.ReviewCount--
+		case m.Status.Public():
+			agg.Add(value)
+		default:
+			agg.Remove(value)
+		}
+		agg.UpdatedAt = time.Now()
+	}
+	*cur = m
+	return r.audit(model.ModerationAction{
+		Kind: kind, RecordID: recordID, RecordType: recordType, UserID: userID,
+		From: from, To: m.Status, Reason: m.Reason, ActorID: m.ModeratorID, CreatedAt: m.UpdatedAt,
+	}), nil
+}
+
+// audit appends an action to the audit trail. The caller must
+// hold the write lock.
+func (r *Repository) audit(a model.ModerationAction) *model.ModerationAction {
+	a.ID = int64(len(r.actions) + 1)
+	r.actions = append(r.actions, a)
+	return &a
+}
 
+// ReportReview records a user's report of a review, replacing
+// their earlier report, and flags the review for moderation
+// if it is visible. It returns ErrNotFound if the review does
+// not exist.
+func (r *Repository) ReportReview(ctx context.Context, report *model.ReviewReport) error {
+	r.Lock()
+	defer r.Unlock()
+	key := reviewKey{record{report.RecordID, report.RecordType}, report.AuthorID}
+	review, ok := r.reviews[key.record][report.AuthorID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if r.reports[key] == nil {
+		r.reports[key] = map[model.UserID]model.ReviewReport{}
+	}
+	r.reports[key][report.ReporterID] = *report
+
+	if review.Moderation.Status != model.ModerationStatusVisible {
+		return nil
+	}
+	review.Moderation = model.Moderation{Status: model.ModerationStatusPending, Reason: report.Reason, UpdatedAt: report.CreatedAt}
+	r.audit(model.ModerationAction{
+		Kind: model.ModerationKindReview, RecordID: report.RecordID, RecordType: report.RecordType, UserID: report.AuthorID,
+		From: model.ModerationStatusVisible, To: model.ModerationStatusPending, Reason: report.Reason,
+		ActorID: report.ReporterID, CreatedAt: report.CreatedAt,
+	})
 	return nil
 }
+
+// ListFlagged returns a page of a moderation queue.
+func (r *Repository) ListFlagged(ctx context.Context, q repository.ModerationQuery) ([]model.FlaggedItem, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []model.FlaggedItem
+	add := func(item model.FlaggedItem) {
+		if item.Moderation.Status == q.Status && (q.After == nil || q.After.Before(repository.CursorOf(&item))) {
+			res = append(res, item)
+		}
+	}
+	switch q.Kind {
+	case model.ModerationKindRating:
+		for _, records := range r.data {
+			for _, ratings := range records {
+				for _, rating := range ratings {
+					add(model.FlaggedItem{
+						Kind: q.Kind, RecordID: rating.RecordID, RecordType: rating.RecordType, UserID: rating.UserID,
+						Value: rating.Value, Moderation: rating.Moderation,
+					})
+				}
+			}
+		}
+	case model.ModerationKindReview:
+		for rec, reviews := range r.reviews {
+			for _, review := range reviews {
+				add(model.FlaggedItem{
+					Kind: q.Kind, RecordID: review.RecordID, RecordType: review.RecordType, UserID: review.UserID,
+					Title: review.Title, Body: review.Body, Moderation: review.Moderation,
+					Reports: int64(len(r.reports[reviewKey{rec, review.UserID}])),
+				})
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return repository.CursorOf(&res[i]).Before(repository.CursorOf(&res[j])) })
+	if len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+	return res, nil
+}
+
+// ListModerationActions returns a page of the moderation audit
+// trail, newest first.
+func (r *Repository) ListModerationActions(ctx context.Context, q repository.ModerationActionQuery) ([]model.ModerationAction, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []model.ModerationAction
+	for i := len(r.actions) - 1; i >= 0 && len(res) < q.Limit; i-- {
+		a := r.actions[i]
+		switch {
+		case q.BeforeID > 0 && a.ID >= q.BeforeID:
+		case q.RecordID != "" && (a.RecordID != q.RecordID || a.RecordType != q.RecordType):
+		case q.UserID != "" && a.UserID != q.UserID:
+		default:
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// ErrFinalStatus is returned when the status of a removed
+// item is changed.
+var ErrFinalStatus = errors.New("moderation status is final")
+
+// ModerationQuery defines a page of a moderation queue: the
+// items of a kind with a status, least recently moderated
+// first, then by record type, record ID and user ID.
+type ModerationQuery struct {
+	Kind   model.ModerationKind
+	Status model.ModerationStatus
+
+	// Limit is the maximum number of items to return.
+	Limit int
+	// After, when set, starts the page right after the item at
+	// this position.
+	After *ModerationCursor
+}
+
+// ModerationCursor defines a position in a moderation queue.
+type ModerationCursor struct {
+	UpdatedAt  time.Time        `json:"updatedAt"`
+	RecordType model.RecordType `json:"recordType"`
+	RecordID   model.RecordID   `json:"recordId"`
+	UserID     model.UserID     `json:"userId"`
+}
+
+// CursorOf returns the position of an item in a moderation
+// queue.
+func CursorOf(item *model.FlaggedItem) *ModerationCursor {
+	return &ModerationCursor{UpdatedAt: item.Moderation.UpdatedAt, RecordType: item.RecordType, RecordID: item.RecordID, UserID: item.UserID}
+}
+
+// Before reports whether the item at a comes before the one
+// at b in a moderation queue.
+func (a *ModerationCursor) Before(b *ModerationCursor) bool {
+	switch {
+	case !a.UpdatedAt.Equal(b.UpdatedAt):
+		return a.UpdatedAt.Before(b.UpdatedAt)
+	case a.RecordType != b.RecordType:
+		return a.RecordType < b.RecordType
+	case a.RecordID != b.RecordID:
+		return a.RecordID < b.RecordID
+	}
+	return a.UserID < b.UserID
+}
+
+// ModerationActionQuery defines a page of the moderation audit
+// trail, newest first.
+type ModerationActionQuery struct {
+	// RecordID and RecordType, when set, limit the page to
+	// the actions on the ratings and reviews of a record.
+	RecordID   model.RecordID
+	RecordType model.RecordType
+	// UserID, when set, limits the page to the actions on the
+	// ratings and reviews of a user.
+	UserID model.UserID
+
+	// Limit is the maximum number of actions to return.
+	Limit int
+	// BeforeID, when set, starts the page right after the
+	// action with this ID.
+	BeforeID int64
+}
@@ -3,45 +3,70 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/phongld0308/movie-example/rating/internal/repository"
 	"github.com/phongld0308/movie-example/rating/pkg/model"
 )
 
-// Repository defines a MySQL-based rating repository.
+// Repository defines a MySQL-based rating repository. It
+// keeps the ratings, their moderation status and the
+// aggregates of the public ones; reviews, the moderation
+// audit trail, rankings and abuse detection need the
+// PostgreSQL repository.
 type Repository struct {
 	db *sql.DB
 }
 
 // New creates a new MySQL-based rating repository.
 func New() (*Repository, error) {
-	db, err := sql.Open("mysql", "root:password@/movieexample")
+	db, err := sql.Open("mysql", "root:password@/movieexample?parseTime=true")
 	if err != nil {
 		return nil, err
 	}
 	return &Repository{db: db}, nil
 }
 
+// ratingColumns lists the rating columns read by scanRating,
+// in order.
+const ratingColumns = "user_id, value, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP), status, moderation_reason, moderator_id, moderated_at"
+
+// scanRating scans ratingColumns into rating.
+func scanRating(row interface{ Scan(...any) error }, rating *model.Rating) error {
+	var userID string
+	var value int32
+	var moderatedAt sql.NullTime
+	m := &rating.Moderation
+	if err := row.Scan(&userID, &value, &rating.UpdatedAt, &m.Status, &m.Reason, &m.ModeratorID, &moderatedAt); err != nil {
+		return err
+	}
+	rating.UserID = model.UserID(userID)
+	rating.Value = model.RatingValue(value)
+	m.UpdatedAt = moderatedAt.Time
+	return nil
+}
+
 // Get retrieves all ratings for a given record.
 func (r *Repository) Get(ctx context.Context, recordID model.RecordID, recordType model.RecordType) ([]model.Rating, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT user_id, value FROM ratings WHERE record_id = ? AND record_type = ?", recordID, recordType)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+ratingColumns+" FROM ratings WHERE record_id = ? AND record_type = ?", recordID, recordType)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var res []model.Rating
 	for rows.Next() {
-		var userID string
-		var value int32
-		if err := rows.Scan(&userID, &value); err != nil {
+		rating := model.Rating{RecordID: recordID, RecordType: recordType}
+		if err := scanRating(rows, &rating); err != nil {
 			return nil, err
 		}
-
-		res = append(res, model.Rating{
-			UserID: model.UserID(userID),
-			Value:  model.RatingValue(value),
-		})
+		res = append(res, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	if len(res) == 0 {
@@ -51,9 +76,258 @@ func (r *Repository) Get(ctx context.Context, recordID model.RecordID, recordTyp
 	return res, nil
 }
 
-// Put adds a rating for a given record.
+// GetUserRatings returns the ratings a user gave to the given
+// records, whatever their moderation status, in no particular
+// order.
+func (r *Repository) GetUserRatings(ctx context.Context, userID model.UserID, keys []model.RecordKey) ([]model.Rating, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	where, args := recordKeys(keys)
+	rows, err := r.db.QueryContext(ctx, "SELECT record_id, record_type, "+ratingColumns+" FROM ratings WHERE user_id = ? AND ("+where+")", append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.Rating
+	for rows.Next() {
+		var rating model.Rating
+		var recordID, recordType string
+		if err := scanRating(prefixScanner{rows, []any{&recordID, &recordType}}, &rating); err != nil {
+			return nil, err
+		}
+		rating.RecordID, rating.RecordType = model.RecordID(recordID), model.RecordType(recordType)
+		res = append(res, rating)
+	}
+	return res, rows.Err()
+}
+
+// prefixScanner scans the columns of prefix before those
+// scanned by its caller.
+type prefixScanner struct {
+	row    interface{ Scan(...any) error }
+	prefix []any
+}
+
+func (s prefixScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(s.prefix, dest...)...)
+}
+
+// recordKeys returns a condition matching the given records
+// and its arguments.
+func recordKeys(keys []model.RecordKey) (string, []any) {
+	conds := make([]string, len(keys))
+	args := make([]any, 0, 2*len(keys))
+	for i, k := range keys {
+		conds[i] = "(record_id = ? AND record_type = ?)"
+		args = append(args, k.ID, k.Type)
+	}
+	return strings.Join(conds, " OR "), args
+}
+
+// Put adds a rating for a given record, replacing an earlier
+// rating by the same user and keeping its moderation status.
+// A rating put with the held status is held instead, unless
+// the rating it replaces is already withheld. Ratings without
+// UpdatedAt are stamped with the current time. The aggregate
+// of the record is updated in the same transaction if the
+// rating is public.
 func (r *Repository) Put(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rating *model.Rating) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO ratings (record_id, record_type, user_id, type) VALUES (?, ?, ?, ?)", recordID, recordType, rating.UserID, rating.Value)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	agg, err := lockAggregate(ctx, tx, recordID, recordType)
+	if err != nil {
+		return err
+	}
+	old, err := previousRating(ctx, tx, recordID, recordType, rating.UserID)
+	if err != nil {
+		return err
+	}
+
+	at := rating.UpdatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO ratings (record_id, record_type, user_id, value, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE value = VALUES(value), updated_at = VALUES(updated_at)", recordID, recordType, rating.UserID, rating.Value, at, at); err != nil {
+		return err
+	}
+
+	if old != nil && !old.Moderation.Status.Public() {
+		return tx.Commit()
+	}
+	if old != nil {
+		agg.Remove(old.Value)
+	}
+	if m := rating.Moderation; m.Status == model.ModerationStatusHeld {
+		if _, err := tx.ExecContext(ctx, "UPDATE ratings SET status = ?, moderation_reason = ?, moderator_id = ?, moderated_at = ? WHERE record_id = ? AND record_type = ? AND user_id = ?", m.Status, m.Reason, m.ModeratorID, m.UpdatedAt, recordID, recordType, rating.UserID); err != nil {
+			return err
+		}
+	} else {
+		agg.Add(rating.Value)
+	}
+	if err := saveAggregate(ctx, tx, agg); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete removes the rating of a user for a given record and
+// updates the aggregate of the record in the same
+// transaction.
+func (r *Repository) Delete(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	agg, err := lockAggregate(ctx, tx, recordID, recordType)
+	if err != nil {
+		return err
+	}
+	old, err := previousRating(ctx, tx, recordID, recordType, userID)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		return repository.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM ratings WHERE record_id = ? AND record_type = ? AND user_id = ?", recordID, recordType, userID); err != nil {
+		return err
+	}
+
+	if old.Moderation.Status.Public() {
+		agg.Remove(old.Value)
+	}
+	if err := saveAggregate(ctx, tx, agg); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockAggregate returns the aggregate of a record, creating
+// it if needed, and locks its row until the transaction ends.
+func lockAggregate(ctx context.Context, tx *sql.Tx, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error) {
+	agg := model.NewAggregate(recordID, recordType)
+	empty, _ := json.Marshal(agg.Histogram)
+	if _, err := tx.ExecContext(ctx, "INSERT IGNORE INTO rating_aggregates (record_id, record_type, histogram) VALUES (?, ?, ?)", recordID, recordType, empty); err != nil {
+		return nil, err
+	}
 
+	if err := scanAggregate(tx.QueryRowContext(ctx, "SELECT "+aggregateColumns+" FROM rating_aggregates WHERE record_id = ? AND record_type = ? FOR UPDATE", recordID, recordType), agg); err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// aggregateColumns lists the aggregate columns read by
+// scanAggregate, in order.
+const aggregateColumns = "rating_sum, rating_count, histogram, review_count, updated_at"
+
+// scanAggregate scans aggregateColumns into agg, followed by
+// any extra columns. The histogram is stored as a JSON array.
+func scanAggregate(row interface{ Scan(...any) error }, agg *model.Aggregate, extra ...any) error {
+	var histogram []byte
+	if err := row.Scan(append([]any{&agg.Sum, &agg.Count, &histogram, &agg.ReviewCount, &agg.UpdatedAt}, extra...)...); err != nil {
+		return err
+	}
+	return json.Unmarshal(histogram, &agg.Histogram)
+}
+
+// previousRating returns the value and moderation status of
+// the current rating of a user for a record, or nil if there
+// is none.
+func previousRating(ctx context.Context, tx *sql.Tx, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (*model.Rating, error) {
+	rating := model.Rating{RecordID: recordID, RecordType: recordType, UserID: userID}
+	err := tx.QueryRowContext(ctx, "SELECT value, status FROM ratings WHERE record_id = ? AND record_type = ? AND user_id = ?", recordID, recordType, userID).Scan(&rating.Value, &rating.Moderation.Status)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func saveAggregate(ctx context.Context, tx *sql.Tx, agg *model.Aggregate) error {
+	histogram, err := json.Marshal(agg.Histogram)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE rating_aggregates SET rating_sum = ?, rating_count = ?, histogram = ?, updated_at = CURRENT_TIMESTAMP WHERE record_id = ? AND record_type = ?", agg.Sum, agg.Count, histogram, agg.RecordID, agg.RecordType)
 	return err
 }
+
+// GetAggregate returns the rating aggregate of a record.
+func (r *Repository) GetAggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error) {
+	agg := model.NewAggregate(recordID, recordType)
+	err := scanAggregate(r.db.QueryRowContext(ctx, "SELECT "+aggregateColumns+" FROM rating_aggregates WHERE record_id = ? AND record_type = ? AND rating_count > 0", recordID, recordType), agg)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// GetAggregates returns the rating aggregates of the given
+// records that have ratings, in no particular order.
+func (r *Repository) GetAggregates(ctx context.Context, keys []model.RecordKey) ([]*model.Aggregate, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	where, args := recordKeys(keys)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+aggregateColumns+", record_id, record_type FROM rating_aggregates WHERE rating_count > 0 AND ("+where+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []*model.Aggregate
+	for rows.Next() {
+		agg := model.NewAggregate("", "")
+		if err := scanAggregate(rows, agg, &agg.RecordID, &agg.RecordType); err != nil {
+			return nil, err
+		}
+		res = append(res, agg)
+	}
+	return res, rows.Err()
+}
+
+// RebuildAggregates recomputes every rating aggregate from
+// the stored public ratings.
+func (r *Repository) RebuildAggregates(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rating_aggregates"); err != nil {
+		return err
+	}
+	var counts []string
+	for v := model.MinRatingValue; v <= model.MaxRatingValue; v++ {
+		counts = append(counts, fmt.Sprintf("SUM(public AND value = %d)", v))
+	}
+	// Reading the ratings with FOR SHARE blocks writers until
+	// the aggregates are rebuilt.
+	if _, err := tx.ExecContext(ctx, "INSERT INTO rating_aggregates (record_id, record_type, rating_sum, rating_count, histogram) SELECT record_id, record_type, COALESCE(SUM(IF(public, value, 0)), 0), SUM(public), JSON_ARRAY("+strings.Join(counts, ", ")+") FROM (SELECT record_id, record_type, value, "+publicStatus+" AS public FROM ratings FOR SHARE) r GROUP BY record_id, record_type"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// publicStatus matches the ratings that are aggregated, as
+// model.ModerationStatus.Public.
+const publicStatus = "status IN ('visible', 'pending')"
+
+// Close closes the database connection.
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
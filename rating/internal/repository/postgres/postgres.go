@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/phongld0308/movie-example/rating/internal/repository"
 	"github.com/phongld0308/movie-example/rating/pkg/model"
 )
@@ -36,10 +38,17 @@ func New(host string, port int, user, password, dbname string) (*Repository, err
 	return &Repository{db}, nil
 }
 
-// Get retrieves all ratings for a given record.
+// publicStatus matches the ratings and reviews that are
+// aggregated and listed, as model.ModerationStatus.Public.
+const publicStatus = "status IN ('visible', 'pending')"
+
+// Get retrieves all ratings for a given record, whatever
+// their moderation status.
 func (r *Repository) Get(ctx context.Context, recordID model.RecordID, recordType model.RecordType) ([]model.Rating, error) {
 	rows, err := r.db.QueryContext(ctx,
-		"SELECT user_id, value FROM ratings WHERE record_id = $1 AND record_type = $2",
+		`SELECT user_id, value, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP),
+		        status, moderation_reason, moderator_id, moderated_at
+		 FROM ratings WHERE record_id = $1 AND record_type = $2`,
 		recordID, recordType,
 	)
 	if err != nil {
@@ -51,15 +60,21 @@ func (r *Repository) Get(ctx context.Context, recordID model.RecordID, recordTyp
 	for rows.Next() {
 		var userID string
 		var value int32
-		if err := rows.Scan(&userID, &value); err != nil {
+		var updatedAt time.Time
+		var m model.Moderation
+		var moderatedAt sql.NullTime
+		if err := rows.Scan(&userID, &value, &updatedAt, &m.Status, &m.Reason, &m.ModeratorID, &moderatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan rating: %v", err)
 		}
+		m.UpdatedAt = moderatedAt.Time
 
 		ratings = append(ratings, model.Rating{
 			UserID:     model.UserID(userID),
 			RecordID:   recordID,
 			RecordType: recordType,
 			Value:      model.RatingValue(value),
+			UpdatedAt:  updatedAt,
+			Moderation: m,
 		})
 	}
 
@@ -74,21 +89,1190 @@ func (r *Repository) Get(ctx context.Context, recordID model.RecordID, recordTyp
 	return ratings, nil
 }
 
-// Put adds a rating for a given record.
+// Put adds a rating for a given record, replacing an earlier
+// rating by the same user and keeping its moderation status.
+// A rating put with the held status is held instead, unless
+// the rating it replaces is already withheld, and the change
+// is audited. Ratings without UpdatedAt are stamped with the
+// current time. The aggregate of the record is updated in the
+// same transaction if the rating is public.
 func (r *Repository) Put(ctx context.Context, recordID model.RecordID, recordType model.RecordType, rating *model.Rating) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO ratings (record_id, record_type, user_id, value)
-		 VALUES ($1, $2, $3, $4)
-		 ON CONFLICT (record_id, record_type, user_id) DO UPDATE 
-		 SET value = $4`,
-		recordID, recordType, rating.UserID, rating.Value,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	agg, err := lockAggregate(ctx, tx, recordID, recordType)
+	if err != nil {
+		return err
+	}
+	old, err := previousRating(ctx, tx, recordID, recordType, rating.UserID)
+	if err != nil {
+		return err
+	}
+
+	at := rating.UpdatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO ratings (record_id, record_type, user_id, value, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $5)
+		 ON CONFLICT (record_id, record_type, user_id) DO UPDATE
+		 SET value = $4, updated_at = $5`,
+		recordID, recordType, rating.UserID, rating.Value, at,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert rating: %v", err)
 	}
+
+	if old != nil && !old.Moderation.Status.Public() {
+		return tx.Commit()
+	}
+	from := model.ModerationStatusVisible
+	if old != nil {
+		from = old.Moderation.Status
+		agg.Remove(old.Value)
+	}
+	if m := rating.Moderation; m.Status == model.ModerationStatusHeld {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE ratings
+			 SET status = $4, moderation_reason = $5, moderator_id = $6, moderated_at = $7
+			 WHERE record_id = $1 AND record_type = $2 AND user_id = $3`,
+			recordID, recordType, rating.UserID, m.Status, m.Reason, m.ModeratorID, m.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to hold rating: %v", err)
+		}
+		err = insertAction(ctx, tx, &model.ModerationAction{
+			Kind: model.ModerationKindRating, RecordID: recordID, RecordType: recordType, UserID: rating.UserID,
+			From: from, To: m.Status, Reason: m.Reason, ActorID: m.ModeratorID, CreatedAt: m.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		agg.Add(rating.Value)
+	}
+	if err := saveAggregate(ctx, tx, agg); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordActivity records a rating for abuse detection and
+// returns the activity around it within the windows of the
+// query, in a single statement. The account of the user is
+// dated by the same upsert, from their first recorded rating.
+func (r *Repository) RecordActivity(ctx context.Context, a repository.RatingActivity, q repository.ActivityQuery) (*repository.Activity, error) {
+	// The statement reads the activity as of before the
+	// rating, which is added to the result afterwards.
+	rows, err := r.db.QueryContext(ctx,
+		`WITH account AS (
+		     INSERT INTO user_first_ratings AS f (user_id, first_rated_at) VALUES ($1, $5)
+		     ON CONFLICT (user_id) DO UPDATE SET first_rated_at = LEAST(f.first_rated_at, EXCLUDED.first_rated_at)
+		     RETURNING $5::timestamptz - first_rated_at < make_interval(secs => $8::float8) AS new_account
+		 ), activity AS (
+		     INSERT INTO rating_activity (user_id, record_id, record_type, value, rated_at, new_account)
+		     SELECT $1, $2, $3, $4, $5, new_account FROM account
+		     RETURNING new_account
+		 ), counts AS (
+		     SELECT COUNT(*) AS ratings, COUNT(*) FILTER (WHERE new_account) AS new_account_ratings
+		     FROM rating_activity
+		     WHERE record_type = $3 AND record_id = $2 AND rated_at > $6 AND rated_at <= $5
+		 )
+		 SELECT a.new_account, c.ratings, c.new_account_ratings, u.record_id, u.record_type, u.value, u.rated_at
+		 FROM activity a CROSS JOIN counts c
+		 LEFT JOIN LATERAL (
+		     SELECT id, record_id, record_type, value, rated_at FROM rating_activity
+		     WHERE user_id = $1 AND rated_at > $7 AND rated_at <= $5
+		 ) u ON true
+		 ORDER BY u.rated_at, u.id`,
+		a.UserID, a.Record.ID, a.Record.Type, a.Value, a.At, q.RecordSince, q.UserSince, q.NewAccountAge.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record rating activity: %v", err)
+	}
+	defer rows.Close()
+
+	res := &repository.Activity{}
+	for rows.Next() {
+		var recordID, recordType sql.NullString
+		var value sql.NullInt32
+		var ratedAt sql.NullTime
+		if err := rows.Scan(&res.NewAccount, &res.RecordRatings, &res.NewAccountRatings, &recordID, &recordType, &value, &ratedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rating activity: %v", err)
+		}
+		if ratedAt.Valid {
+			res.UserRatings = append(res.UserRatings, repository.RatingActivity{
+				UserID: a.UserID,
+				Record: model.RecordKey{ID: model.RecordID(recordID.String), Type: model.RecordType(recordType.String)},
+				Value:  model.RatingValue(value.Int32),
+				At:     ratedAt.Time,
+			})
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating activity: %v", err)
+	}
+
+	if a.At.After(q.RecordSince) {
+		res.RecordRatings++
+		if res.NewAccount {
+			res.NewAccountRatings++
+		}
+	}
+	if a.At.After(q.UserSince) {
+		res.UserRatings = append(res.UserRatings, a)
+	}
+	return res, nil
+}
+
+// SharePattern sets the rating pattern of a user as of at, or
+// clears it when pattern is empty, and returns the number of
+// users who showed it from since to at, including them.
+func (r *Repository) SharePattern(ctx context.Context, userID model.UserID, pattern string, at, since time.Time) (int, error) {
+	if pattern == "" {
+		if _, err := r.db.ExecContext(ctx, "DELETE FROM rating_patterns WHERE user_id = $1", userID); err != nil {
+			return 0, fmt.Errorf("failed to delete rating pattern: %v", err)
+		}
+		return 0, nil
+	}
+
+	// Keep the pattern lookup in sync with
+	// idx_rating_patterns_pattern.
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`WITH shown AS (
+		     INSERT INTO rating_patterns (user_id, pattern, shown_at) VALUES ($1, $2, $3)
+		     ON CONFLICT (user_id) DO UPDATE SET pattern = EXCLUDED.pattern, shown_at = EXCLUDED.shown_at
+		 )
+		 SELECT COUNT(*) + 1 FROM rating_patterns
+		 WHERE md5(pattern) = md5($2) AND pattern = $2 AND user_id <> $1 AND shown_at >= $4 AND shown_at <= $3`,
+		userID, pattern, at, since,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to share rating pattern: %v", err)
+	}
+	return n, nil
+}
+
+// DeleteActivity drops the ratings and patterns recorded
+// before the given time.
+func (r *Repository) DeleteActivity(ctx context.Context, before time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM rating_activity WHERE rated_at <= $1", before); err != nil {
+		return fmt.Errorf("failed to delete rating activity: %v", err)
+	}
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM rating_patterns WHERE shown_at < $1", before); err != nil {
+		return fmt.Errorf("failed to delete rating patterns: %v", err)
+	}
+	return nil
+}
+
+// Delete removes the rating of a user for a given record,
+// together with its review, and updates the aggregate of the
+// record in the same transaction.
+func (r *Repository) Delete(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	agg, err := lockAggregate(ctx, tx, recordID, recordType)
+	if err != nil {
+		return err
+	}
+	old, err := previousRating(ctx, tx, recordID, recordType, userID)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		return repository.ErrNotFound
+	}
+	reviewStatus, err := reviewStatus(ctx, tx, recordID, recordType, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM ratings WHERE record_id = $1 AND record_type = $2 AND user_id = $3",
+		recordID, recordType, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete rating: %v", err)
+	}
+
+	// The review goes with the rating.
+	if old.Moderation.Status.Public() {
+		agg.Remove(old.Value)
+	}
+	if reviewStatus != "" && reviewStatus.Public() {
+		agg.ReviewCount--
+	}
+	if err := saveAggregate(ctx, tx, agg); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockAggregate returns the aggregate of a record, creating
+// it if needed, and locks it until the transaction ends so
+// that concurrent writes to the record are serialized.
+func lockAggregate(ctx context.Context, tx *sql.Tx, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error) {
+	agg := model.NewAggregate(recordID, recordType)
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO rating_aggregates (record_id, record_type, histogram)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (record_id, record_type) DO NOTHING`,
+		recordID, recordType, pq.Array(agg.Histogram),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rating aggregate: %v", err)
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT rating_sum, rating_count, histogram, review_count, updated_at FROM rating_aggregates
+		 WHERE record_id = $1 AND record_type = $2
+		 FOR UPDATE`,
+		recordID, recordType,
+	).Scan(&agg.Sum, &agg.Count, pq.Array(&agg.Histogram), &agg.ReviewCount, &agg.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock rating aggregate: %v", err)
+	}
+	return agg, nil
+}
+
+// previousRating returns the value and moderation status of
+// the current rating of a user for a record, or nil if there
+// is none.
+func previousRating(ctx context.Context, tx *sql.Tx, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (*model.Rating, error) {
+	rating := model.Rating{RecordID: recordID, RecordType: recordType, UserID: userID}
+	err := tx.QueryRowContext(ctx,
+		"SELECT value::int, status FROM ratings WHERE record_id = $1 AND record_type = $2 AND user_id = $3",
+		recordID, recordType, userID,
+	).Scan(&rating.Value, &rating.Moderation.Status)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating: %v", err)
+	}
+	return &rating, nil
+}
+
+// reviewStatus returns the moderation status of the review of
+// a user for a record, or an empty status if there is none.
+func reviewStatus(ctx context.Context, tx *sql.Tx, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (model.ModerationStatus, error) {
+	var status model.ModerationStatus
+	err := tx.QueryRowContext(ctx,
+		"SELECT status FROM reviews WHERE record_id = $1 AND record_type = $2 AND user_id = $3",
+		recordID, recordType, userID,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query review: %v", err)
+	}
+	return status, nil
+}
+
+func saveAggregate(ctx context.Context, tx *sql.Tx, agg *model.Aggregate) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE rating_aggregates
+		 SET rating_sum = $3, rating_count = $4, histogram = $5, review_count = $6, updated_at = CURRENT_TIMESTAMP
+		 WHERE record_id = $1 AND record_type = $2`,
+		agg.RecordID, agg.RecordType, agg.Sum, agg.Count, pq.Array(agg.Histogram), agg.ReviewCount,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update rating aggregate: %v", err)
+	}
+	return nil
+}
+
+// GetAggregate returns the rating aggregate of a record.
+func (r *Repository) GetAggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error) {
+	agg := model.NewAggregate(recordID, recordType)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT rating_sum, rating_count, histogram, review_count, updated_at FROM rating_aggregates
+		 WHERE record_id = $1 AND record_type = $2 AND rating_count > 0`,
+		recordID, recordType,
+	).Scan(&agg.Sum, &agg.Count, pq.Array(&agg.Histogram), &agg.ReviewCount, &agg.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating aggregate: %v", err)
+	}
+	return agg, nil
+}
+
+// recordKeys returns the ids and types of keys as arrays, to
+// be matched with unnest.
+func recordKeys(keys []model.RecordKey) (any, any) {
+	ids := make([]string, len(keys))
+	types := make([]string, len(keys))
+	for i, k := range keys {
+		ids[i], types[i] = string(k.ID), string(k.Type)
+	}
+	return pq.Array(ids), pq.Array(types)
+}
+
+// GetAggregates returns the rating aggregates of the given
+// records that have ratings, in no particular order.
+func (r *Repository) GetAggregates(ctx context.Context, keys []model.RecordKey) ([]*model.Aggregate, error) {
+	ids, types := recordKeys(keys)
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT a.record_id, a.record_type, a.rating_sum, a.rating_count, a.histogram, a.review_count, a.updated_at
+		 FROM rating_aggregates a
+		 JOIN (SELECT DISTINCT * FROM unnest($1::text[], $2::text[])) k (record_id, record_type)
+		   ON a.record_id = k.record_id AND a.record_type = k.record_type
+		 WHERE a.rating_count > 0`,
+		ids, types,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating aggregates: %v", err)
+	}
+	defer rows.Close()
+
+	var res []*model.Aggregate
+	for rows.Next() {
+		agg := model.NewAggregate("", "")
+		if err := rows.Scan(&agg.RecordID, &agg.RecordType, &agg.Sum, &agg.Count, pq.Array(&agg.Histogram), &agg.ReviewCount, &agg.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rating aggregate: %v", err)
+		}
+		res = append(res, agg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating aggregates: %v", err)
+	}
+	return res, nil
+}
+
+// GetUserRatings returns the ratings a user gave to the given
+// records, whatever their moderation status, in no particular
+// order.
+func (r *Repository) GetUserRatings(ctx context.Context, userID model.UserID, keys []model.RecordKey) ([]model.Rating, error) {
+	ids, types := recordKeys(keys)
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT r.record_id, r.record_type, r.value, COALESCE(r.updated_at, r.created_at, CURRENT_TIMESTAMP),
+		        r.status, r.moderation_reason, r.moderator_id, r.moderated_at
+		 FROM ratings r
+		 JOIN (SELECT DISTINCT * FROM unnest($2::text[], $3::text[])) k (record_id, record_type)
+		   ON r.record_id = k.record_id AND r.record_type = k.record_type
+		 WHERE r.user_id = $1`,
+		userID, ids, types,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ratings: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.Rating
+	for rows.Next() {
+		rating := model.Rating{UserID: userID}
+		var value int32
+		var moderatedAt sql.NullTime
+		m := &rating.Moderation
+		if err := rows.Scan(&rating.RecordID, &rating.RecordType, &value, &rating.UpdatedAt, &m.Status, &m.Reason, &m.ModeratorID, &moderatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %v", err)
+		}
+		rating.Value = model.RatingValue(value)
+		m.UpdatedAt = moderatedAt.Time
+		res = append(res, rating)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ratings: %v", err)
+	}
+	return res, nil
+}
+
+// GetRollupAggregate returns the rating aggregate of a record
+// and all its descendants, such as the seasons and episodes
+// of a series.
+func (r *Repository) GetRollupAggregate(ctx context.Context, recordID model.RecordID, recordType model.RecordType) (*model.Aggregate, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`WITH RECURSIVE tree (id, record_type) AS (
+		     SELECT id, record_type FROM movies WHERE id = $1 AND record_type = $2
+		     UNION ALL
+		     SELECT m.id, m.record_type FROM movies m JOIN tree t ON m.parent_id = t.id
+		 )
+		 SELECT a.rating_sum, a.rating_count, a.histogram, a.review_count, a.updated_at
+		 FROM rating_aggregates a
+		 JOIN tree t ON a.record_id = t.id AND a.record_type = t.record_type`,
+		recordID, recordType,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating aggregates: %v", err)
+	}
+	defer rows.Close()
+
+	res := model.NewAggregate(recordID, recordType)
+	for rows.Next() {
+		agg := model.NewAggregate(recordID, recordType)
+		if err := rows.Scan(&agg.Sum, &agg.Count, pq.Array(&agg.Histogram), &agg.ReviewCount, &agg.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rating aggregate: %v", err)
+		}
+		res.Merge(agg)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating aggregates: %v", err)
+	}
+
+	if res.Count == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return res, nil
+}
+
+// ListTopRated returns a page of the records of a type with
+// at least the minimum number of ratings, highest rated first.
+// Mean rankings are served by idx_rating_aggregates_mean and
+// scored rankings by idx_top_rated_scores_rank.
+func (r *Repository) ListTopRated(ctx context.Context, q repository.TopRatedQuery) ([]model.RankedRecord, error) {
+	// Keep the mean expression in sync with
+	// idx_rating_aggregates_mean.
+	table, rating := "rating_aggregates", "rating_sum::float8 / rating_count"
+	if q.Scored {
+		table, rating = "top_rated_scores", "rating"
+	}
+	args := []any{q.RecordType, max(q.MinVotes, 1), q.Limit}
+	where := ""
+	if q.After != nil {
+		args = append(args, q.After.Rating, q.After.ID)
+		where = fmt.Sprintf("AND (%s < $4 OR (%[1]s = $4 AND record_id > $5))", rating)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT record_id, `+rating+`, rating_count
+		 FROM `+table+`
+		 WHERE record_type = $1 AND rating_count > 0 AND rating_count >= $2 `+where+`
+		 ORDER BY `+rating+` DESC, record_id
+		 LIMIT $3`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top rated records: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.RankedRecord
+	for rows.Next() {
+		rr := model.RankedRecord{RecordType: q.RecordType}
+		if err := rows.Scan(&rr.RecordID, &rr.Rating, &rr.RatingCount); err != nil {
+			return nil, fmt.Errorf("failed to scan top rated record: %v", err)
+		}
+		res = append(res, rr)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating top rated records: %v", err)
+	}
+	return res, nil
+}
+
+// ListAggregates returns the rating aggregates of the rated
+// records of a type, in no particular order.
+func (r *Repository) ListAggregates(ctx context.Context, recordType model.RecordType) ([]*model.Aggregate, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT record_id, rating_sum, rating_count, histogram, review_count, updated_at
+		 FROM rating_aggregates
+		 WHERE record_type = $1 AND rating_count > 0`,
+		recordType,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating aggregates: %v", err)
+	}
+	defer rows.Close()
+
+	var res []*model.Aggregate
+	for rows.Next() {
+		agg := model.NewAggregate("", recordType)
+		if err := rows.Scan(&agg.RecordID, &agg.Sum, &agg.Count, pq.Array(&agg.Histogram), &agg.ReviewCount, &agg.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rating aggregate: %v", err)
+		}
+		res = append(res, agg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating aggregates: %v", err)
+	}
+	return res, nil
+}
+
+// PutTopRatedScores replaces the top-rated scores of a type in
+// a single transaction, so that readers see either list whole.
+func (r *Repository) PutTopRatedScores(ctx context.Context, recordType model.RecordType, records []model.RankedRecord, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM top_rated_scores WHERE record_type = $1", recordType); err != nil {
+		return fmt.Errorf("failed to delete top rated scores: %v", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("top_rated_scores",
+		"record_type", "record_id", "rating", "rating_count", "computed_at"))
+	if err != nil {
+		return fmt.Errorf("failed to prepare top rated scores copy: %v", err)
+	}
+	defer stmt.Close()
+	for _, rr := range records {
+		if _, err := stmt.ExecContext(ctx, recordType, rr.RecordID, rr.Rating, rr.RatingCount, now); err != nil {
+			return fmt.Errorf("failed to copy top rated score: %v", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to copy top rated scores: %v", err)
+	}
+	return tx.Commit()
+}
+
+// GetTypeAggregate returns the rating aggregate of all
+// records of a type.
+func (r *Repository) GetTypeAggregate(ctx context.Context, recordType model.RecordType) (*model.Aggregate, error) {
+	agg := model.NewAggregate("", recordType)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(rating_sum), 0), COALESCE(SUM(rating_count), 0)
+		 FROM rating_aggregates
+		 WHERE record_type = $1`,
+		recordType,
+	).Scan(&agg.Sum, &agg.Count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating aggregates: %v", err)
+	}
+	return agg, nil
+}
+
+// UserTrust returns the trust weights of the given users that
+// have one.
+func (r *Repository) UserTrust(ctx context.Context, users []model.UserID) (map[model.UserID]float64, error) {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = string(u)
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT user_id, trust FROM user_trust WHERE user_id = ANY($1)",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user trust: %v", err)
+	}
+	defer rows.Close()
+
+	res := map[model.UserID]float64{}
+	for rows.Next() {
+		var userID model.UserID
+		var trust float64
+		if err := rows.Scan(&userID, &trust); err != nil {
+			return nil, fmt.Errorf("failed to scan user trust: %v", err)
+		}
+		res[userID] = trust
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user trust: %v", err)
+	}
+	return res, nil
+}
+
+// RebuildAggregates recomputes every rating aggregate from
+// the stored public ratings and reviews.
+func (r *Repository) RebuildAggregates(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Block writers so that no rating is missed while the
+	// aggregates are recomputed.
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE ratings, reviews, rating_aggregates IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock ratings: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rating_aggregates"); err != nil {
+		return fmt.Errorf("failed to delete rating aggregates: %v", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO rating_aggregates (record_id, record_type, rating_sum, rating_count, histogram, review_count)
+		 SELECT record_id, record_type, COALESCE(SUM(value::int) FILTER (WHERE public), 0),
+		        COUNT(*) FILTER (WHERE public), `+histogramExpr+`, COUNT(*) FILTER (WHERE reviewed)
+		 FROM (
+		     SELECT r.record_id, r.record_type, r.value, r.`+publicStatus+` AS public, v.`+publicStatus+` AS reviewed
+		     FROM ratings r
+		     LEFT JOIN reviews v USING (record_id, record_type, user_id)
+		 ) r
+		 GROUP BY record_id, record_type`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild rating aggregates: %v", err)
+	}
+	return tx.Commit()
+}
+
+// histogramExpr counts the public ratings of each value of a
+// group.
+var histogramExpr = func() string {
+	var counts []string
+	for v := model.MinRatingValue; v <= model.MaxRatingValue; v++ {
+		counts = append(counts, fmt.Sprintf("COUNT(*) FILTER (WHERE public AND value::int = %d)", v))
+	}
+	return "ARRAY[" + strings.Join(counts, ", ") + "]::bigint[]"
+}()
+
+// RefreshTrending recomputes the trending scores of every
+// window as of now. Readers see the previous scores until the
+// new ones are committed.
+func (r *Repository) RefreshTrending(ctx context.Context, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM trending_scores"); err != nil {
+		return fmt.Errorf("failed to delete trending scores: %v", err)
+	}
+	for _, w := range model.TrendingWindows {
+		// Weights halve every half-life of age, as in
+		// model.TrendingWindow.DecayWeight.
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO trending_scores (time_window, record_type, record_id, score, weight, computed_at)
+			 SELECT $1, record_type, record_id, SUM(value * weight), SUM(weight), $2::timestamptz
+			 FROM (
+			     SELECT record_type, record_id, value::float8 AS value,
+			            POWER(2, -GREATEST(EXTRACT(EPOCH FROM $2::timestamptz - rated_at), 0) / $4::float8) AS weight
+			     FROM (
+			         SELECT record_type, record_id, value, COALESCE(updated_at, created_at) AS rated_at
+			         FROM ratings
+			         WHERE `+publicStatus+`
+			     ) r
+			     WHERE rated_at >= $2::timestamptz - make_interval(secs => $3::float8)
+			 ) w
+			 GROUP BY record_type, record_id`,
+			w, now, w.Duration().Seconds(), w.HalfLife().Seconds(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to compute %s trending scores: %v", w, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListTrending returns up to limit records of a type with the
+// highest trending scores in a window, as of the last refresh.
+func (r *Repository) ListTrending(ctx context.Context, recordType model.RecordType, window model.TrendingWindow, limit int) ([]model.TrendingScore, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT record_id, score, weight, computed_at FROM trending_scores
+		 WHERE time_window = $1 AND record_type = $2
+		 ORDER BY score DESC, record_id
+		 LIMIT $3`,
+		window, recordType, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending scores: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.TrendingScore
+	for rows.Next() {
+		s := model.TrendingScore{RecordType: recordType}
+		if err := rows.Scan(&s.RecordID, &s.Score, &s.Weight, &s.ComputedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trending score: %v", err)
+		}
+		res = append(res, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trending scores: %v", err)
+	}
+	return res, nil
+}
+
+// PutReview writes the review of a rating, keeping its
+// creation time and moderation status if it is edited. It returns ErrNotFound if
+// the user has not rated the record.
+func (r *Repository) PutReview(ctx context.Context, review *model.Review, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	agg, err := lockAggregate(ctx, tx, review.RecordID, review.RecordType)
+	if err != nil {
+		return err
+	}
+	old, err := previousRating(ctx, tx, review.RecordID, review.RecordType, review.UserID)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		return repository.ErrNotFound
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE reviews SET title = $4, body = $5, edited_at = $6
+		 WHERE record_id = $1 AND record_type = $2 AND user_id = $3`,
+		review.RecordID, review.RecordType, review.UserID, review.Title, review.Body, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update review: %v", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update review: %v", err)
+	} else if n > 0 {
+		return tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO reviews (record_id, record_type, user_id, title, body, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		review.RecordID, review.RecordType, review.UserID, review.Title, review.Body, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert review: %v", err)
+	}
+	agg.ReviewCount++
+	if err := saveAggregate(ctx, tx, agg); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// reviewColumns lists the review columns read by scanReview.
+const reviewColumns = "record_id, record_type, user_id, title, body, helpful_votes, unhelpful_votes, created_at, edited_at, " +
+	"status, moderation_reason, moderator_id, moderated_at"
+
+func scanReview(scan func(dest ...any) error) (*model.Review, error) {
+	var review model.Review
+	var editedAt, moderatedAt sql.NullTime
+	m := &review.Moderation
+	if err := scan(&review.RecordID, &review.RecordType, &review.UserID, &review.Title, &review.Body,
+		&review.HelpfulVotes, &review.UnhelpfulVotes, &review.CreatedAt, &editedAt,
+		&m.Status, &m.Reason, &m.ModeratorID, &moderatedAt); err != nil {
+		return nil, err
+	}
+	review.EditedAt, m.UpdatedAt = editedAt.Time, moderatedAt.Time
+	return &review, nil
+}
+
+// GetReview returns the review of a user for a record.
+func (r *Repository) GetReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, userID model.UserID) (*model.Review, error) {
+	review, err := scanReview(r.db.QueryRowContext(ctx,
+		`SELECT `+reviewColumns+` FROM reviews
+		 WHERE record_id = $1 AND record_type = $2 AND user_id = $3`,
+		recordID, recordType, userID,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review: %v", err)
+	}
+	return review, nil
+}
+
+// ListReviews returns a page of the public reviews of a
+// record.
+func (r *Repository) ListReviews(ctx context.Context, q repository.ReviewQuery) ([]model.Review, error) {
+	args := []any{q.RecordID, q.RecordType}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	order := "created_at DESC, user_id"
+	where := ""
+	if q.After != nil {
+		where = fmt.Sprintf("AND (created_at < %s OR (created_at = %[1]s AND user_id > %s))", arg(q.After.CreatedAt), arg(q.After.UserID))
+	}
+	if q.SortBy == model.ReviewSortHelpful {
+		order = "helpful_score DESC, " + order
+		if q.After != nil {
+			where = fmt.Sprintf("AND (helpful_score < %s OR (helpful_score = %[1]s %s))", arg(q.After.HelpfulScore), where)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+reviewColumns+` FROM reviews
+		 WHERE record_id = $1 AND record_type = $2 AND `+publicStatus+` `+where+`
+		 ORDER BY `+order+`
+		 LIMIT `+arg(q.Limit),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.Review
+	for rows.Next() {
+		review, err := scanReview(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %v", err)
+		}
+		res = append(res, *review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %v", err)
+	}
+	return res, nil
+}
+
+// VoteReview records whether a user found a review helpful,
+// replacing their previous vote, and updates the vote counts
+// and helpful score of the review in the same transaction. It
+// returns ErrNotFound if the review does not exist.
+func (r *Repository) VoteReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID, helpful bool) error {
+	return r.voteReview(ctx, recordID, recordType, authorID, voterID, &helpful)
+}
+
+// DeleteReviewVote removes the vote of a user on a review and
+// updates the vote counts and helpful score of the review in
+// the same transaction. It returns ErrNotFound if the user
+// has not voted on the review.
+func (r *Repository) DeleteReviewVote(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID) error {
+	return r.voteReview(ctx, recordID, recordType, authorID, voterID, nil)
+}
+
+// voteReview replaces the vote of a user on a review, or
+// removes it if helpful is nil.
+func (r *Repository) voteReview(ctx context.Context, recordID model.RecordID, recordType model.RecordType, authorID, voterID model.UserID, helpful *bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	review := model.Review{RecordID: recordID, RecordType: recordType, UserID: authorID}
+	err = tx.QueryRowContext(ctx,
+		`SELECT helpful_votes, unhelpful_votes FROM reviews
+		 WHERE record_id = $1 AND record_type = $2 AND user_id = $3
+		 FOR UPDATE`,
+		recordID, recordType, authorID,
+	).Scan(&review.HelpfulVotes, &review.UnhelpfulVotes)
+	if err == sql.ErrNoRows {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock review: %v", err)
+	}
+
+	var old sql.NullBool
+	err = tx.QueryRowContext(ctx,
+		`SELECT helpful FROM review_votes
+		 WHERE record_id = $1 AND record_type = $2 AND author_id = $3 AND voter_id = $4`,
+		recordID, recordType, authorID, voterID,
+	).Scan(&old)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to query review vote: %v", err)
+	}
+
+	switch {
+	case helpful != nil:
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO review_votes (record_id, record_type, author_id, voter_id, helpful)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (record_id, record_type, author_id, voter_id) DO UPDATE
+			 SET helpful = $5`,
+			recordID, recordType, authorID, voterID, *helpful,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert review vote: %v", err)
+		}
+	case old.Valid:
+		_, err = tx.ExecContext(ctx,
+			`DELETE FROM review_votes
+			 WHERE record_id = $1 AND record_type = $2 AND author_id = $3 AND voter_id = $4`,
+			recordID, recordType, authorID, voterID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete review vote: %v", err)
+		}
+	default:
+		return repository.ErrNotFound
+	}
+
+	countVote(&review, old, -1)
+	if helpful != nil {
+		countVote(&review, sql.NullBool{Bool: *helpful, Valid: true}, 1)
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE reviews SET helpful_votes = $4, unhelpful_votes = $5, helpful_score = $6
+		 WHERE record_id = $1 AND record_type = $2 AND user_id = $3`,
+		recordID, recordType, authorID, review.HelpfulVotes, review.UnhelpfulVotes, review.HelpfulScore(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update review: %v", err)
+	}
+	return tx.Commit()
+}
+
+// countVote adds delta to the count of a vote, if any.
+func countVote(review *model.Review, vote sql.NullBool, delta int64) {
+	switch {
+	case !vote.Valid:
+	case vote.Bool:
+		review.HelpfulVotes += delta
+	default:
+		review.UnhelpfulVotes += delta
+	}
+}
+
+// moderatedTables maps moderated item kinds to their tables.
+var moderatedTables = map[model.ModerationKind]string{
+	model.ModerationKindRating: "ratings",
+	model.ModerationKindReview: "reviews",
+}
+
+// SetModeration changes the moderation status of a rating or
+// review and audits the change. The aggregate of the record is
+// updated in the same transaction when the item joins or
+// leaves it. It returns ErrNotFound if there is no such item
+// and ErrFinalStatus if it was removed.
+func (r *Repository) SetModeration(ctx context.Context, kind model.ModerationKind, recordID model.RecordID, recordType model.RecordType, userID model.UserID, m model.Moderation) (*model.ModerationAction, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	agg, err := lockAggregate(ctx, tx, recordID, recordType)
+	if err != nil {
+		return nil, err
+	}
+	var from model.ModerationStatus
+	var value model.RatingValue
+	switch kind {
+	case model.ModerationKindRating:
+		old, err := previousRating(ctx, tx, recordID, recordType, userID)
+		if err != nil {
+			return nil, err
+		}
+		if old == nil {
+			return nil, repository.ErrNotFound
+		}
+		from, value = old.Moderation.Status, old.Value
+	case model.ModerationKindReview:
+		if from, err = reviewStatus(ctx, tx, recordID, recordType, userID); err != nil {
+			return nil, err
+		}
+		if from == "" {
+			return nil, repository.ErrNotFound
+		}
+	}
+	if from.Final() {
+		return nil, repository.ErrFinalStatus
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE `+moderatedTables[kind]+`
+		 SET status = $4, moderation_reason = $5, moderator_id = $6, moderated_at = $7
+		 WHERE record_id = $1 AND record_type = $2 AND user_id = $3`,
+		recordID, recordType, userID, m.Status, m.Reason, m.ModeratorID, m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update moderation status: %v", err)
+	}
+	if from.Public() != m.Status.Public() {
+		switch {
+		case kind == model.ModerationKindReview && m.Status.Public():
+			agg.ReviewCount++
+		case kind == model.ModerationKindReview:
+			agg.ReviewCount--
+		case m.Status.Public():
+			agg.Add(value)
+		default:
+			agg.Remove(value)
+		}
+		if err := saveAggregate(ctx, tx, agg); err != nil {
+			return nil, err
+		}
+	}
+
+	action := &model.ModerationAction{
+		Kind: kind, RecordID: recordID, RecordType: recordType, UserID: userID,
+		From: from, To: m.Status, Reason: m.Reason, ActorID: m.ModeratorID, CreatedAt: m.UpdatedAt,
+	}
+	if err := insertAction(ctx, tx, action); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit moderation: %v", err)
+	}
+	return action, nil
+}
+
+// insertAction appends an action to the audit trail and sets
+// its ID.
+func insertAction(ctx context.Context, tx *sql.Tx, a *model.ModerationAction) error {
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO moderation_actions (kind, record_id, record_type, user_id, from_status, to_status, reason, actor_id, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING id`,
+		a.Kind, a.RecordID, a.RecordType, a.UserID, a.From, a.To, a.Reason, a.ActorID, a.CreatedAt,
+	).Scan(&a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert moderation action: %v", err)
+	}
 	return nil
 }
 
+// ReportReview records a user's report of a review, replacing
+// their earlier report, and flags the review for moderation
+// if it is visible. It returns ErrNotFound if the review does
+// not exist.
+func (r *Repository) ReportReview(ctx context.Context, report *model.ReviewReport) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Serialize with SetModeration, which locks the aggregate.
+	if _, err := lockAggregate(ctx, tx, report.RecordID, report.RecordType); err != nil {
+		return err
+	}
+	status, err := reviewStatus(ctx, tx, report.RecordID, report.RecordType, report.AuthorID)
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		return repository.ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO review_reports (record_id, record_type, author_id, reporter_id, reason, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (record_id, record_type, author_id, reporter_id) DO UPDATE
+		 SET reason = $5, created_at = $6`,
+		report.RecordID, report.RecordType, report.AuthorID, report.ReporterID, report.Reason, report.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert review report: %v", err)
+	}
+	if status != model.ModerationStatusVisible {
+		return tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE reviews SET status = $4, moderation_reason = $5, moderator_id = '', moderated_at = $6
+		 WHERE record_id = $1 AND record_type = $2 AND user_id = $3`,
+		report.RecordID, report.RecordType, report.AuthorID, model.ModerationStatusPending, report.Reason, report.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to flag review: %v", err)
+	}
+	err = insertAction(ctx, tx, &model.ModerationAction{
+		Kind: model.ModerationKindReview, RecordID: report.RecordID, RecordType: report.RecordType, UserID: report.AuthorID,
+		From: status, To: model.ModerationStatusPending, Reason: report.Reason,
+		ActorID: report.ReporterID, CreatedAt: report.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListFlagged returns a page of a moderation queue.
+func (r *Repository) ListFlagged(ctx context.Context, q repository.ModerationQuery) ([]model.FlaggedItem, error) {
+	args := []any{q.Status}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := ""
+	if a := q.After; a != nil {
+		where = fmt.Sprintf("AND (i.moderated_at, i.record_type, i.record_id, i.user_id) > (%s, %s, %s, %s)",
+			arg(a.UpdatedAt), arg(a.RecordType), arg(a.RecordID), arg(a.UserID))
+	}
+	// Both kinds share the columns of model.FlaggedItem.
+	columns := "i.value::int, '', '', 0::bigint"
+	if q.Kind == model.ModerationKindReview {
+		columns = `0, i.title, i.body,
+		           (SELECT COUNT(*) FROM review_reports p
+		            WHERE p.record_id = i.record_id AND p.record_type = i.record_type AND p.author_id = i.user_id)`
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT i.record_id, i.record_type, i.user_id, `+columns+`,
+		        i.status, i.moderation_reason, i.moderator_id, i.moderated_at
+		 FROM `+moderatedTables[q.Kind]+` i
+		 WHERE i.status = $1 `+where+`
+		 ORDER BY i.moderated_at, i.record_type, i.record_id, i.user_id
+		 LIMIT `+arg(q.Limit),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query moderation queue: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.FlaggedItem
+	for rows.Next() {
+		item := model.FlaggedItem{Kind: q.Kind}
+		m := &item.Moderation
+		var moderatedAt sql.NullTime
+		if err := rows.Scan(&item.RecordID, &item.RecordType, &item.UserID, &item.Value, &item.Title, &item.Body, &item.Reports,
+			&m.Status, &m.Reason, &m.ModeratorID, &moderatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation queue: %v", err)
+		}
+		m.UpdatedAt = moderatedAt.Time
+		res = append(res, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating moderation queue: %v", err)
+	}
+	return res, nil
+}
+
+// ListModerationActions returns a page of the moderation audit
+// trail, newest first.
+func (r *Repository) ListModerationActions(ctx context.Context, q repository.ModerationActionQuery) ([]model.ModerationAction, error) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := []string{"TRUE"}
+	if q.RecordID != "" {
+		where = append(where, fmt.Sprintf("record_id = %s AND record_type = %s", arg(q.RecordID), arg(q.RecordType)))
+	}
+	if q.UserID != "" {
+		where = append(where, "user_id = "+arg(q.UserID))
+	}
+	if q.BeforeID > 0 {
+		where = append(where, "id < "+arg(q.BeforeID))
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, kind, record_id, record_type, user_id, from_status, to_status, reason, actor_id, created_at
+		 FROM moderation_actions
+		 WHERE `+strings.Join(where, " AND ")+`
+		 ORDER BY id DESC
+		 LIMIT `+arg(q.Limit),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query moderation actions: %v", err)
+	}
+	defer rows.Close()
+
+	var res []model.ModerationAction
+	for rows.Next() {
+		var a model.ModerationAction
+		if err := rows.Scan(&a.ID, &a.Kind, &a.RecordID, &a.RecordType, &a.UserID, &a.From, &a.To, &a.Reason, &a.ActorID, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation action: %v", err)
+		}
+		res = append(res, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating moderation actions: %v", err)
+	}
+	return res, nil
+}
+
 // Close closes the database connection.
 func (r *Repository) Close() error {
 	return r.db.Close()
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	model "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// ReviewQuery defines a page of the reviews of a record.
+// Newest reviews are listed by decreasing creation time and
+// most helpful ones by decreasing helpful score, then
+// creation time, with the user ID breaking ties.
+type ReviewQuery struct {
+	RecordID   model.RecordID
+	RecordType model.RecordType
+	SortBy     model.ReviewSort
+
+	// Limit is the maximum number of reviews to return.
+	Limit int
+	// After, when set, starts the page right after the review
+	// at this position in the requested order.
+	After *ReviewCursor
+}
+
+// ReviewCursor defines a position in a review listing.
+type ReviewCursor struct {
+	HelpfulScore float64      `json:"helpfulScore,omitempty"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	UserID       model.UserID `json:"userId"`
+}
+
+// CursorOf returns the position of a review in the order of q.
+func (q *ReviewQuery) CursorOf(r *model.Review) *ReviewCursor {
+	c := &ReviewCursor{CreatedAt: r.CreatedAt, UserID: r.UserID}
+	if q.SortBy == model.ReviewSortHelpful {
+		c.HelpfulScore = r.HelpfulScore()
+	}
+	return c
+}
+
+// Before reports whether review a comes before b in the order
+// of q.
+func (q *ReviewQuery) Before(a, b *ReviewCursor) bool {
+	if q.SortBy == model.ReviewSortHelpful && a.HelpfulScore != b.HelpfulScore {
+		return a.HelpfulScore > b.HelpfulScore
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.UserID < b.UserID
+}
@@ -0,0 +1,33 @@
+package repository
+
+import model "github.com/phongld0308/movie-example/rating/pkg/model"
+
+// TopRatedQuery defines a page of a top-rated list. Records
+// are ranked highest rated first with their ID breaking ties.
+type TopRatedQuery struct {
+	RecordType model.RecordType
+	// MinVotes is the minimum number of ratings of a listed
+	// record. Records without ratings are never listed.
+	MinVotes int64
+	// Scored ranks records by the scores last stored with
+	// PutTopRatedScores rather than by their mean rating.
+	Scored bool
+
+	// Limit is the maximum number of records to return.
+	Limit int
+	// After, when set, starts the page right after the record
+	// at this position.
+	After *TopRatedCursor
+}
+
+// TopRatedCursor defines a position in a top-rated list.
+type TopRatedCursor struct {
+	Rating float64        `json:"rating"`
+	ID     model.RecordID `json:"id"`
+}
+
+// Before reports whether a record with the given rating and
+// ID comes before or at the position of c.
+func (c *TopRatedCursor) Before(rating float64, id model.RecordID) bool {
+	return rating > c.Rating || rating == c.Rating && id <= c.ID
+}
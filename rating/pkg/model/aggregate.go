@@ -0,0 +1,140 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Rating values range from MinRatingValue to MaxRatingValue.
+const (
+	MinRatingValue = RatingValue(1)
+	MaxRatingValue = RatingValue(5)
+)
+
+// Valid reports whether v is within the rating value range.
+func (v RatingValue) Valid() bool {
+	return v >= MinRatingValue && v <= MaxRatingValue
+}
+
+// AggregatedRating defines the rating of a record by the
+// aggregator configured for its type, together with the
+// number of ratings it was computed from.
+type AggregatedRating struct {
+	Value float64 `json:"value"`
+	Count int64   `json:"count"`
+}
+
+// Aggregate defines the running totals of the ratings of a
+// record, kept up to date as ratings are written so that
+// reads do not scan the ratings.
+type Aggregate struct {
+	RecordID   RecordID   `json:"recordId"`
+	RecordType RecordType `json:"recordType"`
+	Sum        int64      `json:"sum"`
+	Count      int64      `json:"count"`
+	// Histogram counts the ratings of each value, starting
+	// with MinRatingValue.
+	Histogram []int64 `json:"histogram"`
+	// ReviewCount is the number of ratings with a review.
+	ReviewCount int64     `json:"reviewCount"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// NewAggregate returns the empty aggregate of a record.
+func NewAggregate(recordID RecordID, recordType RecordType) *Aggregate {
+	return &Aggregate{
+		RecordID:   recordID,
+		RecordType: recordType,
+		Histogram:  make([]int64, MaxRatingValue-MinRatingValue+1),
+	}
+}
+
+// Add counts a rating of value v.
+func (a *Aggregate) Add(v RatingValue) {
+	a.Sum += int64(v)
+	a.Count++
+	a.Histogram[v-MinRatingValue]++
+}
+
+// Remove uncounts a rating of value v.
+func (a *Aggregate) Remove(v RatingValue) {
+	a.Sum -= int64(v)
+	a.Count--
+	a.Histogram[v-MinRatingValue]--
+}
+
+// Merge adds the totals of b to a.
+func (a *Aggregate) Merge(b *Aggregate) {
+	a.Sum += b.Sum
+	a.Count += b.Count
+	a.ReviewCount += b.ReviewCount
+	for i, n := range b.Histogram {
+		a.Histogram[i] += n
+	}
+	if b.UpdatedAt.After(a.UpdatedAt) {
+		a.UpdatedAt = b.UpdatedAt
+	}
+}
+
+// Mean returns the average rating, or 0 without ratings.
+func (a *Aggregate) Mean() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return float64(a.Sum) / float64(a.Count)
+}
+
+// Stats defines summary statistics of the ratings of a
+// record.
+type Stats struct {
+	Count int64 `json:"count"`
+	// Histogram counts the ratings of each value, starting
+	// with MinRatingValue.
+	Histogram   []int64   `json:"histogram"`
+	Mean        float64   `json:"mean"`
+	Median      float64   `json:"median"`
+	StdDev      float64   `json:"stddev"`
+	ReviewCount int64     `json:"reviewCount"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	// Rating is the rating by the aggregator configured for
+	// the record type, as ranked in top-rated lists. It is set
+	// by the rating service, not by Aggregate.Stats.
+	Rating float64 `json:"rating"`
+}
+
+// Stats computes the statistics of the aggregated ratings.
+// Rating values are discrete, so the histogram is enough to
+// derive them without the individual ratings.
+func (a *Aggregate) Stats() *Stats {
+	s := &Stats{
+		Count:       a.Count,
+		Histogram:   append([]int64(nil), a.Histogram...),
+		Mean:        a.Mean(),
+		ReviewCount: a.ReviewCount,
+		UpdatedAt:   a.UpdatedAt,
+	}
+	if a.Count == 0 {
+		return s
+	}
+
+	var variance float64
+	for i, n := range a.Histogram {
+		d := float64(MinRatingValue+RatingValue(i)) - s.Mean
+		variance += float64(n) * d * d
+	}
+	s.StdDev = math.Sqrt(variance / float64(a.Count))
+	s.Median = (a.valueAt((a.Count-1)/2) + a.valueAt(a.Count/2)) / 2
+	return s
+}
+
+// valueAt returns the value of the rating at 0-based position
+// i in ascending order.
+func (a *Aggregate) valueAt(i int64) float64 {
+	for v, n := range a.Histogram {
+		if i < n {
+			return float64(MinRatingValue + RatingValue(v))
+		}
+		i -= n
+	}
+	return float64(MaxRatingValue)
+}
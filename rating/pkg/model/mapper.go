@@ -0,0 +1,150 @@
+package model
+
+import (
+	"github.com/phongld0308/movie-example/gen"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// StatsToProto converts a Stats struct into a generated
+// proto counterpart.
+func StatsToProto(s *Stats) *gen.RatingStats {
+	return &gen.RatingStats{
+		Count:       s.Count,
+		Histogram:   s.Histogram,
+		Mean:        s.Mean,
+		Median:      s.Median,
+		Stddev:      s.StdDev,
+		ReviewCount: s.ReviewCount,
+		UpdatedAt:   timestamppb.New(s.UpdatedAt),
+		RatingValue: s.Rating,
+	}
+}
+
+// StatsFromProto converts a generated proto counterpart into
+// a Stats struct.
+func StatsFromProto(s *gen.RatingStats) *Stats {
+	return &Stats{
+		Count:       s.Count,
+		Histogram:   s.Histogram,
+		Mean:        s.Mean,
+		Median:      s.Median,
+		StdDev:      s.Stddev,
+		ReviewCount: s.ReviewCount,
+		UpdatedAt:   s.UpdatedAt.AsTime(),
+		Rating:      s.RatingValue,
+	}
+}
+
+// RecordKeyToProto converts a RecordKey struct into a
+// generated proto counterpart.
+func RecordKeyToProto(k RecordKey) *gen.RecordRef {
+	return &gen.RecordRef{RecordId: string(k.ID), RecordType: string(k.Type)}
+}
+
+// RecordKeyFromProto converts a generated proto counterpart
+// into a RecordKey struct.
+func RecordKeyFromProto(r *gen.RecordRef) RecordKey {
+	return RecordKey{ID: RecordID(r.RecordId), Type: RecordType(r.RecordType)}
+}
+
+// TrendingScoreToProto converts a TrendingScore struct into a
+// generated proto counterpart.
+func TrendingScoreToProto(s *TrendingScore) *gen.TrendingRecord {
+	return &gen.TrendingRecord{
+		RecordId:   string(s.RecordID),
+		RecordType: string(s.RecordType),
+		Score:      s.Score,
+		Weight:     s.Weight,
+		ComputedAt: timestamppb.New(s.ComputedAt),
+	}
+}
+
+// RankedRecordToProto converts a RankedRecord struct into a
+// generated proto counterpart.
+func RankedRecordToProto(r *RankedRecord) *gen.RankedRecord {
+	return &gen.RankedRecord{
+		RecordId:    string(r.RecordID),
+		RecordType:  string(r.RecordType),
+		RatingValue: r.Rating,
+		RatingCount: r.RatingCount,
+	}
+}
+
+// RankedRecordFromProto converts a generated proto
+// counterpart into a RankedRecord struct.
+func RankedRecordFromProto(r *gen.RankedRecord) *RankedRecord {
+	return &RankedRecord{
+		RecordID:    RecordID(r.RecordId),
+		RecordType:  RecordType(r.RecordType),
+		Rating:      r.RatingValue,
+		RatingCount: r.RatingCount,
+	}
+}
+
+// ReviewToProto converts a Review struct into a generated
+// proto counterpart.
+func ReviewToProto(r *Review) *gen.Review {
+	p := &gen.Review{
+		RecordId:       string(r.RecordID),
+		RecordType:     string(r.RecordType),
+		UserId:         string(r.UserID),
+		Title:          r.Title,
+		Body:           r.Body,
+		HelpfulVotes:   r.HelpfulVotes,
+		UnhelpfulVotes: r.UnhelpfulVotes,
+		HelpfulScore:   r.HelpfulScore(),
+		CreatedAt:      timestamppb.New(r.CreatedAt),
+		Moderation:     ModerationToProto(&r.Moderation),
+	}
+	if !r.EditedAt.IsZero() {
+		p.EditedAt = timestamppb.New(r.EditedAt)
+	}
+	return p
+}
+
+// ModerationToProto converts a Moderation struct into a
+// generated proto counterpart.
+func ModerationToProto(m *Moderation) *gen.Moderation {
+	p := &gen.Moderation{
+		Status:      string(m.Status),
+		Reason:      m.Reason,
+		ModeratorId: string(m.ModeratorID),
+	}
+	if !m.UpdatedAt.IsZero() {
+		p.UpdatedAt = timestamppb.New(m.UpdatedAt)
+	}
+	return p
+}
+
+// ModerationActionToProto converts a ModerationAction struct
+// into a generated proto counterpart.
+func ModerationActionToProto(a *ModerationAction) *gen.ModerationAction {
+	return &gen.ModerationAction{
+		Id:         a.ID,
+		Kind:       string(a.Kind),
+		RecordId:   string(a.RecordID),
+		RecordType: string(a.RecordType),
+		UserId:     string(a.UserID),
+		FromStatus: string(a.From),
+		ToStatus:   string(a.To),
+		Reason:     a.Reason,
+		ActorId:    string(a.ActorID),
+		CreatedAt:  timestamppb.New(a.CreatedAt),
+	}
+}
+
+// FlaggedItemToProto converts a FlaggedItem struct into a
+// generated proto counterpart.
+func FlaggedItemToProto(i *FlaggedItem) *gen.FlaggedItem {
+	return &gen.FlaggedItem{
+		Kind:        string(i.Kind),
+		RecordId:    string(i.RecordID),
+		RecordType:  string(i.RecordType),
+		UserId:      string(i.UserID),
+		RatingValue: int32(i.Value),
+		Title:       i.Title,
+		Body:        i.Body,
+		Moderation:  ModerationToProto(&i.Moderation),
+		Reports:     i.Reports,
+	}
+}
@@ -0,0 +1,133 @@
+package model
+
+import "time"
+
+// ModerationStatus defines the moderation status of a rating
+// or review.
+type ModerationStatus string
+
+// Moderation statuses. Pending items are awaiting a moderator
+// decision and are still shown. Held items are awaiting a
+// decision too, but are withheld from aggregates and listings
+// meanwhile, as suspected abuse. Hidden items are withheld
+// until a moderator restores them. Removed items are withheld
+// for good and kept only for the record.
+const (
+	ModerationStatusVisible = ModerationStatus("visible")
+	ModerationStatusPending = ModerationStatus("pending")
+	ModerationStatusHeld    = ModerationStatus("held")
+	ModerationStatusHidden  = ModerationStatus("hidden")
+	ModerationStatusRemoved = ModerationStatus("removed")
+)
+
+// ModerationStatuses lists the moderation statuses.
+var ModerationStatuses = []ModerationStatus{ModerationStatusVisible, ModerationStatusPending, ModerationStatusHeld, ModerationStatusHidden, ModerationStatusRemoved}
+
+// Valid reports whether s is a moderation status.
+func (s ModerationStatus) Valid() bool {
+	for _, m := range ModerationStatuses {
+		if m == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Public reports whether items with the status are counted in
+// aggregates and listed. Items without a status are visible.
+func (s ModerationStatus) Public() bool {
+	return s == "" || s == ModerationStatusVisible || s == ModerationStatusPending
+}
+
+// Final reports whether items with the status can no longer
+// change status.
+func (s ModerationStatus) Final() bool {
+	return s == ModerationStatusRemoved
+}
+
+// Moderation defines the moderation state of a rating or
+// review.
+type Moderation struct {
+	Status ModerationStatus `json:"status"`
+	// Reason, ModeratorID and UpdatedAt describe the last
+	// status change. They are empty for items that were never
+	// moderated.
+	Reason      string    `json:"reason,omitempty"`
+	ModeratorID UserID    `json:"moderatorId,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt,omitempty"`
+}
+
+// ModerationKind defines the kind of a moderated item.
+type ModerationKind string
+
+// Moderated item kinds. Items are identified by the record
+// and the user who rated or reviewed it.
+const (
+	ModerationKindRating = ModerationKind("rating")
+	ModerationKindReview = ModerationKind("review")
+)
+
+// Valid reports whether k is a moderated item kind.
+func (k ModerationKind) Valid() bool {
+	return k == ModerationKindRating || k == ModerationKindReview
+}
+
+// ModerationAction defines an audited moderation status
+// change.
+type ModerationAction struct {
+	ID         int64            `json:"id"`
+	Kind       ModerationKind   `json:"kind"`
+	RecordID   RecordID         `json:"recordId"`
+	RecordType RecordType       `json:"recordType"`
+	UserID     UserID           `json:"userId"`
+	From       ModerationStatus `json:"from"`
+	To         ModerationStatus `json:"to"`
+	Reason     string           `json:"reason"`
+	// ActorID is the moderator who changed the status, or the
+	// user whose report flagged the item.
+	ActorID   UserID    `json:"actorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ModerationActionPage defines a page of the moderation audit
+// trail.
+type ModerationActionPage struct {
+	Actions []ModerationAction `json:"actions"`
+	// NextPageToken fetches the following page. It is empty
+	// on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// ReviewReport defines a user's report of an abusive review.
+type ReviewReport struct {
+	RecordID   RecordID   `json:"recordId"`
+	RecordType RecordType `json:"recordType"`
+	AuthorID   UserID     `json:"authorId"`
+	ReporterID UserID     `json:"reporterId"`
+	Reason     string     `json:"reason"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// FlaggedItem defines a rating or review in a moderation
+// queue.
+type FlaggedItem struct {
+	Kind       ModerationKind `json:"kind"`
+	RecordID   RecordID       `json:"recordId"`
+	RecordType RecordType     `json:"recordType"`
+	UserID     UserID         `json:"userId"`
+	// Value is set for ratings, Title and Body for reviews.
+	Value      RatingValue `json:"value,omitempty"`
+	Title      string      `json:"title,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Moderation Moderation  `json:"moderation"`
+	// Reports is the number of users who reported a review.
+	Reports int64 `json:"reports"`
+}
+
+// FlaggedPage defines a page of a moderation queue.
+type FlaggedPage struct {
+	Items []FlaggedItem `json:"items"`
+	// NextPageToken fetches the following page. It is empty
+	// on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
@@ -1,5 +1,11 @@
 package model
 
+import (
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/recordtype"
+)
+
 // RecordID defines a record id. Together with RecordType
 // identifies unique records across all types.
 type RecordID string
@@ -8,12 +14,42 @@ type RecordID string
 // identifies unique record across all types.
 type RecordType string
 
-// Existing record types.
-
+// Existing record types, as defined by package recordtype.
+// Seasons belong to a series and episodes to a season.
 const (
-	RecordTypeMovie = RecordType("movie")
+	RecordTypeMovie   = RecordType(recordtype.Movie)
+	RecordTypeSeries  = RecordType(recordtype.Series)
+	RecordTypeSeason  = RecordType(recordtype.Season)
+	RecordTypeEpisode = RecordType(recordtype.Episode)
 )
 
+// RecordTypes lists the registered record types, which can
+// be rated.
+var RecordTypes = func() []RecordType {
+	res := make([]RecordType, len(recordtype.All))
+	for i, t := range recordtype.All {
+		res[i] = RecordType(t)
+	}
+	return res
+}()
+
+// IsRegistered reports whether t is a registered record type.
+func (t RecordType) IsRegistered() bool {
+	return recordtype.Valid(string(t))
+}
+
+// ParentType returns the record type of the parent of t, or
+// an empty type if t has no parent.
+func (t RecordType) ParentType() RecordType {
+	return RecordType(recordtype.Parent(string(t)))
+}
+
+// RecordKey identifies a record across all types.
+type RecordKey struct {
+	ID   RecordID   `json:"id"`
+	Type RecordType `json:"type"`
+}
+
 // UserID defines a user id.
 type UserID string
 
@@ -27,6 +63,9 @@ type Rating struct {
 	RecordType RecordType  `json:"recordType"`
 	UserID     UserID      `json:"userId"`
 	Value      RatingValue `json:"value"`
+	// UpdatedAt is when the user last rated the record.
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	Moderation Moderation `json:"moderation"`
 }
 
 // RatingEvent defines a event containing rating information.
@@ -36,6 +75,9 @@ type RatingEvent struct {
 	RecordType      RecordType      `json:"recordType"`
 	Value           RatingValue     `json:"value"`
 	RatingEventType RatingEventType `json:"eventType"`
+	// Timestamp is when the rating was given. Events without
+	// one are dated when they are applied.
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // RatingEventType defines the type of a rating event.
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Review defines the written explanation of a rating by its
+// user. It exists only as long as the rating does.
+type Review struct {
+	RecordID   RecordID   `json:"recordId"`
+	RecordType RecordType `json:"recordType"`
+	UserID     UserID     `json:"userId"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	// HelpfulVotes and UnhelpfulVotes count the users who
+	// found the review helpful or not.
+	HelpfulVotes   int64     `json:"helpfulVotes"`
+	UnhelpfulVotes int64     `json:"unhelpfulVotes"`
+	CreatedAt      time.Time `json:"createdAt"`
+	// EditedAt is when the review was last edited, or zero
+	// if it never was.
+	EditedAt   time.Time  `json:"editedAt"`
+	Moderation Moderation `json:"moderation"`
+}
+
+// HelpfulScore returns the lower bound of the 95% Wilson
+// score interval of the share of helpful votes. It ranks a
+// review with many mostly helpful votes above one with a
+// single helpful vote.
+func (r *Review) HelpfulScore() float64 {
+	return WilsonLowerBound(r.HelpfulVotes, r.HelpfulVotes+r.UnhelpfulVotes)
+}
+
+// WilsonLowerBound returns the lower bound of the 95% Wilson
+// score interval of a proportion of positive out of n
+// observations, or 0 without observations.
+func WilsonLowerBound(positive, n int64) float64 {
+	if n == 0 {
+		return 0
+	}
+	const z = 1.959963984540054
+	p := float64(positive) / float64(n)
+	nf := float64(n)
+	return (p + z*z/(2*nf) - z*math.Sqrt((p*(1-p)+z*z/(4*nf))/nf)) / (1 + z*z/nf)
+}
+
+// ReviewSort defines an order of the reviews of a record.
+type ReviewSort string
+
+// Supported review orders.
+const (
+	ReviewSortNewest = ReviewSort("newest")
+	// ReviewSortHelpful orders reviews by HelpfulScore.
+	ReviewSortHelpful = ReviewSort("helpful")
+)
+
+// ReviewPage defines a page of the reviews of a record.
+type ReviewPage struct {
+	Reviews []Review `json:"reviews"`
+	// NextPageToken fetches the following page. It is empty
+	// on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
@@ -0,0 +1,17 @@
+package model
+
+// RankedRecord defines a record in a top-rated list.
+type RankedRecord struct {
+	RecordID    RecordID   `json:"recordId"`
+	RecordType  RecordType `json:"recordType"`
+	Rating      float64    `json:"rating"`
+	RatingCount int64      `json:"ratingCount"`
+}
+
+// TopRatedPage defines a page of a top-rated list.
+type TopRatedPage struct {
+	Records []RankedRecord `json:"records"`
+	// NextPageToken fetches the following page. It is empty
+	// on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// TrendingWindow defines the period over which recent ratings
+// make a record trend.
+type TrendingWindow string
+
+// Supported trending windows.
+const (
+	TrendingWindowDay   = TrendingWindow("24h")
+	TrendingWindowWeek  = TrendingWindow("7d")
+	TrendingWindowMonth = TrendingWindow("30d")
+)
+
+// TrendingWindows lists the supported trending windows.
+var TrendingWindows = []TrendingWindow{TrendingWindowDay, TrendingWindowWeek, TrendingWindowMonth}
+
+var trendingWindowDurations = map[TrendingWindow]time.Duration{
+	TrendingWindowDay:   24 * time.Hour,
+	TrendingWindowWeek:  7 * 24 * time.Hour,
+	TrendingWindowMonth: 30 * 24 * time.Hour,
+}
+
+// Duration returns the length of the window, or 0 if the
+// window is not supported.
+func (w TrendingWindow) Duration() time.Duration {
+	return trendingWindowDurations[w]
+}
+
+// HalfLife returns the age at which a rating counts half as
+// much as a new one. Ratings at the end of the window count
+// a sixteenth.
+func (w TrendingWindow) HalfLife() time.Duration {
+	return w.Duration() / 4
+}
+
+// DecayWeight returns the weight of a rating of the given age
+// in the window, or 0 if it is outside of it.
+func (w TrendingWindow) DecayWeight(age time.Duration) float64 {
+	if age < 0 {
+		age = 0
+	}
+	if age > w.Duration() {
+		return 0
+	}
+	return math.Exp2(-age.Seconds() / w.HalfLife().Seconds())
+}
+
+// TrendingScore defines the trending score of a record in a
+// window: the sum of its rating values weighted by their
+// decay, so that records rated often, well and recently rank
+// first.
+type TrendingScore struct {
+	RecordID   RecordID   `json:"recordId"`
+	RecordType RecordType `json:"recordType"`
+	Score      float64    `json:"score"`
+	// Weight is the decayed number of ratings.
+	Weight     float64   `json:"weight"`
+	ComputedAt time.Time `json:"computedAt"`
+}
+
+// RankTrending returns the trending scores of the records of
+// the given ratings in a window at now, highest first. Ratings
+// without a timestamp are ignored.
+func RankTrending(ratings []Rating, window TrendingWindow, now time.Time) []TrendingScore {
+	type record struct {
+		id  RecordID
+		typ RecordType
+	}
+	scores := map[record]*TrendingScore{}
+	for _, r := range ratings {
+		if r.UpdatedAt.IsZero() {
+			continue
+		}
+		w := window.DecayWeight(now.Sub(r.UpdatedAt))
+		if w == 0 {
+			continue
+		}
+		rec := record{r.RecordID, r.RecordType}
+		s, ok := scores[rec]
+		if !ok {
+			s = &TrendingScore{RecordID: r.RecordID, RecordType: r.RecordType, ComputedAt: now}
+			scores[rec] = s
+		}
+		s.Score += w * float64(r.Value)
+		s.Weight += w
+	}
+
+	res := make([]TrendingScore, 0, len(scores))
+	for _, s := range scores {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].RecordID < res[j].RecordID
+	})
+	return res
+}
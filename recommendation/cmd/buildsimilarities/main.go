@@ -0,0 +1,61 @@
+// Command buildsimilarities rebuilds the item-item similarity
+// model that recommendations are served from, for example
+// after a bulk import or on a schedule.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/phongld0308/movie-example/recommendation/internal/controller/recommendation"
+	"github.com/phongld0308/movie-example/recommendation/internal/repository/postgres"
+	"github.com/phongld0308/movie-example/recommendation/internal/similarity"
+)
+
+func main() {
+	cfg := similarity.DefaultConfig()
+	var timeout time.Duration
+	flag.DurationVar(&timeout, "timeout", 30*time.Minute, "maximum duration of the build")
+	flag.IntVar(&cfg.Neighbors, "neighbors", cfg.Neighbors, "number of most similar records kept per record")
+	flag.Int64Var(&cfg.MinCoRatings, "min-co-ratings", cfg.MinCoRatings, "number of users who must have rated both records to compare them")
+	flag.IntVar(&cfg.MaxUserRatings, "max-user-ratings", cfg.MaxUserRatings, "number of most recent ratings of a user compared, 0 for all")
+	flag.IntVar(&cfg.Partitions, "partitions", cfg.Partitions, "number of passes over the ratings, each holding the pairs of a share of the records")
+	flag.Parse()
+
+	dbPort, err := strconv.Atoi(getEnvOrDefault("DB_PORT", "5432"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid port number: %v", err))
+	}
+	repo, err := postgres.New(
+		getEnvOrDefault("DB_HOST", "localhost"),
+		dbPort,
+		getEnvOrDefault("DB_USER", "postgres"),
+		getEnvOrDefault("DB_PASSWORD", "password"),
+		getEnvOrDefault("DB_NAME", "movieexample"),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer repo.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	n, err := recommendation.NewWithConfig(repo, cfg).BuildModel(ctx)
+	if err != nil {
+		log.Fatalf("Failed to build the similarity model: %v", err)
+	}
+	log.Printf("Built %d similarities in %v", n, time.Since(start))
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/discovery"
+	"github.com/phongld0308/movie-example/pkg/discovery/consul"
+	"github.com/phongld0308/movie-example/pkg/validation"
+	"github.com/phongld0308/movie-example/recommendation/internal/controller/recommendation"
+	grpchandler "github.com/phongld0308/movie-example/recommendation/internal/handler/grpc"
+	"github.com/phongld0308/movie-example/recommendation/internal/repository/postgres"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+const serviceName = "recommendation"
+
+func main() {
+	var port int
+	flag.IntVar(&port, "port", 8084, "API handler port")
+	flag.Parse()
+	log.Printf("Starting the recommendation service on port %d", port)
+
+	// Get configuration from environment
+	consulAddr := getEnvOrDefault("CONSUL_ADDR", "consul:8500")
+	registry, err := consul.NewRegistry(consulAddr)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	instanceID := discovery.GenerateInstanceID(serviceName)
+	if err := registry.Register(ctx, instanceID, serviceName, fmt.Sprintf("recommendation:%d", port)); err != nil {
+		panic(err)
+	}
+
+	go func() {
+		for {
+			if err := registry.ReportHealthyState(instanceID, serviceName); err != nil {
+				log.Println("Failed to report healthy state: " + err.Error())
+			}
+			time.Sleep(1 * time.Second)
+		}
+	}()
+
+	defer registry.Deregister(ctx, instanceID, serviceName)
+
+	// Get database configuration from environment
+	dbHost := getEnvOrDefault("DB_HOST", "localhost")
+	dbPort := getEnvOrDefault("DB_PORT", "5432")
+	dbUser := getEnvOrDefault("DB_USER", "postgres")
+	dbPassword := getEnvOrDefault("DB_PASSWORD", "password")
+	dbName := getEnvOrDefault("DB_NAME", "movieexample")
+
+	// Parse port number
+	dbPortInt, err := strconv.Atoi(dbPort)
+	if err != nil {
+		panic(fmt.Sprintf("invalid port number: %v", err))
+	}
+
+	// Initialize PostgreSQL repository
+	repo, err := postgres.New(
+		dbHost,
+		dbPortInt,
+		dbUser,
+		dbPassword,
+		dbName,
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer repo.Close()
+
+	ctrl := recommendation.New(repo)
+
+	// Rebuild the similarity model in the background so that
+	// it follows new ratings. Instances skip the rebuild when
+	// another one built the model within the interval. Zero
+	// leaves it to the buildsimilarities command.
+	rebuild, err := time.ParseDuration(getEnvOrDefault("SIMILARITY_REBUILD_INTERVAL", "6h"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid similarity rebuild interval: %v", err))
+	}
+	if rebuild > 0 {
+		go func() {
+			for {
+				if _, err := ctrl.RefreshModel(ctx, rebuild); err != nil {
+					log.Println("Failed to build the similarity model: " + err.Error())
+				}
+				time.Sleep(rebuild)
+			}
+		}()
+	}
+
+	h := grpchandler.New(ctrl)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%v", port))
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		validation.UnaryServerInterceptor(),
+	))
+	reflection.Register(srv)
+	gen.RegisterRecommendationServiceServer(srv, h)
+	if err := srv.Serve(lis); err != nil {
+		panic(err)
+	}
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,250 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/phongld0308/movie-example/pkg/errs"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+	"github.com/phongld0308/movie-example/recommendation/internal/similarity"
+	model "github.com/phongld0308/movie-example/recommendation/pkg/model"
+)
+
+// Recommendation list sizes.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// maxBecause is the number of rated records a recommendation
+// is explained by.
+const maxBecause = 3
+
+// ErrUnknownRecordType is returned when recommendations are
+// requested for a record type that is not registered.
+var ErrUnknownRecordType = errs.InvalidArgument("unknown record type")
+
+// ErrBuildInProgress is returned when the similarity model is
+// already being built, by this or another instance.
+var ErrBuildInProgress = errs.Conflict("similarity model is being built")
+
+type recommendationRepository interface {
+	EachUserRatings(ctx context.Context, fn func(ratings []ratingmodel.Rating) error) error
+	UserRatings(ctx context.Context, userID ratingmodel.UserID) ([]ratingmodel.Rating, error)
+	LockModel(ctx context.Context) (unlock func(), ok bool, err error)
+	ModelBuiltAt(ctx context.Context) (time.Time, error)
+	ReplaceSimilarities(ctx context.Context, sims []model.Similarity) error
+	ListSimilarities(ctx context.Context, recordType ratingmodel.RecordType, recordIDs []ratingmodel.RecordID) ([]model.Similarity, error)
+}
+
+// Controller defines a recommendation service controller.
+type Controller struct {
+	repo recommendationRepository
+	cfg  similarity.Config
+	now  func() time.Time
+}
+
+// New creates a new recommendation service controller that
+// builds models with the default config.
+func New(repo recommendationRepository) *Controller {
+	return NewWithConfig(repo, similarity.DefaultConfig())
+}
+
+// NewWithConfig creates a new recommendation service
+// controller that builds models as configured.
+func NewWithConfig(repo recommendationRepository, cfg similarity.Config) *Controller {
+	return &Controller{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// BuildModel rebuilds the item-item similarity model from the
+// public ratings and returns the number of similarities kept.
+// Recommendations are served from the last model built. One
+// build runs at a time across the instances of the service,
+// and the others fail with ErrBuildInProgress.
+func (c *Controller) BuildModel(ctx context.Context) (int, error) {
+	unlock, ok, err := c.repo.LockModel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrBuildInProgress
+	}
+	defer unlock()
+	return c.buildModel(ctx)
+}
+
+// RefreshModel rebuilds the similarity model as BuildModel
+// does if it was built more than maxAge ago, so that
+// instances refreshing it on the same schedule build it once
+// between them. It reports whether the model was rebuilt.
+func (c *Controller) RefreshModel(ctx context.Context, maxAge time.Duration) (bool, error) {
+	unlock, ok, err := c.repo.LockModel(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+	defer unlock()
+	builtAt, err := c.repo.ModelBuiltAt(ctx)
+	if err != nil {
+		return false, err
+	}
+	if c.now().Sub(builtAt) < maxAge {
+		return false, nil
+	}
+	if _, err := c.buildModel(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// buildModel rebuilds the similarity model, streaming the
+// ratings of one user at a time into it, once per partition
+// of the records. The caller must hold the model lock.
+func (c *Controller) buildModel(ctx context.Context) (int, error) {
+	sims, err := similarity.BuildEach(c.cfg, c.now(), func(fn func(ratings []ratingmodel.Rating) error) error {
+		return c.repo.EachUserRatings(ctx, fn)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := c.repo.ReplaceSimilarities(ctx, sims); err != nil {
+		return 0, err
+	}
+	return len(sims), nil
+}
+
+// SimilarItems returns up to limit records of the same type
+// that users rated most like a record, most similar first.
+func (c *Controller) SimilarItems(ctx context.Context, recordID ratingmodel.RecordID, recordType ratingmodel.RecordType, limit int) ([]model.Similarity, error) {
+	if !recordType.IsRegistered() {
+		return nil, ErrUnknownRecordType.WithViolations(errs.FieldViolation{Field: "record_type", Description: fmt.Sprintf("must be one of %v", ratingmodel.RecordTypes)})
+	}
+	limit = clampLimit(limit)
+
+	sims, err := c.repo.ListSimilarities(ctx, recordType, []ratingmodel.RecordID{recordID})
+	if err != nil {
+		return nil, err
+	}
+	if len(sims) > limit {
+		sims = sims[:limit]
+	}
+	if sims == nil {
+		sims = []model.Similarity{}
+	}
+	return sims, nil
+}
+
+// candidate accumulates the prediction of a user's rating of
+// a record from the records they rated that are similar to it.
+type candidate struct {
+	id ratingmodel.RecordID
+	// num and den sum the similarity weighted deviations of
+	// the user's ratings from their mean, and the
+	// similarities.
+	num, den float64
+	because  []model.Similarity
+}
+
+// RecommendForUser returns up to limit records of a type that
+// a user has not rated, by decreasing predicted rating. The
+// prediction is the user's mean rating plus the deviations
+// from it of their ratings of similar records, weighted by
+// similarity. Users without ratings get no recommendations.
+func (c *Controller) RecommendForUser(ctx context.Context, userID ratingmodel.UserID, recordType ratingmodel.RecordType, limit int) ([]model.Recommendation, error) {
+	if !recordType.IsRegistered() {
+		return nil, ErrUnknownRecordType.WithViolations(errs.FieldViolation{Field: "record_type", Description: fmt.Sprintf("must be one of %v", ratingmodel.RecordTypes)})
+	}
+	limit = clampLimit(limit)
+
+	ratings, err := c.repo.UserRatings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := []model.Recommendation{}
+	if len(ratings) == 0 {
+		return res, nil
+	}
+	// The mean is taken over all the user's ratings, as when
+	// the model is built.
+	var sum float64
+	rated := map[ratingmodel.RecordID]ratingmodel.RatingValue{}
+	var ids []ratingmodel.RecordID
+	for _, r := range ratings {
+		sum += float64(r.Value)
+		if r.RecordType == recordType {
+			rated[r.RecordID] = r.Value
+			ids = append(ids, r.RecordID)
+		}
+	}
+	mean := sum / float64(len(ratings))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	sims, err := c.repo.ListSimilarities(ctx, recordType, ids)
+	if err != nil {
+		return nil, err
+	}
+	candidates := map[ratingmodel.RecordID]*candidate{}
+	for _, sim := range sims {
+		if _, ok := rated[sim.SimilarID]; ok {
+			continue
+		}
+		cand := candidates[sim.SimilarID]
+		if cand == nil {
+			cand = &candidate{id: sim.SimilarID}
+			candidates[sim.SimilarID] = cand
+		}
+		cand.num += sim.Score * (float64(rated[sim.RecordID]) - mean)
+		cand.den += sim.Score
+		cand.because = append(cand.because, sim)
+	}
+
+	list := make([]*candidate, 0, len(candidates))
+	for _, cand := range candidates {
+		list = append(list, cand)
+	}
+	score := func(cand *candidate) float64 {
+		p := mean + cand.num/cand.den
+		return max(float64(ratingmodel.MinRatingValue), min(float64(ratingmodel.MaxRatingValue), p))
+	}
+	// Records backed by more similarity come first among
+	// equal predictions.
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch {
+		case score(a) != score(b):
+			return score(a) > score(b)
+		case a.den != b.den:
+			return a.den > b.den
+		}
+		return a.id < b.id
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	for _, cand := range list {
+		sort.Slice(cand.because, func(i, j int) bool {
+			a, b := cand.because[i], cand.because[j]
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.RecordID < b.RecordID
+		})
+		rec := model.Recommendation{RecordID: cand.id, RecordType: recordType, Score: score(cand)}
+		for i := 0; i < len(cand.because) && i < maxBecause; i++ {
+			rec.Because = append(rec.Because, cand.because[i].RecordID)
+		}
+		res = append(res, rec)
+	}
+	return res, nil
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	return min(limit, MaxLimit)
+}
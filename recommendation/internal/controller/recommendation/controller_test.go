@@ -0,0 +1,119 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+	"github.com/phongld0308/movie-example/recommendation/internal/repository/memory"
+	model "github.com/phongld0308/movie-example/recommendation/pkg/model"
+)
+
+func TestRecommendations(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	// a and b are liked by the same users, c and d by the
+	// others.
+	for user, values := range map[ratingmodel.UserID]map[ratingmodel.RecordID]ratingmodel.RatingValue{
+		"u1": {"a": 5, "b": 5, "c": 1, "d": 1},
+		"u2": {"a": 4, "b": 5, "c": 2, "d": 1},
+		"u3": {"a": 1, "b": 2, "c": 5, "d": 5},
+		"u4": {"a": 2, "b": 1, "c": 4, "d": 4},
+		"u5": {"a": 5, "c": 1},
+	} {
+		for id, v := range values {
+			repo.PutRating(ratingmodel.Rating{RecordID: id, RecordType: ratingmodel.RecordTypeMovie, UserID: user, Value: v})
+		}
+	}
+	// Hidden ratings are left out of the model.
+	repo.PutRating(ratingmodel.Rating{RecordID: "e", RecordType: ratingmodel.RecordTypeMovie, UserID: "u1", Value: 5,
+		Moderation: ratingmodel.Moderation{Status: ratingmodel.ModerationStatusHidden}})
+
+	ctrl := New(repo)
+	builtAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctrl.now = func() time.Time { return builtAt }
+
+	recs, err := ctrl.RecommendForUser(ctx, "u5", ratingmodel.RecordTypeMovie, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 0 {
+		t.Errorf("recommendations before the model is built = %+v, want none", recs)
+	}
+
+	if _, err := ctrl.BuildModel(ctx); err != nil {
+		t.Fatal(err)
+	}
+	recs, err = ctrl.RecommendForUser(ctx, "u5", ratingmodel.RecordTypeMovie, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Recommendation{
+		{RecordID: "b", RecordType: ratingmodel.RecordTypeMovie, Score: 5, Because: []ratingmodel.RecordID{"a"}},
+		{RecordID: "d", RecordType: ratingmodel.RecordTypeMovie, Score: 1, Because: []ratingmodel.RecordID{"c"}},
+	}
+	if !reflect.DeepEqual(recs, want) {
+		t.Errorf("recommendations = %+v, want %+v", recs, want)
+	}
+	if recs, err := ctrl.RecommendForUser(ctx, "u5", ratingmodel.RecordTypeMovie, 1); err != nil || len(recs) != 1 || recs[0].RecordID != "b" {
+		t.Errorf("first recommendation = %+v, %v, want b", recs, err)
+	}
+	if recs, err := ctrl.RecommendForUser(ctx, "nobody", ratingmodel.RecordTypeMovie, 0); err != nil || len(recs) != 0 {
+		t.Errorf("recommendations without ratings = %+v, %v, want none", recs, err)
+	}
+	if _, err := ctrl.RecommendForUser(ctx, "u5", "book", 0); !errors.Is(err, ErrUnknownRecordType) {
+		t.Errorf("unknown record type: got %v, want %v", err, ErrUnknownRecordType)
+	}
+
+	sims, err := ctrl.SimilarItems(ctx, "a", ratingmodel.RecordTypeMovie, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sims) != 1 || sims[0].SimilarID != "b" || sims[0].CoRatings != 4 || !sims[0].BuiltAt.Equal(builtAt) {
+		t.Errorf("similar to a = %+v, want b rated by 4 users", sims)
+	}
+	if sims, err := ctrl.SimilarItems(ctx, "e", ratingmodel.RecordTypeMovie, 0); err != nil || len(sims) != 0 {
+		t.Errorf("similar to a hidden rating's record = %+v, %v, want none", sims, err)
+	}
+}
+
+func TestRefreshModel(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	for _, user := range []ratingmodel.UserID{"u1", "u2", "u3"} {
+		repo.PutRating(ratingmodel.Rating{RecordID: "a", RecordType: ratingmodel.RecordTypeMovie, UserID: user, Value: 5})
+		repo.PutRating(ratingmodel.Rating{RecordID: "b", RecordType: ratingmodel.RecordTypeMovie, UserID: user, Value: 5})
+	}
+	repo.PutRating(ratingmodel.Rating{RecordID: "c", RecordType: ratingmodel.RecordTypeMovie, UserID: "u1", Value: 1})
+	ctrl := New(repo)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctrl.now = func() time.Time { return now }
+
+	if built, err := ctrl.RefreshModel(ctx, time.Hour); err != nil || !built {
+		t.Fatalf("refresh of an empty model: got %v, %v, want built", built, err)
+	}
+	now = now.Add(30 * time.Minute)
+	if built, err := ctrl.RefreshModel(ctx, time.Hour); err != nil || built {
+		t.Errorf("refresh of a fresh model: got %v, %v, want skipped", built, err)
+	}
+
+	// A build in progress, by another instance, is not raced.
+	unlock, ok, err := repo.LockModel(ctx)
+	if err != nil || !ok {
+		t.Fatalf("lock: got %v, %v", ok, err)
+	}
+	if _, err := ctrl.BuildModel(ctx); !errors.Is(err, ErrBuildInProgress) {
+		t.Errorf("build while locked: got %v, want %v", err, ErrBuildInProgress)
+	}
+	now = now.Add(time.Hour)
+	if built, err := ctrl.RefreshModel(ctx, time.Hour); err != nil || built {
+		t.Errorf("refresh while locked: got %v, %v, want skipped", built, err)
+	}
+	unlock()
+	if built, err := ctrl.RefreshModel(ctx, time.Hour); err != nil || !built {
+		t.Errorf("refresh of a stale model: got %v, %v, want built", built, err)
+	}
+}
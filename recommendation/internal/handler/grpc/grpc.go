@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/phongld0308/movie-example/gen"
+	"github.com/phongld0308/movie-example/pkg/errs"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+	"github.com/phongld0308/movie-example/recommendation/internal/controller/recommendation"
+	model "github.com/phongld0308/movie-example/recommendation/pkg/model"
+)
+
+// Handler defines a gRPC API handler. Requests are expected
+// to be validated by the validation interceptor.
+type Handler struct {
+	gen.UnimplementedRecommendationServiceServer
+	ctrl *recommendation.Controller
+}
+
+// New creates a gRPC API handler
+func New(ctrl *recommendation.Controller) *Handler {
+	return &Handler{ctrl: ctrl}
+}
+
+// RecommendForUser returns the records a user is predicted to
+// rate best among those they have not rated.
+func (h *Handler) RecommendForUser(ctx context.Context, req *gen.RecommendForUserRequest) (*gen.RecommendForUserResponse, error) {
+	recs, err := h.ctrl.RecommendForUser(ctx, ratingmodel.UserID(req.UserId), ratingmodel.RecordType(req.RecordType), int(req.Limit))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.RecommendForUserResponse{}
+	for i := range recs {
+		resp.Recommendations = append(resp.Recommendations, model.RecommendationToProto(&recs[i]))
+	}
+	return resp, nil
+}
+
+// SimilarItems returns the records that users rated most like
+// a record.
+func (h *Handler) SimilarItems(ctx context.Context, req *gen.SimilarItemsRequest) (*gen.SimilarItemsResponse, error) {
+	sims, err := h.ctrl.SimilarItems(ctx, ratingmodel.RecordID(req.RecordId), ratingmodel.RecordType(req.RecordType), int(req.Limit))
+	if err != nil {
+		return nil, errs.ToGRPC(err)
+	}
+	resp := &gen.SimilarItemsResponse{}
+	for i := range sims {
+		resp.Items = append(resp.Items, model.SimilarItemToProto(&sims[i]))
+	}
+	return resp, nil
+}
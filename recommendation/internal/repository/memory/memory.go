@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+	"github.com/phongld0308/movie-example/recommendation/internal/similarity"
+	model "github.com/phongld0308/movie-example/recommendation/pkg/model"
+)
+
+// Repository defines a memory recommendation repository.
+type Repository struct {
+	sync.RWMutex
+	ratings      []ratingmodel.Rating
+	similarities map[record][]model.Similarity
+	// building is held while the similarity model is built.
+	building sync.Mutex
+}
+
+type record struct {
+	id  ratingmodel.RecordID
+	typ ratingmodel.RecordType
+}
+
+// New creates a new memory repository.
+func New() *Repository {
+	return &Repository{similarities: map[record][]model.Similarity{}}
+}
+
+// PutRating adds a rating, replacing an earlier rating by the
+// same user for the same record. The ratings are kept by the
+// rating service in other repositories.
+func (r *Repository) PutRating(rating ratingmodel.Rating) {
+	r.Lock()
+	defer r.Unlock()
+	for i, old := range r.ratings {
+		if old.RecordID == rating.RecordID && old.RecordType == rating.RecordType && old.UserID == rating.UserID {
+			r.ratings[i] = rating
+			return
+		}
+	}
+	r.ratings = append(r.ratings, rating)
+}
+
+// EachUserRatings calls fn with the public ratings of each
+// user in turn, most recent first. fn errors stop the
+// iteration.
+func (r *Repository) EachUserRatings(ctx context.Context, fn func(ratings []ratingmodel.Rating) error) error {
+	r.RLock()
+	users := map[ratingmodel.UserID][]ratingmodel.Rating{}
+	var ids []ratingmodel.UserID
+	for _, rating := range r.ratings {
+		if !rating.Moderation.Status.Public() {
+			continue
+		}
+		if users[rating.UserID] == nil {
+			ids = append(ids, rating.UserID)
+		}
+		users[rating.UserID] = append(users[rating.UserID], rating)
+	}
+	r.RUnlock()
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		ratings := users[id]
+		sort.SliceStable(ratings, func(i, j int) bool { return ratings[i].UpdatedAt.After(ratings[j].UpdatedAt) })
+		if err := fn(ratings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UserRatings returns the public ratings of a user.
+func (r *Repository) UserRatings(ctx context.Context, userID ratingmodel.UserID) ([]ratingmodel.Rating, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var res []ratingmodel.Rating
+	for _, rating := range r.ratings {
+		if rating.UserID == userID && rating.Moderation.Status.Public() {
+			res = append(res, rating)
+		}
+	}
+	return res, nil
+}
+
+// LockModel takes the lock held while the similarity model is
+// built and returns its release. It reports false if another
+// build holds it.
+func (r *Repository) LockModel(ctx context.Context) (func(), bool, error) {
+	if !r.building.TryLock() {
+		return nil, false, nil
+	}
+	return r.building.Unlock, true, nil
+}
+
+// ModelBuiltAt returns when the similarity model was built,
+// or the zero time if it is empty.
+func (r *Repository) ModelBuiltAt(ctx context.Context) (time.Time, error) {
+	r.RLock()
+	defer r.RUnlock()
+	var builtAt time.Time
+	for _, sims := range r.similarities {
+		for _, sim := range sims {
+			if sim.BuiltAt.After(builtAt) {
+				builtAt = sim.BuiltAt
+			}
+		}
+	}
+	return builtAt, nil
+}
+
+// ReplaceSimilarities replaces the similarity model.
+func (r *Repository) ReplaceSimilarities(ctx context.Context, sims []model.Similarity) error {
+	r.Lock()
+	defer r.Unlock()
+	r.similarities = map[record][]model.Similarity{}
+	for _, sim := range sims {
+		rec := record{sim.RecordID, sim.RecordType}
+		r.similarities[rec] = append(r.similarities[rec], sim)
+	}
+	for _, list := range r.similarities {
+		sort.Slice(list, func(i, j int) bool { return similarity.Less(&list[i], &list[j]) })
+	}
+	return nil
+}
+
+// ListSimilarities returns the records similar to the given
+// records of a type, by record, most similar first.
+func (r *Repository) ListSimilarities(ctx context.Context, recordType ratingmodel.RecordType, recordIDs []ratingmodel.RecordID) ([]model.Similarity, error) {
+	r.RLock()
+	defer r.RUnlock()
+	ids := append([]ratingmodel.RecordID(nil), recordIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var res []model.Similarity
+	for i, id := range ids {
+		if i > 0 && ids[i-1] == id {
+			continue
+		}
+		res = append(res, r.similarities[record{id, recordType}]...)
+	}
+	return res, nil
+}
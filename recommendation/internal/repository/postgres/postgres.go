@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+	model "github.com/phongld0308/movie-example/recommendation/pkg/model"
+)
+
+// Repository defines a PostgreSQL-based recommendation
+// repository. It reads the ratings kept by the rating service
+// and keeps the similarity model.
+type Repository struct {
+	db *sql.DB
+}
+
+// New creates a new PostgreSQL-based repository.
+func New(host string, port int, user, password, dbname string) (*Repository, error) {
+	// Build PostgreSQL connection string
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		host, port, user, password, dbname,
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	return &Repository{db}, nil
+}
+
+// publicStatus matches the ratings that are aggregated by the
+// rating service, as ratingmodel.ModerationStatus.Public.
+const publicStatus = "status IN ('visible', 'pending')"
+
+// EachUserRatings calls fn with the public ratings of each
+// user in turn, most recent first. The ratings are streamed
+// rather than loaded all at once, and fn errors stop the
+// iteration.
+func (r *Repository) EachUserRatings(ctx context.Context, fn func(ratings []ratingmodel.Rating) error) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT record_id, record_type, user_id, value::int, COALESCE(updated_at, created_at)
+		 FROM ratings WHERE `+publicStatus+`
+		 ORDER BY user_id, COALESCE(updated_at, created_at) DESC`)
+	if err != nil {
+		return fmt.Errorf("failed to query ratings: %v", err)
+	}
+	defer rows.Close()
+
+	var ratings []ratingmodel.Rating
+	for rows.Next() {
+		var rating ratingmodel.Rating
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&rating.RecordID, &rating.RecordType, &rating.UserID, &rating.Value, &updatedAt); err != nil {
+			return fmt.Errorf("failed to scan rating: %v", err)
+		}
+		rating.UpdatedAt = updatedAt.Time
+		if len(ratings) > 0 && ratings[0].UserID != rating.UserID {
+			if err := fn(ratings); err != nil {
+				return err
+			}
+			ratings = nil
+		}
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating ratings: %v", err)
+	}
+	if len(ratings) > 0 {
+		return fn(ratings)
+	}
+	return nil
+}
+
+// UserRatings returns the public ratings of a user.
+func (r *Repository) UserRatings(ctx context.Context, userID ratingmodel.UserID) ([]ratingmodel.Rating, error) {
+	return r.queryRatings(ctx,
+		"SELECT record_id, record_type, user_id, value::int FROM ratings WHERE user_id = $1 AND "+publicStatus,
+		userID,
+	)
+}
+
+func (r *Repository) queryRatings(ctx context.Context, query string, args ...any) ([]ratingmodel.Rating, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ratings: %v", err)
+	}
+	defer rows.Close()
+
+	var ratings []ratingmodel.Rating
+	for rows.Next() {
+		var rating ratingmodel.Rating
+		if err := rows.Scan(&rating.RecordID, &rating.RecordType, &rating.UserID, &rating.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %v", err)
+		}
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ratings: %v", err)
+	}
+	return ratings, nil
+}
+
+// LockModel takes the lock held while the similarity model is
+// built, shared by every instance of the service, and returns
+// its release. It reports false if another build holds it.
+func (r *Repository) LockModel(ctx context.Context) (func(), bool, error) {
+	// Session advisory locks are held by a connection, which
+	// is kept out of the pool until the lock is released.
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection: %v", err)
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext('item_similarities'))").Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to lock the similarity model: %v", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext('item_similarities'))"); err != nil {
+			log.Printf("Failed to unlock the similarity model: %v\n", err)
+		}
+		conn.Close()
+	}, true, nil
+}
+
+// ModelBuiltAt returns when the similarity model was built,
+// or the zero time if it is empty.
+func (r *Repository) ModelBuiltAt(ctx context.Context) (time.Time, error) {
+	var builtAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, "SELECT MAX(built_at) FROM item_similarities").Scan(&builtAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to query the similarity model: %v", err)
+	}
+	return builtAt.Time, nil
+}
+
+// ReplaceSimilarities replaces the similarity model in a
+// single transaction, so that readers see either model whole.
+// Concurrent replacements wait on a lock of the table that
+// readers do not wait on, rather than mixing their models.
+func (r *Repository) ReplaceSimilarities(ctx context.Context, sims []model.Similarity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE item_similarities IN EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock similarities: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM item_similarities"); err != nil {
+		return fmt.Errorf("failed to delete similarities: %v", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("item_similarities",
+		"record_type", "record_id", "similar_id", "score", "co_ratings", "built_at"))
+	if err != nil {
+		return fmt.Errorf("failed to prepare similarities copy: %v", err)
+	}
+	defer stmt.Close()
+	for _, sim := range sims {
+		if _, err := stmt.ExecContext(ctx, sim.RecordType, sim.RecordID, sim.SimilarID, sim.Score, sim.CoRatings, sim.BuiltAt); err != nil {
+			return fmt.Errorf("failed to copy similarity: %v", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to copy similarities: %v", err)
+	}
+	return tx.Commit()
+}
+
+// ListSimilarities returns the records similar to the given
+// records of a type, by record, most similar first.
+func (r *Repository) ListSimilarities(ctx context.Context, recordType ratingmodel.RecordType, recordIDs []ratingmodel.RecordID) ([]model.Similarity, error) {
+	ids := make([]string, len(recordIDs))
+	for i, id := range recordIDs {
+		ids[i] = string(id)
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT record_id, similar_id, score, co_ratings, built_at
+		 FROM item_similarities
+		 WHERE record_type = $1 AND record_id = ANY($2)
+		 ORDER BY record_id, score DESC, co_ratings DESC, similar_id`,
+		recordType, pq.Array(ids),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similarities: %v", err)
+	}
+	defer rows.Close()
+
+	var sims []model.Similarity
+	for rows.Next() {
+		sim := model.Similarity{RecordType: recordType}
+		if err := rows.Scan(&sim.RecordID, &sim.SimilarID, &sim.Score, &sim.CoRatings, &sim.BuiltAt); err != nil {
+			return nil, fmt.Errorf("failed to scan similarity: %v", err)
+		}
+		sims = append(sims, sim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating similarities: %v", err)
+	}
+	return sims, nil
+}
+
+// Close closes the database connection.
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,251 @@
+// Package similarity builds item-item similarity models from
+// user ratings.
+package similarity
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+	model "github.com/phongld0308/movie-example/recommendation/pkg/model"
+)
+
+// Config defines how a similarity model is built.
+type Config struct {
+	// Neighbors is the number of most similar records kept
+	// for each record.
+	Neighbors int
+	// MinCoRatings is the number of users who must have rated
+	// both records for them to be compared. Fewer co-ratings
+	// give similarities that are mostly noise.
+	MinCoRatings int64
+	// MaxUserRatings bounds the ratings of a user that are
+	// compared to their most recent ones, as a user with k
+	// ratings contributes k² pairs. Zero compares them all.
+	MaxUserRatings int
+	// Partitions is the number of passes over the ratings a
+	// model is built in, each building the similarities of a
+	// share of the records, so that only about 2/Partitions
+	// of the pairs of records are held at once. Zero or one
+	// builds it in a single pass.
+	Partitions int
+}
+
+// DefaultConfig returns the default model config.
+func DefaultConfig() Config {
+	return Config{Neighbors: 50, MinCoRatings: 3, MaxUserRatings: 200, Partitions: 4}
+}
+
+type item struct {
+	id  ratingmodel.RecordID
+	typ ratingmodel.RecordType
+}
+
+type pair struct {
+	a, b int
+}
+
+// pairSums accumulates the adjusted cosine of a pair of
+// records over the users who rated both.
+type pairSums struct {
+	dot, sqA, sqB float64
+	n             int64
+}
+
+// Builder builds a similarity model from the ratings of one
+// user at a time, so that the ratings need not be held in
+// memory all at once.
+type Builder struct {
+	cfg   Config
+	items map[item]int
+	keys  []item
+	// mine reports whether each record is in the partition
+	// built.
+	mine []bool
+	sums map[pair]pairSums
+
+	part, parts int
+}
+
+// NewBuilder creates a new similarity model builder for all
+// the records, in a single pass.
+func NewBuilder(cfg Config) *Builder {
+	return newBuilder(cfg, 0, 1)
+}
+
+// newBuilder creates a builder of the similarities of the
+// records in partition part of parts. It compares the pairs
+// of records with at least one in the partition.
+func newBuilder(cfg Config, part, parts int) *Builder {
+	return &Builder{cfg: cfg, items: map[item]int{}, sums: map[pair]pairSums{}, part: part, parts: parts}
+}
+
+// AddUser adds the ratings of a user, all of them at once.
+// Their mean is taken over all of them, but only the
+// cfg.MaxUserRatings most recent ones are compared.
+func (b *Builder) AddUser(ratings []ratingmodel.Rating) {
+	if len(ratings) == 0 {
+		return
+	}
+	var sum float64
+	for _, r := range ratings {
+		sum += float64(r.Value)
+	}
+	mean := sum / float64(len(ratings))
+	if b.cfg.MaxUserRatings > 0 && len(ratings) > b.cfg.MaxUserRatings {
+		ratings = slices.Clone(ratings)
+		sort.SliceStable(ratings, func(i, j int) bool { return ratings[i].UpdatedAt.After(ratings[j].UpdatedAt) })
+		ratings = ratings[:b.cfg.MaxUserRatings]
+	}
+
+	rated := make([]int, len(ratings))
+	for x, r := range ratings {
+		rated[x] = b.item(item{r.RecordID, r.RecordType})
+	}
+	for x := range ratings {
+		for y := x + 1; y < len(ratings); y++ {
+			i, j := rated[x], rated[y]
+			if i == j || b.keys[i].typ != b.keys[j].typ || !b.mine[i] && !b.mine[j] {
+				continue
+			}
+			di, dj := float64(ratings[x].Value)-mean, float64(ratings[y].Value)-mean
+			if i > j {
+				i, j, di, dj = j, i, dj, di
+			}
+			s := b.sums[pair{i, j}]
+			s.dot += di * dj
+			s.sqA += di * di
+			s.sqB += dj * dj
+			s.n++
+			b.sums[pair{i, j}] = s
+		}
+	}
+}
+
+// item returns the index of a record, adding it if needed.
+func (b *Builder) item(it item) int {
+	i, ok := b.items[it]
+	if !ok {
+		i = len(b.keys)
+		b.items[it] = i
+		b.keys = append(b.keys, it)
+		b.mine = append(b.mine, b.parts <= 1 || partition(it, b.parts) == b.part)
+	}
+	return i
+}
+
+// partition returns the partition of parts a record is in.
+func partition(it item, parts int) int {
+	h := fnv.New32a()
+	h.Write([]byte(it.typ))
+	h.Write([]byte{0})
+	h.Write([]byte(it.id))
+	return int(h.Sum32() % uint32(parts))
+}
+
+// Build returns the similarities of the records rated by the
+// users added, at most cfg.Neighbors of them per record, most
+// similar first. Records are compared with the records of the
+// same type only. The similarity is the adjusted cosine: the
+// cosine of the ratings of the users who rated both records,
+// less the mean rating of each user, so that generous and
+// harsh users compare alike. Only positive similarities are
+// kept.
+func (b *Builder) Build(now time.Time) []model.Similarity {
+	neighbors := map[int][]model.Similarity{}
+	for p, s := range b.sums {
+		if s.n < b.cfg.MinCoRatings || s.sqA == 0 || s.sqB == 0 {
+			continue
+		}
+		score := s.dot / (math.Sqrt(s.sqA) * math.Sqrt(s.sqB))
+		if score <= 0 {
+			continue
+		}
+		x, y := b.keys[p.a], b.keys[p.b]
+		if b.mine[p.a] {
+			neighbors[p.a] = append(neighbors[p.a], model.Similarity{RecordID: x.id, RecordType: x.typ, SimilarID: y.id, Score: score, CoRatings: s.n, BuiltAt: now})
+		}
+		if b.mine[p.b] {
+			neighbors[p.b] = append(neighbors[p.b], model.Similarity{RecordID: y.id, RecordType: y.typ, SimilarID: x.id, Score: score, CoRatings: s.n, BuiltAt: now})
+		}
+	}
+
+	var res []model.Similarity
+	for _, sims := range neighbors {
+		sort.Slice(sims, func(i, j int) bool { return Less(&sims[i], &sims[j]) })
+		if len(sims) > b.cfg.Neighbors {
+			sims = sims[:b.cfg.Neighbors]
+		}
+		res = append(res, sims...)
+	}
+	sortSimilarities(res)
+	return res
+}
+
+// BuildEach builds the similarities of the records rated by
+// the users each streams the ratings of, one user at a time,
+// in cfg.Partitions passes over them.
+func BuildEach(cfg Config, now time.Time, each func(fn func(ratings []ratingmodel.Rating) error) error) ([]model.Similarity, error) {
+	parts := max(cfg.Partitions, 1)
+	var res []model.Similarity
+	for part := 0; part < parts; part++ {
+		b := newBuilder(cfg, part, parts)
+		err := each(func(ratings []ratingmodel.Rating) error {
+			b.AddUser(ratings)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, b.Build(now)...)
+	}
+	sortSimilarities(res)
+	return res, nil
+}
+
+// Build returns the similarities of the records of the
+// ratings, as BuildEach over the ratings of each user.
+func Build(ratings []ratingmodel.Rating, cfg Config, now time.Time) []model.Similarity {
+	users := map[ratingmodel.UserID][]ratingmodel.Rating{}
+	for _, r := range ratings {
+		users[r.UserID] = append(users[r.UserID], r)
+	}
+	res, _ := BuildEach(cfg, now, func(fn func(ratings []ratingmodel.Rating) error) error {
+		for _, rated := range users {
+			if err := fn(rated); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return res
+}
+
+// sortSimilarities sorts similarities by record, then by rank.
+func sortSimilarities(sims []model.Similarity) {
+	sort.Slice(sims, func(i, j int) bool {
+		if sims[i].RecordType != sims[j].RecordType {
+			return sims[i].RecordType < sims[j].RecordType
+		}
+		if sims[i].RecordID != sims[j].RecordID {
+			return sims[i].RecordID < sims[j].RecordID
+		}
+		return Less(&sims[i], &sims[j])
+	})
+}
+
+// Less reports whether the similar record of a ranks before
+// that of b: by decreasing score, then decreasing co-ratings,
+// then ID.
+func Less(a, b *model.Similarity) bool {
+	switch {
+	case a.Score != b.Score:
+		return a.Score > b.Score
+	case a.CoRatings != b.CoRatings:
+		return a.CoRatings > b.CoRatings
+	}
+	return a.SimilarID < b.SimilarID
+}
@@ -0,0 +1,147 @@
+package similarity
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+	model "github.com/phongld0308/movie-example/recommendation/pkg/model"
+)
+
+func ratings(typ ratingmodel.RecordType, byUser map[ratingmodel.UserID]map[ratingmodel.RecordID]ratingmodel.RatingValue) []ratingmodel.Rating {
+	var res []ratingmodel.Rating
+	for user, values := range byUser {
+		for id, v := range values {
+			res = append(res, ratingmodel.Rating{RecordID: id, RecordType: typ, UserID: user, Value: v})
+		}
+	}
+	return res
+}
+
+func format(sims []model.Similarity) string {
+	var s string
+	for _, sim := range sims {
+		s += fmt.Sprintf("%s/%s~%s:%.3f/%d ", sim.RecordType, sim.RecordID, sim.SimilarID, sim.Score, sim.CoRatings)
+	}
+	return s
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	// a and b are liked by the same users, c by the others. d
+	// is rated by too few users to be compared.
+	rs := ratings(ratingmodel.RecordTypeMovie, map[ratingmodel.UserID]map[ratingmodel.RecordID]ratingmodel.RatingValue{
+		"u1": {"a": 5, "b": 5, "c": 1, "d": 5},
+		"u2": {"a": 4, "b": 5, "c": 2, "d": 4},
+		"u3": {"a": 1, "b": 2, "c": 5},
+		"u4": {"a": 2, "b": 1, "c": 4},
+	})
+	// Series are not compared with movies, even when rated by
+	// the same users.
+	rs = append(rs, ratings(ratingmodel.RecordTypeSeries, map[ratingmodel.UserID]map[ratingmodel.RecordID]ratingmodel.RatingValue{
+		"u1": {"a": 5},
+		"u2": {"a": 4},
+		"u3": {"a": 1},
+	})...)
+
+	sims := Build(rs, Config{Neighbors: 10, MinCoRatings: 3}, now)
+	if got, want := format(sims), "movie/a~b:0.539/4 movie/b~a:0.539/4 "; got != want {
+		t.Errorf("similarities = %s, want %s", got, want)
+	}
+	for _, sim := range sims {
+		if !sim.BuiltAt.Equal(now) {
+			t.Errorf("%s built at %v, want %v", sim.RecordID, sim.BuiltAt, now)
+		}
+	}
+
+	sims = Build(rs, Config{Neighbors: 1, MinCoRatings: 2}, now)
+	for _, sim := range sims {
+		if sim.RecordID == "d" && sim.SimilarID != "a" {
+			t.Errorf("most similar to d = %s, want a", sim.SimilarID)
+		}
+		if sim.Score <= 0 || sim.Score > 1+1e-9 || math.IsNaN(sim.Score) {
+			t.Errorf("%s~%s: score %v out of range", sim.RecordID, sim.SimilarID, sim.Score)
+		}
+	}
+	perRecord := map[ratingmodel.RecordID]int{}
+	for _, sim := range sims {
+		perRecord[sim.RecordID]++
+	}
+	for id, n := range perRecord {
+		if n > 1 {
+			t.Errorf("%s has %d neighbors, want at most 1", id, n)
+		}
+	}
+}
+
+func TestMaxUserRatings(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuilder(Config{Neighbors: 10, MinCoRatings: 1, MaxUserRatings: 2})
+	// Only the two most recent ratings of u1, of a and b, are
+	// compared, though c is rated like them.
+	b.AddUser([]ratingmodel.Rating{
+		{RecordID: "c", RecordType: ratingmodel.RecordTypeMovie, UserID: "u1", Value: 5, UpdatedAt: now.Add(-2 * time.Hour)},
+		{RecordID: "a", RecordType: ratingmodel.RecordTypeMovie, UserID: "u1", Value: 5, UpdatedAt: now},
+		{RecordID: "b", RecordType: ratingmodel.RecordTypeMovie, UserID: "u1", Value: 5, UpdatedAt: now.Add(-time.Hour)},
+		{RecordID: "d", RecordType: ratingmodel.RecordTypeMovie, UserID: "u1", Value: 1, UpdatedAt: now.Add(-3 * time.Hour)},
+	})
+	sims := b.Build(now)
+	if got, want := format(sims), "movie/a~b:1.000/1 movie/b~a:1.000/1 "; got != want {
+		t.Errorf("similarities = %s, want %s", got, want)
+	}
+}
+
+// Building in partitions gives the model of a single pass,
+// holding only the pairs of a share of the records at a time.
+func TestPartitions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	byUser := map[ratingmodel.UserID]map[ratingmodel.RecordID]ratingmodel.RatingValue{}
+	for u := 0; u < 20; u++ {
+		values := map[ratingmodel.RecordID]ratingmodel.RatingValue{}
+		for r := u % 3; r < 30; r += 1 + u%4 {
+			values[ratingmodel.RecordID(fmt.Sprint("r", r))] = ratingmodel.RatingValue(1 + (u*u+3*r*r+u*r)%5)
+		}
+		byUser[ratingmodel.UserID(fmt.Sprint("u", u))] = values
+	}
+	rs := ratings(ratingmodel.RecordTypeMovie, byUser)
+
+	// Scores are compared within rounding, as the ratings of
+	// users are summed in no particular order.
+	scores := func(sims []model.Similarity) map[[2]ratingmodel.RecordID]float64 {
+		res := map[[2]ratingmodel.RecordID]float64{}
+		for _, sim := range sims {
+			res[[2]ratingmodel.RecordID{sim.RecordID, sim.SimilarID}] = sim.Score
+		}
+		return res
+	}
+	cfg := Config{Neighbors: 30, MinCoRatings: 2}
+	want := scores(Build(rs, cfg, now))
+	if len(want) == 0 {
+		t.Fatal("no similarities built")
+	}
+	cfg.Partitions = 3
+	got := scores(Build(rs, cfg, now))
+	if len(got) != len(want) {
+		t.Errorf("%d similarities in partitions, want %d", len(got), len(want))
+	}
+	for k, score := range want {
+		if math.Abs(got[k]-score) > 1e-9 {
+			t.Errorf("%s~%s: score in partitions %v, want %v", k[0], k[1], got[k], score)
+		}
+	}
+
+	all, part := NewBuilder(cfg), newBuilder(cfg, 0, cfg.Partitions)
+	for _, values := range byUser {
+		var user []ratingmodel.Rating
+		for id, v := range values {
+			user = append(user, ratingmodel.Rating{RecordID: id, RecordType: ratingmodel.RecordTypeMovie, Value: v})
+		}
+		all.AddUser(user)
+		part.AddUser(user)
+	}
+	if len(part.sums) >= len(all.sums) {
+		t.Errorf("partition holds %d pairs, want fewer than %d", len(part.sums), len(all.sums))
+	}
+}
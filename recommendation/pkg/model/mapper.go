@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/phongld0308/movie-example/gen"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RecommendationToProto converts a Recommendation struct into
+// a generated proto counterpart.
+func RecommendationToProto(r *Recommendation) *gen.Recommendation {
+	p := &gen.Recommendation{
+		RecordId:   string(r.RecordID),
+		RecordType: string(r.RecordType),
+		Score:      r.Score,
+	}
+	for _, id := range r.Because {
+		p.Because = append(p.Because, string(id))
+	}
+	return p
+}
+
+// SimilarItemToProto converts the similar record of a
+// Similarity struct into a generated proto counterpart.
+func SimilarItemToProto(s *Similarity) *gen.SimilarItem {
+	return &gen.SimilarItem{
+		RecordId:   string(s.SimilarID),
+		RecordType: string(s.RecordType),
+		Score:      s.Score,
+		CoRatings:  s.CoRatings,
+		BuiltAt:    timestamppb.New(s.BuiltAt),
+	}
+}
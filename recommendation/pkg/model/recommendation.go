@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	ratingmodel "github.com/phongld0308/movie-example/rating/pkg/model"
+)
+
+// Similarity defines how similar a record is to another of
+// the same type, judged by the ratings of the users who rated
+// both.
+type Similarity struct {
+	RecordID   ratingmodel.RecordID   `json:"recordId"`
+	RecordType ratingmodel.RecordType `json:"recordType"`
+	SimilarID  ratingmodel.RecordID   `json:"similarId"`
+	// Score is the adjusted cosine similarity of the ratings,
+	// from -1 to 1.
+	Score float64 `json:"score"`
+	// CoRatings is the number of users who rated both records.
+	CoRatings int64 `json:"coRatings"`
+	// BuiltAt is when the similarity model was built.
+	BuiltAt time.Time `json:"builtAt"`
+}
+
+// Recommendation defines a record recommended to a user.
+type Recommendation struct {
+	RecordID   ratingmodel.RecordID   `json:"recordId"`
+	RecordType ratingmodel.RecordType `json:"recordType"`
+	// Score is the rating the user is predicted to give.
+	Score float64 `json:"score"`
+	// Because lists the records rated by the user that the
+	// recommendation is most similar to, most similar first.
+	Because []ratingmodel.RecordID `json:"because"`
+}
@@ -0,0 +1,2 @@
+-- Add optimistic concurrency versions to movie metadata.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+-- Indexes backing the movie catalog listing: keyset pages
+-- ordered by title, title prefix search and director filters.
+CREATE INDEX IF NOT EXISTS idx_movies_title_id ON movies(title, id);
+CREATE INDEX IF NOT EXISTS idx_movies_title_pattern ON movies(title text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_movies_director ON movies(director, title, id);
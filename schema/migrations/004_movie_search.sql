@@ -0,0 +1,9 @@
+-- Full-text search over movie metadata. Titles rank above
+-- directors, which rank above descriptions.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(director, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_movies_search ON movies USING GIN (search_vector);
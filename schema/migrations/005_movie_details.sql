@@ -0,0 +1,13 @@
+-- Release, runtime, language, artwork and content rating
+-- details of movies. Existing rows keep their data and get
+-- empty details.
+ALTER TABLE movies
+    ADD COLUMN IF NOT EXISTS release_date DATE,
+    ADD COLUMN IF NOT EXISTS runtime_minutes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS genres TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS original_language VARCHAR(35) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS spoken_languages TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS poster_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS backdrop_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS content_rating VARCHAR(16) NOT NULL DEFAULT '';
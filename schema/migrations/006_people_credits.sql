@@ -0,0 +1,26 @@
+-- People and their credits on movies.
+CREATE TABLE IF NOT EXISTS people (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    birth_date DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS credits (
+    movie_id VARCHAR(255) NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    person_id VARCHAR(255) NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('director', 'writer', 'actor')),
+    character_name VARCHAR(255) NOT NULL DEFAULT '',
+    billing_order INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (movie_id, person_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_credits_person ON credits(person_id);
+
+DROP TRIGGER IF EXISTS update_people_updated_at ON people;
+CREATE TRIGGER update_people_updated_at
+    BEFORE UPDATE ON people
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
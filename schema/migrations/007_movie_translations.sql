@@ -0,0 +1,8 @@
+-- Per-locale titles and descriptions of movies.
+CREATE TABLE IF NOT EXISTS movie_translations (
+    movie_id VARCHAR(255) NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    locale VARCHAR(35) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (movie_id, locale)
+);
@@ -0,0 +1,25 @@
+-- Series, seasons and episodes share the movies table.
+-- Seasons point to their series and episodes to their
+-- season through parent_id.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS record_type VARCHAR(16) NOT NULL DEFAULT 'movie';
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS parent_id VARCHAR(255) REFERENCES movies(id) ON DELETE CASCADE;
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS season_number INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS episode_number INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_record_type_check;
+ALTER TABLE movies ADD CONSTRAINT movies_record_type_check
+    CHECK (record_type IN ('movie', 'series', 'season', 'episode'));
+
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_id_record_type_key CASCADE;
+ALTER TABLE movies ADD CONSTRAINT movies_id_record_type_key UNIQUE (id, record_type);
+
+CREATE INDEX IF NOT EXISTS idx_movies_parent ON movies(parent_id, season_number, episode_number);
+
+-- Ratings now refer to a record of the same type.
+ALTER TABLE ratings DROP CONSTRAINT IF EXISTS fk_movie_ratings;
+ALTER TABLE ratings
+    ADD CONSTRAINT fk_movie_ratings
+    FOREIGN KEY (record_id, record_type)
+    REFERENCES movies(id, record_type)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE;
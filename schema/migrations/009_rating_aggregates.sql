@@ -0,0 +1,26 @@
+-- Running totals of the ratings of each record, updated with
+-- every rating write so that reads do not scan the ratings.
+CREATE TABLE IF NOT EXISTS rating_aggregates (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    rating_sum BIGINT NOT NULL DEFAULT 0,
+    rating_count BIGINT NOT NULL DEFAULT 0,
+    histogram BIGINT[] NOT NULL DEFAULT '{0,0,0,0,0}',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, record_type),
+    FOREIGN KEY (record_id, record_type) REFERENCES movies(id, record_type)
+        ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Backfill the aggregates of existing ratings.
+INSERT INTO rating_aggregates (record_id, record_type, rating_sum, rating_count, histogram)
+SELECT record_id, record_type, SUM(value::int), COUNT(*), ARRAY[
+    COUNT(*) FILTER (WHERE value::int = 1),
+    COUNT(*) FILTER (WHERE value::int = 2),
+    COUNT(*) FILTER (WHERE value::int = 3),
+    COUNT(*) FILTER (WHERE value::int = 4),
+    COUNT(*) FILTER (WHERE value::int = 5)
+]::bigint[]
+FROM ratings
+GROUP BY record_id, record_type
+ON CONFLICT (record_id, record_type) DO NOTHING;
@@ -0,0 +1,7 @@
+-- Trust weights of users for the trust-weighted rating
+-- aggregator. Users without a row get the default weight.
+CREATE TABLE IF NOT EXISTS user_trust (
+    user_id VARCHAR(255) PRIMARY KEY,
+    trust DOUBLE PRECISION NOT NULL CHECK (trust >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
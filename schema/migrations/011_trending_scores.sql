@@ -0,0 +1,14 @@
+-- Trending scores of records per window, recomputed
+-- periodically by the rating service from ratings weighted by
+-- their age. The rating service serves ListTrending from it.
+CREATE TABLE IF NOT EXISTS trending_scores (
+    time_window VARCHAR(16) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    weight DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (time_window, record_type, record_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trending_scores_rank ON trending_scores(time_window, record_type, score DESC, record_id);
@@ -0,0 +1,5 @@
+-- Indexes for top-rated lists: candidates with enough votes
+-- are found by rating count, and lists ranked by the mean
+-- rating are read in index order.
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_votes ON rating_aggregates(record_type, rating_count DESC);
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_mean ON rating_aggregates(record_type, (rating_sum::float8 / rating_count) DESC, record_id) WHERE rating_count > 0;
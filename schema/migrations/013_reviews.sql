@@ -0,0 +1,20 @@
+-- Written reviews of ratings, deleted with their rating.
+CREATE TABLE IF NOT EXISTS reviews (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    title VARCHAR(200) NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '' CHECK (char_length(body) <= 10000),
+    helpful_votes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (record_id, record_type, user_id),
+    FOREIGN KEY (record_id, record_type, user_id) REFERENCES ratings(record_id, record_type, user_id)
+        ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_newest ON reviews(record_id, record_type, created_at DESC, user_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_helpful ON reviews(record_id, record_type, helpful_votes DESC, created_at DESC, user_id);
+
+-- Count the reviews of each record with its rating totals.
+ALTER TABLE rating_aggregates ADD COLUMN IF NOT EXISTS review_count BIGINT NOT NULL DEFAULT 0;
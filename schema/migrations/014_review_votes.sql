@@ -0,0 +1,21 @@
+-- Helpfulness votes on reviews, one per voter, deleted with
+-- the review.
+CREATE TABLE IF NOT EXISTS review_votes (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    author_id VARCHAR(255) NOT NULL,
+    voter_id VARCHAR(255) NOT NULL,
+    helpful BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, record_type, author_id, voter_id),
+    FOREIGN KEY (record_id, record_type, author_id) REFERENCES reviews(record_id, record_type, user_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CHECK (author_id <> voter_id)
+);
+
+-- Rank the most helpful reviews by the Wilson lower bound of
+-- their share of helpful votes instead of the helpful count.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS unhelpful_votes BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS helpful_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS idx_reviews_helpful;
+CREATE INDEX IF NOT EXISTS idx_reviews_helpful_score ON reviews(record_id, record_type, helpful_score DESC, created_at DESC, user_id);
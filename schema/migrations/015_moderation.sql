@@ -0,0 +1,52 @@
+-- Moderation status of ratings and reviews. Hidden and removed
+-- items are left out of aggregates and listings.
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'visible'
+    CHECK (status IN ('visible', 'pending', 'hidden', 'removed'));
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS moderation_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS moderator_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'visible'
+    CHECK (status IN ('visible', 'pending', 'hidden', 'removed'));
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderation_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderator_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS review_reports (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    author_id VARCHAR(255) NOT NULL,
+    reporter_id VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, record_type, author_id, reporter_id),
+    FOREIGN KEY (record_id, record_type, author_id) REFERENCES reviews(record_id, record_type, user_id)
+        ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Audit trail of status changes, kept after the items are
+-- deleted.
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('rating', 'review')),
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    actor_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ratings_moderation ON ratings(status, moderated_at, record_type, record_id, user_id) WHERE status <> 'visible';
+CREATE INDEX IF NOT EXISTS idx_reviews_moderation ON reviews(status, moderated_at, record_type, record_id, user_id) WHERE status <> 'visible';
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_record ON moderation_actions(record_id, record_type, id DESC);
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_user ON moderation_actions(user_id, id DESC);
+
+-- Keep updated_at, which dates ratings for trending scores,
+-- from moving on moderation.
+DROP TRIGGER IF EXISTS update_ratings_updated_at ON ratings;
+CREATE TRIGGER update_ratings_updated_at
+    BEFORE UPDATE OF value ON ratings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
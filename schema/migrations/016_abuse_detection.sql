@@ -0,0 +1,11 @@
+-- Ratings suspected of abuse are held from aggregates and
+-- listings until a moderator reviews them.
+ALTER TABLE ratings DROP CONSTRAINT IF EXISTS ratings_status_check;
+ALTER TABLE ratings ADD CONSTRAINT ratings_status_check
+    CHECK (status IN ('visible', 'pending', 'held', 'hidden', 'removed'));
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_status_check;
+ALTER TABLE reviews ADD CONSTRAINT reviews_status_check
+    CHECK (status IN ('visible', 'pending', 'held', 'hidden', 'removed'));
+
+-- Accounts are dated by their first rating.
+CREATE INDEX IF NOT EXISTS idx_ratings_user ON ratings(user_id, created_at);
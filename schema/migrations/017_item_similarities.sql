@@ -0,0 +1,12 @@
+-- Item-item similarity model of the recommendation service,
+-- rebuilt offline from the ratings. Each record keeps its most
+-- similar records of the same type.
+CREATE TABLE IF NOT EXISTS item_similarities (
+    record_type VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    similar_id VARCHAR(255) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    co_ratings BIGINT NOT NULL,
+    built_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (record_type, record_id, similar_id)
+);
@@ -0,0 +1,6 @@
+-- Indexes for movie listings sorted by rating or vote count: the
+-- listing joins rating_aggregates and orders by the same expressions,
+-- with movies looked up by type and title for the title sort.
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_list_rating ON rating_aggregates(record_type, (COALESCE(rating_sum::float8 / NULLIF(rating_count, 0), 0)), record_id);
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_list_votes ON rating_aggregates(record_type, (COALESCE(rating_count, 0)), record_id);
+CREATE INDEX IF NOT EXISTS idx_movies_type_title ON movies(record_type, title, id);
@@ -0,0 +1,13 @@
+-- Top-rated scores of the record types ranked by an aggregator
+-- other than the mean, recomputed periodically by the rating
+-- service so that top-rated pages are served by an index.
+CREATE TABLE IF NOT EXISTS top_rated_scores (
+    record_type VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    rating DOUBLE PRECISION NOT NULL,
+    rating_count BIGINT NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (record_type, record_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_top_rated_scores_rank ON top_rated_scores(record_type, rating DESC, record_id);
@@ -0,0 +1,38 @@
+-- Recent rating activity screened for abuse, shared by every
+-- instance of the rating service. Rows older than the longest
+-- detection window are deleted as the detector sweeps.
+CREATE TABLE IF NOT EXISTS rating_activity (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    value INTEGER NOT NULL,
+    rated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    new_account BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rating_activity_record ON rating_activity(record_type, record_id, rated_at);
+CREATE INDEX IF NOT EXISTS idx_rating_activity_user ON rating_activity(user_id, rated_at);
+CREATE INDEX IF NOT EXISTS idx_rating_activity_rated_at ON rating_activity(rated_at);
+
+-- Accounts are dated by their first rating, kept even if the
+-- rating was deleted since.
+CREATE TABLE IF NOT EXISTS user_first_ratings (
+    user_id VARCHAR(255) PRIMARY KEY,
+    first_rated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+INSERT INTO user_first_ratings (user_id, first_rated_at)
+SELECT user_id, MIN(created_at) FROM ratings WHERE created_at IS NOT NULL GROUP BY user_id
+ON CONFLICT (user_id) DO NOTHING;
+
+-- Latest rating pattern of each user, matched against the
+-- patterns of other users.
+CREATE TABLE IF NOT EXISTS rating_patterns (
+    user_id VARCHAR(255) PRIMARY KEY,
+    pattern TEXT NOT NULL,
+    shown_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rating_patterns_pattern ON rating_patterns(md5(pattern), shown_at);
+CREATE INDEX IF NOT EXISTS idx_rating_patterns_shown_at ON rating_patterns(shown_at);
@@ -4,8 +4,29 @@ CREATE TABLE IF NOT EXISTS movies (
     title VARCHAR(255) NOT NULL,
     description TEXT,
     director VARCHAR(255),
+    version BIGINT NOT NULL DEFAULT 1,
+    release_date DATE,
+    runtime_minutes INTEGER NOT NULL DEFAULT 0,
+    genres TEXT[] NOT NULL DEFAULT '{}',
+    original_language VARCHAR(35) NOT NULL DEFAULT '',
+    spoken_languages TEXT[] NOT NULL DEFAULT '{}',
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    poster_url TEXT NOT NULL DEFAULT '',
+    backdrop_url TEXT NOT NULL DEFAULT '',
+    content_rating VARCHAR(16) NOT NULL DEFAULT '',
+    record_type VARCHAR(16) NOT NULL DEFAULT 'movie'
+        CHECK (record_type IN ('movie', 'series', 'season', 'episode')),
+    parent_id VARCHAR(255) REFERENCES movies(id) ON DELETE CASCADE,
+    season_number INTEGER NOT NULL DEFAULT 0,
+    episode_number INTEGER NOT NULL DEFAULT 0,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(director, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+    ) STORED,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (id, record_type)
 );
 
 -- Create ratings table with composite primary key
@@ -14,21 +35,237 @@ CREATE TABLE IF NOT EXISTS ratings (
     record_type VARCHAR(255) NOT NULL,
     user_id VARCHAR(255) NOT NULL,
     value NUMERIC(3,2) NOT NULL CHECK (value >= 0 AND value <= 5),
+    status VARCHAR(16) NOT NULL DEFAULT 'visible'
+        CHECK (status IN ('visible', 'pending', 'held', 'hidden', 'removed')),
+    moderation_reason TEXT NOT NULL DEFAULT '',
+    moderator_id VARCHAR(255) NOT NULL DEFAULT '',
+    moderated_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (record_id, record_type, user_id)
 );
 
--- Add foreign key constraint
+-- Add foreign key constraint: ratings refer to a catalog
+-- record of the same type
 ALTER TABLE ratings
     ADD CONSTRAINT fk_movie_ratings
-    FOREIGN KEY (record_id)
-    REFERENCES movies(id)
-    ON DELETE CASCADE;
+    FOREIGN KEY (record_id, record_type)
+    REFERENCES movies(id, record_type)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE;
+
+-- Create rating aggregates table, kept up to date with the
+-- ratings of each record. The histogram counts the ratings
+-- of each value from 1 to 5.
+CREATE TABLE IF NOT EXISTS rating_aggregates (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    rating_sum BIGINT NOT NULL DEFAULT 0,
+    rating_count BIGINT NOT NULL DEFAULT 0,
+    histogram BIGINT[] NOT NULL DEFAULT '{0,0,0,0,0}',
+    review_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, record_type),
+    FOREIGN KEY (record_id, record_type) REFERENCES movies(id, record_type)
+        ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Create reviews table: the optional written explanation of
+-- a rating, deleted with it
+CREATE TABLE IF NOT EXISTS reviews (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    title VARCHAR(200) NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '' CHECK (char_length(body) <= 10000),
+    helpful_votes BIGINT NOT NULL DEFAULT 0,
+    unhelpful_votes BIGINT NOT NULL DEFAULT 0,
+    -- Wilson lower bound of the share of helpful votes
+    helpful_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'visible'
+        CHECK (status IN ('visible', 'pending', 'held', 'hidden', 'removed')),
+    moderation_reason TEXT NOT NULL DEFAULT '',
+    moderator_id VARCHAR(255) NOT NULL DEFAULT '',
+    moderated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (record_id, record_type, user_id),
+    FOREIGN KEY (record_id, record_type, user_id) REFERENCES ratings(record_id, record_type, user_id)
+        ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Create review votes table: whether each voter found a review
+-- helpful, deleted with the review
+CREATE TABLE IF NOT EXISTS review_votes (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    author_id VARCHAR(255) NOT NULL,
+    voter_id VARCHAR(255) NOT NULL,
+    helpful BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, record_type, author_id, voter_id),
+    FOREIGN KEY (record_id, record_type, author_id) REFERENCES reviews(record_id, record_type, user_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CHECK (author_id <> voter_id)
+);
+
+-- Create review reports table: one report of abuse per user
+-- and review, deleted with the review
+CREATE TABLE IF NOT EXISTS review_reports (
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    author_id VARCHAR(255) NOT NULL,
+    reporter_id VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, record_type, author_id, reporter_id),
+    FOREIGN KEY (record_id, record_type, author_id) REFERENCES reviews(record_id, record_type, user_id)
+        ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Create moderation actions table: the append-only audit trail
+-- of rating and review status changes, kept after the items
+-- are deleted
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('rating', 'review')),
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    actor_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create user trust table weighting the ratings of each user
+-- for the trust-weighted aggregator
+CREATE TABLE IF NOT EXISTS user_trust (
+    user_id VARCHAR(255) PRIMARY KEY,
+    trust DOUBLE PRECISION NOT NULL CHECK (trust >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create trending scores table, recomputed periodically by
+-- the rating service from recent ratings
+CREATE TABLE IF NOT EXISTS trending_scores (
+    time_window VARCHAR(16) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    weight DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (time_window, record_type, record_id)
+);
+
+-- Create top-rated scores table, recomputed periodically by
+-- the rating service for types not ranked by their mean rating
+CREATE TABLE IF NOT EXISTS top_rated_scores (
+    record_type VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    rating DOUBLE PRECISION NOT NULL,
+    rating_count BIGINT NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (record_type, record_id)
+);
+
+-- Create rating activity table, the recent ratings screened
+-- for abuse by every instance of the rating service
+CREATE TABLE IF NOT EXISTS rating_activity (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    record_type VARCHAR(255) NOT NULL,
+    value INTEGER NOT NULL,
+    rated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    new_account BOOLEAN NOT NULL
+);
+
+-- Create user first ratings table, dating accounts for abuse
+-- detection
+CREATE TABLE IF NOT EXISTS user_first_ratings (
+    user_id VARCHAR(255) PRIMARY KEY,
+    first_rated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create rating patterns table, the latest rating pattern of
+-- each user for abuse detection
+CREATE TABLE IF NOT EXISTS rating_patterns (
+    user_id VARCHAR(255) PRIMARY KEY,
+    pattern TEXT NOT NULL,
+    shown_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create item similarities table, the model of the
+-- recommendation service rebuilt offline from the ratings
+CREATE TABLE IF NOT EXISTS item_similarities (
+    record_type VARCHAR(255) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    similar_id VARCHAR(255) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    co_ratings BIGINT NOT NULL,
+    built_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (record_type, record_id, similar_id)
+);
+
+-- Create movie translations table
+CREATE TABLE IF NOT EXISTS movie_translations (
+    movie_id VARCHAR(255) NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    locale VARCHAR(35) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (movie_id, locale)
+);
+
+-- Create people table
+CREATE TABLE IF NOT EXISTS people (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    birth_date DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create credits table linking people to movies
+CREATE TABLE IF NOT EXISTS credits (
+    movie_id VARCHAR(255) NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    person_id VARCHAR(255) NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('director', 'writer', 'actor')),
+    character_name VARCHAR(255) NOT NULL DEFAULT '',
+    billing_order INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (movie_id, person_id, role)
+);
 
 -- Create index for faster lookups
 CREATE INDEX IF NOT EXISTS idx_movies_title ON movies(title);
+CREATE INDEX IF NOT EXISTS idx_movies_title_id ON movies(title, id);
+CREATE INDEX IF NOT EXISTS idx_movies_title_pattern ON movies(title text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_movies_director ON movies(director, title, id);
+CREATE INDEX IF NOT EXISTS idx_movies_parent ON movies(parent_id, season_number, episode_number);
+CREATE INDEX IF NOT EXISTS idx_movies_search ON movies USING GIN (search_vector);
 CREATE INDEX IF NOT EXISTS idx_ratings_record ON ratings(record_id, record_type);
+CREATE INDEX IF NOT EXISTS idx_ratings_user ON ratings(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_rating_activity_record ON rating_activity(record_type, record_id, rated_at);
+CREATE INDEX IF NOT EXISTS idx_rating_activity_user ON rating_activity(user_id, rated_at);
+CREATE INDEX IF NOT EXISTS idx_rating_activity_rated_at ON rating_activity(rated_at);
+CREATE INDEX IF NOT EXISTS idx_rating_patterns_pattern ON rating_patterns(md5(pattern), shown_at);
+CREATE INDEX IF NOT EXISTS idx_rating_patterns_shown_at ON rating_patterns(shown_at);
+CREATE INDEX IF NOT EXISTS idx_credits_person ON credits(person_id);
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_votes ON rating_aggregates(record_type, rating_count DESC);
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_mean ON rating_aggregates(record_type, (rating_sum::float8 / rating_count) DESC, record_id) WHERE rating_count > 0;
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_list_rating ON rating_aggregates(record_type, (COALESCE(rating_sum::float8 / NULLIF(rating_count, 0), 0)), record_id);
+CREATE INDEX IF NOT EXISTS idx_rating_aggregates_list_votes ON rating_aggregates(record_type, (COALESCE(rating_count, 0)), record_id);
+CREATE INDEX IF NOT EXISTS idx_movies_type_title ON movies(record_type, title, id);
+CREATE INDEX IF NOT EXISTS idx_trending_scores_rank ON trending_scores(time_window, record_type, score DESC, record_id);
+CREATE INDEX IF NOT EXISTS idx_top_rated_scores_rank ON top_rated_scores(record_type, rating DESC, record_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_newest ON reviews(record_id, record_type, created_at DESC, user_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_helpful_score ON reviews(record_id, record_type, helpful_score DESC, created_at DESC, user_id);
+CREATE INDEX IF NOT EXISTS idx_ratings_moderation ON ratings(status, moderated_at, record_type, record_id, user_id) WHERE status <> 'visible';
+CREATE INDEX IF NOT EXISTS idx_reviews_moderation ON reviews(status, moderated_at, record_type, record_id, user_id) WHERE status <> 'visible';
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_record ON moderation_actions(record_id, record_type, id DESC);
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_user ON moderation_actions(user_id, id DESC);
 
 -- Add update timestamp trigger
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -44,7 +281,26 @@ CREATE TRIGGER update_movies_updated_at
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at_column();
 
+-- Only rating changes move updated_at, which dates ratings for
+-- trending scores; moderation does not.
 CREATE TRIGGER update_ratings_updated_at
-    BEFORE UPDATE ON ratings
+    BEFORE UPDATE OF value ON ratings
     FOR EACH ROW
-    EXECUTE FUNCTION update_updated_at_column(); 
\ No newline at end of file
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_people_updated_at
+    BEFORE UPDATE ON people
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Stored responses of write requests sent with an idempotency key
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(512) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    response BYTEA,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
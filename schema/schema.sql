@@ -1,4 +1,5 @@
-CREATE TABLE IF NOT EXISTS movies (id VARCHAR(255), title VARCHAR(255), description TEXT, director VARCHAR(255));
+CREATE TABLE IF NOT EXISTS movies (id VARCHAR(255), title VARCHAR(255), description TEXT, director VARCHAR(255), version BIGINT NOT NULL DEFAULT 1, release_date DATE, runtime_minutes INT NOT NULL DEFAULT 0, genres JSON, original_language VARCHAR(35) NOT NULL DEFAULT '', spoken_languages JSON, country VARCHAR(2) NOT NULL DEFAULT '', poster_url VARCHAR(2048) NOT NULL DEFAULT '', backdrop_url VARCHAR(2048) NOT NULL DEFAULT '', content_rating VARCHAR(16) NOT NULL DEFAULT '', record_type VARCHAR(16) NOT NULL DEFAULT 'movie', parent_id VARCHAR(255), season_number INT NOT NULL DEFAULT 0, episode_number INT NOT NULL DEFAULT 0, PRIMARY KEY (id), INDEX idx_movies_parent (parent_id, season_number, episode_number));
 
-CREATE TABLE IF NOT EXISTS ratings (record_id VARCHAR(255), record_type VARCHAR(255), user_id VARCHAR(255), value INT);
+CREATE TABLE IF NOT EXISTS ratings (record_id VARCHAR(255), record_type VARCHAR(255), user_id VARCHAR(255), value INT, status VARCHAR(16) NOT NULL DEFAULT 'visible', moderation_reason VARCHAR(1024) NOT NULL DEFAULT '', moderator_id VARCHAR(255) NOT NULL DEFAULT '', moderated_at TIMESTAMP NULL, created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (record_id, record_type, user_id), INDEX idx_ratings_user (user_id, created_at));
 
+CREATE TABLE IF NOT EXISTS rating_aggregates (record_id VARCHAR(255), record_type VARCHAR(255), rating_sum BIGINT NOT NULL DEFAULT 0, rating_count BIGINT NOT NULL DEFAULT 0, histogram JSON NOT NULL, review_count BIGINT NOT NULL DEFAULT 0, updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (record_id, record_type));